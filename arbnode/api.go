@@ -4,6 +4,7 @@
 package arbnode
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/big"
@@ -36,6 +37,12 @@ func (a *BlockValidatorAPI) LatestValidatedBlockHash(ctx context.Context) (commo
 	return hash, nil
 }
 
+// ValidationProgress reports how far validation is behind the head, how fast
+// it's going, and how long recent blocks took to validate.
+func (a *BlockValidatorAPI) ValidationProgress(ctx context.Context) (*validator.ValidationProgress, error) {
+	return a.val.Progress(), nil
+}
+
 type BlockValidatorDebugAPI struct {
 	val        *validator.StatelessBlockValidator
 	blockchain *core.BlockChain
@@ -58,15 +65,9 @@ func (a *BlockValidatorDebugAPI) ValidateBlock(
 	if !a.blockchain.Config().IsArbitrumNitro(header.Number) {
 		return result, types.ErrUseFallback
 	}
-	var moduleRoot common.Hash
-	if moduleRootOptional != nil {
-		moduleRoot = *moduleRootOptional
-	} else {
-		moduleRoots := a.val.GetModuleRootsToValidate()
-		if len(moduleRoots) == 0 {
-			return result, errors.New("no current WasmModuleRoot configured, must provide parameter")
-		}
-		moduleRoot = moduleRoots[0]
+	moduleRoot, err := a.moduleRoot(header, moduleRootOptional)
+	if err != nil {
+		return result, err
 	}
 	start_time := time.Now()
 	valid, err := a.val.ValidateBlock(ctx, header, full, moduleRoot)
@@ -75,6 +76,65 @@ func (a *BlockValidatorDebugAPI) ValidateBlock(
 	return result, err
 }
 
+func (a *BlockValidatorDebugAPI) moduleRoot(header *types.Header, moduleRootOptional *common.Hash) (common.Hash, error) {
+	if moduleRootOptional != nil {
+		return *moduleRootOptional, nil
+	}
+	moduleRoots, err := a.val.ModuleRootsForBlock(header)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(moduleRoots) == 0 {
+		return common.Hash{}, errors.New("no current WasmModuleRoot configured, must provide parameter")
+	}
+	return moduleRoots[0], nil
+}
+
+// ExportBundle returns a validation bundle of the block, which can be re-run
+// with cmd/validate-bundle on any host with the machine for its module root.
+func (a *BlockValidatorDebugAPI) ExportBundle(
+	ctx context.Context, blockNum rpc.BlockNumberOrHash, moduleRootOptional *common.Hash,
+) (hexutil.Bytes, error) {
+	header, err := arbitrum.HeaderByNumberOrHash(a.blockchain, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if !a.blockchain.Config().IsArbitrumNitro(header.Number) {
+		return nil, types.ErrUseFallback
+	}
+	moduleRoot, err := a.moduleRoot(header, moduleRootOptional)
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := a.val.CreateValidationBundle(ctx, header, moduleRoot)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := validator.WriteValidationBundle(&buf, bundle); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type AssertionCheckerAPI struct {
+	checker *validator.AssertionChecker
+}
+
+// CheckAssertion checks whether an assertion building on a node with the
+// given inbox max count is correct according to the local chain.
+func (a *AssertionCheckerAPI) CheckAssertion(
+	ctx context.Context, assertion validator.Assertion, inboxMaxCount hexutil.Uint64,
+) (*validator.AssertionCheck, error) {
+	return a.checker.CheckAssertion(ctx, &assertion, uint64(inboxMaxCount))
+}
+
+// CheckNode checks whether the assertion of an existing rollup node is correct
+// according to the local chain.
+func (a *AssertionCheckerAPI) CheckNode(ctx context.Context, nodeNum hexutil.Uint64) (*validator.AssertionCheck, error) {
+	return a.checker.CheckNode(ctx, uint64(nodeNum))
+}
+
 type ArbAPI struct {
 	txPublisher TransactionPublisher
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/offchainlabs/nitro/arbos"
 	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/das"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/util/arbmath"
@@ -39,7 +40,8 @@ type BatchPoster struct {
 	config              *BatchPosterConfig
 	inboxContract       *bridgegen.SequencerInbox
 	gasRefunder         common.Address
-	transactOpts        *bind.TransactOpts
+	signer              util.Signer
+	l1ChainId           *big.Int // read from the L1 client when first posting
 	building            *buildingBatch
 	pendingMsgTimestamp time.Time
 	lastBatchCount      uint64
@@ -105,7 +107,7 @@ var TestBatchPosterConfig = BatchPosterConfig{
 	GasMarginBasisPoints: 500,
 }
 
-func NewBatchPoster(l1Reader *headerreader.HeaderReader, inbox *InboxTracker, streamer *TransactionStreamer, config *BatchPosterConfig, contractAddress common.Address, transactOpts *bind.TransactOpts, daWriter das.DataAvailabilityServiceWriter) (*BatchPoster, error) {
+func NewBatchPoster(l1Reader *headerreader.HeaderReader, inbox *InboxTracker, streamer *TransactionStreamer, config *BatchPosterConfig, contractAddress common.Address, signer util.Signer, daWriter das.DataAvailabilityServiceWriter) (*BatchPoster, error) {
 	inboxContract, err := bridgegen.NewSequencerInbox(contractAddress, l1Reader.Client())
 	if err != nil {
 		return nil, err
@@ -119,7 +121,7 @@ func NewBatchPoster(l1Reader *headerreader.HeaderReader, inbox *InboxTracker, st
 		streamer:      streamer,
 		config:        config,
 		inboxContract: inboxContract,
-		transactOpts:  transactOpts,
+		signer:        signer,
 		gasRefunder:   common.HexToAddress(config.GasRefunderAddress),
 		daWriter:      daWriter,
 	}, nil
@@ -427,11 +429,16 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context, batchSeqNum u
 		}
 	}
 
-	txOpts := *b.transactOpts
-	txOpts.Context = ctx
+	if b.l1ChainId == nil {
+		b.l1ChainId, err = b.l1Reader.Client().ChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	txOpts := util.TransactOpts(ctx, b.signer, b.l1ChainId)
 	txOpts.NoSend = true
 	txOpts.GasMargin = b.config.GasMarginBasisPoints
-	tx, err := b.inboxContract.AddSequencerL2BatchFromOrigin(&txOpts, new(big.Int).SetUint64(batchSeqNum), sequencerMsg, new(big.Int).SetUint64(b.building.segments.delayedMsg), b.gasRefunder)
+	tx, err := b.inboxContract.AddSequencerL2BatchFromOrigin(txOpts, new(big.Int).SetUint64(batchSeqNum), sequencerMsg, new(big.Int).SetUint64(b.building.segments.delayedMsg), b.gasRefunder)
 	if err != nil {
 		return nil, err
 	}
@@ -464,7 +471,7 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context, batchSeqNum u
 				Data:       tx.Data(),
 				AccessList: tx.AccessList(),
 			})
-			tx, err = txOpts.Signer(txOpts.From, tx)
+			tx, err = txOpts.Signer(b.signer.Address(), tx)
 			if err != nil {
 				return nil, err
 			}
@@ -18,6 +18,7 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/das"
 	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/headerreader"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
@@ -92,6 +93,7 @@ type InboxReader struct {
 	caughtUpChan   chan bool
 	client         arbutil.L1Interface
 	l1Reader       *headerreader.HeaderReader
+	dasPrefetcher  *das.Prefetcher
 
 	// Atomic
 	lastSeenBatchCount uint64
@@ -102,7 +104,7 @@ type InboxReader struct {
 	lastReadBatchCount uint64
 }
 
-func NewInboxReader(tracker *InboxTracker, client arbutil.L1Interface, l1Reader *headerreader.HeaderReader, firstMessageBlock *big.Int, delayedBridge *DelayedBridge, sequencerInbox *SequencerInbox, config *InboxReaderConfig) (*InboxReader, error) {
+func NewInboxReader(tracker *InboxTracker, client arbutil.L1Interface, l1Reader *headerreader.HeaderReader, firstMessageBlock *big.Int, delayedBridge *DelayedBridge, sequencerInbox *SequencerInbox, dasPrefetcher *das.Prefetcher, config *InboxReaderConfig) (*InboxReader, error) {
 	err := config.Validate()
 	if err != nil {
 		return nil, err
@@ -113,6 +115,7 @@ func NewInboxReader(tracker *InboxTracker, client arbutil.L1Interface, l1Reader
 		sequencerInbox:    sequencerInbox,
 		client:            client,
 		l1Reader:          l1Reader,
+		dasPrefetcher:     dasPrefetcher,
 		firstMessageBlock: firstMessageBlock,
 		caughtUpChan:      make(chan bool, 1),
 		config:            config,
@@ -421,6 +424,16 @@ func (ir *InboxReader) run(ctx context.Context, hadError bool) error {
 
 			log.Trace("looking up messages", "from", from.String(), "to", to.String(), "missingDelayed", missingDelayed, "missingSequencer", missingSequencer, "reorgingDelayed", reorgingDelayed, "reorgingSequencer", reorgingSequencer)
 			if !reorgingDelayed && !reorgingSequencer && (len(delayedMessages) != 0 || len(sequencerBatches) != 0) {
+				if ir.dasPrefetcher != nil {
+					// Start fetching the data of DAS batches before they're processed one by one.
+					for _, batch := range sequencerBatches {
+						sequencerMsg, err := batch.Serialize(ctx, ir.client)
+						if err != nil {
+							return err
+						}
+						ir.dasPrefetcher.Prefetch(sequencerMsg)
+					}
+				}
 				delayedMismatch, err := ir.addMessages(ctx, sequencerBatches, delayedMessages)
 				if err != nil {
 					return err
@@ -37,6 +37,7 @@ import (
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/broadcastclient"
 	"github.com/offchainlabs/nitro/broadcaster"
+	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/das"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/solgen/go/challengegen"
@@ -702,7 +703,7 @@ func createNodeImpl(
 	l2BlockChain *core.BlockChain,
 	l1client arbutil.L1Interface,
 	deployInfo *RollupAddresses,
-	txOpts *bind.TransactOpts,
+	l1Signer util.Signer,
 	dataSigner signature.DataSignerFunc,
 	fatalErrChan chan error,
 ) (*Node, error) {
@@ -872,6 +873,7 @@ func createNodeImpl(
 	var daWriter das.DataAvailabilityServiceWriter
 	var daReader das.DataAvailabilityServiceReader
 	var dasLifecycleManager *das.LifecycleManager
+	var dasPrefetcher *das.Prefetcher
 	if config.DataAvailability.Enable {
 		if config.BatchPoster.Enable {
 			daWriter, daReader, dasLifecycleManager, err = das.CreateBatchPosterDAS(ctx, &config.DataAvailability, dataSigner, l1client, deployInfo.SequencerInbox)
@@ -887,6 +889,23 @@ func createNodeImpl(
 
 		daReader = das.NewReaderTimeoutWrapper(daReader, config.DataAvailability.RequestTimeout)
 
+		if config.DataAvailability.PrefetchConfig.Enable {
+			prefetchCache, err := das.NewBigCacheStorageService(das.BigCacheConfig{
+				Enable:     true,
+				Expiration: config.DataAvailability.PrefetchConfig.Expiration,
+			}, das.NewEmptyStorageService())
+			if err != nil {
+				return nil, err
+			}
+			dasPrefetcher, err = das.NewPrefetcher(daReader, prefetchCache, &config.DataAvailability.PrefetchConfig)
+			if err != nil {
+				return nil, err
+			}
+			dasPrefetcher.Start(ctx)
+			dasLifecycleManager.Register(dasPrefetcher)
+			daReader = dasPrefetcher
+		}
+
 		if config.DataAvailability.PanicOnError {
 			if daWriter != nil {
 				daWriter = das.NewWriterPanicWrapper(daWriter)
@@ -901,7 +920,7 @@ func createNodeImpl(
 	if err != nil {
 		return nil, err
 	}
-	inboxReader, err := NewInboxReader(inboxTracker, l1client, l1Reader, new(big.Int).SetUint64(deployInfo.DeployedAt), delayedBridge, sequencerInbox, &(config.InboxReader))
+	inboxReader, err := NewInboxReader(inboxTracker, l1client, l1Reader, new(big.Int).SetUint64(deployInfo.DeployedAt), delayedBridge, sequencerInbox, dasPrefetcher, &(config.InboxReader))
 	if err != nil {
 		return nil, err
 	}
@@ -960,7 +979,15 @@ func createNodeImpl(
 	var staker *validator.Staker
 	if config.Validator.Enable {
 		// TODO: remember validator wallet in JSON instead of querying it from L1 every time
-		wallet, err := validator.NewValidatorWallet(nil, deployInfo.ValidatorWalletCreator, deployInfo.Rollup, l1Reader, txOpts, int64(deployInfo.DeployedAt), func(common.Address) {})
+		var walletAddress *common.Address
+		if config.Validator.ContractWalletAddress != "" {
+			if !common.IsHexAddress(config.Validator.ContractWalletAddress) {
+				return nil, errors.New("invalid validator contract wallet address")
+			}
+			address := common.HexToAddress(config.Validator.ContractWalletAddress)
+			walletAddress = &address
+		}
+		wallet, err := validator.NewValidatorWallet(walletAddress, deployInfo.ValidatorWalletCreator, deployInfo.Rollup, l1Reader, l1Signer, int64(deployInfo.DeployedAt), func(common.Address) {})
 		if err != nil {
 			return nil, err
 		}
@@ -973,10 +1000,10 @@ func createNodeImpl(
 	var batchPoster *BatchPoster
 	var delayedSequencer *DelayedSequencer
 	if config.BatchPoster.Enable {
-		if txOpts == nil {
-			return nil, errors.New("batchposter, but no TxOpts")
+		if l1Signer == nil {
+			return nil, errors.New("batchposter, but no L1 signer")
 		}
-		batchPoster, err = NewBatchPoster(l1Reader, inboxTracker, txStreamer, &config.BatchPoster, deployInfo.SequencerInbox, txOpts, daWriter)
+		batchPoster, err = NewBatchPoster(l1Reader, inboxTracker, txStreamer, &config.BatchPoster, deployInfo.SequencerInbox, l1Signer, daWriter)
 		if err != nil {
 			return nil, err
 		}
@@ -1151,6 +1178,7 @@ func SetUpDataAvailability(
 				topLevelStorageService = das.NewFallbackStorageService(topLevelStorageService, restAgg,
 					retentionPeriodSeconds, syncConf.IgnoreWriteErrors, true)
 			}
+			topLevelStorageService = das.NewMetricsStorageService("fallback", topLevelStorageService)
 		} else {
 			topLevelStorageService = das.NewReadLimitedStorageService(restAgg)
 		}
@@ -1161,20 +1189,20 @@ func SetUpDataAvailability(
 	if config.AggregatorConfig.Enable {
 		panic("Tried to make an aggregator using wrong factory method")
 	}
-	if hasPersistentStorage && (config.KeyConfig.KeyDir != "" || config.KeyConfig.PrivKey != "") {
+	if hasPersistentStorage && config.KeyConfig.IsSet() {
 		_seqInboxCaller := seqInboxCaller
 		if config.DisableSignatureChecking {
 			_seqInboxCaller = nil
 		}
 
-		privKey, err := config.KeyConfig.BLSPrivKey()
+		signer, err := config.KeyConfig.BLSSigner(ctx)
 		if err != nil {
 			return nil, nil, err
 		}
 
 		// TODO rename StorageServiceDASAdapter
-		topLevelDas, err = das.NewSignAfterStoreDASWithSeqInboxCaller(
-			privKey,
+		signAfterStoreDas, err := das.NewSignAfterStoreDASWithSigner(
+			signer,
 			_seqInboxCaller,
 			topLevelStorageService,
 			config.ExtraSignatureCheckingPublicKey,
@@ -1182,6 +1210,15 @@ func SetUpDataAvailability(
 		if err != nil {
 			return nil, nil, err
 		}
+		if config.SigningJournal.Enable {
+			journal, err := config.SigningJournal.Open()
+			if err != nil {
+				return nil, nil, err
+			}
+			signAfterStoreDas.SetJournal(journal)
+		}
+		dasLifecycleManager.Register(signAfterStoreDas)
+		topLevelDas = signAfterStoreDas
 	} else {
 		topLevelDas = das.NewReadLimitedDataAvailabilityService(topLevelStorageService)
 	}
@@ -1193,7 +1230,7 @@ func SetUpDataAvailability(
 		if err != nil {
 			return nil, nil, err
 		}
-		topLevelDas = das.NewCacheStorageToDASAdapter(topLevelDas, cache)
+		topLevelDas = das.NewCacheStorageToDASAdapter(topLevelDas, das.NewMetricsStorageService("redis", cache))
 	}
 	if config.LocalCacheConfig.Enable {
 		cache, err := das.NewBigCacheStorageService(config.LocalCacheConfig, das.NewEmptyStorageService())
@@ -1201,7 +1238,7 @@ func SetUpDataAvailability(
 		if err != nil {
 			return nil, nil, err
 		}
-		topLevelDas = das.NewCacheStorageToDASAdapter(topLevelDas, cache)
+		topLevelDas = das.NewCacheStorageToDASAdapter(topLevelDas, das.NewMetricsStorageService("bigcache", cache))
 	}
 
 	if topLevelDas != nil && seqInbox != nil {
@@ -1244,11 +1281,11 @@ func CreateNode(
 	l2BlockChain *core.BlockChain,
 	l1client arbutil.L1Interface,
 	deployInfo *RollupAddresses,
-	txOpts *bind.TransactOpts,
+	l1Signer util.Signer,
 	dataSigner signature.DataSignerFunc,
 	fatalErrChan chan error,
 ) (*Node, error) {
-	currentNode, err := createNodeImpl(ctx, stack, chainDb, arbDb, configFetcher, l2BlockChain, l1client, deployInfo, txOpts, dataSigner, fatalErrChan)
+	currentNode, err := createNodeImpl(ctx, stack, chainDb, arbDb, configFetcher, l2BlockChain, l1client, deployInfo, l1Signer, dataSigner, fatalErrChan)
 	if err != nil {
 		return nil, err
 	}
@@ -1272,6 +1309,25 @@ func CreateNode(
 			Public: false,
 		})
 	}
+	if currentNode.InboxTracker != nil {
+		var rollup *validator.RollupWatcher
+		if currentNode.L1Reader != nil {
+			rollup, err = validator.NewRollupWatcher(deployInfo.Rollup, currentNode.L1Reader.Client(), bind.CallOpts{})
+			if err != nil {
+				return nil, err
+			}
+		}
+		checker, err := validator.NewAssertionChecker(l2BlockChain, currentNode.InboxTracker, currentNode.TxStreamer, currentNode.BlockValidator, rollup)
+		if err != nil {
+			return nil, err
+		}
+		apis = append(apis, rpc.API{
+			Namespace: "arbvalidator",
+			Version:   "1.0",
+			Service:   &AssertionCheckerAPI{checker},
+			Public:    false,
+		})
+	}
 
 	apis = append(apis, rpc.API{
 		Namespace: "arb",
@@ -15,6 +15,7 @@ type L1Config struct {
 	URL                string                        `koanf:"url"`
 	ConnectionAttempts int                           `koanf:"connection-attempts"`
 	Wallet             genericconf.WalletConfig      `koanf:"wallet"`
+	NextWallet         genericconf.WalletConfig      `koanf:"next-wallet"`
 }
 
 var L1ConfigDefault = L1Config{
@@ -23,6 +24,7 @@ var L1ConfigDefault = L1Config{
 	URL:                "",
 	ConnectionAttempts: 15,
 	Wallet:             genericconf.WalletConfigDefault,
+	NextWallet:         genericconf.WalletConfigDefault,
 }
 
 func L1ConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -31,10 +33,13 @@ func L1ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	arbnode.RollupAddressesConfigAddOptions(prefix+".rollup", f)
 	f.Int(prefix+".connection-attempts", L1ConfigDefault.ConnectionAttempts, "layer 1 RPC connection attempts (spaced out at least 1 second per attempt, 0 to retry infinitely)")
 	genericconf.WalletConfigAddOptions(prefix+".wallet", f, "wallet")
+	// Next wallet does not exist unless specified
+	genericconf.WalletConfigAddOptions(prefix+".next-wallet", f, "")
 }
 
 func (c *L1Config) ResolveDirectoryNames(chain string) {
 	c.Wallet.ResolveDirectoryNames(chain)
+	c.NextWallet.ResolveDirectoryNames(chain)
 }
 
 type L2Config struct {
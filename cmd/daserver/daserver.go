@@ -6,9 +6,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -20,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics/exp"
 
 	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/das"
@@ -35,8 +39,11 @@ type DAServerConfig struct {
 	RESTAddr           string                              `koanf:"rest-addr"`
 	RESTPort           uint64                              `koanf:"rest-port"`
 	RESTServerTimeouts genericconf.HTTPServerTimeoutConfig `koanf:"rest-server-timeouts"`
+	RESTAccessLog      das.AccessLogConfig                 `koanf:"rest-access-log"`
+	RESTEndpoints      das.RestfulEndpointsConfig          `koanf:"rest-endpoints"`
 
 	DAConf das.DataAvailabilityConfig `koanf:"data-availability"`
+	Chains []string                   `koanf:"chains"`
 
 	ConfConfig genericconf.ConfConfig `koanf:"conf"`
 	LogLevel   int                    `koanf:"log-level"`
@@ -54,6 +61,8 @@ var DefaultDAServerConfig = DAServerConfig{
 	RESTAddr:           "localhost",
 	RESTPort:           9877,
 	RESTServerTimeouts: genericconf.HTTPServerTimeoutConfigDefault,
+	RESTAccessLog:      das.DefaultAccessLogConfig,
+	RESTEndpoints:      das.DefaultRestfulEndpointsConfig,
 	DAConf:             das.DefaultDataAvailabilityConfig,
 	ConfConfig:         genericconf.ConfConfigDefault,
 	Metrics:            false,
@@ -83,12 +92,15 @@ func parseDAServer(args []string) (*DAServerConfig, error) {
 	f.String("rest-addr", DefaultDAServerConfig.RESTAddr, "REST server listening interface")
 	f.Uint64("rest-port", DefaultDAServerConfig.RESTPort, "REST server listening port")
 	genericconf.HTTPServerTimeoutConfigAddOptions("rest-server-timeouts", f)
+	das.AccessLogConfigAddOptions("rest-access-log", f)
+	das.RestfulEndpointsConfigAddOptions("rest-endpoints", f)
 
 	f.Bool("metrics", DefaultDAServerConfig.Metrics, "enable metrics")
 	genericconf.MetricsServerAddOptions("metrics-server", f)
 
 	f.Int("log-level", int(log.LvlInfo), "log level; 1: ERROR, 2: WARN, 3: INFO, 4: DEBUG, 5: TRACE")
 	das.DataAvailabilityConfigAddOptions("data-availability", f)
+	f.StringSlice("chains", []string{}, "serve several chains, each given as <name>=<config file> where the file holds the chain's data-availability options in the same format as this server's config file; each chain's RPC and REST APIs are then served under /chains/<name>, and data-availability options given to the server itself are ignored")
 	genericconf.ConfConfigAddOptions("conf", f)
 
 	k, err := util.BeginCommonParse(f, args)
@@ -120,6 +132,83 @@ func parseDAServer(args []string) (*DAServerConfig, error) {
 	return &serverConfig, nil
 }
 
+type chainDAConfig struct {
+	DAConf     das.DataAvailabilityConfig `koanf:"data-availability"`
+	ConfConfig genericconf.ConfConfig     `koanf:"conf"`
+}
+
+func parseChainDAConfig(configFile string) (*das.DataAvailabilityConfig, error) {
+	f := flag.NewFlagSet("daserver chain", flag.ContinueOnError)
+	das.DataAvailabilityConfigAddOptions("data-availability", f)
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, []string{"--conf.file", configFile})
+	if err != nil {
+		return nil, err
+	}
+
+	var config chainDAConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config.DAConf, nil
+}
+
+// storageNamespaces returns the storage locations used by config, so that
+// chains can be checked not to share any.
+func storageNamespaces(config *das.DataAvailabilityConfig) []string {
+	var namespaces []string
+	if config.LocalDBStorageConfig.Enable {
+		namespaces = append(namespaces, "local-db-storage "+filepath.Clean(config.LocalDBStorageConfig.DataDir))
+	}
+	if config.LocalFileStorageConfig.Enable {
+		namespaces = append(namespaces, "local-file-storage "+filepath.Clean(config.LocalFileStorageConfig.DataDir))
+	}
+	if config.S3StorageServiceConfig.Enable {
+		namespaces = append(namespaces, "s3-storage "+config.S3StorageServiceConfig.Bucket+"/"+config.S3StorageServiceConfig.ObjectPrefix)
+	}
+	return namespaces
+}
+
+// setUpChains creates the DAS of each chain given as <name>=<config file>.
+func setUpChains(ctx context.Context, chains []string) (map[string]das.DataAvailabilityService, []*das.LifecycleManager, error) {
+	dases := make(map[string]das.DataAvailabilityService, len(chains))
+	var lifecycleManagers []*das.LifecycleManager
+	namespaceOwners := make(map[string]string)
+	for _, chain := range chains {
+		separator := strings.Index(chain, "=")
+		if separator < 0 {
+			return nil, lifecycleManagers, fmt.Errorf("chain '%s' must be given as <name>=<config file>", chain)
+		}
+		name, configFile := chain[:separator], chain[separator+1:]
+		if err := das.CheckChainName(name); err != nil {
+			return nil, lifecycleManagers, err
+		}
+		if _, ok := dases[name]; ok {
+			return nil, lifecycleManagers, fmt.Errorf("chain '%s' given more than once", name)
+		}
+		config, err := parseChainDAConfig(configFile)
+		if err != nil {
+			return nil, lifecycleManagers, fmt.Errorf("error parsing config of chain '%s': %w", name, err)
+		}
+		for _, namespace := range storageNamespaces(config) {
+			if owner, ok := namespaceOwners[namespace]; ok {
+				return nil, lifecycleManagers, fmt.Errorf("chains '%s' and '%s' share %s", owner, name, namespace)
+			}
+			namespaceOwners[namespace] = name
+		}
+
+		log.Info("Setting up chain", "chain", name, "config", configFile)
+		dasImpl, dasLifecycleManager, err := arbnode.SetUpDataAvailabilityWithoutNode(ctx, config)
+		if err != nil {
+			return nil, lifecycleManagers, fmt.Errorf("error setting up chain '%s': %w", name, err)
+		}
+		lifecycleManagers = append(lifecycleManagers, dasLifecycleManager)
+		dases[name] = das.NewChainMetricsDAS(name, dasImpl)
+	}
+	return dases, lifecycleManagers, nil
+}
+
 func startup() error {
 	// Some different defaults to DAS config in a node.
 	das.DefaultDataAvailabilityConfig.Enable = true
@@ -157,9 +246,26 @@ func startup() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	dasImpl, dasLifecycleManager, err := arbnode.SetUpDataAvailabilityWithoutNode(ctx, &serverConfig.DAConf)
-	if err != nil {
-		return err
+	var dasImpl das.DataAvailabilityService
+	var chains map[string]das.DataAvailabilityService
+	var dasLifecycleManagers []*das.LifecycleManager
+	defer func() {
+		for _, dasLifecycleManager := range dasLifecycleManagers {
+			dasLifecycleManager.StopAndWaitUntil(2 * time.Second)
+		}
+	}()
+	if len(serverConfig.Chains) > 0 {
+		chains, dasLifecycleManagers, err = setUpChains(ctx, serverConfig.Chains)
+		if err != nil {
+			return err
+		}
+	} else {
+		var dasLifecycleManager *das.LifecycleManager
+		dasImpl, dasLifecycleManager, err = arbnode.SetUpDataAvailabilityWithoutNode(ctx, &serverConfig.DAConf)
+		if err != nil {
+			return err
+		}
+		dasLifecycleManagers = append(dasLifecycleManagers, dasLifecycleManager)
 	}
 
 	vcsRevision, vcsTime := util.GetVersion()
@@ -167,9 +273,20 @@ func startup() error {
 	if serverConfig.EnableRPC {
 		log.Info("Starting HTTP-RPC server", "addr", serverConfig.RPCAddr, "port", serverConfig.RPCPort, "revision", vcsRevision, "vcs.time", vcsTime)
 
-		rpcServer, err = das.StartDASRPCServer(ctx, serverConfig.RPCAddr, serverConfig.RPCPort, serverConfig.RPCServerTimeouts, dasImpl)
-		if err != nil {
-			return err
+		if chains != nil {
+			listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", serverConfig.RPCAddr, serverConfig.RPCPort))
+			if err != nil {
+				return err
+			}
+			rpcServer, err = das.StartDASRPCServerForChainsOnListener(ctx, listener, serverConfig.RPCServerTimeouts, chains)
+			if err != nil {
+				return err
+			}
+		} else {
+			rpcServer, err = das.StartDASRPCServer(ctx, serverConfig.RPCAddr, serverConfig.RPCPort, serverConfig.RPCServerTimeouts, dasImpl)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -177,14 +294,36 @@ func startup() error {
 	if serverConfig.EnableREST {
 		log.Info("Starting REST server", "addr", serverConfig.RESTAddr, "port", serverConfig.RESTPort, "revision", vcsRevision, "vcs.time", vcsTime)
 
-		restServer, err = das.NewRestfulDasServer(serverConfig.RESTAddr, serverConfig.RESTPort, serverConfig.RESTServerTimeouts, dasImpl)
+		accessLog, err := das.NewAccessLogger(&serverConfig.RESTAccessLog)
 		if err != nil {
 			return err
 		}
+		if chains != nil {
+			listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", serverConfig.RESTAddr, serverConfig.RESTPort))
+			if err != nil {
+				return err
+			}
+			readers := make(map[string]arbstate.DataAvailabilityReader, len(chains))
+			for name, chainDAS := range chains {
+				readers[name] = chainDAS
+			}
+			restServer, err = das.NewRestfulDasServerForChainsOnListener(listener, serverConfig.RESTServerTimeouts, serverConfig.RESTEndpoints, accessLog, readers)
+			if err != nil {
+				return err
+			}
+		} else {
+			restServer, err = das.NewRestfulDasServer(serverConfig.RESTAddr, serverConfig.RESTPort, serverConfig.RESTServerTimeouts, serverConfig.RESTEndpoints, accessLog, dasImpl)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	<-sigint
-	dasLifecycleManager.StopAndWaitUntil(2 * time.Second)
+	for _, dasLifecycleManager := range dasLifecycleManagers {
+		dasLifecycleManager.StopAndWaitUntil(2 * time.Second)
+	}
+	dasLifecycleManagers = nil
 
 	var err1, err2 error
 	if rpcServer != nil {
@@ -9,11 +9,16 @@ import (
 	"crypto/ecdsa"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	flag "github.com/spf13/pflag"
@@ -21,19 +26,22 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/blsSignatures"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 
 	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/das"
 	"github.com/offchainlabs/nitro/das/dastree"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/util/signature"
 )
 
 func main() {
 	args := os.Args
 	if len(args) < 2 {
-		panic("Usage: datool [client|keygen|generatehash] ...")
+		panic("Usage: datool [client|keygen|generatehash|keyset|blssigner|migrate|signurllist|cert|hashcheck|signed] ...")
 	}
 
 	var err error
@@ -44,8 +52,22 @@ func main() {
 		err = startKeyGen(args[2:])
 	case "generatehash":
 		err = generateHash(args[2])
+	case "keyset":
+		err = startKeyset(args[2:])
+	case "blssigner":
+		err = startBLSSigner(args[2:])
+	case "migrate":
+		err = startMigrate(args[2:])
+	case "signurllist":
+		err = startSignURLList(args[2:])
+	case "cert":
+		err = startCert(args[2:])
+	case "hashcheck":
+		err = startHashCheck(args[2:])
+	case "signed":
+		err = startSigned(args[2:])
 	default:
-		panic(fmt.Sprintf("Unknown tool '%s' specified, valid tools are 'client', 'keygen', 'generatehash'", args[1]))
+		panic(fmt.Sprintf("Unknown tool '%s' specified, valid tools are 'client', 'keygen', 'generatehash', 'keyset', 'blssigner', 'migrate', 'signurllist', 'cert', 'hashcheck', 'signed'", args[1]))
 	}
 	if err != nil {
 		panic(err)
@@ -152,7 +174,7 @@ func startClientStore(args []string) error {
 			Account:       "",
 			OnlyCreateKey: false,
 		}
-		_, signer, err := util.OpenWallet("datool", walletConf, nil)
+		_, signer, err := util.OpenWallet("datool.wallet", walletConf, nil)
 		if err != nil {
 			return err
 		}
@@ -182,9 +204,14 @@ func startClientStore(args []string) error {
 		return err
 	}
 
+	hashMode, err := dastree.HashModeForCertVersion(cert.Version)
+	if err != nil {
+		return err
+	}
 	serializedCert := das.Serialize(cert)
 	fmt.Printf("Hex Encoded Cert: %s\n", hexutil.Encode(serializedCert))
-	fmt.Printf("Hex Encoded Data Hash: %s\n", hexutil.Encode(cert.DataHash[:]))
+	fmt.Printf("Cert Version: %d\n", cert.Version)
+	fmt.Printf("Hex Encoded Data Hash (%v): %s\n", hashMode, hexutil.Encode(cert.DataHash[:]))
 
 	return nil
 }
@@ -194,6 +221,7 @@ func startClientStore(args []string) error {
 type RESTClientGetByHashConfig struct {
 	URL        string                 `koanf:"url"`
 	DataHash   string                 `koanf:"data-hash"`
+	HashMode   string                 `koanf:"hash-mode"`
 	ConfConfig genericconf.ConfConfig `koanf:"conf"`
 }
 
@@ -201,6 +229,7 @@ func parseRESTClientGetByHashConfig(args []string) (*RESTClientGetByHashConfig,
 	f := flag.NewFlagSet("datool client retrieve", flag.ContinueOnError)
 	f.String("url", "http://localhost:9877", "URL of DAS server to connect to.")
 	f.String("data-hash", "", "hash of the message to retrieve, if starts with '0x' it's treated as hex encoded, otherwise base64 encoded")
+	f.String("hash-mode", "any", "how data-hash commits to the message: 'tree' for the hashes of version 1 certificates, 'flat' for those of version 0 certificates, or 'any' to accept either")
 
 	genericconf.ConfConfigAddOptions("conf", f)
 
@@ -242,10 +271,23 @@ func startRESTClientGetByHash(args []string) error {
 	}
 
 	ctx := context.Background()
-	message, err := client.GetByHash(ctx, common.BytesToHash(decodedHash))
+	hash := common.BytesToHash(decodedHash)
+	var message []byte
+	if config.HashMode == "any" {
+		message, err = client.GetByHash(ctx, hash)
+	} else {
+		var hashMode dastree.HashMode
+		hashMode, err = dastree.ParseHashMode(config.HashMode)
+		if err != nil {
+			return err
+		}
+		message, err = client.GetByHashInMode(ctx, hash, hashMode)
+	}
 	if err != nil {
 		return err
 	}
+	hashMode, _ := dastree.HashModeOf(hash, message)
+	fmt.Printf("Hash Mode: %v\n", hashMode)
 	fmt.Printf("Message: %s\n", message)
 	return nil
 }
@@ -253,10 +295,13 @@ func startRESTClientGetByHash(args []string) error {
 // das keygen
 
 type KeyGenConfig struct {
-	Dir        string
-	ConfConfig genericconf.ConfConfig `koanf:"conf"`
-	ECDSAMode  bool                   `koanf:"ecdsa"`
-	WalletMode bool                   `koanf:"wallet"`
+	Dir                  string
+	ConfConfig           genericconf.ConfConfig `koanf:"conf"`
+	ECDSAMode            bool                   `koanf:"ecdsa"`
+	WalletMode           bool                   `koanf:"wallet"`
+	KeystoreMode         bool                   `koanf:"keystore"`
+	KeystorePasswordFile string                 `koanf:"keystore-password-file"`
+	KeystorePasswordEnv  string                 `koanf:"keystore-password-env"`
 }
 
 func parseKeyGenConfig(args []string) (*KeyGenConfig, error) {
@@ -264,6 +309,9 @@ func parseKeyGenConfig(args []string) (*KeyGenConfig, error) {
 	f.String("dir", "", "the directory to generate the keys in")
 	f.Bool("ecdsa", false, "generate an ECDSA keypair instead of BLS")
 	f.Bool("wallet", false, "generate the ECDSA keypair in a wallet file")
+	f.Bool("keystore", false, fmt.Sprintf("store the BLS private key encrypted in '%s' instead of in plaintext", das.DefaultKeystoreFilename))
+	f.String("keystore-password-file", "", "file containing the password to encrypt the BLS keystore with")
+	f.String("keystore-password-env", "", "name of the environment variable containing the password to encrypt the BLS keystore with, used if keystore-password-file isn't set")
 	genericconf.ConfConfigAddOptions("conf", f)
 
 	k, err := util.BeginCommonParse(f, args)
@@ -284,7 +332,14 @@ func startKeyGen(args []string) error {
 		return err
 	}
 
-	if !config.ECDSAMode {
+	if !config.ECDSAMode && config.KeystoreMode {
+		password, err := das.ReadKeystorePassword(config.KeystorePasswordFile, config.KeystorePasswordEnv)
+		if err != nil {
+			return err
+		}
+		_, err = das.GenerateAndStoreEncryptedKeys(config.Dir, password)
+		return err
+	} else if !config.ECDSAMode {
 		_, _, err = das.GenerateAndStoreKeys(config.Dir)
 		if err != nil {
 			return err
@@ -300,7 +355,7 @@ func startKeyGen(args []string) error {
 			Account:       "",
 			OnlyCreateKey: true,
 		}
-		_, _, err = util.OpenWallet("datool", walletConf, nil)
+		_, _, err = util.OpenWallet("datool.wallet", walletConf, nil)
 		if err != nil && strings.Contains(fmt.Sprint(err), "wallet key created") {
 			return nil
 		}
@@ -309,6 +364,795 @@ func startKeyGen(args []string) error {
 }
 
 func generateHash(message string) error {
-	fmt.Printf("Hex Encoded Data Hash: %s\n", hexutil.Encode(dastree.HashBytes([]byte(message))))
+	fmt.Printf("Hex Encoded Data Hash (tree, certificate version 1): %s\n", hexutil.Encode(dastree.HashBytes([]byte(message))))
+	fmt.Printf("Hex Encoded Data Hash (flat, certificate version 0): %s\n", dastree.FlatHashMode.Hash([]byte(message)).Hex())
+	return nil
+}
+
+// datool keyset ...
+
+func startKeyset(args []string) error {
+	if len(args) == 0 {
+		return errors.New("datool keyset requires one of 'build' or 'diff'")
+	}
+	switch strings.ToLower(args[0]) {
+	case "build":
+		return startKeysetBuild(args[1:])
+	case "diff":
+		return startKeysetDiff(args[1:])
+	}
+	return fmt.Errorf("datool keyset '%s' not supported, valid arguments are 'build' and 'diff'", args[0])
+}
+
+func keysetConfigAddOptions(f *flag.FlagSet) {
+	f.String("backends", "", "JSON RPC backend configuration, in the same format as --node.data-availability.rpc-aggregator.backends")
+	f.String("backends-file", "", "file containing the JSON RPC backend configuration")
+	f.StringSlice("pubkeys", []string{}, "base64 BLS public keys of the committee members, in signer mask order; alternative to backends")
+	f.Uint64("assumed-honest", 1, "number of assumed honest committee members (H)")
+}
+
+func keysetFromFlags(backendsJSON string, backendsFile string, encodedPubKeys []string, assumedHonest uint64) (*arbstate.DataAvailabilityKeyset, error) {
+	if len(encodedPubKeys) > 0 {
+		if backendsJSON != "" || backendsFile != "" {
+			return nil, errors.New("only one of --pubkeys, --backends or --backends-file may be specified")
+		}
+		var pubKeys []blsSignatures.PublicKey
+		for _, encoded := range encodedPubKeys {
+			pubKey, err := das.DecodeBase64BLSPublicKey([]byte(encoded))
+			if err != nil {
+				return nil, err
+			}
+			pubKeys = append(pubKeys, *pubKey)
+		}
+		return das.KeysetFromPubKeys(assumedHonest, pubKeys)
+	}
+	backends := []byte(backendsJSON)
+	if backendsFile != "" {
+		if backendsJSON != "" {
+			return nil, errors.New("only one of --pubkeys, --backends or --backends-file may be specified")
+		}
+		var err error
+		backends, err = os.ReadFile(backendsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(backends) == 0 {
+		return nil, errors.New("one of --pubkeys, --backends or --backends-file must be specified")
+	}
+	cs, err := das.ParseBackendConfigs(backends)
+	if err != nil {
+		return nil, err
+	}
+	return das.KeysetFromBackendConfigs(assumedHonest, cs)
+}
+
+func printKeyset(keyset *arbstate.DataAvailabilityKeyset) error {
+	ksBytes, ksHash, err := das.SerializeKeyset(keyset)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Keyset: %s\n", hexutil.Encode(ksBytes))
+	fmt.Printf("KeysetHash (tree): %s\n", hexutil.Encode(ksHash[:]))
+	fmt.Printf("AssumedHonest: %d\n", keyset.AssumedHonest)
+	for i, pk := range keyset.PubKeys {
+		fmt.Printf("Member %d (signersMask %#x): %s\n", i, uint64(1)<<i, base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(pk)))
+	}
+	return nil
+}
+
+// datool keyset build
+
+type KeysetBuildConfig struct {
+	Backends      string                 `koanf:"backends"`
+	BackendsFile  string                 `koanf:"backends-file"`
+	PubKeys       []string               `koanf:"pubkeys"`
+	AssumedHonest uint64                 `koanf:"assumed-honest"`
+	ConfConfig    genericconf.ConfConfig `koanf:"conf"`
+}
+
+func parseKeysetBuildConfig(args []string) (*KeysetBuildConfig, error) {
+	f := flag.NewFlagSet("datool keyset build", flag.ContinueOnError)
+	keysetConfigAddOptions(f)
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config KeysetBuildConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func startKeysetBuild(args []string) error {
+	config, err := parseKeysetBuildConfig(args)
+	if err != nil {
+		return err
+	}
+	keyset, err := keysetFromFlags(config.Backends, config.BackendsFile, config.PubKeys, config.AssumedHonest)
+	if err != nil {
+		return err
+	}
+	return printKeyset(keyset)
+}
+
+// datool keyset diff
+
+type KeysetDiffConfig struct {
+	Backends              string                 `koanf:"backends"`
+	BackendsFile          string                 `koanf:"backends-file"`
+	PubKeys               []string               `koanf:"pubkeys"`
+	AssumedHonest         uint64                 `koanf:"assumed-honest"`
+	L1NodeURL             string                 `koanf:"l1-node-url"`
+	SequencerInboxAddress string                 `koanf:"sequencer-inbox-address"`
+	FromBlock             uint64                 `koanf:"from-block"`
+	ConfConfig            genericconf.ConfConfig `koanf:"conf"`
+}
+
+func parseKeysetDiffConfig(args []string) (*KeysetDiffConfig, error) {
+	f := flag.NewFlagSet("datool keyset diff", flag.ContinueOnError)
+	keysetConfigAddOptions(f)
+	f.String("l1-node-url", "", "URL of the L1 node to read registered keysets from")
+	f.String("sequencer-inbox-address", "", "L1 address of SequencerInbox contract")
+	f.Uint64("from-block", 0, "L1 block to start searching for SetValidKeyset events from")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config KeysetDiffConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func startKeysetDiff(args []string) error {
+	config, err := parseKeysetDiffConfig(args)
+	if err != nil {
+		return err
+	}
+	keyset, err := keysetFromFlags(config.Backends, config.BackendsFile, config.PubKeys, config.AssumedHonest)
+	if err != nil {
+		return err
+	}
+	_, ksHash, err := das.SerializeKeyset(keyset)
+	if err != nil {
+		return err
+	}
+	if !common.IsHexAddress(config.SequencerInboxAddress) {
+		return fmt.Errorf("invalid --sequencer-inbox-address '%s'", config.SequencerInboxAddress)
+	}
+
+	ctx := context.Background()
+	l1Client, err := ethclient.DialContext(ctx, config.L1NodeURL)
+	if err != nil {
+		return err
+	}
+	seqInbox, err := bridgegen.NewSequencerInbox(common.HexToAddress(config.SequencerInboxAddress), l1Client)
+	if err != nil {
+		return err
+	}
+	registered, err := das.FetchRegisteredKeysets(ctx, seqInbox, config.FromBlock, nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Local KeysetHash: %s\n", hexutil.Encode(ksHash[:]))
+	registeredAndValid := false
+	for _, r := range registered {
+		status := "valid"
+		if !r.Valid {
+			status = "invalidated"
+		}
+		fmt.Printf("\nRegistered KeysetHash: %s (block %d, %s)\n", hexutil.Encode(r.Hash[:]), r.CreationBlock, status)
+		if r.Hash == ksHash {
+			fmt.Printf("  identical to local keyset\n")
+			registeredAndValid = r.Valid
+			continue
+		}
+		diff := das.DiffKeysets(r.Keyset, keyset)
+		if diff.OldAssumedHonest != diff.NewAssumedHonest {
+			fmt.Printf("  assumed-honest: %d -> %d\n", diff.OldAssumedHonest, diff.NewAssumedHonest)
+		}
+		for _, pk := range diff.Added {
+			fmt.Printf("  + %s\n", base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(pk)))
+		}
+		for _, pk := range diff.Removed {
+			fmt.Printf("  - %s\n", base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(pk)))
+		}
+		if diff.MemberOrderChanged {
+			fmt.Printf("  member order (signer masks) changed\n")
+		}
+	}
+	fmt.Println()
+	if registeredAndValid {
+		fmt.Println("Local keyset is registered and valid on the SequencerInbox.")
+	} else {
+		fmt.Println("Local keyset is NOT registered as valid on the SequencerInbox; register it with setValidKeyset before switching to it.")
+	}
+	return nil
+}
+
+// datool blssigner
+
+type BLSSignerConfig struct {
+	Key        das.KeyConfig          `koanf:"key"`
+	Addr       string                 `koanf:"addr"`
+	ConfConfig genericconf.ConfConfig `koanf:"conf"`
+}
+
+func parseBLSSignerConfig(args []string) (*BLSSignerConfig, error) {
+	f := flag.NewFlagSet("datool blssigner", flag.ContinueOnError)
+	das.KeyConfigAddOptions("key", f)
+	f.String("addr", "", "unix socket path, or host:port to serve HTTP on; prefer a unix socket so that only local processes can request signatures")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config BLSSignerConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// startBLSSigner serves the remote BLS signer API used by daserver's
+// --data-availability.key.remote-signer option.
+func startBLSSigner(args []string) error {
+	config, err := parseBLSSignerConfig(args)
+	if err != nil {
+		return err
+	}
+	if config.Key.RemoteSigner.URL != "" {
+		return errors.New("--key.remote-signer.url can't be used by the signer itself")
+	}
+	if config.Addr == "" {
+		return errors.New("--addr must be specified")
+	}
+	ctx := context.Background()
+	signer, err := config.Key.BLSSigner(ctx)
+	if err != nil {
+		return err
+	}
+	server, err := das.NewBLSSignerRPCServer(signer)
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(config.Addr, "/") {
+		listener, err := net.Listen("unix", config.Addr)
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(config.Addr, 0600); err != nil {
+			return err
+		}
+		fmt.Printf("Serving BLS signer %v on unix socket %s\n", signer, config.Addr)
+		return server.ServeListener(listener)
+	}
+	listener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Serving BLS signer %v on http://%s\n", signer, config.Addr)
+	return http.Serve(listener, server)
+}
+
+// datool migrate
+
+type MigrateStorageConfig struct {
+	LocalDBStorage   das.LocalDBStorageConfig   `koanf:"local-db-storage"`
+	LocalFileStorage das.LocalFileStorageConfig `koanf:"local-file-storage"`
+	S3Storage        das.S3StorageServiceConfig `koanf:"s3-storage"`
+}
+
+type MigrateConfig struct {
+	Src              MigrateStorageConfig   `koanf:"src"`
+	Dst              MigrateStorageConfig   `koanf:"dst"`
+	Concurrency      int                    `koanf:"concurrency"`
+	ProgressFile     string                 `koanf:"progress-file"`
+	KeepExpiration   bool                   `koanf:"keep-expiration"`
+	DefaultRetention time.Duration          `koanf:"default-retention"`
+	SkipExisting     bool                   `koanf:"skip-existing"`
+	ConfConfig       genericconf.ConfConfig `koanf:"conf"`
+}
+
+func migrateStorageConfigAddOptions(prefix string, f *flag.FlagSet) {
+	das.LocalDBStorageConfigAddOptions(prefix+".local-db-storage", f)
+	das.LocalFileStorageConfigAddOptions(prefix+".local-file-storage", f)
+	das.S3ConfigAddOptions(prefix+".s3-storage", f)
+}
+
+func parseMigrateConfig(args []string) (*MigrateConfig, error) {
+	f := flag.NewFlagSet("datool migrate", flag.ContinueOnError)
+	migrateStorageConfigAddOptions("src", f)
+	migrateStorageConfigAddOptions("dst", f)
+	f.Int("concurrency", das.DefaultMigrateConfig.Concurrency, "number of values to copy in parallel")
+	f.String("progress-file", das.DefaultMigrateConfig.ProgressFile, "file recording the last key up to which everything has been copied; if it exists the migration resumes from there")
+	f.Bool("keep-expiration", das.DefaultMigrateConfig.KeepExpiration, "store values in the destination with the expiration time recorded by the source (only local-db-storage records them)")
+	f.Duration("default-retention", das.DefaultMigrateConfig.DefaultRetention, "how long the destination should retain values whose expiration time isn't kept or known, 0 means forever")
+	f.Bool("skip-existing", das.DefaultMigrateConfig.SkipExisting, "don't copy values the destination already has")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config MigrateConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func createMigrateStorage(ctx context.Context, name string, config *MigrateStorageConfig) (das.IterableStorageService, error) {
+	var storageService das.StorageService
+	var err error
+	enabled := 0
+	if config.LocalDBStorage.Enable {
+		enabled++
+		storageService, err = das.NewDBStorageService(ctx, config.LocalDBStorage.DataDir, config.LocalDBStorage.DiscardAfterTimeout)
+	}
+	if config.LocalFileStorage.Enable {
+		enabled++
+		storageService, err = das.NewLocalFileStorageService(config.LocalFileStorage.DataDir)
+	}
+	if config.S3Storage.Enable {
+		enabled++
+		storageService, err = das.NewS3StorageService(config.S3Storage)
+	}
+	if enabled != 1 {
+		return nil, fmt.Errorf("exactly one of --%s.local-db-storage.enable, --%s.local-file-storage.enable or --%s.s3-storage.enable must be set", name, name, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	iterable, ok := storageService.(das.IterableStorageService)
+	if !ok {
+		return nil, fmt.Errorf("%v can't be iterated over", storageService)
+	}
+	return iterable, nil
+}
+
+func startMigrate(args []string) error {
+	config, err := parseMigrateConfig(args)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigint
+		fmt.Println("Interrupted, saving progress")
+		cancel()
+	}()
+
+	src, err := createMigrateStorage(ctx, "src", &config.Src)
+	if err != nil {
+		return err
+	}
+	defer src.Close(context.Background())
+	dst, err := createMigrateStorage(ctx, "dst", &config.Dst)
+	if err != nil {
+		return err
+	}
+	defer dst.Close(context.Background())
+
+	fmt.Printf("Migrating from %v to %v\n", src, dst)
+	stats, err := das.MigrateStorage(ctx, src, dst, &das.MigrateConfig{
+		Concurrency:      config.Concurrency,
+		ProgressFile:     config.ProgressFile,
+		KeepExpiration:   config.KeepExpiration,
+		DefaultRetention: config.DefaultRetention,
+		SkipExisting:     config.SkipExisting,
+	})
+	if err != nil {
+		if stats != nil {
+			fmt.Printf("Migration stopped: %v\n", stats)
+		}
+		return err
+	}
+	fmt.Printf("Migration finished: %v\n", stats)
+	return nil
+}
+
+// datool signurllist
+
+type SignURLListConfig struct {
+	URLsFile   string                 `koanf:"urls-file"`
+	SigningKey string                 `koanf:"signing-key"`
+	Version    uint64                 `koanf:"version"`
+	Expiry     time.Duration          `koanf:"expiry"`
+	Output     string                 `koanf:"output"`
+	ConfConfig genericconf.ConfConfig `koanf:"conf"`
+}
+
+func parseSignURLListConfig(args []string) (*SignURLListConfig, error) {
+	f := flag.NewFlagSet("datool signurllist", flag.ContinueOnError)
+	f.String("urls-file", "", "file containing the whitespace-separated URLs of REST DAS endpoints to include in the list")
+	f.String("signing-key", "", "ecdsa private key to sign the list with, treated as a hex string if prefixed with 0x otherise treated as a file")
+	f.Uint64("version", 0, "version of the list; must be at least that of the previously published list, and nodes reject lists older than the newest one they've seen")
+	f.Duration("expiry", 30*24*time.Hour, "how long from now the list is valid for")
+	f.String("output", "", "file to write the signed list to; if not specified it's printed")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config SignURLListConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// startSignURLList produces a signed list for the rest-aggregator's
+// online-url-list and online-url-list-signer options.
+func startSignURLList(args []string) error {
+	config, err := parseSignURLListConfig(args)
+	if err != nil {
+		return err
+	}
+	if config.URLsFile == "" || config.SigningKey == "" {
+		return errors.New("--urls-file and --signing-key must be specified")
+	}
+	contents, err := os.ReadFile(config.URLsFile)
+	if err != nil {
+		return err
+	}
+	var privateKey *ecdsa.PrivateKey
+	if strings.HasPrefix(config.SigningKey, "0x") {
+		privateKey, err = crypto.HexToECDSA(config.SigningKey[2:])
+	} else {
+		privateKey, err = crypto.LoadECDSA(config.SigningKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	list := &das.SignedRestfulServerList{
+		Urls:    strings.Fields(string(contents)),
+		Version: config.Version,
+		Expiry:  uint64(time.Now().Add(config.Expiry).Unix()),
+	}
+	if err := list.Sign(signature.DataSignerFromPrivateKey(privateKey)); err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Signed %d URLs with %v, version %d, expiring %v\n", len(list.Urls), crypto.PubkeyToAddress(privateKey.PublicKey), list.Version, time.Unix(int64(list.Expiry), 0))
+	if config.Output == "" {
+		fmt.Println(string(encoded))
+		return nil
+	}
+	return os.WriteFile(config.Output, encoded, 0644)
+}
+
+// datool cert
+
+type CertConfig struct {
+	Batch                 string                 `koanf:"batch"`
+	TxHash                string                 `koanf:"tx-hash"`
+	L1NodeURL             string                 `koanf:"l1-node-url"`
+	SequencerInboxAddress string                 `koanf:"sequencer-inbox-address"`
+	RESTURL               string                 `koanf:"rest-url"`
+	ConfConfig            genericconf.ConfConfig `koanf:"conf"`
+}
+
+func parseCertConfig(args []string) (*CertConfig, error) {
+	f := flag.NewFlagSet("datool cert", flag.ContinueOnError)
+	f.String("batch", "", "hex encoded batch data as posted to the SequencerInbox, starting with the header byte")
+	f.String("tx-hash", "", "hash of the L1 transaction that posted the batch; alternative to --batch")
+	f.String("l1-node-url", "", "URL of the L1 node to read the batch and keyset from")
+	f.String("sequencer-inbox-address", "", "L1 address of SequencerInbox contract")
+	f.String("rest-url", "", "URL of a REST DAS endpoint to fetch the keyset and batch data from; if not specified the keyset is read from L1 and the data isn't fetched")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config CertConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// batchDataFromTx reads the data of the batch posted by txHash, either from
+// its SequencerBatchData event or from its addSequencerL2BatchFromOrigin input.
+func batchDataFromTx(ctx context.Context, l1Client *ethclient.Client, seqInbox *bridgegen.SequencerInbox, seqInboxAddr common.Address, txHash common.Hash) ([]byte, error) {
+	receipt, err := l1Client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range receipt.Logs {
+		if l.Address != seqInboxAddr {
+			continue
+		}
+		if event, err := seqInbox.ParseSequencerBatchData(*l); err == nil {
+			return event.Data, nil
+		}
+	}
+	tx, _, err := l1Client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	seqInboxABI, err := bridgegen.SequencerInboxMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.Data()) < 4 {
+		return nil, fmt.Errorf("transaction %v didn't post a batch", txHash)
+	}
+	method, err := seqInboxABI.MethodById(tx.Data()[:4])
+	if err != nil || method.Name != "addSequencerL2BatchFromOrigin" {
+		return nil, fmt.Errorf("transaction %v didn't post a batch", txHash)
+	}
+	args := make(map[string]interface{})
+	if err := method.Inputs.UnpackIntoMap(args, tx.Data()[4:]); err != nil {
+		return nil, err
+	}
+	return args["data"].([]byte), nil
+}
+
+// startCert decodes the DAS certificate of a batch, and checks it was signed
+// by enough of the committee of the keyset it references.
+func startCert(args []string) error {
+	config, err := parseCertConfig(args)
+	if err != nil {
+		return err
+	}
+	if (config.Batch == "") == (config.TxHash == "") {
+		return errors.New("exactly one of --batch or --tx-hash must be specified")
+	}
+	if !common.IsHexAddress(config.SequencerInboxAddress) {
+		return fmt.Errorf("invalid --sequencer-inbox-address '%s'", config.SequencerInboxAddress)
+	}
+	seqInboxAddr := common.HexToAddress(config.SequencerInboxAddress)
+
+	ctx := context.Background()
+	l1Client, err := ethclient.DialContext(ctx, config.L1NodeURL)
+	if err != nil {
+		return err
+	}
+	seqInbox, err := bridgegen.NewSequencerInbox(seqInboxAddr, l1Client)
+	if err != nil {
+		return err
+	}
+
+	var batch []byte
+	if config.Batch != "" {
+		batch, err = hexutil.Decode(config.Batch)
+	} else {
+		batch, err = batchDataFromTx(ctx, l1Client, seqInbox, seqInboxAddr, common.HexToHash(config.TxHash))
+	}
+	if err != nil {
+		return err
+	}
+	if len(batch) == 0 || !arbstate.IsDASMessageHeaderByte(batch[0]) {
+		return errors.New("batch doesn't have a DAS certificate")
+	}
+	cert, err := arbstate.DeserializeDASCertFrom(bytes.NewReader(batch))
+	if err != nil {
+		return fmt.Errorf("failed to decode DAS certificate: %w", err)
+	}
+
+	hashMode, err := dastree.HashModeForCertVersion(cert.Version)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Version: %d (%v hashes)\n", cert.Version, hashMode)
+	fmt.Printf("KeysetHash: %s\n", hexutil.Encode(cert.KeysetHash[:]))
+	fmt.Printf("DataHash: %s\n", hexutil.Encode(cert.DataHash[:]))
+	fmt.Printf("Timeout: %d (%v)\n", cert.Timeout, time.Unix(int64(cert.Timeout), 0).UTC())
+	fmt.Printf("SignersMask: %#x\n", cert.SignersMask)
+
+	var inner arbstate.DataAvailabilityReader = das.NewEmptyStorageService()
+	var restClient *das.RestfulDasClient
+	if config.RESTURL != "" {
+		restClient, err = das.NewRestfulDasClientFromURL(config.RESTURL)
+		if err != nil {
+			return err
+		}
+		inner = restClient
+	}
+	reader, err := das.NewChainFetchReaderWithSeqInbox(inner, seqInbox)
+	if err != nil {
+		return err
+	}
+	keysetBytes, err := reader.GetByHash(ctx, cert.KeysetHash)
+	if err != nil {
+		return fmt.Errorf("failed to fetch keyset: %w", err)
+	}
+	keyset, err := arbstate.DeserializeKeyset(bytes.NewReader(keysetBytes), false)
+	if err != nil {
+		return fmt.Errorf("failed to decode keyset: %w", err)
+	}
+
+	fmt.Printf("\nAssumedHonest: %d\n", keyset.AssumedHonest)
+	signers := 0
+	for i, pk := range keyset.PubKeys {
+		status := "did not sign"
+		if cert.SignersMask&(uint64(1)<<i) != 0 {
+			status = "signed"
+			signers++
+		}
+		fmt.Printf("Member %d (signersMask %#x) %s: %s\n", i, uint64(1)<<i, status, base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(pk)))
+	}
+	if len(keyset.PubKeys) < 64 && cert.SignersMask>>len(keyset.PubKeys) != 0 {
+		fmt.Printf("SignersMask has bits set beyond the %d members of the keyset\n", len(keyset.PubKeys))
+	}
+	fmt.Printf("%d of %d members signed, at least %d required\n", signers, len(keyset.PubKeys), len(keyset.PubKeys)-int(keyset.AssumedHonest)+1)
+
+	fmt.Println()
+	if err := keyset.VerifySignature(cert.SignersMask, cert.SerializeSignableFields(), cert.Sig); err != nil {
+		fmt.Printf("Signature INVALID: %v\n", err)
+	} else {
+		fmt.Println("Signature valid.")
+	}
+
+	if restClient != nil {
+		data, err := restClient.GetByHashInMode(ctx, cert.DataHash, hashMode)
+		if err != nil {
+			fmt.Printf("Batch data not retrievable from %s: %v\n", config.RESTURL, err)
+		} else {
+			fmt.Printf("Batch data retrieved from %s (%d bytes).\n", config.RESTURL, len(data))
+		}
+	}
+	return nil
+}
+
+// datool hashcheck
+
+type HashCheckConfig struct {
+	Storage    MigrateStorageConfig   `koanf:"storage"`
+	GapsOnly   bool                   `koanf:"gaps-only"`
+	ConfConfig genericconf.ConfConfig `koanf:"conf"`
+}
+
+func parseHashCheckConfig(args []string) (*HashCheckConfig, error) {
+	f := flag.NewFlagSet("datool hashcheck", flag.ContinueOnError)
+	migrateStorageConfigAddOptions("storage", f)
+	f.Bool("gaps-only", false, "only report preimages that aren't reachable under both their tree and flat hashes, or are stored under a key the upgrade to tree hashes missed")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config HashCheckConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// startHashCheck reports, for each preimage in a storage backend, whether
+// it's reachable under both its tree and flat hashes, to find gaps left by the
+// one-time upgrade to tree hashes.
+func startHashCheck(args []string) error {
+	config, err := parseHashCheckConfig(args)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	storage, err := createMigrateStorage(ctx, "storage", &config.Storage)
+	if err != nil {
+		return err
+	}
+	defer storage.Close(context.Background())
+
+	fmt.Printf("Checking %v\n", storage)
+	stats, err := das.CheckHashModes(ctx, storage, func(result das.HashModeCheckResult) {
+		if config.GapsOnly && result.TreeReachable && result.FlatReachable && result.KeyKind != das.LegacyFlatStorageKey {
+			return
+		}
+		fmt.Printf("%s: stored under %v key, tree hash reachable %v, flat hash reachable %v\n", result.Key.Hex(), result.KeyKind, result.TreeReachable, result.FlatReachable)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Check finished: %v\n", stats)
+	return nil
+}
+
+// datool signed
+
+type SignedConfig struct {
+	URL         string                 `koanf:"url"`
+	JournalFile string                 `koanf:"journal-file"`
+	From        uint64                 `koanf:"from"`
+	To          uint64                 `koanf:"to"`
+	ConfConfig  genericconf.ConfConfig `koanf:"conf"`
+}
+
+func parseSignedConfig(args []string) (*SignedConfig, error) {
+	f := flag.NewFlagSet("datool signed", flag.ContinueOnError)
+	f.String("url", "", "URL of the REST endpoint of the committee member to ask")
+	f.String("journal-file", "", "signing journal file of the committee member to read, instead of asking it over REST")
+	f.Uint64("from", 0, "list the certificates signed at or after this time, in Unix seconds")
+	f.Uint64("to", 0, "list the certificates signed before this time, in Unix seconds (0 for no limit)")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config SignedConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if (config.URL == "") == (config.JournalFile == "") {
+		return nil, errors.New("exactly one of --url and --journal-file must be specified")
+	}
+	return &config, nil
+}
+
+// startSigned lists the certificates a committee member signed in a time
+// range, from its signing journal.
+func startSigned(args []string) error {
+	config, err := parseSignedConfig(args)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	listSigned := func(offset uint64) (*das.SigningJournalList, error) {
+		return das.ListSigningJournalFile(ctx, config.JournalFile, config.From, config.To, offset)
+	}
+	if config.URL != "" {
+		client, err := das.NewRestfulDasClientFromURL(config.URL)
+		if err != nil {
+			return err
+		}
+		listSigned = func(offset uint64) (*das.SigningJournalList, error) {
+			return client.ListSigned(ctx, config.From, config.To, offset)
+		}
+	}
+
+	count := 0
+	var offset uint64
+	for {
+		list, err := listSigned(offset)
+		if err != nil {
+			return err
+		}
+		for _, entry := range list.Entries {
+			fmt.Printf(
+				"%s: data hash %s, timeout %s, keyset hash %s, sig %s\n",
+				time.Unix(int64(entry.SignedAt), 0).UTC().Format(time.RFC3339),
+				entry.DataHash.Hex(),
+				time.Unix(int64(entry.Timeout), 0).UTC().Format(time.RFC3339),
+				entry.KeysetHash.Hex(),
+				entry.Sig,
+			)
+		}
+		count += len(list.Entries)
+		if !list.More || list.Next <= offset {
+			break
+		}
+		offset = list.Next
+	}
+	fmt.Printf("%d certificates signed\n", count)
 	return nil
 }
@@ -64,7 +64,7 @@ func main() {
 		Account:      *deployAccount,
 		PasswordImpl: *l1passphrase,
 	}
-	l1TransactionOpts, _, err := util.OpenWallet("l1", &wallet, l1ChainId)
+	l1TransactionOpts, _, err := util.OpenWallet("l1.wallet", &wallet, l1ChainId)
 	if err != nil {
 		flag.Usage()
 		log.Error("error reading keystore")
@@ -13,11 +13,12 @@ import (
 const PASSWORD_NOT_SET = "PASSWORD_NOT_SET"
 
 type WalletConfig struct {
-	Pathname      string `koanf:"pathname"`
-	PasswordImpl  string `koanf:"password"`
-	PrivateKey    string `koanf:"private-key"`
-	Account       string `koanf:"account"`
-	OnlyCreateKey bool   `koanf:"only-create-key"`
+	Pathname       string `koanf:"pathname"`
+	PasswordImpl   string `koanf:"password"`
+	PrivateKey     string `koanf:"private-key"`
+	Account        string `koanf:"account"`
+	OnlyCreateKey  bool   `koanf:"only-create-key"`
+	ExternalSigner string `koanf:"external-signer"`
 }
 
 func (w *WalletConfig) Password() *string {
@@ -28,11 +29,12 @@ func (w *WalletConfig) Password() *string {
 }
 
 var WalletConfigDefault = WalletConfig{
-	Pathname:      "",
-	PasswordImpl:  PASSWORD_NOT_SET,
-	PrivateKey:    "",
-	Account:       "",
-	OnlyCreateKey: false,
+	Pathname:       "",
+	PasswordImpl:   PASSWORD_NOT_SET,
+	PrivateKey:     "",
+	Account:        "",
+	OnlyCreateKey:  false,
+	ExternalSigner: "",
 }
 
 func WalletConfigAddOptions(prefix string, f *flag.FlagSet, defaultPathname string) {
@@ -41,6 +43,7 @@ func WalletConfigAddOptions(prefix string, f *flag.FlagSet, defaultPathname stri
 	f.String(prefix+".private-key", WalletConfigDefault.PrivateKey, "private key for wallet")
 	f.String(prefix+".account", WalletConfigDefault.Account, "account to use (default is first account in keystore)")
 	f.Bool(prefix+".only-create-key", WalletConfigDefault.OnlyCreateKey, "if true, creates new key then exits")
+	f.String(prefix+".external-signer", WalletConfigDefault.ExternalSigner, "URL of an external signer's JSON-RPC API (e.g. clef) to sign transactions for the account with instead of a local key (can't sign data for feed or DAS signatures)")
 }
 
 func (w *WalletConfig) ResolveDirectoryNames(chain string) {
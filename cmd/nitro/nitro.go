@@ -23,7 +23,6 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/accounts"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -120,7 +119,7 @@ func main() {
 	defer cancelFunc()
 
 	args := os.Args[1:]
-	nodeConfig, l1Wallet, l2DevWallet, l1Client, l1ChainId, err := ParseNode(ctx, args)
+	nodeConfig, l1Wallet, l1NextWallet, l2DevWallet, l1Client, l1ChainId, err := ParseNode(ctx, args)
 	if err != nil {
 		util.HandleError(err, printSampleUsage)
 
@@ -161,17 +160,45 @@ func main() {
 		}
 	}
 
-	var l1TransactionOpts *bind.TransactOpts
+	var l1Signer util.Signer
 	var dataSigner signature.DataSignerFunc
 	sequencerNeedsKey := nodeConfig.Node.Sequencer.Enable && !nodeConfig.Node.Feed.Output.DisableSigning
 	setupNeedsKey := l1Wallet.OnlyCreateKey || nodeConfig.Node.Validator.OnlyCreateWalletContract
 	validatorNeedsKey := nodeConfig.Node.Validator.Enable && !strings.EqualFold(nodeConfig.Node.Validator.Strategy, "watchtower")
+	if l1Wallet.ExternalSigner != "" {
+		// External signers only sign transactions, not the hashes of data.
+		if sequencerNeedsKey {
+			flag.Usage()
+			panic("l1.wallet.external-signer can't sign the sequencer feed, use a local key or set node.feed.output.disable-signing")
+		}
+		if nodeConfig.Node.BatchPoster.Enable && nodeConfig.Node.DataAvailability.Enable {
+			flag.Usage()
+			panic("l1.wallet.external-signer can't sign the batch poster's data availability requests, use a local key")
+		}
+	}
 	if sequencerNeedsKey || nodeConfig.Node.BatchPoster.Enable || setupNeedsKey || validatorNeedsKey {
-		l1TransactionOpts, dataSigner, err = util.OpenWallet("l1", l1Wallet, new(big.Int).SetUint64(nodeConfig.L1.ChainID))
+		l1Signer, err = util.OpenSigner("l1.wallet", l1Wallet)
+		if err != nil {
+			fmt.Printf("%v\n", err.Error())
+			return
+		}
+		if external, ok := l1Signer.(*util.ExternalSigner); ok {
+			defer external.Close()
+		}
+		dataSigner = l1Signer.SignHash
+	}
+
+	var l1NextSigner util.Signer
+	nextWalletSet := l1NextWallet.Pathname != "" || l1NextWallet.PrivateKey != "" || l1NextWallet.ExternalSigner != ""
+	if validatorNeedsKey && nextWalletSet {
+		l1NextSigner, err = util.OpenSigner("l1.next-wallet", l1NextWallet)
 		if err != nil {
 			fmt.Printf("%v\n", err.Error())
 			return
 		}
+		if external, ok := l1NextSigner.(*util.ExternalSigner); ok {
+			defer external.Close()
+		}
 	}
 
 	var rollupAddrs arbnode.RollupAddresses
@@ -208,9 +235,9 @@ func main() {
 			log.Error("error getting deployment info for creating validator wallet contract", "error", err)
 			return
 		}
-		addr, err := validator.GetValidatorWallet(ctx, deployInfo.ValidatorWalletCreator, int64(deployInfo.DeployedAt), l1TransactionOpts, l1Reader, true)
+		addr, err := validator.GetValidatorWallet(ctx, deployInfo.ValidatorWalletCreator, int64(deployInfo.DeployedAt), l1Signer, l1Reader, true)
 		if err != nil {
-			log.Error("error creating validator wallet contract", "error", err, "address", l1TransactionOpts.From.Hex())
+			log.Error("error creating validator wallet contract", "error", err, "address", l1Signer.Address().Hex())
 			return
 		}
 		fmt.Printf("created validator smart contract wallet at %s, remove --node.validator.only-create-wallet-contract and restart\n", addr.String())
@@ -295,13 +322,17 @@ func main() {
 		l2BlockChain,
 		l1Client,
 		&rollupAddrs,
-		l1TransactionOpts,
+		l1Signer,
 		dataSigner,
 		fatalErrChan,
 	)
 	if err != nil {
 		panic(err)
 	}
+	if l1NextSigner != nil && currentNode.Staker != nil {
+		log.Info("rotating validator wallet to next wallet", "from", l1Signer.Address(), "to", l1NextSigner.Address())
+		currentNode.Staker.RotateWallet(l1NextSigner)
+	}
 	if nodeConfig.Node.Dangerous.NoL1Listener && nodeConfig.Init.DevInit {
 		// If we don't have any messages, we're not connected to the L1, and we're using a dev init,
 		// we should create our own fake init message.
@@ -436,14 +467,14 @@ func (c *NodeConfig) CanReload(new *NodeConfig) error {
 	return err
 }
 
-func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.WalletConfig, *genericconf.WalletConfig, *ethclient.Client, *big.Int, error) {
+func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.WalletConfig, *genericconf.WalletConfig, *genericconf.WalletConfig, *ethclient.Client, *big.Int, error) {
 	f := flag.NewFlagSet("", flag.ContinueOnError)
 
 	NodeConfigAddOptions(f)
 
 	k, err := util.BeginCommonParse(f, args)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, err
 	}
 
 	var l1ChainId *big.Int
@@ -474,14 +505,14 @@ func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.Wa
 			select {
 			case <-ctx.Done():
 				timer.Stop()
-				return nil, nil, nil, nil, nil, errors.New("aborting startup")
+				return nil, nil, nil, nil, nil, nil, errors.New("aborting startup")
 			case <-timer.C:
 			}
 		}
 	} else if configChainId == 0 && !k.Bool("conf.dump") {
-		return nil, nil, nil, nil, nil, errors.New("l1 chain id not provided")
+		return nil, nil, nil, nil, nil, nil, errors.New("l1 chain id not provided")
 	} else if k.Bool("node.l1-reader.enable") {
-		return nil, nil, nil, nil, nil, errors.New("l1 reader enabled but --l1.url not provided")
+		return nil, nil, nil, nil, nil, nil, errors.New("l1 reader enabled but --l1.url not provided")
 	}
 
 	if l1ChainId == nil {
@@ -491,14 +522,14 @@ func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.Wa
 	if configChainId != l1ChainId.Uint64() {
 		if configChainId != 0 {
 			log.Error("chain id from L1 does not match command line chain id", "l1", l1ChainId.String(), "cli", configChainId)
-			return nil, nil, nil, nil, nil, errors.New("chain id from L1 does not match command line chain id")
+			return nil, nil, nil, nil, nil, nil, errors.New("chain id from L1 does not match command line chain id")
 		}
 
 		err := k.Load(confmap.Provider(map[string]interface{}{
 			"l1.chain-id": l1ChainId.Uint64(),
 		}, "."), nil)
 		if err != nil {
-			return nil, nil, nil, nil, nil, errors.Wrap(err, "error setting ")
+			return nil, nil, nil, nil, nil, nil, errors.Wrap(err, "error setting ")
 		}
 	}
 
@@ -507,40 +538,40 @@ func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.Wa
 	if l1ChainId.Uint64() == 1 { // mainnet
 		switch l2ChainId {
 		case 0:
-			return nil, nil, nil, nil, nil, errors.New("must specify --l2.chain-id to choose rollup")
+			return nil, nil, nil, nil, nil, nil, errors.New("must specify --l2.chain-id to choose rollup")
 		case 42161:
 			if err := applyArbitrumOneParameters(k); err != nil {
-				return nil, nil, nil, nil, nil, err
+				return nil, nil, nil, nil, nil, nil, err
 			}
 			chainFound = true
 		case 42170:
 			if err := applyArbitrumNovaParameters(k); err != nil {
-				return nil, nil, nil, nil, nil, err
+				return nil, nil, nil, nil, nil, nil, err
 			}
 			chainFound = true
 		}
 	} else if l1ChainId.Uint64() == 4 {
 		switch l2ChainId {
 		case 0:
-			return nil, nil, nil, nil, nil, errors.New("must specify --l2.chain-id to choose rollup")
+			return nil, nil, nil, nil, nil, nil, errors.New("must specify --l2.chain-id to choose rollup")
 		case 421611:
 			if err := applyArbitrumRollupRinkebyTestnetParameters(k); err != nil {
-				return nil, nil, nil, nil, nil, err
+				return nil, nil, nil, nil, nil, nil, err
 			}
 			chainFound = true
 		}
 	} else if l1ChainId.Uint64() == 5 {
 		switch l2ChainId {
 		case 0:
-			return nil, nil, nil, nil, nil, errors.New("must specify --l2.chain-id to choose rollup")
+			return nil, nil, nil, nil, nil, nil, errors.New("must specify --l2.chain-id to choose rollup")
 		case 421613:
 			if err := applyArbitrumRollupGoerliTestnetParameters(k); err != nil {
-				return nil, nil, nil, nil, nil, err
+				return nil, nil, nil, nil, nil, nil, err
 			}
 			chainFound = true
 		case 421703:
 			if err := applyArbitrumAnytrustGoerliTestnetParameters(k); err != nil {
-				return nil, nil, nil, nil, nil, err
+				return nil, nil, nil, nil, nil, nil, err
 			}
 			chainFound = true
 		}
@@ -548,47 +579,51 @@ func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.Wa
 
 	err = util.ApplyOverrides(f, k)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, err
 	}
 
 	var nodeConfig NodeConfig
 	if err := util.EndCommonParse(k, &nodeConfig); err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, err
 	}
 
 	// Don't print wallet passwords
 	if nodeConfig.Conf.Dump {
 		err = util.DumpConfig(k, map[string]interface{}{
-			"l1.wallet.password":        "",
-			"l1.wallet.private-key":     "",
-			"l2.dev-wallet.password":    "",
-			"l2.dev-wallet.private-key": "",
+			"l1.wallet.password":         "",
+			"l1.wallet.private-key":      "",
+			"l1.next-wallet.password":    "",
+			"l1.next-wallet.private-key": "",
+			"l2.dev-wallet.password":     "",
+			"l2.dev-wallet.private-key":  "",
 		})
 		if err != nil {
-			return nil, nil, nil, nil, nil, err
+			return nil, nil, nil, nil, nil, nil, err
 		}
 	}
 
 	if nodeConfig.Persistent.Chain == "" {
 		if !chainFound {
 			// If persistent-chain not defined, user not creating custom chain
-			return nil, nil, nil, nil, nil, fmt.Errorf("Unknown chain with L1: %d, L2: %d.  Change L1, update L2 chain id, or provide --persistent.chain\n", l1ChainId.Uint64(), l2ChainId)
+			return nil, nil, nil, nil, nil, nil, fmt.Errorf("Unknown chain with L1: %d, L2: %d.  Change L1, update L2 chain id, or provide --persistent.chain\n", l1ChainId.Uint64(), l2ChainId)
 		}
-		return nil, nil, nil, nil, nil, errors.New("--persistent.chain not specified")
+		return nil, nil, nil, nil, nil, nil, errors.New("--persistent.chain not specified")
 	}
 
 	err = nodeConfig.ResolveDirectoryNames()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, err
 	}
 
 	// Don't pass around wallet contents with normal configuration
 	l1Wallet := nodeConfig.L1.Wallet
+	l1NextWallet := nodeConfig.L1.NextWallet
 	l2DevWallet := nodeConfig.L2.DevWallet
 	nodeConfig.L1.Wallet = genericconf.WalletConfigDefault
+	nodeConfig.L1.NextWallet = genericconf.WalletConfigDefault
 	nodeConfig.L2.DevWallet = genericconf.WalletConfigDefault
 
-	return &nodeConfig, &l1Wallet, &l2DevWallet, l1Client, l1ChainId, nil
+	return &nodeConfig, &l1Wallet, &l1NextWallet, &l2DevWallet, l1Client, l1ChainId, nil
 }
 
 func applyArbitrumOneParameters(k *koanf.Koanf) error {
@@ -722,7 +757,7 @@ func (c *LiveNodeConfig) Start(ctxIn context.Context) {
 				case <-timer.C:
 				}
 			}
-			nodeConfig, _, _, _, _, err := ParseNode(ctx, c.args)
+			nodeConfig, _, _, _, _, _, err := ParseNode(ctx, c.args)
 			if err != nil {
 				log.Error("error parsing live config", "error", err.Error())
 				continue
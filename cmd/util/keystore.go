@@ -4,6 +4,7 @@
 package util
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"strings"
@@ -21,24 +22,48 @@ import (
 	"github.com/offchainlabs/nitro/util/signature"
 )
 
-func OpenWallet(description string, walletConfig *genericconf.WalletConfig, chainId *big.Int) (*bind.TransactOpts, signature.DataSignerFunc, error) {
+// OpenWallet opens the local key of walletConfig, whose flags are under
+// flagPrefix (e.g. "l1.wallet"). The transaction options are only returned
+// given a chainId. External signers are opened with OpenSigner instead.
+func OpenWallet(flagPrefix string, walletConfig *genericconf.WalletConfig, chainId *big.Int) (*bind.TransactOpts, signature.DataSignerFunc, error) {
+	if walletConfig.ExternalSigner != "" {
+		return nil, nil, fmt.Errorf("--%s.external-signer isn't supported here, as it can't sign data", flagPrefix)
+	}
+	signer, err := OpenSigner(flagPrefix, walletConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	var txOpts *bind.TransactOpts
+	if chainId != nil {
+		txOpts = TransactOpts(context.Background(), signer, chainId)
+	}
+	return txOpts, signer.SignHash, nil
+}
+
+// OpenSigner opens the account of walletConfig, whose flags are under
+// flagPrefix (e.g. "l1.wallet"): through its external signer if it has one,
+// or else its private key or keystore. An external signer's connection stays
+// open until it's closed.
+func OpenSigner(flagPrefix string, walletConfig *genericconf.WalletConfig) (Signer, error) {
+	if walletConfig.ExternalSigner != "" {
+		if !common.IsHexAddress(walletConfig.Account) {
+			return nil, fmt.Errorf("--%s.external-signer needs the address of the account to sign for in --%s.account", flagPrefix, flagPrefix)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), SignTxTimeout)
+		defer cancel()
+		signer, err := NewExternalSigner(ctx, walletConfig.ExternalSigner, common.HexToAddress(walletConfig.Account))
+		if err != nil {
+			return nil, err
+		}
+		return signer, nil
+	}
+
 	if walletConfig.PrivateKey != "" {
 		privateKey, err := crypto.HexToECDSA(walletConfig.PrivateKey)
 		if err != nil {
-			return nil, nil, err
-		}
-		var txOpts *bind.TransactOpts
-		if chainId != nil {
-			txOpts, err = bind.NewKeyedTransactorWithChainID(privateKey, chainId)
-			if err != nil {
-				return nil, nil, err
-			}
-		}
-		signer := func(data []byte) ([]byte, error) {
-			return crypto.Sign(data, privateKey)
+			return nil, err
 		}
-
-		return txOpts, signer, nil
+		return NewPrivateKeySigner(privateKey), nil
 	}
 
 	ks := keystore.NewKeyStore(
@@ -47,32 +72,20 @@ func OpenWallet(description string, walletConfig *genericconf.WalletConfig, chai
 		keystore.StandardScryptP,
 	)
 
-	account, err := openKeystore(ks, description, walletConfig, readPass)
+	account, err := openKeystore(ks, flagPrefix, walletConfig, readPass)
 	if err != nil {
-		return nil, nil, err
-	}
-
-	var txOpts *bind.TransactOpts
-	if chainId != nil {
-		txOpts, err = bind.NewKeyStoreTransactorWithChainID(ks, *account, chainId)
-		if err != nil {
-			return nil, nil, err
-		}
-	}
-	signer := func(data []byte) ([]byte, error) {
-		return ks.SignHash(*account, data)
+		return nil, err
 	}
-
-	return txOpts, signer, nil
+	return &keystoreSigner{ks: ks, account: *account}, nil
 }
 
-func openKeystore(ks *keystore.KeyStore, description string, walletConfig *genericconf.WalletConfig, getPassword func() (string, error)) (*accounts.Account, error) {
+func openKeystore(ks *keystore.KeyStore, flagPrefix string, walletConfig *genericconf.WalletConfig, getPassword func() (string, error)) (*accounts.Account, error) {
 	creatingNew := len(ks.Accounts()) == 0
 	if creatingNew && !walletConfig.OnlyCreateKey {
-		return nil, fmt.Errorf("no wallet exists, re-run with --%s.wallet.only-create-key to create a wallet", description)
+		return nil, fmt.Errorf("no wallet exists, re-run with --%s.only-create-key to create a wallet", flagPrefix)
 	}
 	if !creatingNew && walletConfig.OnlyCreateKey {
-		return nil, fmt.Errorf("wallet key already created, backup key (%s) and remove --%s.wallet.only-create-key to run normally", walletConfig.Pathname, description)
+		return nil, fmt.Errorf("wallet key already created, backup key (%s) and remove --%s.only-create-key to run normally", walletConfig.Pathname, flagPrefix)
 	}
 	passOpt := walletConfig.Password()
 	var password string
@@ -123,7 +136,7 @@ func openKeystore(ks *keystore.KeyStore, description string, walletConfig *gener
 	}
 
 	if creatingNew {
-		return nil, fmt.Errorf("wallet key created with address %s, backup wallet (%s) and remove --%s.wallet.only-create-key to run normally", account.Address.Hex(), walletConfig.Pathname, description)
+		return nil, fmt.Errorf("wallet key created with address %s, backup wallet (%s) and remove --%s.only-create-key to run normally", account.Address.Hex(), walletConfig.Pathname, flagPrefix)
 	}
 
 	err := ks.Unlock(account, password)
@@ -0,0 +1,200 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package util
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Signer signs transactions and hashes for a single L1 account, whether its
+// key is held locally or by an external signer.
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error)
+	SignHash(hash []byte) ([]byte, error)
+}
+
+// How long to wait for a transaction to be signed, which might need approval
+// by hand with an external signer.
+const SignTxTimeout = time.Minute
+
+// TransactOpts returns transaction options for contract bindings that sign
+// with signer for the chain chainId, within ctx.
+func TransactOpts(ctx context.Context, signer Signer, chainId *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: signer.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != signer.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			signCtx, cancel := context.WithTimeout(ctx, SignTxTimeout)
+			defer cancel()
+			return signer.SignTx(signCtx, tx, chainId)
+		},
+		Context: ctx,
+	}
+}
+
+type privateKeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+func NewPrivateKeySigner(key *ecdsa.PrivateKey) Signer {
+	return &privateKeySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+func (s *privateKeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *privateKeySigner) SignTx(_ context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainId), s.key)
+}
+
+func (s *privateKeySigner) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.key)
+}
+
+type keystoreSigner struct {
+	ks      *keystore.KeyStore
+	account accounts.Account
+}
+
+func (s *keystoreSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *keystoreSigner) SignTx(_ context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	return s.ks.SignTx(s.account, tx, chainId)
+}
+
+func (s *keystoreSigner) SignHash(hash []byte) ([]byte, error) {
+	return s.ks.SignHash(s.account, hash)
+}
+
+var ErrExternalSignerSignHash = errors.New("external signers don't sign raw hashes")
+
+// ExternalSigner signs transactions through the JSON-RPC API of an external
+// signer such as clef, which holds the key.
+type ExternalSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+// The arguments of account_signTransaction.
+type externalSignerTxArgs struct {
+	From                 common.Address    `json:"from"`
+	To                   *common.Address   `json:"to"`
+	Gas                  hexutil.Uint64    `json:"gas"`
+	GasPrice             *hexutil.Big      `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
+	Value                hexutil.Big       `json:"value"`
+	Nonce                hexutil.Uint64    `json:"nonce"`
+	Data                 *hexutil.Bytes    `json:"data"`
+	AccessList           *types.AccessList `json:"accessList,omitempty"`
+	ChainID              *hexutil.Big      `json:"chainId,omitempty"`
+}
+
+type externalSignerTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+// NewExternalSigner connects to the external signer at url, and checks it
+// has the key of address.
+func NewExternalSigner(ctx context.Context, url string, address common.Address) (*ExternalSigner, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer %v: %w", url, err)
+	}
+	var available []common.Address
+	if err := client.CallContext(ctx, &available, "account_list"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to list external signer accounts: %w", err)
+	}
+	for _, have := range available {
+		if have == address {
+			return &ExternalSigner{client: client, address: address}, nil
+		}
+	}
+	client.Close()
+	return nil, fmt.Errorf("external signer %v doesn't have account %v", url, address)
+}
+
+func (s *ExternalSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *ExternalSigner) SignTx(ctx context.Context, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	args := externalSignerTxArgs{
+		From:    s.address,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    &data,
+		ChainID: (*hexutil.Big)(chainId),
+	}
+	switch tx.Type() {
+	case types.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.AccessListTxType:
+		accessList := tx.AccessList()
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+		args.AccessList = &accessList
+	case types.DynamicFeeTxType:
+		accessList := tx.AccessList()
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		args.AccessList = &accessList
+	default:
+		return nil, fmt.Errorf("external signer can't sign transaction type %v", tx.Type())
+	}
+	var result externalSignerTxResult
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("external signer failed to sign transaction: %w", err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(result.Raw); err != nil {
+		return nil, fmt.Errorf("external signer returned an invalid transaction: %w", err)
+	}
+	// Don't send anything but the transaction we asked for.
+	txSigner := types.LatestSignerForChainID(chainId)
+	if txSigner.Hash(signed) != txSigner.Hash(tx) {
+		return nil, fmt.Errorf("external signer signed transaction %v instead of %v", signed.Hash(), tx.Hash())
+	}
+	sender, err := types.Sender(txSigner, signed)
+	if err != nil {
+		return nil, err
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("external signer signed transaction as %v instead of %v", sender, s.address)
+	}
+	return signed, nil
+}
+
+func (s *ExternalSigner) SignHash(hash []byte) ([]byte, error) {
+	return nil, ErrExternalSignerSignHash
+}
+
+// Close closes the connection to the external signer.
+func (s *ExternalSigner) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,225 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package util
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+const stubSignerKeyEnv = "NITRO_STUB_SIGNER_KEY"
+
+// stubSignerAPI is the account namespace of a clef-like signer that signs
+// everything it's asked to with a single key.
+type stubSignerAPI struct {
+	key *ecdsa.PrivateKey
+}
+
+func (a *stubSignerAPI) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(a.key.PublicKey)}
+}
+
+func (a *stubSignerAPI) SignTransaction(args externalSignerTxArgs) (*externalSignerTxResult, error) {
+	var inner types.TxData
+	switch {
+	case args.MaxFeePerGas != nil:
+		inner = &types.DynamicFeeTx{
+			ChainID:    args.ChainID.ToInt(),
+			Nonce:      uint64(args.Nonce),
+			GasTipCap:  args.MaxPriorityFeePerGas.ToInt(),
+			GasFeeCap:  args.MaxFeePerGas.ToInt(),
+			Gas:        uint64(args.Gas),
+			To:         args.To,
+			Value:      args.Value.ToInt(),
+			Data:       *args.Data,
+			AccessList: *args.AccessList,
+		}
+	default:
+		inner = &types.LegacyTx{
+			Nonce:    uint64(args.Nonce),
+			GasPrice: args.GasPrice.ToInt(),
+			Gas:      uint64(args.Gas),
+			To:       args.To,
+			Value:    args.Value.ToInt(),
+			Data:     *args.Data,
+		}
+	}
+	tx, err := types.SignNewTx(a.key, types.LatestSignerForChainID(args.ChainID.ToInt()), inner)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &externalSignerTxResult{Raw: hexutil.Bytes(raw)}, nil
+}
+
+// TestStubSignerProcess isn't a test, but the stub signer process the external
+// signer tests run: it serves the signer API for the key in its environment,
+// prints the URL to reach it at, and exits when its stdin closes.
+func TestStubSignerProcess(t *testing.T) {
+	keyHex := os.Getenv(stubSignerKeyEnv)
+	if keyHex == "" {
+		t.Skip("only runs as the stub signer process")
+	}
+	key, err := crypto.HexToECDSA(keyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", &stubSignerAPI{key: key}); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = http.Serve(listener, server)
+	}()
+	fmt.Printf("http://%v\n", listener.Addr())
+	_, _ = io.Copy(io.Discard, os.Stdin)
+	os.Exit(0)
+}
+
+func startStubSigner(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestStubSignerProcess$")
+	cmd.Env = append(os.Environ(), stubSignerKeyEnv+"="+hexutil.Encode(crypto.FromECDSA(key))[2:])
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		stdin.Close()
+		_ = cmd.Wait()
+	})
+	url, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatal("failed to read stub signer URL:", err)
+	}
+	return strings.TrimSpace(url)
+}
+
+func TestExternalSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	url := startStubSigner(t, key)
+	chainId := big.NewInt(1337)
+
+	walletConf := genericconf.WalletConfigDefault
+	walletConf.ExternalSigner = url
+	walletConf.Account = address.Hex()
+	signer, err := OpenSigner("l1.wallet", &walletConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	external, ok := signer.(*ExternalSigner)
+	if !ok {
+		t.Fatalf("opened %T instead of an external signer", signer)
+	}
+	defer external.Close()
+	txOpts := TransactOpts(context.Background(), signer, chainId)
+	if txOpts.From != address {
+		t.Fatalf("transaction options from %v instead of %v", txOpts.From, address)
+	}
+	if _, err := signer.SignHash(crypto.Keccak256([]byte("data"))); !errors.Is(err, ErrExternalSignerSignHash) {
+		t.Fatal("external signer signed a hash")
+	}
+	// Only local keys can sign data as well as transactions.
+	if _, _, err := OpenWallet("l1.wallet", &walletConf, chainId); err == nil {
+		t.Fatal("opened external signer as a wallet")
+	}
+
+	to := common.HexToAddress("0x1234")
+	unsigned := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(100), Gas: 21000, To: &to, Value: big.NewInt(1)}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainId, Nonce: 2, GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(100), Gas: 50000, To: &to, Data: []byte{1, 2, 3}}),
+	}
+	for _, tx := range unsigned {
+		signed, err := txOpts.Signer(address, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(chainId), signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sender != address || signed.Nonce() != tx.Nonce() || signed.Type() != tx.Type() {
+			t.Fatalf("external signer signed %v as %v, expected %v", signed.Hash(), sender, address)
+		}
+	}
+	if _, err := txOpts.Signer(common.HexToAddress("0x5678"), unsigned[0]); err == nil {
+		t.Fatal("signed transaction for another account")
+	}
+
+	// The signer must have the account.
+	walletConf.Account = common.HexToAddress("0x5678").Hex()
+	if _, err := OpenSigner("l1.wallet", &walletConf); err == nil {
+		t.Fatal("opened external signer without the account")
+	}
+	walletConf.Account = ""
+	_, err = OpenSigner("l1.next-wallet", &walletConf)
+	if err == nil {
+		t.Fatal("opened external signer without an account")
+	}
+	if !strings.Contains(err.Error(), "--l1.next-wallet.account") {
+		t.Fatal("error doesn't name the account flag:", err)
+	}
+}
+
+func TestExternalSignerWrongSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	ctx := context.Background()
+	signer, err := NewExternalSigner(ctx, startStubSigner(t, key), address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signer.Close()
+	// Pretend the signer has another account than the one it signs with.
+	signer.address = crypto.PubkeyToAddress(other.PublicKey)
+	to := common.HexToAddress("0x1234")
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(100), Gas: 21000, To: &to})
+	if _, err := signer.SignTx(ctx, tx, big.NewInt(1337)); err == nil {
+		t.Fatal("accepted a transaction signed by the wrong account")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/cmd/util"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+type ValidateBundleConfig struct {
+	Bundle       string             `koanf:"bundle"`
+	Jit          bool               `koanf:"jit"`
+	JitCranelift bool               `koanf:"jit-cranelift"`
+	ModuleRoot   string             `koanf:"module-root"`
+	Wasm         arbnode.WasmConfig `koanf:"wasm"`
+
+	NodeURL string `koanf:"node-url"`
+	Block   uint64 `koanf:"block"`
+
+	ConfConfig genericconf.ConfConfig `koanf:"conf"`
+	LogLevel   int                    `koanf:"log-level"`
+}
+
+var errMismatch = errors.New("validation result mismatch")
+
+func main() {
+	err := startup()
+	if errors.Is(err, errMismatch) {
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+}
+
+func printSampleUsage(progname string) {
+	fmt.Printf("\n")
+	fmt.Printf("Sample usage:                  %s --bundle <file> [--jit] \n", progname)
+	fmt.Printf("Export and validate:           %s --node-url <url> --block <number> --bundle <file> \n", progname)
+}
+
+func parseValidateBundle(args []string) (*ValidateBundleConfig, error) {
+	f := flag.NewFlagSet("validate-bundle", flag.ContinueOnError)
+	f.String("bundle", "", "validation bundle file to run (written to if node-url is set)")
+	f.Bool("jit", false, "run the bundle with the jit machine instead of the arbitrator")
+	f.Bool("jit-cranelift", validator.DefaultBlockValidatorConfig.JitValidatorCranelift, "use Cranelift instead of LLVM for the jit machine")
+	f.String("module-root", "", "run with the machine for this module root instead of the bundle's")
+	arbnode.WasmConfigAddOptions("wasm", f)
+	f.String("node-url", "", "export the bundle of --block from the arbvalidator RPC API of this node first")
+	f.Uint64("block", 0, "block to export the bundle of from --node-url")
+	f.Int("log-level", int(log.LvlWarn), "log level; 1: ERROR, 2: WARN, 3: INFO, 4: DEBUG, 5: TRACE")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ValidateBundleConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if config.Bundle == "" {
+		return nil, errors.New("--bundle must be specified")
+	}
+	return &config, nil
+}
+
+func exportBundle(ctx context.Context, config *ValidateBundleConfig) error {
+	client, err := rpc.DialContext(ctx, config.NodeURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	block := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(config.Block))
+	var bundle hexutil.Bytes
+	var moduleRoot *common.Hash
+	if config.ModuleRoot != "" {
+		hash := common.HexToHash(config.ModuleRoot)
+		moduleRoot = &hash
+	}
+	if err := client.CallContext(ctx, &bundle, "arbvalidator_exportBundle", block, moduleRoot); err != nil {
+		return err
+	}
+	return os.WriteFile(config.Bundle, bundle, 0644)
+}
+
+func startup() error {
+	config, err := parseValidateBundle(os.Args[1:])
+	if err != nil {
+		util.HandleError(err, printSampleUsage)
+		return nil
+	}
+
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(config.LogLevel))
+	log.Root().SetHandler(glogger)
+
+	ctx := context.Background()
+	if config.NodeURL != "" {
+		if err := exportBundle(ctx, config); err != nil {
+			return fmt.Errorf("error exporting bundle of block %d: %w", config.Block, err)
+		}
+		fmt.Printf("Exported bundle of block %d to %s\n", config.Block, config.Bundle)
+	}
+
+	bundle, err := validator.ReadValidationBundleFile(config.Bundle)
+	if err != nil {
+		return err
+	}
+	if config.ModuleRoot != "" {
+		bundle.ModuleRoot = common.HexToHash(config.ModuleRoot)
+	}
+
+	machinesPath, foundMachines := config.Wasm.FindMachineDir()
+	if !foundMachines {
+		return fmt.Errorf("failed to find machines %v", machinesPath)
+	}
+	machineConfig := validator.DefaultNitroMachineConfig
+	machineConfig.RootPath = machinesPath
+	machineConfig.JitCranelift = config.JitCranelift
+	fatalErrChan := make(chan error, 10)
+	machineLoader := validator.NewNitroMachineLoader(machineConfig, fatalErrChan)
+
+	machineName := "arbitrator"
+	if config.Jit {
+		machineName = "jit"
+	}
+	fmt.Printf("Validating block %d (%v) with the %s machine for module root %v\n", bundle.BlockNumber, bundle.BlockHash, machineName, bundle.ModuleRoot)
+	gsEnd, err := bundle.Run(ctx, machineLoader, config.Jit)
+	if err != nil {
+		return fmt.Errorf("error running validation: %w", err)
+	}
+	fmt.Printf("Expected: %+v\n", bundle.EndState)
+	fmt.Printf("Got:      %+v\n", gsEnd)
+	if gsEnd != bundle.EndState {
+		fmt.Println("MISMATCH")
+		return errMismatch
+	}
+	fmt.Println("MATCH")
+	return nil
+}
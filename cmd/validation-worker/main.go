@@ -0,0 +1,181 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	koanfjson "github.com/knadh/koanf/parsers/json"
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/exp"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/cmd/util"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+type ValidationWorkerConfig struct {
+	Addr           string                              `koanf:"addr"`
+	Port           uint64                              `koanf:"port"`
+	ServerTimeouts genericconf.HTTPServerTimeoutConfig `koanf:"server-timeouts"`
+
+	ConcurrentRunsLimit int                `koanf:"concurrent-runs-limit"`
+	JitCranelift        bool               `koanf:"jit-cranelift"`
+	Wasm                arbnode.WasmConfig `koanf:"wasm"`
+
+	ConfConfig genericconf.ConfConfig `koanf:"conf"`
+	LogLevel   int                    `koanf:"log-level"`
+
+	Metrics       bool                            `koanf:"metrics"`
+	MetricsServer genericconf.MetricsServerConfig `koanf:"metrics-server"`
+}
+
+var DefaultValidationWorkerConfig = ValidationWorkerConfig{
+	Addr: "localhost",
+	Port: 8549,
+	// Responses are only written once the validation has run.
+	ServerTimeouts: genericconf.HTTPServerTimeoutConfig{
+		ReadTimeout:       time.Minute,
+		ReadHeaderTimeout: 30 * time.Second,
+		WriteTimeout:      15 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	},
+	ConcurrentRunsLimit: 0,
+	JitCranelift:        validator.DefaultBlockValidatorConfig.JitValidatorCranelift,
+	Wasm:                arbnode.DefaultWasmConfig,
+	ConfConfig:          genericconf.ConfConfigDefault,
+	LogLevel:            3,
+	Metrics:             false,
+	MetricsServer:       genericconf.MetricsServerConfigDefault,
+}
+
+func main() {
+	if err := startup(); err != nil {
+		log.Error("Error running validation worker", "err", err)
+	}
+}
+
+func printSampleUsage(progname string) {
+	fmt.Printf("\n")
+	fmt.Printf("Sample usage:                  %s --help \n", progname)
+}
+
+func parseValidationWorker(args []string) (*ValidationWorkerConfig, error) {
+	f := flag.NewFlagSet("validation-worker", flag.ContinueOnError)
+	f.String("addr", DefaultValidationWorkerConfig.Addr, "HTTP and WebSocket RPC server listening interface")
+	f.Uint64("port", DefaultValidationWorkerConfig.Port, "HTTP and WebSocket RPC server listening port")
+	timeouts := DefaultValidationWorkerConfig.ServerTimeouts
+	f.Duration("server-timeouts.read-timeout", timeouts.ReadTimeout, "the maximum duration for reading the entire request (http.Server.ReadTimeout)")
+	f.Duration("server-timeouts.read-header-timeout", timeouts.ReadHeaderTimeout, "the amount of time allowed to read the request headers (http.Server.ReadHeaderTimeout)")
+	f.Duration("server-timeouts.write-timeout", timeouts.WriteTimeout, "the maximum duration before timing out writes of the response, which must allow for running the validation (http.Server.WriteTimeout)")
+	f.Duration("server-timeouts.idle-timeout", timeouts.IdleTimeout, "the maximum amount of time to wait for the next request when keep-alives are enabled (http.Server.IdleTimeout)")
+
+	f.Int("concurrent-runs-limit", DefaultValidationWorkerConfig.ConcurrentRunsLimit, "validations to run at once (0 for the number of CPUs)")
+	f.Bool("jit-cranelift", DefaultValidationWorkerConfig.JitCranelift, "use Cranelift instead of LLVM when running jit validations")
+	arbnode.WasmConfigAddOptions("wasm", f)
+
+	f.Bool("metrics", DefaultValidationWorkerConfig.Metrics, "enable metrics")
+	genericconf.MetricsServerAddOptions("metrics-server", f)
+
+	f.Int("log-level", int(log.LvlInfo), "log level; 1: ERROR, 2: WARN, 3: INFO, 4: DEBUG, 5: TRACE")
+	genericconf.ConfConfigAddOptions("conf", f)
+
+	k, err := util.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ValidationWorkerConfig
+	if err := util.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if config.ConfConfig.Dump {
+		c, err := k.Marshal(koanfjson.Parser())
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal config file to JSON: %w", err)
+		}
+
+		fmt.Println(string(c))
+		os.Exit(0)
+	}
+
+	return &config, nil
+}
+
+func startup() error {
+	config, err := parseValidationWorker(os.Args[1:])
+	if err != nil {
+		util.HandleError(err, printSampleUsage)
+		return nil
+	}
+
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(config.LogLevel))
+	log.Root().SetHandler(glogger)
+
+	if config.Metrics {
+		if len(config.MetricsServer.Addr) == 0 {
+			fmt.Printf("Metrics is enabled, but missing --metrics-server.addr")
+			return nil
+		}
+
+		go metrics.CollectProcessMetrics(config.MetricsServer.UpdateInterval)
+
+		address := fmt.Sprintf("%v:%v", config.MetricsServer.Addr, config.MetricsServer.Port)
+		exp.Setup(address)
+	}
+
+	machinesPath, foundMachines := config.Wasm.FindMachineDir()
+	if !foundMachines {
+		return fmt.Errorf("failed to find machines %v", machinesPath)
+	}
+	machineConfig := validator.DefaultNitroMachineConfig
+	machineConfig.RootPath = machinesPath
+	machineConfig.JitCranelift = config.JitCranelift
+	fatalErrChan := make(chan error, 10)
+	machineLoader := validator.NewNitroMachineLoader(machineConfig, fatalErrChan)
+
+	concurrent := config.ConcurrentRunsLimit
+	if concurrent == 0 {
+		concurrent = runtime.NumCPU()
+	}
+	worker := validator.NewValidationWorker(machineLoader, concurrent)
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Addr, config.Port))
+	if err != nil {
+		return err
+	}
+	vcsRevision, vcsTime := util.GetVersion()
+	log.Info("Starting validation worker", "addr", listener.Addr(), "machines", machinesPath, "concurrent", concurrent, "revision", vcsRevision, "vcs.time", vcsTime)
+	server, err := validator.ServeValidationWorker(listener, config.ServerTimeouts, worker)
+	if err != nil {
+		return err
+	}
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case <-sigint:
+	case err = <-fatalErrChan:
+		log.Error("Shutting down validation worker", "err", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if shutdownErr := server.Shutdown(ctx); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
+	return err
+}
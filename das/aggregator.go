@@ -10,10 +10,12 @@ import (
 	"fmt"
 	"math/bits"
 	"os"
+	"sync"
 	"time"
 
 	flag "github.com/spf13/pflag"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
@@ -26,39 +28,94 @@ import (
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/util/contracts"
 	"github.com/offchainlabs/nitro/util/pretty"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
 type AggregatorConfig struct {
-	Enable        bool   `koanf:"enable"`
-	AssumedHonest int    `koanf:"assumed-honest"`
-	Backends      string `koanf:"backends"`
-	DumpKeyset    bool   `koanf:"dump-keyset"`
+	Enable                    bool                  `koanf:"enable"`
+	AssumedHonest             int                   `koanf:"assumed-honest"`
+	Backends                  string                `koanf:"backends"`
+	BackendsFile              string                `koanf:"backends-file"`
+	BackendsReloadInterval    time.Duration         `koanf:"backends-reload-interval"`
+	KeysetSwitchTime          int64                 `koanf:"keyset-switch-time"`
+	WaitForKeysetRegistration bool                  `koanf:"wait-for-keyset-registration"`
+	AdaptiveTimeout           AdaptiveTimeoutConfig `koanf:"adaptive-timeout"`
+	DumpKeyset                bool                  `koanf:"dump-keyset"`
 }
 
 var DefaultAggregatorConfig = AggregatorConfig{
-	AssumedHonest: 0,
-	Backends:      "",
-	DumpKeyset:    false,
+	AssumedHonest:             0,
+	Backends:                  "",
+	BackendsFile:              "",
+	BackendsReloadInterval:    time.Minute,
+	KeysetSwitchTime:          0,
+	WaitForKeysetRegistration: true,
+	AdaptiveTimeout:           DefaultAdaptiveTimeoutConfig,
+	DumpKeyset:                false,
 }
 
 func AggregatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultAggregatorConfig.Enable, "enable storage/retrieval of sequencer batch data from a list of RPC endpoints; this should only be used by the batch poster and not in combination with other DAS storage types")
 	f.Int(prefix+".assumed-honest", DefaultAggregatorConfig.AssumedHonest, "Number of assumed honest backends (H). If there are N backends, K=N+1-H valid responses are required to consider an Store request to be successful.")
 	f.String(prefix+".backends", DefaultAggregatorConfig.Backends, "JSON RPC backend configuration")
+	f.String(prefix+".backends-file", DefaultAggregatorConfig.BackendsFile, "file containing the JSON RPC backend configuration, re-read every backends-reload-interval; takes precedence over backends")
+	f.Duration(prefix+".backends-reload-interval", DefaultAggregatorConfig.BackendsReloadInterval, "how often to check backends-file for a changed backend list (0 to disable reloading)")
+	f.Int64(prefix+".keyset-switch-time", DefaultAggregatorConfig.KeysetSwitchTime, "earliest time (unix epoch seconds) at which a keyset reloaded from backends-file replaces the current one (0 to switch as soon as possible)")
+	f.Bool(prefix+".wait-for-keyset-registration", DefaultAggregatorConfig.WaitForKeysetRegistration, "only switch to a keyset reloaded from backends-file once it has been registered as valid on the SequencerInbox; ignored if no SequencerInbox is configured")
+	AdaptiveTimeoutConfigAddOptions(prefix+".adaptive-timeout", f)
 	f.Bool(prefix+".dump-keyset", DefaultAggregatorConfig.DumpKeyset, "Dump the keyset encoded in hexadecimal for the backends string")
 }
 
 type Aggregator struct {
+	stopwaiter.StopWaiter
+
 	config         AggregatorConfig
-	services       []ServiceDetails
 	requestTimeout time.Duration
 
 	// calculated fields
+	bpVerifier     *contracts.BatchPosterVerifier
+	seqInboxCaller *bridgegen.SequencerInboxCaller
+
+	// keyset is used for all Stores; pendingKeyset replaces it once
+	// it is allowed to (see maybeActivatePendingKeyset).
+	keysetMutex   sync.RWMutex
+	keyset        *aggregatorKeyset
+	pendingKeyset *aggregatorKeyset
+
+	memberStats *aggregatorMemberStats
+}
+
+// aggregatorKeyset is the set of backends used for a Store along with the
+// DataAvailabilityKeyset derived from their public keys.
+type aggregatorKeyset struct {
+	services                       []ServiceDetails
+	assumedHonest                  int
 	requiredServicesForStore       int
 	maxAllowedServiceStoreFailures int
 	keysetHash                     [32]byte
 	keysetBytes                    []byte
-	bpVerifier                     *contracts.BatchPosterVerifier
+
+	// Store requests still using the services, which mustn't be closed
+	// before they finish.
+	inUse sync.WaitGroup
+}
+
+// release closes the keyset's backend clients once the Stores still using
+// them are done. It must only be called once the keyset has been replaced,
+// so no new Stores can start using it.
+func (k *aggregatorKeyset) release() {
+	go func() {
+		k.inUse.Wait()
+		closeServices(k.services)
+	}()
+}
+
+func closeServices(services []ServiceDetails) {
+	for _, d := range services {
+		if closer, ok := d.service.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
 }
 
 type ServiceDetails struct {
@@ -120,6 +177,32 @@ func NewAggregatorWithSeqInboxCaller(
 	services []ServiceDetails,
 	seqInboxCaller *bridgegen.SequencerInboxCaller,
 ) (*Aggregator, error) {
+	keyset, err := newAggregatorKeyset(services, config.AggregatorConfig.AssumedHonest)
+	if err != nil {
+		return nil, err
+	}
+	if config.AggregatorConfig.DumpKeyset {
+		fmt.Printf("Keyset: %s\n", hexutil.Encode(keyset.keysetBytes))
+		fmt.Printf("KeysetHash: %s\n", hexutil.Encode(keyset.keysetHash[:]))
+		os.Exit(0)
+	}
+
+	var bpVerifier *contracts.BatchPosterVerifier
+	if seqInboxCaller != nil {
+		bpVerifier = contracts.NewBatchPosterVerifier(seqInboxCaller)
+	}
+
+	return &Aggregator{
+		config:         config.AggregatorConfig,
+		requestTimeout: config.RequestTimeout,
+		bpVerifier:     bpVerifier,
+		seqInboxCaller: seqInboxCaller,
+		keyset:         keyset,
+		memberStats:    newAggregatorMemberStats(config.AggregatorConfig.AdaptiveTimeout, config.RequestTimeout),
+	}, nil
+}
+
+func newAggregatorKeyset(services []ServiceDetails, assumedHonest int) (*aggregatorKeyset, error) {
 	var aggSignersMask uint64
 	pubKeys := []blsSignatures.PublicKey{}
 	for _, d := range services {
@@ -134,7 +217,7 @@ func NewAggregatorWithSeqInboxCaller(
 	}
 
 	keyset := &arbstate.DataAvailabilityKeyset{
-		AssumedHonest: uint64(config.AggregatorConfig.AssumedHonest),
+		AssumedHonest: uint64(assumedHonest),
 		PubKeys:       pubKeys,
 	}
 	ksBuf := bytes.NewBuffer([]byte{})
@@ -145,29 +228,147 @@ func NewAggregatorWithSeqInboxCaller(
 	if err != nil {
 		return nil, err
 	}
-	if config.AggregatorConfig.DumpKeyset {
-		fmt.Printf("Keyset: %s\n", hexutil.Encode(ksBuf.Bytes()))
-		fmt.Printf("KeysetHash: %s\n", hexutil.Encode(keysetHash[:]))
-		os.Exit(0)
-	}
-
-	var bpVerifier *contracts.BatchPosterVerifier
-	if seqInboxCaller != nil {
-		bpVerifier = contracts.NewBatchPosterVerifier(seqInboxCaller)
-	}
 
-	return &Aggregator{
-		config:                         config.AggregatorConfig,
+	return &aggregatorKeyset{
 		services:                       services,
-		requestTimeout:                 config.RequestTimeout,
-		requiredServicesForStore:       len(services) + 1 - config.AggregatorConfig.AssumedHonest,
-		maxAllowedServiceStoreFailures: config.AggregatorConfig.AssumedHonest - 1,
+		assumedHonest:                  assumedHonest,
+		requiredServicesForStore:       len(services) + 1 - assumedHonest,
+		maxAllowedServiceStoreFailures: assumedHonest - 1,
 		keysetHash:                     keysetHash,
 		keysetBytes:                    ksBuf.Bytes(),
-		bpVerifier:                     bpVerifier,
 	}, nil
 }
 
+// KeysetHash returns the hash of the keyset currently used to sign certificates.
+func (a *Aggregator) KeysetHash() common.Hash {
+	a.keysetMutex.RLock()
+	defer a.keysetMutex.RUnlock()
+	return a.keyset.keysetHash
+}
+
+// SetServices replaces the backends of the Aggregator. The new keyset is
+// staged as pending and is used for Stores once the configured switch time
+// has passed and, if required, it has been registered on the SequencerInbox.
+func (a *Aggregator) SetServices(ctx context.Context, services []ServiceDetails) error {
+	keyset, err := newAggregatorKeyset(services, a.config.AssumedHonest)
+	if err != nil {
+		closeServices(services)
+		return err
+	}
+	a.keysetMutex.Lock()
+	if keyset.keysetHash == a.keyset.keysetHash {
+		// Same members, but possibly new URLs for them.
+		a.keyset.release()
+		if a.pendingKeyset != nil {
+			a.pendingKeyset.release()
+		}
+		a.keyset = keyset
+		a.pendingKeyset = nil
+		a.keysetMutex.Unlock()
+		return nil
+	}
+	if a.pendingKeyset == nil || a.pendingKeyset.keysetHash != keyset.keysetHash {
+		log.Info("das.Aggregator: staged new keyset", "current", hexutil.Encode(a.keyset.keysetHash[:]), "pending", hexutil.Encode(keyset.keysetHash[:]))
+	}
+	if a.pendingKeyset != nil {
+		a.pendingKeyset.release()
+	}
+	a.pendingKeyset = keyset
+	a.keysetMutex.Unlock()
+	return a.maybeActivatePendingKeyset(ctx)
+}
+
+func (a *Aggregator) maybeActivatePendingKeyset(ctx context.Context) error {
+	a.keysetMutex.RLock()
+	pending := a.pendingKeyset
+	a.keysetMutex.RUnlock()
+	if pending == nil {
+		return nil
+	}
+	if time.Now().Unix() < a.config.KeysetSwitchTime {
+		return nil
+	}
+	if a.config.WaitForKeysetRegistration && a.seqInboxCaller != nil {
+		valid, err := a.seqInboxCaller.IsValidKeysetHash(&bind.CallOpts{Context: ctx}, pending.keysetHash)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			log.Debug("das.Aggregator: pending keyset not yet registered on SequencerInbox", "keysetHash", hexutil.Encode(pending.keysetHash[:]))
+			return nil
+		}
+	}
+
+	a.keysetMutex.Lock()
+	defer a.keysetMutex.Unlock()
+	if a.pendingKeyset != pending {
+		// Replaced while we were checking; the next check will handle it.
+		return nil
+	}
+	log.Info("das.Aggregator: switching keyset", "old", hexutil.Encode(a.keyset.keysetHash[:]), "new", hexutil.Encode(pending.keysetHash[:]))
+	a.keyset.release()
+	a.keyset = pending
+	a.pendingKeyset = nil
+	return nil
+}
+
+// Start launches a thread which reloads the backends from backends-file and
+// activates pending keysets. It does nothing if backends-file isn't set.
+func (a *Aggregator) Start(ctx context.Context) {
+	a.StopWaiter.Start(ctx)
+	if a.config.BackendsFile == "" || a.config.BackendsReloadInterval == 0 {
+		return
+	}
+	lastBackends, err := os.ReadFile(a.config.BackendsFile)
+	if err != nil {
+		log.Warn("das.Aggregator: couldn't read backends-file", "file", a.config.BackendsFile, "err", err)
+	}
+	a.CallIteratively(func(ctx context.Context) time.Duration {
+		backends, err := os.ReadFile(a.config.BackendsFile)
+		if err != nil {
+			log.Warn("das.Aggregator: couldn't read backends-file", "file", a.config.BackendsFile, "err", err)
+			return a.config.BackendsReloadInterval
+		}
+		if !bytes.Equal(backends, lastBackends) {
+			services, err := ParseServices(backends)
+			if err != nil {
+				log.Warn("das.Aggregator: invalid backends-file, keeping the current backends", "file", a.config.BackendsFile, "err", err)
+				return a.config.BackendsReloadInterval
+			}
+			if err := a.SetServices(ctx, services); err != nil {
+				log.Warn("das.Aggregator: couldn't apply reloaded backends", "err", err)
+				return a.config.BackendsReloadInterval
+			}
+			lastBackends = backends
+			return a.config.BackendsReloadInterval
+		}
+		if err := a.maybeActivatePendingKeyset(ctx); err != nil {
+			log.Warn("das.Aggregator: error checking pending keyset", "err", err)
+		}
+		return a.config.BackendsReloadInterval
+	})
+}
+
+func (a *Aggregator) Close(ctx context.Context) error {
+	a.StopWaiter.StopOnly()
+	waitChan, err := a.StopWaiter.GetWaitChannel()
+	if err == nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-waitChan:
+		}
+	}
+	a.keysetMutex.Lock()
+	defer a.keysetMutex.Unlock()
+	a.keyset.release()
+	if a.pendingKeyset != nil {
+		a.pendingKeyset.release()
+		a.pendingKeyset = nil
+	}
+	return nil
+}
+
 type storeResponse struct {
 	details ServiceDetails
 	sig     blsSignatures.Signature
@@ -179,8 +380,11 @@ type storeResponse struct {
 // signersMasks from each DAS together into the DataAvailabilityCertificate
 // then Store returns immediately. If there were any backend Store subroutines
 // that were still running when Aggregator.Store returns, they are allowed to
-// continue running until the context is canceled (eg via TimeoutWrapper),
-// with their results discarded.
+// continue running until their own timeout, with their results only used for
+// logs, metrics and the per-backend latency stats.
+//
+// Each backend is given request-timeout to respond, or if adaptive-timeout is
+// enabled, a timeout derived from the latency it has shown on previous Stores.
 //
 // If Store gets enough errors that K successes is impossible, then it stops early
 // and returns an error.
@@ -208,28 +412,60 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64,
 		}
 	}
 
-	responses := make(chan storeResponse, len(a.services))
+	a.keysetMutex.RLock()
+	keyset := a.keyset
+	keyset.inUse.Add(len(keyset.services))
+	a.keysetMutex.RUnlock()
+
+	responses := make(chan storeResponse, len(keyset.services))
+
+	// Closed once Store has returned a certificate. Backend requests still in
+	// flight are then left to run to their own timeout rather than being
+	// canceled along with ctx.
+	quorumReached := make(chan struct{})
 
 	expectedHash := dastree.Hash(message)
-	for _, d := range a.services {
+	for _, d := range keyset.services {
 		go func(ctx context.Context, d ServiceDetails) {
-			storeCtx, cancel := context.WithTimeout(ctx, a.requestTimeout)
+			defer keyset.inUse.Done()
+			memberTimeout := a.memberStats.timeout(d)
+			storeCtx, cancel := context.WithTimeout(context.Background(), memberTimeout)
+			defer cancel()
+			go func() {
+				select {
+				case <-ctx.Done():
+					select {
+					case <-quorumReached:
+					default:
+						cancel()
+					}
+				case <-quorumReached:
+				case <-storeCtx.Done():
+				}
+			}()
+
 			const metricBase string = "arb/das/rpc/aggregator/store"
 			var metricWithServiceName string = metricBase + "/" + d.metricName
-			defer cancel()
 			incFailureMetric := func() {
 				metrics.GetOrRegisterGauge(metricWithServiceName+"/failure", nil).Inc(1)
 				metrics.GetOrRegisterGauge(metricBase+"/all/failure", nil).Inc(1)
 			}
 
+			start := time.Now()
 			cert, err := d.service.Store(storeCtx, message, timeout, sig)
+			latency := time.Since(start)
 			if err != nil {
 				incFailureMetric()
-				if errors.Is(err, context.DeadlineExceeded) {
+				timedOut := errors.Is(err, context.DeadlineExceeded)
+				if timedOut {
 					metrics.GetOrRegisterGauge(metricWithServiceName+"/timeout", nil).Inc(1)
 				} else {
 					metrics.GetOrRegisterGauge(metricWithServiceName+"/client_error", nil).Inc(1)
 				}
+				if ctx.Err() == nil || timedOut {
+					// Don't penalize a backend for our caller giving up.
+					a.memberStats.record(d, latency, err, timedOut)
+				}
 				responses <- storeResponse{d, nil, err}
 				return
 			}
@@ -237,31 +473,21 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64,
 			verified, err := blsSignatures.VerifySignature(
 				cert.Sig, cert.SerializeSignableFields(), d.pubKey,
 			)
-			if err != nil {
-				incFailureMetric()
-				metrics.GetOrRegisterGauge(metricWithServiceName+"/bad_response", nil).Inc(1)
-				responses <- storeResponse{d, nil, err}
-				return
+			if err == nil && !verified {
+				err = errors.New("Signature verification failed.")
 			}
-			if !verified {
-				incFailureMetric()
-				metrics.GetOrRegisterGauge(metricWithServiceName+"/bad_response", nil).Inc(1)
-				responses <- storeResponse{d, nil, errors.New("Signature verification failed.")}
-				return
-			}
-
 			// SignersMask from backend DAS is ignored.
-
-			if cert.DataHash != expectedHash {
-				incFailureMetric()
-				metrics.GetOrRegisterGauge(metricWithServiceName+"/bad_response", nil).Inc(1)
-				responses <- storeResponse{d, nil, errors.New("Hash verification failed.")}
-				return
+			if err == nil && cert.DataHash != expectedHash {
+				err = errors.New("Hash verification failed.")
+			}
+			if err == nil && cert.Timeout != timeout {
+				err = fmt.Errorf("Timeout was %d, expected %d", cert.Timeout, timeout)
 			}
-			if cert.Timeout != timeout {
+			a.memberStats.record(d, latency, err, false)
+			if err != nil {
 				incFailureMetric()
 				metrics.GetOrRegisterGauge(metricWithServiceName+"/bad_response", nil).Inc(1)
-				responses <- storeResponse{d, nil, fmt.Errorf("Timeout was %d, expected %d", cert.Timeout, timeout)}
+				responses <- storeResponse{d, nil, err}
 				return
 			}
 
@@ -281,59 +507,64 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64,
 	}
 
 	// Collect responses from backends.
-	certDetailsChan := make(chan certDetails)
+	certDetailsChan := make(chan certDetails, 1)
 	go func() {
 		var pubKeys []blsSignatures.PublicKey
 		var sigs []blsSignatures.Signature
 		var aggSignersMask uint64
 		var storeFailures, successfullyStoredCount int
 		var returned bool
-		for i := 0; i < len(a.services); i++ {
-
-			select {
-			case <-ctx.Done():
-				break
-			case r := <-responses:
-				if r.err != nil {
-					storeFailures++
-					log.Warn("das.Aggregator: Error from backend", "backend", r.details.service, "signerMask", r.details.signersMask, "err", r.err)
-				} else {
-					pubKeys = append(pubKeys, r.details.pubKey)
-					sigs = append(sigs, r.sig)
-					aggSignersMask |= r.details.signersMask
-
-					successfullyStoredCount++
+		// Every backend goroutine sends exactly one response, bounded by its
+		// own timeout, so this loop always terminates.
+		for i := 0; i < len(keyset.services); i++ {
+			r := <-responses
+			if r.err != nil {
+				storeFailures++
+				log.Warn("das.Aggregator: Error from backend", "backend", r.details.service, "signerMask", r.details.signersMask, "err", r.err)
+			} else {
+				pubKeys = append(pubKeys, r.details.pubKey)
+				sigs = append(sigs, r.sig)
+				aggSignersMask |= r.details.signersMask
+
+				successfullyStoredCount++
+				if returned {
+					metrics.GetOrRegisterGauge("arb/das/rpc/aggregator/store/"+r.details.metricName+"/late", nil).Inc(1)
 				}
 			}
 
 			// As soon as enough responses are returned, pass the response to
 			// certDetailsChan, so the Store function can return, but also continue
-			// running until all responses are received (or the context is canceled)
-			// in order to produce accurate logs/metrics.
+			// running until all responses are received in order to produce
+			// accurate logs/metrics.
 			if !returned {
-				if successfullyStoredCount >= a.requiredServicesForStore {
+				if successfullyStoredCount >= keyset.requiredServicesForStore {
 					cd := certDetails{}
 					cd.pubKeys = append(cd.pubKeys, pubKeys...)
 					cd.sigs = append(cd.sigs, sigs...)
 					cd.aggSignersMask = aggSignersMask
 					certDetailsChan <- cd
 					returned = true
-				} else if storeFailures > a.maxAllowedServiceStoreFailures {
+				} else if storeFailures > keyset.maxAllowedServiceStoreFailures {
 					cd := certDetails{}
-					cd.err = fmt.Errorf("Aggregator failed to store message to at least %d out of %d DASes (assuming %d are honest)", a.requiredServicesForStore, len(a.services), a.config.AssumedHonest)
+					cd.err = fmt.Errorf("Aggregator failed to store message to at least %d out of %d DASes (assuming %d are honest)", keyset.requiredServicesForStore, len(keyset.services), keyset.assumedHonest)
 					certDetailsChan <- cd
 					returned = true
 				}
 			}
-
 		}
 	}()
 
-	cd := <-certDetailsChan
+	var cd certDetails
+	select {
+	case cd = <-certDetailsChan:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	if cd.err != nil {
 		return nil, cd.err
 	}
+	close(quorumReached)
 
 	aggCert.Sig = blsSignatures.AggregateSignatures(cd.sigs)
 	aggPubKey := blsSignatures.AggregatePublicKeys(cd.pubKeys)
@@ -341,7 +572,7 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64,
 
 	aggCert.DataHash = expectedHash
 	aggCert.Timeout = timeout
-	aggCert.KeysetHash = a.keysetHash
+	aggCert.KeysetHash = keyset.keysetHash
 	aggCert.Version = 1
 
 	verified, err := blsSignatures.VerifySignature(aggCert.Sig, aggCert.SerializeSignableFields(), aggPubKey)
@@ -354,11 +585,23 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64,
 	return &aggCert, nil
 }
 
+// MemberStats returns the latency and error rate observed for each backend
+// of the current keyset, and the timeout it will be given on the next Store.
+func (a *Aggregator) MemberStats() []AggregatorMemberStats {
+	a.keysetMutex.RLock()
+	services := a.keyset.services
+	a.keysetMutex.RUnlock()
+	return a.memberStats.snapshot(services)
+}
+
 func (a *Aggregator) String() string {
 	var b bytes.Buffer
+	a.keysetMutex.RLock()
+	services := a.keyset.services
+	a.keysetMutex.RUnlock()
 	b.WriteString("das.Aggregator{")
 	first := true
-	for _, d := range a.services {
+	for _, d := range services {
 		if !first {
 			b.WriteString(",")
 		}
@@ -0,0 +1,159 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+)
+
+type AdaptiveTimeoutConfig struct {
+	Enable             bool          `koanf:"enable"`
+	MinTimeout         time.Duration `koanf:"min-timeout"`
+	LatencyMultiplier  float64       `koanf:"latency-multiplier"`
+	DecayFactor        float64       `koanf:"decay-factor"`
+	MinSamples         uint64        `koanf:"min-samples"`
+	ErrorRateThreshold float64       `koanf:"error-rate-threshold"`
+}
+
+var DefaultAdaptiveTimeoutConfig = AdaptiveTimeoutConfig{
+	Enable:             false,
+	MinTimeout:         time.Second,
+	LatencyMultiplier:  3,
+	DecayFactor:        0.1,
+	MinSamples:         5,
+	ErrorRateThreshold: 0.5,
+}
+
+func AdaptiveTimeoutConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultAdaptiveTimeoutConfig.Enable, "set the Store timeout of each backend from its observed latency instead of always using request-timeout")
+	f.Duration(prefix+".min-timeout", DefaultAdaptiveTimeoutConfig.MinTimeout, "lowest Store timeout given to any backend")
+	f.Float64(prefix+".latency-multiplier", DefaultAdaptiveTimeoutConfig.LatencyMultiplier, "a backend's Store timeout is this multiple of its mean latency plus two deviations, capped at request-timeout")
+	f.Float64(prefix+".decay-factor", DefaultAdaptiveTimeoutConfig.DecayFactor, "weight (0-1) given to the newest sample in the moving averages of backend latency and error rate")
+	f.Uint64(prefix+".min-samples", DefaultAdaptiveTimeoutConfig.MinSamples, "number of Stores a backend must have responded to before its timeout is adapted")
+	f.Float64(prefix+".error-rate-threshold", DefaultAdaptiveTimeoutConfig.ErrorRateThreshold, "backends with a (non-timeout) error rate above this are only given min-timeout")
+}
+
+// memberStats tracks exponentially weighted moving averages of the
+// latency and error rate of one committee member.
+type memberStats struct {
+	samples       uint64
+	ewmaLatency   float64 // nanoseconds
+	ewmaDeviation float64 // nanoseconds
+	ewmaErrorRate float64
+}
+
+func (s *memberStats) record(latency time.Duration, isError bool, decay float64) {
+	sample := float64(latency)
+	var errorSample float64
+	if isError {
+		errorSample = 1
+	}
+	if s.samples == 0 {
+		s.ewmaLatency = sample
+		s.ewmaErrorRate = errorSample
+	} else {
+		s.ewmaDeviation = (1-decay)*s.ewmaDeviation + decay*math.Abs(sample-s.ewmaLatency)
+		s.ewmaLatency = (1-decay)*s.ewmaLatency + decay*sample
+		s.ewmaErrorRate = (1-decay)*s.ewmaErrorRate + decay*errorSample
+	}
+	s.samples++
+}
+
+type AggregatorMemberStats struct {
+	Name      string
+	Samples   uint64
+	Latency   time.Duration
+	ErrorRate float64
+	Timeout   time.Duration
+}
+
+// aggregatorMemberStats is keyed by the members' public keys so that stats
+// survive a reload of the backend list.
+type aggregatorMemberStats struct {
+	sync.Mutex
+	config         AdaptiveTimeoutConfig
+	requestTimeout time.Duration
+	stats          map[string]*memberStats
+}
+
+func newAggregatorMemberStats(config AdaptiveTimeoutConfig, requestTimeout time.Duration) *aggregatorMemberStats {
+	return &aggregatorMemberStats{
+		config:         config,
+		requestTimeout: requestTimeout,
+		stats:          make(map[string]*memberStats),
+	}
+}
+
+func memberStatsKey(pubKey blsSignatures.PublicKey) string {
+	return string(blsSignatures.PublicKeyToBytes(pubKey))
+}
+
+// record adds a sample for a Store to d. Timeouts count as errors only
+// through their latency, otherwise a member that was given too short a timeout
+// would never get a longer one.
+func (m *aggregatorMemberStats) record(d ServiceDetails, latency time.Duration, err error, timedOut bool) {
+	m.Lock()
+	defer m.Unlock()
+	key := memberStatsKey(d.pubKey)
+	s, ok := m.stats[key]
+	if !ok {
+		s = &memberStats{}
+		m.stats[key] = s
+	}
+	s.record(latency, err != nil && !timedOut, m.config.DecayFactor)
+
+	metricBase := "arb/das/rpc/aggregator/store/" + d.metricName
+	metrics.GetOrRegisterGauge(metricBase+"/latency", nil).Update(int64(s.ewmaLatency))
+	metrics.GetOrRegisterGaugeFloat64(metricBase+"/error_rate", nil).Update(s.ewmaErrorRate)
+	metrics.GetOrRegisterGauge(metricBase+"/adaptive_timeout_ns", nil).Update(int64(m.timeoutLocked(s)))
+}
+
+func (m *aggregatorMemberStats) timeout(d ServiceDetails) time.Duration {
+	m.Lock()
+	defer m.Unlock()
+	return m.timeoutLocked(m.stats[memberStatsKey(d.pubKey)])
+}
+
+func (m *aggregatorMemberStats) timeoutLocked(s *memberStats) time.Duration {
+	if !m.config.Enable || s == nil || s.samples < m.config.MinSamples {
+		return m.requestTimeout
+	}
+	if s.ewmaErrorRate > m.config.ErrorRateThreshold {
+		return m.config.MinTimeout
+	}
+	timeout := time.Duration(m.config.LatencyMultiplier * (s.ewmaLatency + 2*s.ewmaDeviation))
+	if timeout < m.config.MinTimeout {
+		return m.config.MinTimeout
+	}
+	if timeout > m.requestTimeout {
+		return m.requestTimeout
+	}
+	return timeout
+}
+
+func (m *aggregatorMemberStats) snapshot(services []ServiceDetails) []AggregatorMemberStats {
+	m.Lock()
+	defer m.Unlock()
+	var ret []AggregatorMemberStats
+	for _, d := range services {
+		entry := AggregatorMemberStats{Name: d.metricName}
+		s := m.stats[memberStatsKey(d.pubKey)]
+		if s != nil {
+			entry.Samples = s.samples
+			entry.Latency = time.Duration(s.ewmaLatency)
+			entry.ErrorRate = s.ewmaErrorRate
+		}
+		entry.Timeout = m.timeoutLocked(s)
+		ret = append(ret, entry)
+	}
+	return ret
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/offchainlabs/nitro/blsSignatures"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/offchainlabs/nitro/arbstate"
 )
 
@@ -288,3 +289,88 @@ func TestDAS_AtLeastHStorageFailures(t *testing.T) {
 		})
 	}
 }
+
+type slowStore struct {
+	DataAvailabilityService
+	delay time.Duration
+}
+
+func (s *slowStore) Store(ctx context.Context, message []byte, timeout uint64, sig []byte) (*arbstate.DataAvailabilityCertificate, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return s.DataAvailabilityService.Store(ctx, message, timeout, sig)
+}
+
+func TestDAS_AdaptiveTimeoutAndLateSigners(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const slowDelay = 300 * time.Millisecond
+	backends := newTestBackends(t, ctx, 3)
+	backends[2].service = &slowStore{backends[2].service.(DataAvailabilityService), slowDelay}
+
+	adaptiveConfig := DefaultAdaptiveTimeoutConfig
+	adaptiveConfig.Enable = true
+	adaptiveConfig.MinTimeout = 50 * time.Millisecond
+	adaptiveConfig.MinSamples = 3
+	aggregator, err := NewAggregator(ctx, DataAvailabilityConfig{
+		AggregatorConfig: AggregatorConfig{AssumedHonest: 2, AdaptiveTimeout: adaptiveConfig},
+		L1NodeURL:        "none",
+		RequestTimeout:   5 * time.Second,
+	}, backends)
+	Require(t, err)
+
+	rawMsg := []byte("It's time for you to see the fnords.")
+	for i := 0; i < 5; i++ {
+		start := time.Now()
+		cert, err := aggregator.Store(ctx, rawMsg, 0, []byte{})
+		Require(t, err)
+		if time.Since(start) >= slowDelay {
+			Fail(t, "Store waited for the slow backend")
+		}
+		if cert.SignersMask&backends[2].signersMask != 0 {
+			Fail(t, "slow backend was included in the certificate")
+		}
+		// Let the late signer finish so its latency is recorded.
+		time.Sleep(slowDelay + 100*time.Millisecond)
+	}
+
+	stats := aggregator.MemberStats()
+	if len(stats) != 3 {
+		Fail(t, "expected stats for every backend", stats)
+	}
+	for i, s := range stats {
+		if s.Samples != 5 {
+			Fail(t, "backend", i, "has", s.Samples, "samples, expected 5")
+		}
+		if s.ErrorRate != 0 {
+			Fail(t, "backend", i, "has unexpected errors")
+		}
+		if s.Timeout < adaptiveConfig.MinTimeout || s.Timeout >= 5*time.Second {
+			Fail(t, "backend", i, "timeout", s.Timeout, "wasn't adapted")
+		}
+	}
+	if stats[2].Latency < slowDelay || stats[2].Timeout <= stats[0].Timeout {
+		Fail(t, "slow backend wasn't scored as slow", stats)
+	}
+}
+
+func TestDAS_MemberStatsKeepTimeoutCount(t *testing.T) {
+	// Gauges are no-ops unless metrics are enabled.
+	metrics.Enabled = true
+	d := ServiceDetails{metricName: "member_stats_test"}
+	stats := newAggregatorMemberStats(DefaultAdaptiveTimeoutConfig, 5*time.Second)
+	metricBase := "arb/das/rpc/aggregator/store/" + d.metricName
+	timeouts := metrics.GetOrRegisterGauge(metricBase+"/timeout", nil)
+	timeouts.Inc(1)
+	stats.record(d, time.Second, context.DeadlineExceeded, true)
+	if timeouts.Value() != 1 {
+		Fail(t, "timeout count overwritten with", timeouts.Value())
+	}
+	if adaptive := metrics.GetOrRegisterGauge(metricBase+"/adaptive_timeout_ns", nil).Value(); adaptive != int64(5*time.Second) {
+		Fail(t, "adaptive timeout gauge", adaptive, "expected", int64(5*time.Second))
+	}
+}
@@ -67,6 +67,7 @@ func (bcs *BigCacheStorageService) GetByHash(ctx context.Context, key common.Has
 
 	ret, err := bcs.bigCache.Get(string(key.Bytes()))
 	if err != nil {
+		bigCacheMissCounter.Inc(1)
 		ret, err = bcs.baseStorageService.GetByHash(ctx, key)
 		if err != nil {
 			return nil, err
@@ -79,6 +80,7 @@ func (bcs *BigCacheStorageService) GetByHash(ctx context.Context, key common.Has
 		return ret, err
 	}
 
+	bigCacheHitCounter.Inc(1)
 	return ret, err
 }
 
@@ -0,0 +1,124 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+)
+
+const DefaultKeystoreFilename = "das_bls.json"
+
+const blsKeystoreVersion = 1
+
+// blsKeystoreJSON is the format of an encrypted BLS keystore file. The private
+// key is encrypted with the same scrypt and AES-128-CTR scheme as the
+// go-ethereum ECDSA keystores.
+type blsKeystoreJSON struct {
+	Version int                 `json:"version"`
+	PubKey  string              `json:"pubkey"`
+	Crypto  keystore.CryptoJSON `json:"crypto"`
+}
+
+func EncryptBLSPrivateKey(privKey blsSignatures.PrivateKey, password string, scryptN, scryptP int) ([]byte, error) {
+	pubKey, err := blsSignatures.PublicKeyFromPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	cryptoJSON, err := keystore.EncryptDataV3(blsSignatures.PrivateKeyToBytes(privKey), []byte(password), scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(blsKeystoreJSON{
+		Version: blsKeystoreVersion,
+		PubKey:  base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(pubKey)),
+		Crypto:  cryptoJSON,
+	})
+}
+
+func DecryptBLSKeystore(keystoreBytes []byte, password string) (blsSignatures.PrivateKey, error) {
+	var ks blsKeystoreJSON
+	if err := json.Unmarshal(keystoreBytes, &ks); err != nil {
+		return nil, err
+	}
+	if ks.Version != blsKeystoreVersion {
+		return nil, fmt.Errorf("unsupported BLS keystore version %d", ks.Version)
+	}
+	privKeyBytes, err := keystore.DecryptDataV3(ks.Crypto, password)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := blsSignatures.PrivateKeyFromBytes(privKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := blsSignatures.PublicKeyFromPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	if base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(pubKey)) != ks.PubKey {
+		return nil, errors.New("BLS keystore public key doesn't match its private key")
+	}
+	return privKey, nil
+}
+
+func ReadPrivKeyFromKeystore(keystorePath string, password string) (blsSignatures.PrivateKey, error) {
+	keystoreBytes, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptBLSKeystore(keystoreBytes, password)
+}
+
+// ReadKeystorePassword reads a keystore password from passwordFile if set,
+// otherwise from the environment variable passwordEnv.
+func ReadKeystorePassword(passwordFile string, passwordEnv string) (string, error) {
+	if passwordFile != "" {
+		password, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(password), "\r\n"), nil
+	}
+	if passwordEnv != "" {
+		password, ok := os.LookupEnv(passwordEnv)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s holding the keystore password is not set", passwordEnv)
+		}
+		return password, nil
+	}
+	return "", errors.New("keystore-password-file or keystore-password-env must be specified along with keystore")
+}
+
+func GenerateAndStoreEncryptedKeys(keyDir string, password string) (*blsSignatures.PublicKey, error) {
+	pubKey, privKey, err := blsSignatures.GenerateKeys()
+	if err != nil {
+		return nil, err
+	}
+	keystoreBytes, err := EncryptBLSPrivateKey(privKey, password, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(keyDir+"/"+DefaultKeystoreFilename, keystoreBytes, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKeyBytes := blsSignatures.PublicKeyToBytes(pubKey)
+	encodedPubKey := make([]byte, base64.StdEncoding.EncodedLen(len(pubKeyBytes)))
+	base64.StdEncoding.Encode(encodedPubKey, pubKeyBytes)
+	err = os.WriteFile(keyDir+"/"+DefaultPubKeyFilename, encodedPubKey, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &pubKey, nil
+}
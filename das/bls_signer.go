@@ -0,0 +1,193 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+)
+
+// BLSSigner signs DAS certificates on behalf of a committee member.
+type BLSSigner interface {
+	PublicKey() blsSignatures.PublicKey
+	Sign(ctx context.Context, message []byte) (blsSignatures.Signature, error)
+	fmt.Stringer
+}
+
+// LocalBLSSigner signs with a private key held in this process.
+type LocalBLSSigner struct {
+	privKey blsSignatures.PrivateKey
+	pubKey  blsSignatures.PublicKey
+}
+
+func NewLocalBLSSigner(privKey blsSignatures.PrivateKey) (*LocalBLSSigner, error) {
+	pubKey, err := blsSignatures.PublicKeyFromPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalBLSSigner{
+		privKey: privKey,
+		pubKey:  pubKey,
+	}, nil
+}
+
+func (s *LocalBLSSigner) PublicKey() blsSignatures.PublicKey {
+	return s.pubKey
+}
+
+func (s *LocalBLSSigner) Sign(ctx context.Context, message []byte) (blsSignatures.Signature, error) {
+	return blsSignatures.SignMessage(s.privKey, message)
+}
+
+func (s *LocalBLSSigner) String() string {
+	return fmt.Sprintf("LocalBLSSigner{%v}", hexutil.Encode(blsSignatures.PublicKeyToBytes(s.pubKey)))
+}
+
+type RemoteBLSSignerConfig struct {
+	URL     string        `koanf:"url"`
+	PubKey  string        `koanf:"pubkey"`
+	Timeout time.Duration `koanf:"timeout"`
+}
+
+var DefaultRemoteBLSSignerConfig = RemoteBLSSignerConfig{
+	URL:     "",
+	PubKey:  "",
+	Timeout: 5 * time.Second,
+}
+
+func RemoteBLSSignerConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".url", DefaultRemoteBLSSignerConfig.URL, "URL (http:// or ws://) or unix socket path of a remote BLS signer implementing the blssigner_publicKey and blssigner_sign JSON-RPC methods")
+	f.String(prefix+".pubkey", DefaultRemoteBLSSignerConfig.PubKey, "base64 BLS public key the remote signer is expected to sign with; if empty it is fetched from the signer")
+	f.Duration(prefix+".timeout", DefaultRemoteBLSSignerConfig.Timeout, "timeout for requests to the remote signer")
+}
+
+// RemoteBLSSigner signs through a separate signer process over JSON-RPC, so
+// the private key never enters this process. Every signature returned is
+// checked against the signer's public key before being used.
+type RemoteBLSSigner struct {
+	client  *rpc.Client
+	url     string
+	pubKey  blsSignatures.PublicKey
+	timeout time.Duration
+}
+
+func NewRemoteBLSSigner(ctx context.Context, config *RemoteBLSSignerConfig) (*RemoteBLSSigner, error) {
+	if config.URL == "" {
+		return nil, errors.New("remote-signer.url must be specified")
+	}
+	client, err := rpc.DialContext(ctx, config.URL)
+	if err != nil {
+		return nil, err
+	}
+	s := &RemoteBLSSigner{
+		client:  client,
+		url:     config.URL,
+		timeout: config.Timeout,
+	}
+
+	callCtx, cancel := s.callContext(ctx)
+	defer cancel()
+	var pubKeyBytes hexutil.Bytes
+	if err := client.CallContext(callCtx, &pubKeyBytes, "blssigner_publicKey"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error fetching public key from remote BLS signer: %w", err)
+	}
+	remotePubKey, err := blsSignatures.PublicKeyFromBytes(pubKeyBytes, false)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	if config.PubKey != "" {
+		expectedPubKey, err := DecodeBase64BLSPublicKey([]byte(config.PubKey))
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+		if string(blsSignatures.PublicKeyToBytes(*expectedPubKey)) != string(pubKeyBytes) {
+			client.Close()
+			return nil, errors.New("remote BLS signer's public key doesn't match remote-signer.pubkey")
+		}
+	}
+	s.pubKey = remotePubKey
+	return s, nil
+}
+
+func (s *RemoteBLSSigner) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+func (s *RemoteBLSSigner) PublicKey() blsSignatures.PublicKey {
+	return s.pubKey
+}
+
+func (s *RemoteBLSSigner) Sign(ctx context.Context, message []byte) (blsSignatures.Signature, error) {
+	callCtx, cancel := s.callContext(ctx)
+	defer cancel()
+	var sigBytes hexutil.Bytes
+	if err := s.client.CallContext(callCtx, &sigBytes, "blssigner_sign", hexutil.Bytes(message)); err != nil {
+		return nil, err
+	}
+	sig, err := blsSignatures.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return nil, err
+	}
+	verified, err := blsSignatures.VerifySignature(sig, message, s.pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, errors.New("remote BLS signer returned an invalid signature")
+	}
+	return sig, nil
+}
+
+func (s *RemoteBLSSigner) Close() {
+	s.client.Close()
+}
+
+func (s *RemoteBLSSigner) String() string {
+	return fmt.Sprintf("RemoteBLSSigner{url:%s, pubKey:%v}", s.url, hexutil.Encode(blsSignatures.PublicKeyToBytes(s.pubKey)))
+}
+
+// BLSSignerAPI is the JSON-RPC service, registered under the "blssigner"
+// namespace, that a remote signer has to provide.
+type BLSSignerAPI struct {
+	signer BLSSigner
+}
+
+func NewBLSSignerAPI(signer BLSSigner) *BLSSignerAPI {
+	return &BLSSignerAPI{signer: signer}
+}
+
+func (api *BLSSignerAPI) PublicKey(ctx context.Context) (hexutil.Bytes, error) {
+	return blsSignatures.PublicKeyToBytes(api.signer.PublicKey()), nil
+}
+
+func (api *BLSSignerAPI) Sign(ctx context.Context, message hexutil.Bytes) (hexutil.Bytes, error) {
+	sig, err := api.signer.Sign(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	return blsSignatures.SignatureToBytes(sig), nil
+}
+
+func NewBLSSignerRPCServer(signer BLSSigner) (*rpc.Server, error) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("blssigner", NewBLSSignerAPI(signer)); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
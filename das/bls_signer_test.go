@@ -0,0 +1,193 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+)
+
+func TestDAS_BLSKeystore(t *testing.T) {
+	_, privKey, err := blsSignatures.GenerateKeys()
+	Require(t, err)
+
+	keystoreBytes, err := EncryptBLSPrivateKey(privKey, "correct horse", keystore.LightScryptN, keystore.LightScryptP)
+	Require(t, err)
+	if bytes.Contains(keystoreBytes, []byte(base64.StdEncoding.EncodeToString(blsSignatures.PrivateKeyToBytes(privKey)))) {
+		Fail(t, "keystore contains the plaintext private key")
+	}
+
+	decrypted, err := DecryptBLSKeystore(keystoreBytes, "correct horse")
+	Require(t, err)
+	if !bytes.Equal(blsSignatures.PrivateKeyToBytes(decrypted), blsSignatures.PrivateKeyToBytes(privKey)) {
+		Fail(t, "decrypted key doesn't match")
+	}
+	if _, err := DecryptBLSKeystore(keystoreBytes, "battery staple"); err == nil {
+		Fail(t, "decrypted keystore with the wrong password")
+	}
+
+	dir := t.TempDir()
+	keystorePath := filepath.Join(dir, DefaultKeystoreFilename)
+	Require(t, os.WriteFile(keystorePath, keystoreBytes, 0600))
+	passwordPath := filepath.Join(dir, "password")
+	Require(t, os.WriteFile(passwordPath, []byte("correct horse\n"), 0600))
+
+	config := KeyConfig{Keystore: keystorePath, KeystorePasswordFile: passwordPath}
+	fromConfig, err := config.BLSPrivKey()
+	Require(t, err)
+	if !bytes.Equal(blsSignatures.PrivateKeyToBytes(fromConfig), blsSignatures.PrivateKeyToBytes(privKey)) {
+		Fail(t, "key read through KeyConfig doesn't match")
+	}
+
+	t.Setenv("TEST_DAS_KEYSTORE_PASSWORD", "correct horse")
+	config = KeyConfig{Keystore: keystorePath, KeystorePasswordEnv: "TEST_DAS_KEYSTORE_PASSWORD"}
+	_, err = config.BLSPrivKey()
+	Require(t, err)
+
+	config = KeyConfig{Keystore: keystorePath}
+	if _, err := config.BLSPrivKey(); err == nil {
+		Fail(t, "expected error when no keystore password source is configured")
+	}
+}
+
+// badBLSSigner signs with a different key than the one it advertises.
+type badBLSSigner struct {
+	*LocalBLSSigner
+	otherKey blsSignatures.PrivateKey
+}
+
+func (s *badBLSSigner) Sign(ctx context.Context, message []byte) (blsSignatures.Signature, error) {
+	return blsSignatures.SignMessage(s.otherKey, message)
+}
+
+type failingBLSSigner struct {
+	*LocalBLSSigner
+}
+
+func (s *failingBLSSigner) Sign(ctx context.Context, message []byte) (blsSignatures.Signature, error) {
+	return nil, errors.New("signing refused")
+}
+
+func startStubBLSSigner(t *testing.T, signer BLSSigner) string {
+	server, err := NewBLSSignerRPCServer(signer)
+	Require(t, err)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
+
+func newTestLocalBLSSigner(t *testing.T) *LocalBLSSigner {
+	_, privKey, err := blsSignatures.GenerateKeys()
+	Require(t, err)
+	signer, err := NewLocalBLSSigner(privKey)
+	Require(t, err)
+	return signer
+}
+
+func TestDAS_RemoteBLSSigner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	localSigner := newTestLocalBLSSigner(t)
+	url := startStubBLSSigner(t, localSigner)
+
+	remoteConfig := DefaultRemoteBLSSignerConfig
+	remoteConfig.URL = url
+	remoteConfig.PubKey = base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(localSigner.PublicKey()))
+	config := DataAvailabilityConfig{
+		KeyConfig: KeyConfig{RemoteSigner: remoteConfig},
+		L1NodeURL: "none",
+	}
+	storageService := NewMemoryBackedStorageService(ctx)
+	das, err := NewSignAfterStoreDAS(ctx, config, storageService)
+	Require(t, err)
+	defer func() {
+		Require(t, das.Close(ctx))
+	}()
+
+	message := []byte("signed somewhere else")
+	cert, err := das.Store(ctx, message, 0, nil)
+	Require(t, err)
+	verified, err := blsSignatures.VerifySignature(cert.Sig, cert.SerializeSignableFields(), localSigner.PublicKey())
+	Require(t, err)
+	if !verified {
+		Fail(t, "certificate signed by remote signer didn't verify")
+	}
+	stored, err := storageService.GetByHash(ctx, cert.DataHash)
+	Require(t, err)
+	if !bytes.Equal(stored, message) {
+		Fail(t, "message wasn't stored")
+	}
+
+	// A signer advertising a different public key than configured is rejected.
+	wrongKeyConfig := remoteConfig
+	wrongKeyConfig.PubKey = base64.StdEncoding.EncodeToString(blsSignatures.PublicKeyToBytes(newTestLocalBLSSigner(t).PublicKey()))
+	if _, err := NewRemoteBLSSigner(ctx, &wrongKeyConfig); err == nil {
+		Fail(t, "expected error for mismatched remote signer public key")
+	}
+
+	// Signatures that don't verify against the advertised key are rejected.
+	_, otherKey, err := blsSignatures.GenerateKeys()
+	Require(t, err)
+	badConfig := DefaultRemoteBLSSignerConfig
+	badConfig.URL = startStubBLSSigner(t, &badBLSSigner{newTestLocalBLSSigner(t), otherKey})
+	badSigner, err := NewRemoteBLSSigner(ctx, &badConfig)
+	Require(t, err)
+	if _, err := badSigner.Sign(ctx, message); err == nil {
+		Fail(t, "expected error for invalid remote signature")
+	}
+
+	// Errors from the signer are passed on to Store.
+	failingConfig := DefaultRemoteBLSSignerConfig
+	failingConfig.URL = startStubBLSSigner(t, &failingBLSSigner{newTestLocalBLSSigner(t)})
+	failingSigner, err := NewRemoteBLSSigner(ctx, &failingConfig)
+	Require(t, err)
+	failingDAS, err := NewSignAfterStoreDASWithSigner(failingSigner, nil, NewMemoryBackedStorageService(ctx), "")
+	Require(t, err)
+	if _, err := failingDAS.Store(ctx, message, 0, nil); err == nil {
+		Fail(t, "expected Store to fail when the signer refuses")
+	}
+}
+
+func TestDAS_RemoteBLSSignerUnixSocket(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	localSigner := newTestLocalBLSSigner(t)
+	server, err := NewBLSSignerRPCServer(localSigner)
+	Require(t, err)
+	socketPath := filepath.Join(t.TempDir(), "blssigner.ipc")
+	listener, err := net.Listen("unix", socketPath)
+	Require(t, err)
+	go func() {
+		_ = server.ServeListener(listener)
+	}()
+	defer server.Stop()
+
+	config := DefaultRemoteBLSSignerConfig
+	config.URL = socketPath
+	signer, err := NewRemoteBLSSigner(ctx, &config)
+	Require(t, err)
+	defer signer.Close()
+
+	message := []byte("over a socket")
+	sig, err := signer.Sign(ctx, message)
+	Require(t, err)
+	verified, err := blsSignatures.VerifySignature(sig, message, localSigner.PublicKey())
+	Require(t, err)
+	if !verified {
+		Fail(t, "signature over unix socket didn't verify")
+	}
+}
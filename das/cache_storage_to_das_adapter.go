@@ -38,7 +38,7 @@ func (a *CacheStorageToDASAdapter) GetByHash(ctx context.Context, hash common.Ha
 			return nil, err
 		}
 
-		err = a.cache.Put(ctx, ret, 0 /* this value is ignored for the cache */)
+		err = a.cache.Put(ctx, ret, 0 /* expiration unknown */)
 		if err != nil {
 			log.Warn("Error caching retrieved DAS batch data, returning anyway", "err", err)
 		}
@@ -56,7 +56,8 @@ func (a *CacheStorageToDASAdapter) Store(
 		return nil, err
 	}
 
-	err = a.cache.Put(ctx, message, 0 /* this value is ignored for the cache */)
+	// Caches that support expiration won't keep the data past timeout.
+	err = a.cache.Put(ctx, message, timeout)
 	if err != nil {
 		log.Warn("Error caching stored DAS batch data, returning anyway", "err", err)
 	}
@@ -64,6 +65,12 @@ func (a *CacheStorageToDASAdapter) Store(
 	return cert, nil
 }
 
+// ForEach iterates over the keys of the underlying DAS; the cache only holds
+// a subset of them.
+func (a *CacheStorageToDASAdapter) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	return forEachKey(ctx, a.DataAvailabilityService, startAfter, fn)
+}
+
 func (a *CacheStorageToDASAdapter) String() string {
 	return fmt.Sprintf("CacheStorageToDASAdapter{inner: %v, cache: %v}", a.DataAvailabilityService, a.cache)
 }
@@ -102,3 +109,7 @@ func (s *emptyStorageService) String() string {
 func (s *emptyStorageService) HealthCheck(ctx context.Context) error {
 	return nil
 }
+
+func (a *CacheStorageToDASAdapter) ListSigned(ctx context.Context, from, to, offset uint64) (*SigningJournalList, error) {
+	return listSigned(ctx, a.DataAvailabilityService, from, to, offset)
+}
@@ -91,6 +91,14 @@ func (this *ChainFetchDAS) GetByHash(ctx context.Context, hash common.Hash) ([]b
 	return chainFetchGetByHash(ctx, this.DataAvailabilityService, &this.keysetCache, this.seqInboxCaller, this.seqInboxFilterer, hash)
 }
 
+func (this *ChainFetchDAS) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	return forEachKey(ctx, this.DataAvailabilityService, startAfter, fn)
+}
+
+func (this *ChainFetchDAS) ListSigned(ctx context.Context, from, to, offset uint64) (*SigningJournalList, error) {
+	return listSigned(ctx, this.DataAvailabilityService, from, to, offset)
+}
+
 func (this *ChainFetchReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
 	log.Trace("das.ChainFetchReader.GetByHash", "hash", pretty.PrettyHash(hash))
 	return chainFetchGetByHash(ctx, this.DataAvailabilityReader, &this.keysetCache, this.seqInboxCaller, this.seqInboxFilterer, hash)
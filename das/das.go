@@ -45,11 +45,14 @@ type DataAvailabilityConfig struct {
 	LocalCacheConfig BigCacheConfig `koanf:"local-cache"`
 	RedisCacheConfig RedisConfig    `koanf:"redis-cache"`
 
+	PrefetchConfig PrefetcherConfig `koanf:"prefetch"`
+
 	LocalDBStorageConfig   LocalDBStorageConfig   `koanf:"local-db-storage"`
 	LocalFileStorageConfig LocalFileStorageConfig `koanf:"local-file-storage"`
 	S3StorageServiceConfig S3StorageServiceConfig `koanf:"s3-storage"`
 
-	KeyConfig KeyConfig `koanf:"key"`
+	KeyConfig      KeyConfig            `koanf:"key"`
+	SigningJournal SigningJournalConfig `koanf:"signing-journal"`
 
 	AggregatorConfig              AggregatorConfig              `koanf:"rpc-aggregator"`
 	RestfulClientAggregatorConfig RestfulClientAggregatorConfig `koanf:"rest-aggregator"`
@@ -67,6 +70,7 @@ var DefaultDataAvailabilityConfig = DataAvailabilityConfig{
 	RequestTimeout:                5 * time.Second,
 	Enable:                        false,
 	RestfulClientAggregatorConfig: DefaultRestfulClientAggregatorConfig,
+	PrefetchConfig:                DefaultPrefetcherConfig,
 	L1ConnectionAttempts:          15,
 	PanicOnError:                  false,
 }
@@ -95,6 +99,7 @@ func DataAvailabilityConfigAddOptions(prefix string, f *flag.FlagSet) {
 	// Cache options
 	BigCacheConfigAddOptions(prefix+".local-cache", f)
 	RedisConfigAddOptions(prefix+".redis-cache", f)
+	PrefetcherConfigAddOptions(prefix+".prefetch", f)
 
 	// Storage options
 	LocalDBStorageConfigAddOptions(prefix+".local-db-storage", f)
@@ -103,6 +108,7 @@ func DataAvailabilityConfigAddOptions(prefix string, f *flag.FlagSet) {
 
 	// Key config for storage
 	KeyConfigAddOptions(prefix+".key", f)
+	SigningJournalConfigAddOptions(prefix+".signing-journal", f)
 
 	// Aggregator options
 	AggregatorConfigAddOptions(prefix+".rpc-aggregator", f)
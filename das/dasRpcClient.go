@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/das/dastree"
 	"github.com/offchainlabs/nitro/util/pretty"
 )
 
@@ -44,6 +45,10 @@ func (c *DASRPCClient) Store(ctx context.Context, message []byte, timeout uint64
 	if err != nil {
 		return nil, err
 	}
+	// The certificate version determines whether DataHash is a flat or tree hash.
+	if _, err := dastree.HashModeForCertVersion(byte(ret.Version)); err != nil {
+		return nil, fmt.Errorf("%v returned a certificate with %w", c, err)
+	}
 	return &arbstate.DataAvailabilityCertificate{
 		DataHash:    common.BytesToHash(ret.DataHash),
 		Timeout:     uint64(ret.Timeout),
@@ -54,6 +59,11 @@ func (c *DASRPCClient) Store(ctx context.Context, message []byte, timeout uint64
 	}, nil
 }
 
+// Close closes the connection to the backend, failing any calls in progress.
+func (c *DASRPCClient) Close() {
+	c.clnt.Close()
+}
+
 func (c *DASRPCClient) String() string {
 	return fmt.Sprintf("DASRPCClient{url:%s}", c.url)
 }
@@ -70,3 +80,12 @@ func (c *DASRPCClient) ExpirationPolicy(ctx context.Context) (arbstate.Expiratio
 	}
 	return arbstate.StringToExpirationPolicy(res)
 }
+
+func (c *DASRPCClient) ListSigned(ctx context.Context, from, to, offset uint64) (*SigningJournalList, error) {
+	var res SigningJournalList
+	err := c.clnt.CallContext(ctx, &res, "das_listSigned", hexutil.Uint64(from), hexutil.Uint64(to), hexutil.Uint64(offset))
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -30,6 +30,12 @@ var (
 	// Lower reservoir size for stores since they typically will be every 30 minutes,
 	// and at most several times per minute.
 	rpcStoreDurationHistogram = metrics.NewRegisteredHistogram("arb/das/rpc/store/duration", nil, metrics.NewExpDecaySample(32, 0.015))
+	rpcStoreSizeHistogram     = metrics.NewRegisteredHistogram("arb/das/rpc/store/size", nil, metrics.NewExpDecaySample(32, 0.015))
+
+	rpcHealthCheckRequestCounter      = metrics.NewRegisteredCounter("arb/das/rpc/healthcheck/requests", nil)
+	rpcHealthCheckFailureCounter      = metrics.NewRegisteredCounter("arb/das/rpc/healthcheck/failure", nil)
+	rpcExpirationPolicyRequestCounter = metrics.NewRegisteredCounter("arb/das/rpc/expirationpolicy/requests", nil)
+	rpcExpirationPolicyFailureCounter = metrics.NewRegisteredCounter("arb/das/rpc/expirationpolicy/failure", nil)
 )
 
 type DASRPCServer struct {
@@ -50,9 +56,12 @@ func StartDASRPCServerOnListener(ctx context.Context, listener net.Listener, rpc
 	if err != nil {
 		return nil, err
 	}
+	return serveDASRPC(ctx, listener, rpcServerTimeouts, rpcServer), nil
+}
 
+func serveDASRPC(ctx context.Context, listener net.Listener, rpcServerTimeouts genericconf.HTTPServerTimeoutConfig, handler http.Handler) *http.Server {
 	srv := &http.Server{
-		Handler:           rpcServer,
+		Handler:           handler,
 		ReadTimeout:       rpcServerTimeouts.ReadTimeout,
 		ReadHeaderTimeout: rpcServerTimeouts.ReadHeaderTimeout,
 		WriteTimeout:      rpcServerTimeouts.WriteTimeout,
@@ -69,7 +78,7 @@ func StartDASRPCServerOnListener(ctx context.Context, listener net.Listener, rpc
 		<-ctx.Done()
 		_ = srv.Shutdown(context.Background())
 	}()
-	return srv, nil
+	return srv
 }
 
 type StoreResult struct {
@@ -84,6 +93,7 @@ type StoreResult struct {
 func (serv *DASRPCServer) Store(ctx context.Context, message hexutil.Bytes, timeout hexutil.Uint64, sig hexutil.Bytes) (*StoreResult, error) {
 	log.Trace("dasRpc.DASRPCServer.Store", "message", pretty.FirstFewBytes(message), "message length", len(message), "timeout", time.Unix(int64(timeout), 0), "sig", pretty.FirstFewBytes(sig), "this", serv)
 	rpcStoreRequestGauge.Inc(1)
+	rpcStoreSizeHistogram.Update(int64(len(message)))
 	start := time.Now()
 	success := false
 	defer func() {
@@ -112,13 +122,24 @@ func (serv *DASRPCServer) Store(ctx context.Context, message hexutil.Bytes, time
 }
 
 func (serv *DASRPCServer) HealthCheck(ctx context.Context) error {
-	return serv.localDAS.HealthCheck(ctx)
+	rpcHealthCheckRequestCounter.Inc(1)
+	err := serv.localDAS.HealthCheck(ctx)
+	if err != nil {
+		rpcHealthCheckFailureCounter.Inc(1)
+	}
+	return err
 }
 
 func (serv *DASRPCServer) ExpirationPolicy(ctx context.Context) (string, error) {
+	rpcExpirationPolicyRequestCounter.Inc(1)
 	expirationPolicy, err := serv.localDAS.ExpirationPolicy(ctx)
 	if err != nil {
+		rpcExpirationPolicyFailureCounter.Inc(1)
 		return "", err
 	}
 	return expirationPolicy.String()
 }
+
+func (serv *DASRPCServer) ListSigned(ctx context.Context, from, to, offset hexutil.Uint64) (*SigningJournalList, error) {
+	return listSigned(ctx, serv.localDAS, uint64(from), uint64(to), uint64(offset))
+}
@@ -110,14 +110,79 @@ func FlatHashToTreeLeaf(flat bytes32) []byte {
 }
 
 func ValidHash(hash bytes32, preimage []byte) bool {
+	_, valid := HashModeOf(hash, preimage)
+	return valid
+}
+
+// HashMode is how a hash commits to its preimage. Version 0 certificates use
+// flat hashes, which are the keccak256 of the preimage, and version 1
+// certificates use tree hashes, which are the root of its dastree.
+type HashMode uint8
+
+const (
+	FlatHashMode HashMode = 0
+	TreeHashMode HashMode = 1
+)
+
+func HashModeForCertVersion(version uint8) (HashMode, error) {
+	switch version {
+	case 0:
+		return FlatHashMode, nil
+	case 1:
+		return TreeHashMode, nil
+	}
+	return 0, fmt.Errorf("unknown certificate version %d", version)
+}
+
+func ParseHashMode(s string) (HashMode, error) {
+	switch s {
+	case "flat":
+		return FlatHashMode, nil
+	case "tree":
+		return TreeHashMode, nil
+	}
+	return 0, fmt.Errorf("unknown hash mode '%s', valid modes are 'flat' and 'tree'", s)
+}
+
+func (m HashMode) String() string {
+	switch m {
+	case FlatHashMode:
+		return "flat"
+	case TreeHashMode:
+		return "tree"
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(m))
+}
+
+func (m HashMode) Hash(preimage []byte) bytes32 {
+	if m == FlatHashMode {
+		return crypto.Keccak256Hash(preimage)
+	}
+	return Hash(preimage)
+}
+
+// StorageKey is the key a preimage with the given hash is stored under.
+// Storage was upgraded to key flat-hashed preimages by FlatHashToTreeHash, but
+// not yet upgraded storage may still key them by their flat hash.
+func (m HashMode) StorageKey(hash bytes32) bytes32 {
+	if m == FlatHashMode {
+		return FlatHashToTreeHash(hash)
+	}
+	return hash
+}
+
+// HashModeOf returns the mode in which hash is the hash of preimage, if any.
+func HashModeOf(hash bytes32, preimage []byte) (HashMode, bool) {
 	if hash == Hash(preimage) {
-		return true
+		return TreeHashMode, true
 	}
 	if len(preimage) > 0 {
 		kind := preimage[0]
-		return kind != NodeByte && kind != LeafByte && hash == crypto.Keccak256Hash(preimage)
+		if kind != NodeByte && kind != LeafByte && hash == crypto.Keccak256Hash(preimage) {
+			return FlatHashMode, true
+		}
 	}
-	return false
+	return 0, false
 }
 
 func Content(root bytes32, oracle func(bytes32) []byte) ([]byte, error) {
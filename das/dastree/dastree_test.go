@@ -61,6 +61,36 @@ func TestDASTree(t *testing.T) {
 	}
 }
 
+func TestHashModes(t *testing.T) {
+	preimage := []byte("hash mode test")
+	flat := crypto.Keccak256Hash(preimage)
+	tree := Hash(preimage)
+	for _, mode := range []HashMode{FlatHashMode, TreeHashMode} {
+		hash := mode.Hash(preimage)
+		found, valid := HashModeOf(hash, preimage)
+		if !valid || found != mode {
+			Fail(t, "hash in", mode, "mode identified as", found, valid)
+		}
+		parsed, err := ParseHashMode(mode.String())
+		Require(t, err)
+		if parsed != mode {
+			Fail(t, "parsed", mode, "as", parsed)
+		}
+	}
+	if FlatHashMode.Hash(preimage) != flat || TreeHashMode.Hash(preimage) != tree {
+		Fail(t, "unexpected hashes")
+	}
+	if FlatHashMode.StorageKey(flat) != FlatHashToTreeHash(flat) || TreeHashMode.StorageKey(tree) != tree {
+		Fail(t, "unexpected storage keys")
+	}
+	if _, valid := HashModeOf(FlatHashToTreeHash(flat), preimage); valid {
+		Fail(t, "upgraded storage key isn't a hash of the preimage")
+	}
+	if _, err := HashModeForCertVersion(2); err == nil {
+		Fail(t, "expected certificate version 2 to be unknown")
+	}
+}
+
 func Require(t *testing.T, err error, printables ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, printables...)
@@ -104,9 +104,12 @@ func (dbs *DBStorageService) GetByHash(ctx context.Context, key common.Hash) ([]
 
 func (dbs *DBStorageService) Put(ctx context.Context, data []byte, timeout uint64) error {
 	logPut("das.DBStorageService.Put", data, timeout, dbs)
+	return dbs.PutByKey(ctx, dastree.Hash(data), data, timeout)
+}
 
+func (dbs *DBStorageService) PutByKey(ctx context.Context, key common.Hash, data []byte, timeout uint64) error {
 	return dbs.db.Update(func(txn *badger.Txn) error {
-		e := badger.NewEntry(dastree.HashBytes(data), data)
+		e := badger.NewEntry(key.Bytes(), data)
 		if dbs.discardAfterTimeout {
 			e = e.WithTTL(time.Until(time.Unix(int64(timeout), 0)))
 		}
@@ -114,6 +117,32 @@ func (dbs *DBStorageService) Put(ctx context.Context, data []byte, timeout uint6
 	})
 }
 
+func (dbs *DBStorageService) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	return dbs.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(startAfter.Bytes()); it.Valid(); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			item := it.Item()
+			if len(item.Key()) != 32 {
+				continue
+			}
+			key := common.BytesToHash(item.Key())
+			if key == startAfter {
+				continue
+			}
+			if err := fn(key, item.ExpiresAt()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (dbs *DBStorageService) Sync(ctx context.Context) error {
 	return dbs.db.Sync()
 }
@@ -27,7 +27,7 @@ func CreatePersistentStorageService(
 			return nil, nil, err
 		}
 		lifecycleManager.Register(s)
-		storageServices = append(storageServices, s)
+		storageServices = append(storageServices, NewMetricsStorageService("local_db", s))
 	}
 
 	if config.LocalFileStorageConfig.Enable {
@@ -36,7 +36,7 @@ func CreatePersistentStorageService(
 			return nil, nil, err
 		}
 		lifecycleManager.Register(s)
-		storageServices = append(storageServices, s)
+		storageServices = append(storageServices, NewMetricsStorageService("local_file", s))
 	}
 
 	if config.S3StorageServiceConfig.Enable {
@@ -45,7 +45,7 @@ func CreatePersistentStorageService(
 			return nil, nil, err
 		}
 		lifecycleManager.Register(s)
-		storageServices = append(storageServices, s)
+		storageServices = append(storageServices, NewMetricsStorageService("s3", s))
 	}
 
 	if len(storageServices) > 1 {
@@ -81,15 +81,19 @@ func CreateBatchPosterDAS(
 		return nil, nil, nil, errors.New("--node.data-availability.local-db-storage.enable, local-file-storage.enable, s3-storage.enable may not be set when running a Batch Poster in AnyTrust mode.")
 	}
 
-	if config.KeyConfig.KeyDir != "" || config.KeyConfig.PrivKey != "" {
-		return nil, nil, nil, errors.New("--node.data-availability.key.key-dir, priv-key may not be set when running a Batch Poster in AnyTrust mode.")
+	if config.KeyConfig.IsSet() {
+		return nil, nil, nil, errors.New("--node.data-availability.key.key-dir, priv-key, keystore, remote-signer.url may not be set when running a Batch Poster in AnyTrust mode.")
 	}
 
+	var lifecycleManager LifecycleManager
 	var daWriter DataAvailabilityServiceWriter
-	daWriter, err := NewRPCAggregator(ctx, *config)
+	rpcAgg, err := NewRPCAggregator(ctx, *config)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	rpcAgg.Start(ctx)
+	lifecycleManager.Register(rpcAgg)
+	daWriter = rpcAgg
 	if dataSigner != nil {
 		// In some tests the batch poster does not sign Store requests
 		daWriter, err = NewStoreSigningDAS(daWriter, dataSigner)
@@ -103,7 +107,6 @@ func CreateBatchPosterDAS(
 		return nil, nil, nil, err
 	}
 	restAgg.Start(ctx)
-	var lifecycleManager LifecycleManager
 	lifecycleManager.Register(restAgg)
 	var daReader DataAvailabilityServiceReader = restAgg
 	daReader, err = NewChainFetchReader(daReader, l1Reader, sequencerInboxAddr)
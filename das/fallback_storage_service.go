@@ -71,6 +71,7 @@ func (f *FallbackStorageService) GetByHash(ctx context.Context, key common.Hash)
 			f.currentlyFetchingMutex.Unlock()
 		}
 		log.Trace("das.FallbackStorageService.GetByHash trying fallback")
+		fallbackBackupRequestCounter.Inc(1)
 		data, err = f.backup.GetByHash(ctx, key)
 		if doDelete {
 			f.currentlyFetchingMutex.Lock()
@@ -78,20 +79,31 @@ func (f *FallbackStorageService) GetByHash(ctx context.Context, key common.Hash)
 			f.currentlyFetchingMutex.Unlock()
 		}
 		if err != nil {
+			fallbackBackupFailureCounter.Inc(1)
 			return nil, err
 		}
 		if dastree.ValidHash(key, data) {
 			putErr := f.StorageService.Put(
 				ctx, data, arbmath.SaturatingUAdd(uint64(time.Now().Unix()), f.backupRetentionSeconds),
 			)
-			if putErr != nil && !f.ignoreRetentionWriteErrors {
-				return nil, err
+			if putErr != nil {
+				fallbackRetentionErrorCounter.Inc(1)
+				if !f.ignoreRetentionWriteErrors {
+					return nil, err
+				}
 			}
 		}
+	} else {
+		fallbackPrimaryHitCounter.Inc(1)
 	}
 	return data, err
 }
 
+// ForEach iterates over the keys of the primary.
+func (f *FallbackStorageService) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	return forEachKey(ctx, f.StorageService, startAfter, fn)
+}
+
 func (f *FallbackStorageService) String() string {
 	return "FallbackStorageService(stoargeService:" + f.StorageService.String() + ")"
 }
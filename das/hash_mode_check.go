@@ -0,0 +1,147 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+// StorageKeyKind is how the key a preimage is stored under relates to it.
+type StorageKeyKind uint8
+
+const (
+	// The key is the tree hash of the preimage.
+	TreeStorageKey StorageKeyKind = iota
+	// The key is FlatHashToTreeHash of the flat hash of the preimage, as the
+	// one-time upgrade renames flat-hashed preimages to.
+	UpgradedFlatStorageKey
+	// The key is the flat hash of the preimage; it hasn't been upgraded.
+	LegacyFlatStorageKey
+	// The key isn't a hash of the preimage in either mode.
+	MismatchedStorageKey
+)
+
+func (k StorageKeyKind) String() string {
+	switch k {
+	case TreeStorageKey:
+		return "tree"
+	case UpgradedFlatStorageKey:
+		return "upgraded flat"
+	case LegacyFlatStorageKey:
+		return "legacy flat"
+	case MismatchedStorageKey:
+		return "mismatched"
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(k))
+}
+
+func storageKeyKind(key common.Hash, preimage []byte) StorageKeyKind {
+	mode, valid := dastree.HashModeOf(key, preimage)
+	switch {
+	case valid && mode == dastree.TreeHashMode:
+		return TreeStorageKey
+	case valid && mode == dastree.FlatHashMode:
+		return LegacyFlatStorageKey
+	case key == dastree.FlatHashToTreeHash(crypto.Keccak256Hash(preimage)):
+		return UpgradedFlatStorageKey
+	}
+	return MismatchedStorageKey
+}
+
+type HashModeCheckResult struct {
+	Key     common.Hash
+	KeyKind StorageKeyKind
+	// Whether a version 1 certificate committing to the preimage can read it.
+	TreeReachable bool
+	// Whether a version 0 certificate committing to the preimage can read it,
+	// under either its upgraded or its legacy key.
+	FlatReachable bool
+}
+
+type HashModeCheckStats struct {
+	Checked       uint64
+	BothReachable uint64
+	TreeOnly      uint64
+	FlatOnly      uint64
+	Legacy        uint64
+	Mismatched    uint64
+}
+
+func (s HashModeCheckStats) String() string {
+	return fmt.Sprintf(
+		"checked %d: reachable by both hashes %d, tree hash only %d, flat hash only %d; not upgraded %d, mismatched %d",
+		s.Checked, s.BothReachable, s.TreeOnly, s.FlatOnly, s.Legacy, s.Mismatched,
+	)
+}
+
+func storageHasKey(ctx context.Context, storage StorageService, key common.Hash) (bool, error) {
+	_, err := storage.GetByHash(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CheckHashModes walks storage and reports to fn, for each preimage stored,
+// how it's keyed and whether it's reachable under both its tree and its flat
+// hash. This finds preimages the one-time upgrade to tree hashes missed.
+func CheckHashModes(ctx context.Context, storage StorageService, fn func(HashModeCheckResult)) (HashModeCheckStats, error) {
+	var stats HashModeCheckStats
+	err := forEachKey(ctx, storage, common.Hash{}, func(key common.Hash, expiration uint64) error {
+		preimage, err := storage.GetByHash(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			// Expired since it was listed.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result := HashModeCheckResult{
+			Key:     key,
+			KeyKind: storageKeyKind(key, preimage),
+		}
+		if result.KeyKind != MismatchedStorageKey {
+			result.TreeReachable, err = storageHasKey(ctx, storage, dastree.Hash(preimage))
+			if err != nil {
+				return err
+			}
+			flatHash := crypto.Keccak256Hash(preimage)
+			result.FlatReachable, err = storageHasKey(ctx, storage, dastree.FlatHashMode.StorageKey(flatHash))
+			if err != nil {
+				return err
+			}
+			if !result.FlatReachable {
+				result.FlatReachable, err = storageHasKey(ctx, storage, flatHash)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		stats.Checked++
+		switch {
+		case result.KeyKind == MismatchedStorageKey:
+			stats.Mismatched++
+		case result.TreeReachable && result.FlatReachable:
+			stats.BothReachable++
+		case result.TreeReachable:
+			stats.TreeOnly++
+		case result.FlatReachable:
+			stats.FlatOnly++
+		}
+		if result.KeyKind == LegacyFlatStorageKey {
+			stats.Legacy++
+		}
+		fn(result)
+		return nil
+	})
+	return stats, err
+}
@@ -0,0 +1,61 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+func TestDAS_CheckHashModes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+	treeOnly := []byte("stored under its tree hash")
+	upgraded := []byte("stored under its upgraded flat hash")
+	legacy := []byte("stored under its flat hash")
+	both := []byte("stored under both")
+	Require(t, storage.Put(ctx, treeOnly, 0))
+	storage.contents[dastree.FlatHashToTreeHash(crypto.Keccak256Hash(upgraded))] = upgraded
+	storage.contents[crypto.Keccak256Hash(legacy)] = legacy
+	Require(t, storage.Put(ctx, both, 0))
+	storage.contents[dastree.FlatHashToTreeHash(crypto.Keccak256Hash(both))] = both
+	storage.contents[common.Hash{1}] = []byte("not a preimage of its key")
+
+	results := make(map[string]HashModeCheckResult)
+	stats, err := CheckHashModes(ctx, storage, func(result HashModeCheckResult) {
+		preimage, err := storage.GetByHash(ctx, result.Key)
+		Require(t, err)
+		results[string(preimage)+"/"+result.KeyKind.String()] = result
+	})
+	Require(t, err)
+
+	expect := func(preimage []byte, kind StorageKeyKind, treeReachable, flatReachable bool) {
+		t.Helper()
+		result, ok := results[string(preimage)+"/"+kind.String()]
+		if !ok {
+			Fail(t, "no result for", string(preimage), "under a", kind, "key")
+		}
+		if result.TreeReachable != treeReachable || result.FlatReachable != flatReachable {
+			Fail(t, "unexpected result for", string(preimage), result)
+		}
+	}
+	expect(treeOnly, TreeStorageKey, true, false)
+	expect(upgraded, UpgradedFlatStorageKey, false, true)
+	expect(legacy, LegacyFlatStorageKey, false, true)
+	expect(both, TreeStorageKey, true, true)
+	expect(both, UpgradedFlatStorageKey, true, true)
+	expect([]byte("not a preimage of its key"), MismatchedStorageKey, false, false)
+
+	expected := HashModeCheckStats{Checked: 6, BothReachable: 2, TreeOnly: 1, FlatOnly: 2, Legacy: 1, Mismatched: 1}
+	if stats != expected {
+		Fail(t, "unexpected stats", stats, "expected", expected)
+	}
+}
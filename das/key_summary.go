@@ -0,0 +1,207 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/sha3"
+)
+
+// Mirrors compare the keys they store by splitting the key space into
+// 2^bucketBits buckets by the leading bits of the keys, and exchanging a
+// digest of each bucket. Only the keys in buckets whose digests differ need to
+// be listed to find the ones missing.
+
+const MaxKeySummaryBucketBits = 16
+const DefaultKeySummaryBucketBits = 8
+const maxKeyListLimit = 10000
+
+// KeyFilter restricts summaries and listings to the keys whose expiration is
+// within [MinExpiration, MaxExpiration]. A MaxExpiration of 0 means no upper
+// bound. Keys whose expiration the backend doesn't know are always included.
+type KeyFilter struct {
+	MinExpiration uint64
+	MaxExpiration uint64
+}
+
+func (f KeyFilter) includes(expiration uint64) bool {
+	if expiration == 0 {
+		return true
+	}
+	return expiration >= f.MinExpiration && (f.MaxExpiration == 0 || expiration <= f.MaxExpiration)
+}
+
+type KeySummaryBucket struct {
+	Count uint64 `json:"count"`
+	// Keccak256 of the bucket's keys in ascending order, or zero if it's empty.
+	Digest common.Hash `json:"digest"`
+}
+
+type KeySummary struct {
+	BucketBits uint8              `json:"bucketBits"`
+	Buckets    []KeySummaryBucket `json:"buckets"`
+}
+
+type KeyListEntry struct {
+	Key        common.Hash `json:"key"`
+	Expiration uint64      `json:"expiration"`
+}
+
+type KeyList struct {
+	Keys []KeyListEntry `json:"keys"`
+	// More is set if the bucket has keys after the last one listed.
+	More bool `json:"more"`
+}
+
+func keyBucket(key common.Hash, bucketBits uint8) uint64 {
+	if bucketBits == 0 {
+		return 0
+	}
+	prefix := uint64(key[0])<<8 | uint64(key[1])
+	return prefix >> (MaxKeySummaryBucketBits - bucketBits)
+}
+
+// keyBeforeBucket returns the key to start iterating after to reach the
+// first key of bucket.
+func keyBeforeBucket(bucket uint64, bucketBits uint8) common.Hash {
+	if bucket == 0 {
+		return common.Hash{}
+	}
+	first := new(big.Int).Lsh(new(big.Int).SetUint64(bucket), 256-uint(bucketBits))
+	return common.BigToHash(first.Sub(first, big.NewInt(1)))
+}
+
+func checkBucketBits(bucketBits uint8) error {
+	if bucketBits > MaxKeySummaryBucketBits {
+		return fmt.Errorf("bucket bits %d is more than the maximum of %d", bucketBits, MaxKeySummaryBucketBits)
+	}
+	return nil
+}
+
+var errStopIteration = errors.New("stop iteration")
+
+// ComputeKeySummary summarizes the keys of storage matching filter.
+func ComputeKeySummary(ctx context.Context, storage interface{}, bucketBits uint8, filter KeyFilter) (*KeySummary, error) {
+	if err := checkBucketBits(bucketBits); err != nil {
+		return nil, err
+	}
+	summary := &KeySummary{
+		BucketBits: bucketBits,
+		Buckets:    make([]KeySummaryBucket, 1<<bucketBits),
+	}
+	hasher := sha3.NewLegacyKeccak256()
+	currentBucket := uint64(0)
+	finishBucket := func() {
+		if summary.Buckets[currentBucket].Count > 0 {
+			summary.Buckets[currentBucket].Digest = common.BytesToHash(hasher.Sum(nil))
+		}
+		hasher.Reset()
+	}
+	err := forEachKey(ctx, storage, common.Hash{}, func(key common.Hash, expiration uint64) error {
+		if !filter.includes(expiration) {
+			return nil
+		}
+		bucket := keyBucket(key, bucketBits)
+		if bucket != currentBucket {
+			finishBucket()
+			currentBucket = bucket
+		}
+		summary.Buckets[bucket].Count++
+		hasher.Write(key.Bytes())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	finishBucket()
+	return summary, nil
+}
+
+// ListKeys lists up to limit keys of storage in bucket that match filter,
+// starting after startAfter. A limit of 0 lists all of them.
+func ListKeys(ctx context.Context, storage interface{}, bucketBits uint8, bucket uint64, startAfter common.Hash, limit int, filter KeyFilter) (*KeyList, error) {
+	if err := checkBucketBits(bucketBits); err != nil {
+		return nil, err
+	}
+	if bucket >= 1<<bucketBits {
+		return nil, fmt.Errorf("bucket %d out of range for %d bucket bits", bucket, bucketBits)
+	}
+	if keyBucket(startAfter, bucketBits) < bucket {
+		startAfter = keyBeforeBucket(bucket, bucketBits)
+	}
+	list := &KeyList{Keys: []KeyListEntry{}}
+	err := forEachKey(ctx, storage, startAfter, func(key common.Hash, expiration uint64) error {
+		if keyBucket(key, bucketBits) != bucket {
+			return errStopIteration
+		}
+		if !filter.includes(expiration) {
+			return nil
+		}
+		if limit > 0 && len(list.Keys) == limit {
+			list.More = true
+			return errStopIteration
+		}
+		list.Keys = append(list.Keys, KeyListEntry{key, expiration})
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopIteration) {
+		return nil, err
+	}
+	return list, nil
+}
+
+const maxCachedKeySummaries = 16
+
+type keySummaryCacheKey struct {
+	bucketBits uint8
+	filter     KeyFilter
+}
+
+type cachedKeySummary struct {
+	summary  *KeySummary
+	computed time.Time
+}
+
+// keySummaryCache keeps key summaries for a while, and computes one at a time,
+// since each needs a full iteration over the storage.
+type keySummaryCache struct {
+	mutex     sync.Mutex
+	cacheTime time.Duration
+	summaries map[keySummaryCacheKey]cachedKeySummary
+}
+
+func newKeySummaryCache(cacheTime time.Duration) *keySummaryCache {
+	return &keySummaryCache{
+		cacheTime: cacheTime,
+		summaries: make(map[keySummaryCacheKey]cachedKeySummary),
+	}
+}
+
+func (c *keySummaryCache) get(ctx context.Context, storage interface{}, bucketBits uint8, filter KeyFilter) (*KeySummary, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key := keySummaryCacheKey{bucketBits, filter}
+	now := time.Now()
+	if cached, ok := c.summaries[key]; ok && now.Sub(cached.computed) < c.cacheTime {
+		return cached.summary, nil
+	}
+	summary, err := ComputeKeySummary(ctx, storage, bucketBits, filter)
+	if err != nil {
+		return nil, err
+	}
+	for k, cached := range c.summaries {
+		if now.Sub(cached.computed) >= c.cacheTime || len(c.summaries) >= maxCachedKeySummaries {
+			delete(c.summaries, k)
+		}
+	}
+	c.summaries[key] = cachedKeySummary{summary, now}
+	return summary, nil
+}
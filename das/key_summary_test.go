@@ -0,0 +1,223 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+func TestDAS_KeyBuckets(t *testing.T) {
+	key := common.HexToHash("0xabcd000000000000000000000000000000000000000000000000000000000000")
+	for bits, expected := range map[uint8]uint64{0: 0, 1: 1, 4: 0xa, 8: 0xab, 16: 0xabcd} {
+		if keyBucket(key, bits) != expected {
+			Fail(t, "bucket of", key, "with", bits, "bits was", keyBucket(key, bits), "expected", expected)
+		}
+	}
+	before := keyBeforeBucket(0xab, 8)
+	if before != common.HexToHash("0xaaffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff") {
+		Fail(t, "unexpected key before bucket", before)
+	}
+}
+
+func TestDAS_KeySummary(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	full := NewMemoryBackedStorageService(ctx)
+	partial := NewMemoryBackedStorageService(ctx)
+	var missing []common.Hash
+	for i := 0; i < 200; i++ {
+		data := []byte(fmt.Sprintf("summarized value %d", i))
+		Require(t, full.Put(ctx, data, 0))
+		if i%20 == 0 {
+			missing = append(missing, dastree.Hash(data))
+		} else {
+			Require(t, partial.Put(ctx, data, 0))
+		}
+	}
+
+	const bucketBits = 4
+	fullSummary, err := ComputeKeySummary(ctx, full, bucketBits, KeyFilter{})
+	Require(t, err)
+	partialSummary, err := ComputeKeySummary(ctx, partial, bucketBits, KeyFilter{})
+	Require(t, err)
+	var total uint64
+	differing := make(map[uint64]bool)
+	for bucket := range fullSummary.Buckets {
+		total += fullSummary.Buckets[bucket].Count
+		if fullSummary.Buckets[bucket] != partialSummary.Buckets[bucket] {
+			differing[uint64(bucket)] = true
+		}
+	}
+	if total != 200 {
+		Fail(t, "summary counted", total, "keys, expected 200")
+	}
+	for _, key := range missing {
+		if !differing[keyBucket(key, bucketBits)] {
+			Fail(t, "bucket of missing key", key, "has the same digest")
+		}
+	}
+	if len(differing) > len(missing) {
+		Fail(t, len(differing), "buckets differ but only", len(missing), "keys are missing")
+	}
+
+	// Listing a bucket in pages returns each of its keys once, in order.
+	bucket := keyBucket(missing[0], bucketBits)
+	var listed []common.Hash
+	var startAfter common.Hash
+	for {
+		list, err := ListKeys(ctx, full, bucketBits, bucket, startAfter, 3, KeyFilter{})
+		Require(t, err)
+		for _, entry := range list.Keys {
+			if keyBucket(entry.Key, bucketBits) != bucket {
+				Fail(t, "listed key", entry.Key, "isn't in bucket", bucket)
+			}
+			listed = append(listed, entry.Key)
+		}
+		if !list.More {
+			break
+		}
+		startAfter = list.Keys[len(list.Keys)-1].Key
+	}
+	if uint64(len(listed)) != fullSummary.Buckets[bucket].Count {
+		Fail(t, "listed", len(listed), "keys, expected", fullSummary.Buckets[bucket].Count)
+	}
+	for i := 1; i < len(listed); i++ {
+		if bytes.Compare(listed[i-1].Bytes(), listed[i].Bytes()) >= 0 {
+			Fail(t, "keys not listed in ascending order")
+		}
+	}
+
+	if _, err := ComputeKeySummary(ctx, NewEmptyStorageService(), bucketBits, KeyFilter{}); !errors.Is(err, ErrIterationNotSupported) {
+		Fail(t, "expected iteration to be unsupported, got", err)
+	}
+}
+
+func TestDAS_ReconcileWithPeer(t *testing.T) {
+	initTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peerStorage := NewMemoryBackedStorageService(ctx)
+	local := NewMemoryBackedStorageService(ctx)
+	var values [][]byte
+	missing := 0
+	for i := 0; i < 100; i++ {
+		data := []byte(fmt.Sprintf("mirrored value %d", i))
+		values = append(values, data)
+		Require(t, peerStorage.Put(ctx, data, 0))
+		if i%7 == 0 {
+			missing++
+		} else {
+			Require(t, local.Put(ctx, data, 0))
+		}
+	}
+	// Values only the local mirror has are left alone.
+	localOnly := []byte("only stored locally")
+	Require(t, local.Put(ctx, localOnly, 0))
+
+	server, port, err := NewRestfulDasServerOnRandomPort(LocalServerAddressForTest, peerStorage)
+	Require(t, err)
+	defer func() {
+		Require(t, server.Shutdown())
+	}()
+	peer := NewRestfulDasClient("http", LocalServerAddressForTest, port)
+
+	stats, err := ReconcileWithPeer(ctx, local, peer, DefaultKeySummaryBucketBits, uint64(time.Hour.Seconds()))
+	Require(t, err)
+	if stats.Copied != uint64(missing) || stats.Failed != 0 {
+		Fail(t, "unexpected stats", stats, "with", missing, "values missing")
+	}
+	for _, data := range values {
+		got, err := local.GetByHash(ctx, dastree.Hash(data))
+		Require(t, err)
+		if !bytes.Equal(got, data) {
+			Fail(t, "reconciled value doesn't match")
+		}
+	}
+
+	stats, err = ReconcileWithPeer(ctx, local, peer, DefaultKeySummaryBucketBits, uint64(time.Hour.Seconds()))
+	Require(t, err)
+	if stats.Copied != 0 || stats.MismatchedBuckets > 1 {
+		Fail(t, "expected nothing to copy once reconciled", stats)
+	}
+
+	// A peer that can't list its keys is reported as such.
+	readOnlyServer, readOnlyPort, err := NewRestfulDasServerOnRandomPort(LocalServerAddressForTest, NewEmptyStorageService())
+	Require(t, err)
+	defer func() {
+		Require(t, readOnlyServer.Shutdown())
+	}()
+	_, err = ReconcileWithPeer(ctx, local, NewRestfulDasClient("http", LocalServerAddressForTest, readOnlyPort), DefaultKeySummaryBucketBits, 0)
+	if !errors.Is(err, ErrIterationNotSupported) {
+		Fail(t, "expected iteration to be unsupported, got", err)
+	}
+}
+
+func TestDAS_KeyIterationEndpoints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := NewMemoryBackedStorageService(ctx)
+	Require(t, storage.Put(ctx, []byte("first value"), 0))
+
+	// The endpoints are off unless enabled.
+	listener, err := net.Listen("tcp", LocalServerAddressForTest+":0")
+	Require(t, err)
+	server, err := NewRestfulDasServerOnListener(listener, genericconf.HTTPServerTimeoutConfigDefault, DefaultRestfulEndpointsConfig, nil, storage)
+	Require(t, err)
+	defer func() {
+		Require(t, server.Shutdown())
+	}()
+	client := NewRestfulDasClient("http", LocalServerAddressForTest, listener.Addr().(*net.TCPAddr).Port)
+	if _, err := client.KeySummary(ctx, DefaultKeySummaryBucketBits, KeyFilter{}); err == nil {
+		Fail(t, "key summary served while disabled")
+	}
+	if _, err := client.ListKeys(ctx, DefaultKeySummaryBucketBits, 0, common.Hash{}, 10, KeyFilter{}); err == nil {
+		Fail(t, "keys listed while disabled")
+	}
+
+	// Summaries are cached rather than computed for every request.
+	endpoints := RestfulEndpointsConfig{KeyIteration: true, KeySummaryCacheTime: time.Hour}
+	listener, err = net.Listen("tcp", LocalServerAddressForTest+":0")
+	Require(t, err)
+	cachingServer, err := NewRestfulDasServerOnListener(listener, genericconf.HTTPServerTimeoutConfigDefault, endpoints, nil, storage)
+	Require(t, err)
+	defer func() {
+		Require(t, cachingServer.Shutdown())
+	}()
+	client = NewRestfulDasClient("http", LocalServerAddressForTest, listener.Addr().(*net.TCPAddr).Port)
+	countKeys := func(summary *KeySummary) uint64 {
+		var count uint64
+		for _, bucket := range summary.Buckets {
+			count += bucket.Count
+		}
+		return count
+	}
+	summary, err := client.KeySummary(ctx, DefaultKeySummaryBucketBits, KeyFilter{})
+	Require(t, err)
+	Require(t, storage.Put(ctx, []byte("second value"), 0))
+	cached, err := client.KeySummary(ctx, DefaultKeySummaryBucketBits, KeyFilter{})
+	Require(t, err)
+	if countKeys(summary) != 1 || countKeys(cached) != 1 {
+		Fail(t, "key summary wasn't cached", countKeys(summary), countKeys(cached))
+	}
+	other, err := client.KeySummary(ctx, 4, KeyFilter{})
+	Require(t, err)
+	if countKeys(other) != 2 {
+		Fail(t, "summary with other parameters has", countKeys(other), "keys, expected 2")
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+)
+
+// KeysetFromPubKeys builds the keyset a committee with the given members would
+// sign with. The order of pubKeys must match the signer mask bit of each member.
+func KeysetFromPubKeys(assumedHonest uint64, pubKeys []blsSignatures.PublicKey) (*arbstate.DataAvailabilityKeyset, error) {
+	if len(pubKeys) == 0 {
+		return nil, errors.New("keyset must have at least one member")
+	}
+	if assumedHonest == 0 || assumedHonest > uint64(len(pubKeys)) {
+		return nil, errors.New("assumed-honest must be between 1 and the number of members")
+	}
+	return &arbstate.DataAvailabilityKeyset{
+		AssumedHonest: assumedHonest,
+		PubKeys:       pubKeys,
+	}, nil
+}
+
+// KeysetFromBackendConfigs builds the keyset an Aggregator configured with
+// the given backends would sign with.
+func KeysetFromBackendConfigs(assumedHonest uint64, cs []BackendConfig) (*arbstate.DataAvailabilityKeyset, error) {
+	pubKeys := make([]blsSignatures.PublicKey, 0, len(cs))
+	for _, b := range cs {
+		pubKey, err := DecodeBase64BLSPublicKey([]byte(b.PubKeyBase64Encoded))
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, *pubKey)
+	}
+	return KeysetFromPubKeys(assumedHonest, pubKeys)
+}
+
+func SerializeKeyset(keyset *arbstate.DataAvailabilityKeyset) ([]byte, common.Hash, error) {
+	ksBuf := bytes.NewBuffer([]byte{})
+	if err := keyset.Serialize(ksBuf); err != nil {
+		return nil, common.Hash{}, err
+	}
+	ksHash, err := keyset.Hash()
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return ksBuf.Bytes(), ksHash, nil
+}
+
+type RegisteredKeyset struct {
+	Hash          common.Hash
+	Keyset        *arbstate.DataAvailabilityKeyset
+	CreationBlock uint64
+	Valid         bool // false if the keyset was later invalidated
+}
+
+// FetchRegisteredKeysets returns every keyset registered with SetValidKeyset
+// on the SequencerInbox in the given block range, in registration order.
+func FetchRegisteredKeysets(ctx context.Context, seqInbox *bridgegen.SequencerInbox, fromBlock uint64, toBlock *uint64) ([]RegisteredKeyset, error) {
+	iter, err := seqInbox.FilterSetValidKeyset(&bind.FilterOpts{
+		Start:   fromBlock,
+		End:     toBlock,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	seen := make(map[common.Hash]bool)
+	var keysets []RegisteredKeyset
+	for iter.Next() {
+		ksHash := common.Hash(iter.Event.KeysetHash)
+		if seen[ksHash] {
+			continue
+		}
+		seen[ksHash] = true
+		keyset, err := arbstate.DeserializeKeyset(bytes.NewReader(iter.Event.KeysetBytes), true)
+		if err != nil {
+			return nil, err
+		}
+		keysets = append(keysets, RegisteredKeyset{
+			Hash:          ksHash,
+			Keyset:        keyset,
+			CreationBlock: iter.Event.Raw.BlockNumber,
+		})
+	}
+	if iter.Error() != nil {
+		return nil, iter.Error()
+	}
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	for i := range keysets {
+		keysets[i].Valid, err = seqInbox.IsValidKeysetHash(callOpts, keysets[i].Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keysets, nil
+}
+
+type KeysetDiff struct {
+	Added              []blsSignatures.PublicKey
+	Removed            []blsSignatures.PublicKey
+	OldAssumedHonest   uint64
+	NewAssumedHonest   uint64
+	MemberOrderChanged bool
+}
+
+func (d *KeysetDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && d.OldAssumedHonest == d.NewAssumedHonest && !d.MemberOrderChanged
+}
+
+// DiffKeysets reports the changes needed to go from keyset "from" to keyset "to".
+// A change in member order matters since it changes each member's signer mask.
+func DiffKeysets(from, to *arbstate.DataAvailabilityKeyset) *KeysetDiff {
+	diff := &KeysetDiff{
+		OldAssumedHonest: from.AssumedHonest,
+		NewAssumedHonest: to.AssumedHonest,
+	}
+	fromKeys := make(map[string]int)
+	for i, pk := range from.PubKeys {
+		fromKeys[string(blsSignatures.PublicKeyToBytes(pk))] = i
+	}
+	toKeys := make(map[string]int)
+	for i, pk := range to.PubKeys {
+		key := string(blsSignatures.PublicKeyToBytes(pk))
+		toKeys[key] = i
+		fromIndex, ok := fromKeys[key]
+		if !ok {
+			diff.Added = append(diff.Added, pk)
+		} else if fromIndex != i {
+			diff.MemberOrderChanged = true
+		}
+	}
+	for _, pk := range from.PubKeys {
+		if _, ok := toKeys[string(blsSignatures.PublicKeyToBytes(pk))]; !ok {
+			diff.Removed = append(diff.Removed, pk)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,219 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+)
+
+func newTestBackends(t *testing.T, ctx context.Context, numBackendDAS int) []ServiceDetails {
+	var backends []ServiceDetails
+	for i := 0; i < numBackendDAS; i++ {
+		privKey, err := blsSignatures.GeneratePrivKeyString()
+		Require(t, err)
+
+		config := DataAvailabilityConfig{
+			Enable: true,
+			KeyConfig: KeyConfig{
+				PrivKey: privKey,
+			},
+			L1NodeURL: "none",
+		}
+
+		das, err := NewSignAfterStoreDAS(ctx, config, NewMemoryBackedStorageService(ctx))
+		Require(t, err)
+		details, err := NewServiceDetails(das, *das.pubKey, uint64(1<<i), "service"+strconv.Itoa(i))
+		Require(t, err)
+		backends = append(backends, *details)
+	}
+	return backends
+}
+
+func pubKeysOf(backends []ServiceDetails) []blsSignatures.PublicKey {
+	var pubKeys []blsSignatures.PublicKey
+	for _, d := range backends {
+		pubKeys = append(pubKeys, d.pubKey)
+	}
+	return pubKeys
+}
+
+func TestDAS_DiffKeysets(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backends := newTestBackends(t, ctx, 4)
+	pubKeys := pubKeysOf(backends)
+
+	oldKeyset, err := KeysetFromPubKeys(2, pubKeys[:3])
+	Require(t, err)
+	if diff := DiffKeysets(oldKeyset, oldKeyset); !diff.Empty() {
+		Fail(t, "diff of a keyset with itself wasn't empty", diff)
+	}
+
+	// Rotate out member 1 for member 3.
+	newKeyset, err := KeysetFromPubKeys(2, []blsSignatures.PublicKey{pubKeys[0], pubKeys[3], pubKeys[2]})
+	Require(t, err)
+	diff := DiffKeysets(oldKeyset, newKeyset)
+	if len(diff.Added) != 1 || len(diff.Removed) != 1 || diff.MemberOrderChanged {
+		Fail(t, "unexpected diff", diff)
+	}
+	if string(blsSignatures.PublicKeyToBytes(diff.Added[0])) != string(blsSignatures.PublicKeyToBytes(pubKeys[3])) {
+		Fail(t, "wrong key added")
+	}
+	if string(blsSignatures.PublicKeyToBytes(diff.Removed[0])) != string(blsSignatures.PublicKeyToBytes(pubKeys[1])) {
+		Fail(t, "wrong key removed")
+	}
+
+	reordered, err := KeysetFromPubKeys(2, []blsSignatures.PublicKey{pubKeys[1], pubKeys[0], pubKeys[2]})
+	Require(t, err)
+	if diff := DiffKeysets(oldKeyset, reordered); !diff.MemberOrderChanged {
+		Fail(t, "reordering members wasn't detected")
+	}
+
+	if _, err := KeysetFromPubKeys(4, pubKeys[:3]); err == nil {
+		Fail(t, "expected error for assumed-honest larger than the committee")
+	}
+}
+
+func TestDAS_AggregatorKeysetSwitch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backends := newTestBackends(t, ctx, 4)
+	config := DataAvailabilityConfig{
+		AggregatorConfig: AggregatorConfig{AssumedHonest: 1},
+		L1NodeURL:        "none",
+		RequestTimeout:   5 * time.Second,
+	}
+	aggregator, err := NewAggregator(ctx, config, backends[:3])
+	Require(t, err)
+	oldHash := aggregator.KeysetHash()
+
+	rawMsg := []byte("It's time for you to see the fnords.")
+	cert, err := aggregator.Store(ctx, rawMsg, 0, []byte{})
+	Require(t, err)
+	if cert.KeysetHash != oldHash {
+		Fail(t, "cert signed with unexpected keyset")
+	}
+
+	// Switch time in the future: the new keyset stays pending.
+	aggregator.config.KeysetSwitchTime = time.Now().Add(time.Hour).Unix()
+	rotated := []ServiceDetails{backends[0], backends[3], backends[2]}
+	rotated[1].signersMask = 1 << 1
+	Require(t, aggregator.SetServices(ctx, rotated))
+	if aggregator.KeysetHash() != oldHash {
+		Fail(t, "keyset switched before keyset-switch-time")
+	}
+	cert, err = aggregator.Store(ctx, rawMsg, 0, []byte{})
+	Require(t, err)
+	if cert.KeysetHash != oldHash {
+		Fail(t, "cert signed with pending keyset")
+	}
+
+	// Switch time reached: the pending keyset is activated.
+	aggregator.config.KeysetSwitchTime = 0
+	Require(t, aggregator.maybeActivatePendingKeyset(ctx))
+	newKeyset, err := KeysetFromPubKeys(1, pubKeysOf(rotated))
+	Require(t, err)
+	_, newHash, err := SerializeKeyset(newKeyset)
+	Require(t, err)
+	if aggregator.KeysetHash() != newHash {
+		Fail(t, "keyset wasn't switched")
+	}
+	cert, err = aggregator.Store(ctx, rawMsg, 0, []byte{})
+	Require(t, err)
+	if cert.KeysetHash != newHash {
+		Fail(t, "cert not signed with new keyset")
+	}
+	if err := newKeyset.VerifySignature(cert.SignersMask, cert.SerializeSignableFields(), cert.Sig); err != nil {
+		Fail(t, "cert doesn't verify against new keyset", err)
+	}
+}
+
+type closeTrackingService struct {
+	DataAvailabilityServiceWriter
+	closed chan struct{}
+}
+
+func (s *closeTrackingService) Close() {
+	close(s.closed)
+}
+
+// trackClosing wraps each backend as though a new client had been created
+// for it, as happens when backends are reloaded.
+func trackClosing(backends []ServiceDetails) []ServiceDetails {
+	var tracked []ServiceDetails
+	for _, d := range backends {
+		d.service = &closeTrackingService{d.service, make(chan struct{})}
+		tracked = append(tracked, d)
+	}
+	return tracked
+}
+
+func waitClosed(t *testing.T, services []ServiceDetails, expectClosed bool) {
+	t.Helper()
+	for _, d := range services {
+		closed := d.service.(*closeTrackingService).closed
+		if !expectClosed {
+			select {
+			case <-closed:
+				Fail(t, "backend client closed while still in use")
+			default:
+			}
+			continue
+		}
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			Fail(t, "replaced backend client wasn't closed")
+		}
+	}
+}
+
+func TestDAS_AggregatorClosesReplacedBackends(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backends := newTestBackends(t, ctx, 4)
+	config := DataAvailabilityConfig{
+		AggregatorConfig: AggregatorConfig{AssumedHonest: 1},
+		L1NodeURL:        "none",
+		RequestTimeout:   5 * time.Second,
+	}
+	first := trackClosing(backends[:3])
+	aggregator, err := NewAggregator(ctx, config, first)
+	Require(t, err)
+
+	// Reloading the same members replaces their clients right away.
+	reloaded := trackClosing(backends[:3])
+	Require(t, aggregator.SetServices(ctx, reloaded))
+	waitClosed(t, first, true)
+	_, err = aggregator.Store(ctx, []byte("still works"), 0, []byte{})
+	Require(t, err)
+
+	// A pending keyset that's replaced before activating is closed, and the
+	// current keyset's clients are closed once the new one takes over.
+	aggregator.config.KeysetSwitchTime = time.Now().Add(time.Hour).Unix()
+	pending := trackClosing([]ServiceDetails{backends[0], backends[3], backends[2]})
+	pending[1].signersMask = 1 << 1
+	Require(t, aggregator.SetServices(ctx, pending))
+	waitClosed(t, reloaded, false)
+	superseding := trackClosing([]ServiceDetails{backends[3], backends[1], backends[2]})
+	superseding[0].signersMask = 1 << 0
+	Require(t, aggregator.SetServices(ctx, superseding))
+	waitClosed(t, pending, true)
+
+	aggregator.config.KeysetSwitchTime = 0
+	Require(t, aggregator.maybeActivatePendingKeyset(ctx))
+	waitClosed(t, reloaded, true)
+	waitClosed(t, superseding, false)
+
+	Require(t, aggregator.Close(ctx))
+	waitClosed(t, superseding, true)
+}
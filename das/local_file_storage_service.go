@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -67,7 +68,11 @@ func (s *LocalFileStorageService) GetByHash(ctx context.Context, key common.Hash
 
 func (s *LocalFileStorageService) Put(ctx context.Context, data []byte, timeout uint64) error {
 	logPut("das.LocalFileStorageService.Store", data, timeout, s)
-	fileName := EncodeStorageServiceKey(dastree.Hash(data))
+	return s.PutByKey(ctx, dastree.Hash(data), data, timeout)
+}
+
+func (s *LocalFileStorageService) PutByKey(ctx context.Context, key common.Hash, data []byte, timeout uint64) error {
+	fileName := EncodeStorageServiceKey(key)
 	finalPath := s.dataDir + "/" + fileName
 
 	// Use a temp file and rename to achieve atomic writes.
@@ -92,6 +97,50 @@ func (s *LocalFileStorageService) Put(ctx context.Context, data []byte, timeout
 
 }
 
+func (s *LocalFileStorageService) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	entries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		return err
+	}
+	// Files may be named in either the current or the legacy base32 encoding,
+	// so the directory order isn't the key order.
+	var keys []common.Hash
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		var keyBytes []byte
+		key, err := DecodeStorageServiceKey(entry.Name())
+		if err == nil && len(entry.Name()) == 64 {
+			keyBytes = key.Bytes()
+		} else if decoded, err := base32.StdEncoding.DecodeString(entry.Name()); err == nil && len(decoded) == 32 {
+			keyBytes = decoded
+		} else {
+			// Temporary files from interrupted writes, or unrelated files.
+			continue
+		}
+		key = common.BytesToHash(keyBytes)
+		if bytes.Compare(key.Bytes(), startAfter.Bytes()) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i].Bytes(), keys[j].Bytes()) < 0
+	})
+	for i, key := range keys {
+		if i > 0 && key == keys[i-1] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(key, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *LocalFileStorageService) Sync(ctx context.Context) error {
 	return nil
 }
@@ -4,8 +4,10 @@
 package das
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"sort"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -44,12 +46,41 @@ func (m *MemoryBackedStorageService) GetByHash(ctx context.Context, key common.H
 
 func (m *MemoryBackedStorageService) Put(ctx context.Context, data []byte, expirationTime uint64) error {
 	logPut("das.MemoryBackedStorageService.Store", data, expirationTime, m)
+	return m.PutByKey(ctx, dastree.Hash(data), data, expirationTime)
+}
+
+func (m *MemoryBackedStorageService) PutByKey(ctx context.Context, key common.Hash, data []byte, expirationTime uint64) error {
 	m.rwmutex.Lock()
 	defer m.rwmutex.Unlock()
 	if m.closed {
 		return ErrClosed
 	}
-	m.contents[dastree.Hash(data)] = append([]byte{}, data...)
+	m.contents[key] = append([]byte{}, data...)
+	return nil
+}
+
+func (m *MemoryBackedStorageService) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	m.rwmutex.RLock()
+	if m.closed {
+		m.rwmutex.RUnlock()
+		return ErrClosed
+	}
+	var keys []common.Hash
+	for key := range m.contents {
+		if bytes.Compare(key[:], startAfter.Bytes()) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	m.rwmutex.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i].Bytes(), keys[j].Bytes()) < 0
+	})
+	for _, key := range keys {
+		if err := fn(key, 0); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,275 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type MigrateConfig struct {
+	// Number of values copied in parallel.
+	Concurrency int
+	// File recording the last key up to which everything has been copied.
+	ProgressFile string
+	// Use the expiration times recorded by the source where it has them.
+	KeepExpiration bool
+	// How long to retain other values, 0 means forever.
+	DefaultRetention time.Duration
+	// Don't copy values the destination already has.
+	SkipExisting bool
+}
+
+var DefaultMigrateConfig = MigrateConfig{
+	Concurrency:      16,
+	ProgressFile:     "",
+	KeepExpiration:   true,
+	DefaultRetention: 0,
+	SkipExisting:     true,
+}
+
+type MigrateStats struct {
+	Copied     uint64
+	Skipped    uint64
+	Failed     uint64
+	Bytes      uint64
+	FailedKeys []common.Hash
+}
+
+func (s MigrateStats) String() string {
+	return fmt.Sprintf("copied %d (%d bytes), skipped %d, failed %d", s.Copied, s.Bytes, s.Skipped, s.Failed)
+}
+
+// migrateCursor tracks the largest key such that it and every key before it
+// has been copied, even though values are copied out of order. It never moves
+// past a value that failed to copy, so a resumed migration retries it.
+type migrateCursor struct {
+	mutex    sync.Mutex
+	next     uint64
+	pending  map[uint64]common.Hash
+	done     map[uint64]bool
+	lastDone common.Hash
+	advanced bool
+}
+
+func (c *migrateCursor) add(key common.Hash) uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	index := c.next + uint64(len(c.pending))
+	c.pending[index] = key
+	return index
+}
+
+func (c *migrateCursor) complete(index uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.done[index] = true
+	for c.done[c.next] {
+		c.lastDone = c.pending[c.next]
+		c.advanced = true
+		delete(c.done, c.next)
+		delete(c.pending, c.next)
+		c.next++
+	}
+}
+
+func (c *migrateCursor) takeProgress() (common.Hash, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	advanced := c.advanced
+	c.advanced = false
+	return c.lastDone, advanced
+}
+
+func readMigrateProgress(progressFile string) (common.Hash, error) {
+	if progressFile == "" {
+		return common.Hash{}, nil
+	}
+	contents, err := os.ReadFile(progressFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return common.Hash{}, nil
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return DecodeStorageServiceKey(strings.TrimSpace(string(contents)))
+}
+
+func writeMigrateProgress(progressFile string, key common.Hash) error {
+	tmpFile := progressFile + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(EncodeStorageServiceKey(key)+"\n"), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, progressFile)
+}
+
+// MigrateStorage copies every value in src to dst, checking that each value
+// hashes to its key. Values stored under their flat hash, or its upgraded
+// key, are stored under the same key in dst, which must be a KeyedPutter. If config.ProgressFile is set, progress is saved there
+// and a later call resumes where an interrupted one stopped. Values that fail
+// to copy are logged and recorded in the stats, and cause an error to be
+// returned once all others have been copied. The saved progress stops short
+// of the first of them, so resuming retries them.
+func MigrateStorage(ctx context.Context, src IterableStorageService, dst StorageService, config *MigrateConfig) (*MigrateStats, error) {
+	if config.Concurrency <= 0 {
+		return nil, errors.New("concurrency must be positive")
+	}
+	startAfter, err := readMigrateProgress(config.ProgressFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading progress file: %w", err)
+	}
+	if startAfter != (common.Hash{}) {
+		log.Info("Resuming migration", "after", EncodeStorageServiceKey(startAfter))
+	}
+
+	var retentionExpiration uint64 = math.MaxUint64
+	if config.DefaultRetention > 0 {
+		retentionExpiration = arbmath.SaturatingUAdd(uint64(time.Now().Unix()), uint64(config.DefaultRetention.Seconds()))
+	}
+
+	type migrateJob struct {
+		index      uint64
+		key        common.Hash
+		expiration uint64
+	}
+	cursor := &migrateCursor{
+		pending: make(map[uint64]common.Hash),
+		done:    make(map[uint64]bool),
+	}
+	var stats MigrateStats
+	var statsMutex sync.Mutex
+
+	migrateOne := func(job migrateJob) error {
+		if config.SkipExisting {
+			if _, err := dst.GetByHash(ctx, job.key); err == nil {
+				statsMutex.Lock()
+				stats.Skipped++
+				statsMutex.Unlock()
+				return nil
+			}
+		}
+		data, err := src.GetByHash(ctx, job.key)
+		if err != nil {
+			return err
+		}
+		expiration := retentionExpiration
+		if config.KeepExpiration && job.expiration != 0 {
+			expiration = job.expiration
+		}
+		switch storageKeyKind(job.key, data) {
+		case TreeStorageKey:
+			err = dst.Put(ctx, data, expiration)
+		case LegacyFlatStorageKey, UpgradedFlatStorageKey:
+			// Put would store it under its tree hash, where version 0
+			// certificates don't look for it.
+			err = putByKey(ctx, dst, job.key, data, expiration)
+		default:
+			err = errors.New("value doesn't match its key")
+		}
+		if err != nil {
+			return err
+		}
+		statsMutex.Lock()
+		stats.Copied++
+		stats.Bytes += uint64(len(data))
+		statsMutex.Unlock()
+		return nil
+	}
+
+	jobs := make(chan migrateJob, config.Concurrency)
+	var workers sync.WaitGroup
+	for i := 0; i < config.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				if err := migrateOne(job); err != nil {
+					if ctx.Err() != nil {
+						// Leave it for a resumed migration.
+						continue
+					}
+					log.Warn("Failed to migrate value", "key", EncodeStorageServiceKey(job.key), "err", err)
+					statsMutex.Lock()
+					stats.Failed++
+					stats.FailedKeys = append(stats.FailedKeys, job.key)
+					statsMutex.Unlock()
+					continue
+				}
+				cursor.complete(job.index)
+			}
+		}()
+	}
+
+	saveProgress := func() error {
+		if config.ProgressFile == "" {
+			return nil
+		}
+		key, advanced := cursor.takeProgress()
+		if !advanced {
+			return nil
+		}
+		return writeMigrateProgress(config.ProgressFile, key)
+	}
+
+	lastSave := time.Now()
+	var saveErr error
+	iterErr := src.ForEach(ctx, startAfter, func(key common.Hash, expiration uint64) error {
+		index := cursor.add(key)
+		select {
+		case jobs <- migrateJob{index, key, expiration}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if time.Since(lastSave) > time.Second {
+			lastSave = time.Now()
+			if err := saveProgress(); err != nil {
+				saveErr = err
+				return err
+			}
+			statsMutex.Lock()
+			log.Info("Migrating", "progress", stats.String())
+			statsMutex.Unlock()
+		}
+		return nil
+	})
+	close(jobs)
+	workers.Wait()
+
+	if saveErr != nil {
+		return &stats, fmt.Errorf("error writing progress file: %w", saveErr)
+	}
+	if err := saveProgress(); err != nil {
+		return &stats, fmt.Errorf("error writing progress file: %w", err)
+	}
+	if iterErr != nil {
+		return &stats, iterErr
+	}
+	if err := dst.Sync(ctx); err != nil {
+		return &stats, err
+	}
+	if stats.Failed > 0 {
+		sort.Slice(stats.FailedKeys, func(i, j int) bool {
+			return bytes.Compare(stats.FailedKeys[i].Bytes(), stats.FailedKeys[j].Bytes()) < 0
+		})
+		var failed []string
+		for _, key := range stats.FailedKeys {
+			failed = append(failed, EncodeStorageServiceKey(key))
+		}
+		return &stats, fmt.Errorf("%d values failed to migrate: %s", stats.Failed, strings.Join(failed, ", "))
+	}
+	return &stats, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+func collectKeys(t *testing.T, ctx context.Context, s IterableStorageService, startAfter common.Hash) []common.Hash {
+	var keys []common.Hash
+	Require(t, s.ForEach(ctx, startAfter, func(key common.Hash, expiration uint64) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	return keys
+}
+
+func TestDAS_StorageIteration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fileStorage, err := NewLocalFileStorageService(t.TempDir())
+	Require(t, err)
+	dbStorage, err := NewDBStorageService(ctx, t.TempDir(), false)
+	Require(t, err)
+	defer dbStorage.Close(ctx)
+
+	for _, s := range []StorageService{NewMemoryBackedStorageService(ctx), fileStorage, dbStorage} {
+		iterable := s.(IterableStorageService)
+		var expected []common.Hash
+		for i := 0; i < 20; i++ {
+			data := []byte(fmt.Sprintf("value %d", i))
+			Require(t, s.Put(ctx, data, uint64(time.Now().Add(time.Hour).Unix())))
+			expected = append(expected, dastree.Hash(data))
+		}
+		keys := collectKeys(t, ctx, iterable, common.Hash{})
+		if len(keys) != len(expected) {
+			Fail(t, s, "iterated over", len(keys), "keys, expected", len(expected))
+		}
+		for i := 1; i < len(keys); i++ {
+			if bytes.Compare(keys[i-1].Bytes(), keys[i].Bytes()) >= 0 {
+				Fail(t, s, "keys not in ascending order")
+			}
+		}
+		resumed := collectKeys(t, ctx, iterable, keys[9])
+		if len(resumed) != 10 || resumed[0] != keys[10] {
+			Fail(t, s, "iteration didn't resume after the given key")
+		}
+
+		stop := errors.New("stop")
+		count := 0
+		err := iterable.ForEach(ctx, common.Hash{}, func(key common.Hash, expiration uint64) error {
+			count++
+			return stop
+		})
+		if !errors.Is(err, stop) || count != 1 {
+			Fail(t, s, "iteration didn't stop at the callback's error")
+		}
+	}
+}
+
+func TestDAS_MigrateStorage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+	var values [][]byte
+	for i := 0; i < 50; i++ {
+		data := []byte(fmt.Sprintf("migrated value %d", i))
+		Require(t, src.Put(ctx, data, 0))
+		values = append(values, data)
+	}
+
+	dst, err := NewLocalFileStorageService(t.TempDir())
+	Require(t, err)
+	progressFile := filepath.Join(t.TempDir(), "progress")
+	config := DefaultMigrateConfig
+	config.Concurrency = 4
+	config.ProgressFile = progressFile
+
+	stats, err := MigrateStorage(ctx, src, dst, &config)
+	Require(t, err)
+	if stats.Copied != uint64(len(values)) || stats.Failed != 0 {
+		Fail(t, "unexpected stats", stats)
+	}
+	for _, data := range values {
+		got, err := dst.GetByHash(ctx, dastree.Hash(data))
+		Require(t, err)
+		if !bytes.Equal(got, data) {
+			Fail(t, "migrated value doesn't match")
+		}
+	}
+	keys := collectKeys(t, ctx, src, common.Hash{})
+	progress, err := readMigrateProgress(progressFile)
+	Require(t, err)
+	if progress != keys[len(keys)-1] {
+		Fail(t, "progress file doesn't record the last key")
+	}
+
+	// Resuming after new values were added only copies those after the
+	// recorded progress.
+	newData := []byte("added after the first migration")
+	Require(t, src.Put(ctx, newData, 0))
+	stats, err = MigrateStorage(ctx, src, dst, &config)
+	Require(t, err)
+	newKeyIsAfterProgress := bytes.Compare(dastree.Hash(newData).Bytes(), progress.Bytes()) > 0
+	if (stats.Copied == 1) != newKeyIsAfterProgress || stats.Skipped != 0 {
+		Fail(t, "unexpected stats on resume", stats)
+	}
+
+	// Without a progress file everything is considered, and values the
+	// destination already has are skipped.
+	Require(t, os.Remove(progressFile))
+	stats, err = MigrateStorage(ctx, src, dst, &config)
+	Require(t, err)
+	if stats.Skipped+stats.Copied != uint64(len(values)+1) {
+		Fail(t, "unexpected stats on full re-run", stats)
+	}
+
+	// Values that don't match their keys are reported and not copied.
+	corruptKey := dastree.Hash([]byte("original"))
+	src.contents[corruptKey] = []byte("corrupted")
+	Require(t, os.Remove(progressFile))
+	stats, err = MigrateStorage(ctx, src, dst, &config)
+	if err == nil || stats.Failed != 1 {
+		Fail(t, "expected corrupt value to fail migration", stats, err)
+	}
+	if _, err := dst.GetByHash(ctx, corruptKey); !errors.Is(err, ErrNotFound) {
+		Fail(t, "corrupt value was copied")
+	}
+	if len(stats.FailedKeys) != 1 || stats.FailedKeys[0] != corruptKey {
+		Fail(t, "failed keys", stats.FailedKeys, "expected", corruptKey)
+	}
+
+	// The saved progress stops before the failed value, so once it's fixed a
+	// resumed migration copies it.
+	progress, err = readMigrateProgress(progressFile)
+	Require(t, err)
+	if bytes.Compare(progress.Bytes(), corruptKey.Bytes()) >= 0 {
+		Fail(t, "progress", progress, "moved past failed key", corruptKey)
+	}
+	src.contents[corruptKey] = []byte("original")
+	stats, err = MigrateStorage(ctx, src, dst, &config)
+	Require(t, err)
+	if stats.Copied != 1 || stats.Failed != 0 {
+		Fail(t, "unexpected stats retrying failed value", stats)
+	}
+	if _, err := dst.GetByHash(ctx, corruptKey); err != nil {
+		Fail(t, "failed value wasn't retried", err)
+	}
+}
+
+func TestDAS_MigrateFlatKeyedStorage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+	treeData := []byte("stored under its tree hash")
+	legacyData := []byte("stored under its flat hash")
+	upgradedData := []byte("stored under its upgraded flat hash")
+	Require(t, src.Put(ctx, treeData, 0))
+	legacyKey := crypto.Keccak256Hash(legacyData)
+	upgradedKey := dastree.FlatHashToTreeHash(crypto.Keccak256Hash(upgradedData))
+	Require(t, src.PutByKey(ctx, legacyKey, legacyData, 0))
+	Require(t, src.PutByKey(ctx, upgradedKey, upgradedData, 0))
+
+	dst, err := NewLocalFileStorageService(t.TempDir())
+	Require(t, err)
+	config := DefaultMigrateConfig
+	config.Concurrency = 2
+	stats, err := MigrateStorage(ctx, src, dst, &config)
+	Require(t, err)
+	if stats.Copied != 3 || stats.Failed != 0 {
+		Fail(t, "unexpected stats", stats)
+	}
+
+	// Each value keeps the key it had, so the certificates that could read it
+	// from the source still can.
+	for key, data := range map[common.Hash][]byte{
+		dastree.Hash(treeData): treeData,
+		legacyKey:              legacyData,
+		upgradedKey:            upgradedData,
+	} {
+		got, err := dst.GetByHash(ctx, key)
+		Require(t, err, "key", key)
+		if !bytes.Equal(got, data) {
+			Fail(t, "value under key", key, "doesn't match")
+		}
+	}
+	for _, data := range [][]byte{legacyData, upgradedData} {
+		if _, err := dst.GetByHash(ctx, dastree.Hash(data)); !errors.Is(err, ErrNotFound) {
+			Fail(t, "flat keyed value was also stored under its tree hash")
+		}
+	}
+
+	// Destinations that can only store values under their tree hash fail the
+	// flat keyed ones.
+	stats, err = MigrateStorage(ctx, src, &treeKeyedStorage{NewMemoryBackedStorageService(ctx)}, &config)
+	if err == nil || stats.Failed != 2 {
+		Fail(t, "expected flat keyed values to fail migration", stats, err)
+	}
+}
+
+// treeKeyedStorage hides the PutByKey of the storage it wraps.
+type treeKeyedStorage struct {
+	StorageService
+}
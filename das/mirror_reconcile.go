@@ -0,0 +1,148 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+type ReconcileStats struct {
+	MismatchedBuckets uint64
+	Copied            uint64
+	Failed            uint64
+}
+
+func (s ReconcileStats) String() string {
+	return fmt.Sprintf("%d buckets differed, copied %d, failed %d", s.MismatchedBuckets, s.Copied, s.Failed)
+}
+
+// ReconcileWithPeer copies into local the values that peer has and local
+// doesn't, retaining them for retentionSeconds (MaxUint64 means forever).
+// Only the keys in buckets whose digests differ are listed, and only the
+// missing values are fetched. Values that fail to copy are logged and counted.
+func ReconcileWithPeer(ctx context.Context, local StorageService, peer *RestfulDasClient, bucketBits uint8, retentionSeconds uint64) (ReconcileStats, error) {
+	var stats ReconcileStats
+	peerSummary, err := peer.KeySummary(ctx, bucketBits, KeyFilter{})
+	if err != nil {
+		return stats, fmt.Errorf("error fetching key summary from %v: %w", peer, err)
+	}
+	localSummary, err := ComputeKeySummary(ctx, local, bucketBits, KeyFilter{})
+	if err != nil {
+		return stats, fmt.Errorf("error summarizing local keys: %w", err)
+	}
+
+	for bucket := range peerSummary.Buckets {
+		peerBucket, localBucket := peerSummary.Buckets[bucket], localSummary.Buckets[bucket]
+		if peerBucket.Count == 0 || peerBucket == localBucket {
+			continue
+		}
+		stats.MismatchedBuckets++
+
+		localKeys, err := ListKeys(ctx, local, bucketBits, uint64(bucket), common.Hash{}, 0, KeyFilter{})
+		if err != nil {
+			return stats, err
+		}
+		have := make(map[common.Hash]bool, len(localKeys.Keys))
+		for _, entry := range localKeys.Keys {
+			have[entry.Key] = true
+		}
+
+		var startAfter common.Hash
+		for {
+			peerKeys, err := peer.ListKeys(ctx, bucketBits, uint64(bucket), startAfter, maxKeyListLimit, KeyFilter{})
+			if err != nil {
+				return stats, fmt.Errorf("error listing keys from %v: %w", peer, err)
+			}
+			now := uint64(time.Now().Unix())
+			for _, entry := range peerKeys.Keys {
+				if have[entry.Key] || (entry.Expiration != 0 && entry.Expiration <= now) {
+					continue
+				}
+				if err := copyFromPeer(ctx, local, peer, entry.Key, arbmath.SaturatingUAdd(now, retentionSeconds)); err != nil {
+					if ctx.Err() != nil {
+						return stats, ctx.Err()
+					}
+					log.Warn("Failed to copy value from peer mirror", "peer", peer, "key", EncodeStorageServiceKey(entry.Key), "err", err)
+					stats.Failed++
+					continue
+				}
+				stats.Copied++
+			}
+			if !peerKeys.More || len(peerKeys.Keys) == 0 {
+				break
+			}
+			next := peerKeys.Keys[len(peerKeys.Keys)-1].Key
+			if bytes.Compare(next.Bytes(), startAfter.Bytes()) <= 0 {
+				return stats, fmt.Errorf("%v returned keys out of order", peer)
+			}
+			startAfter = next
+		}
+	}
+	return stats, nil
+}
+
+func copyFromPeer(ctx context.Context, local StorageService, peer *RestfulDasClient, key common.Hash, storeUntil uint64) error {
+	// RestfulDasClient checks the data matches its hash.
+	data, err := peer.GetByHash(ctx, key)
+	if err != nil {
+		return err
+	}
+	return local.Put(ctx, data, storeUntil)
+}
+
+// mirrorReconciler periodically reconciles local storage with peer mirrors.
+type mirrorReconciler struct {
+	stopwaiter.StopWaiter
+	local            StorageService
+	peers            []*RestfulDasClient
+	interval         time.Duration
+	retentionSeconds uint64
+}
+
+func newMirrorReconciler(local StorageService, urls []string, interval time.Duration, retentionSeconds uint64) (*mirrorReconciler, error) {
+	peers := make([]*RestfulDasClient, 0, len(urls))
+	for _, url := range urls {
+		peer, err := NewRestfulDasClientFromURL(url)
+		if err != nil {
+			return nil, err
+		}
+		peers = append(peers, peer)
+	}
+	return &mirrorReconciler{
+		local:            local,
+		peers:            peers,
+		interval:         interval,
+		retentionSeconds: retentionSeconds,
+	}, nil
+}
+
+func (r *mirrorReconciler) reconcile(ctx context.Context) time.Duration {
+	for _, peer := range r.peers {
+		start := time.Now()
+		stats, err := ReconcileWithPeer(ctx, r.local, peer, DefaultKeySummaryBucketBits, r.retentionSeconds)
+		if ctx.Err() != nil {
+			return 0
+		}
+		if err != nil {
+			log.Warn("Error reconciling with peer mirror", "peer", peer, "stats", stats, "err", err)
+			continue
+		}
+		log.Info("Reconciled with peer mirror", "peer", peer, "stats", stats, "elapsed", time.Since(start))
+	}
+	return r.interval
+}
+
+func (r *mirrorReconciler) Start(ctx context.Context) {
+	r.StopWaiter.Start(ctx)
+	r.CallIteratively(r.reconcile)
+}
@@ -0,0 +1,194 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+// A server hosting several chains serves each chain's RPC and REST APIs
+// under /chains/<name>, so clients of a chain just add that to the URL.
+const chainsRequestPathPrefix = "/chains/"
+
+var validChainName = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
+func CheckChainName(name string) error {
+	if !validChainName.MatchString(name) {
+		return fmt.Errorf("invalid chain name '%s', it may only contain letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ChainMetricsDAS records the requests made to one chain's DAS of a server
+// hosting several, under arb/das/chain/<name>/.
+type ChainMetricsDAS struct {
+	DataAvailabilityService
+	name    string
+	get     storageOperationMetrics
+	store   storageOperationMetrics
+	healthy metrics.Gauge
+}
+
+func NewChainMetricsDAS(name string, das DataAvailabilityService) *ChainMetricsDAS {
+	prefix := "arb/das/chain/" + name
+	return &ChainMetricsDAS{
+		DataAvailabilityService: das,
+		name:                    name,
+		get:                     newStorageOperationMetrics(prefix + "/get"),
+		store:                   newStorageOperationMetrics(prefix + "/store"),
+		healthy:                 metrics.GetOrRegisterGauge(prefix+"/healthy", nil),
+	}
+}
+
+func (m *ChainMetricsDAS) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	start := time.Now()
+	data, err := m.DataAvailabilityService.GetByHash(ctx, hash)
+	m.get.record(start, len(data), err)
+	return data, err
+}
+
+func (m *ChainMetricsDAS) Store(ctx context.Context, message []byte, timeout uint64, sig []byte) (*arbstate.DataAvailabilityCertificate, error) {
+	start := time.Now()
+	cert, err := m.DataAvailabilityService.Store(ctx, message, timeout, sig)
+	m.store.record(start, len(message), err)
+	return cert, err
+}
+
+func (m *ChainMetricsDAS) HealthCheck(ctx context.Context) error {
+	err := m.DataAvailabilityService.HealthCheck(ctx)
+	if err != nil {
+		m.healthy.Update(0)
+	} else {
+		m.healthy.Update(1)
+	}
+	return err
+}
+
+func (m *ChainMetricsDAS) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	return forEachKey(ctx, m.DataAvailabilityService, startAfter, fn)
+}
+
+func (m *ChainMetricsDAS) ListSigned(ctx context.Context, from, to, offset uint64) (*SigningJournalList, error) {
+	return listSigned(ctx, m.DataAvailabilityService, from, to, offset)
+}
+
+func (m *ChainMetricsDAS) String() string {
+	return fmt.Sprintf("ChainMetricsDAS(%s, %v)", m.name, m.DataAvailabilityService)
+}
+
+// StartDASRPCServerForChainsOnListener serves the RPC API of each chain's DAS
+// under /chains/<name>.
+func StartDASRPCServerForChainsOnListener(ctx context.Context, listener net.Listener, rpcServerTimeouts genericconf.HTTPServerTimeoutConfig, chains map[string]DataAvailabilityService) (*http.Server, error) {
+	mux := http.NewServeMux()
+	for name, localDAS := range chains {
+		if err := CheckChainName(name); err != nil {
+			return nil, err
+		}
+		rpcServer := rpc.NewServer()
+		err := rpcServer.RegisterName("das", &DASRPCServer{localDAS: localDAS})
+		if err != nil {
+			return nil, err
+		}
+		mux.Handle(chainsRequestPathPrefix+name, rpcServer)
+		mux.Handle(chainsRequestPathPrefix+name+"/", rpcServer)
+	}
+	return serveDASRPC(ctx, listener, rpcServerTimeouts, mux), nil
+}
+
+// restfulChainRouter serves each chain's REST API under /chains/<name>/, and
+// the health of all of them under /health.
+type restfulChainRouter map[string]*RestfulDasServer
+
+func (router restfulChainRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestPath := path.Clean(r.URL.Path)
+	if requestPath == healthRequestPath {
+		router.healthHandler(w, r)
+		return
+	}
+	name, chainPath := "", ""
+	if strings.HasPrefix(requestPath, chainsRequestPathPrefix) {
+		name = strings.TrimPrefix(requestPath, chainsRequestPathPrefix)
+		if slash := strings.Index(name, "/"); slash >= 0 {
+			name, chainPath = name[:slash], name[slash:]
+		}
+	}
+	chain, ok := router[name]
+	if !ok {
+		log.Warn("Unknown chain in requestPath", "requestPath", requestPath)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	chainRequest := r.Clone(r.Context())
+	chainRequest.URL.Path = chainPath
+	chain.ServeHTTP(w, chainRequest)
+}
+
+// healthHandler reports whether each chain is healthy, and fails unless all are.
+func (router restfulChainRouter) healthHandler(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(router))
+	for name := range router {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	status := http.StatusOK
+	health := make(map[string]string, len(router))
+	for _, name := range names {
+		if err := router[name].storage.HealthCheck(r.Context()); err != nil {
+			log.Warn("Unhealthy chain", "chain", name, "err", err)
+			health[name] = err.Error()
+			status = http.StatusServiceUnavailable
+		} else {
+			health[name] = "ok"
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		log.Warn("Failed encoding and writing response", "path", healthRequestPath, "err", err)
+	}
+}
+
+// NewRestfulDasServerForChainsOnListener serves the REST API of each chain's
+// DAS under /chains/<name>. If accessLog is non-nil every request is logged to
+// it with the chain's name.
+func NewRestfulDasServerForChainsOnListener(listener net.Listener, restServerTimeouts genericconf.HTTPServerTimeoutConfig, endpoints RestfulEndpointsConfig, accessLog log.Logger, chains map[string]arbstate.DataAvailabilityReader) (*RestfulDasServer, error) {
+	router := make(restfulChainRouter, len(chains))
+	for name, storage := range chains {
+		if err := CheckChainName(name); err != nil {
+			return nil, err
+		}
+		chainAccessLog := accessLog
+		if accessLog != nil {
+			chainAccessLog = accessLog.New("chain", name)
+		}
+		router[name] = &RestfulDasServer{
+			storage:      storage,
+			accessLog:    chainAccessLog,
+			endpoints:    endpoints,
+			keySummaries: newKeySummaryCache(endpoints.KeySummaryCacheTime),
+		}
+	}
+	ret := &RestfulDasServer{
+		httpServerExitedChan: make(chan interface{}),
+	}
+	ret.serve(listener, restServerTimeouts, router)
+	return ret, nil
+}
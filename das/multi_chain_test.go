@@ -0,0 +1,101 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+func TestDAS_MultipleChains(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	names := []string{"chain-a", "chain-b"}
+	storages := make(map[string]StorageService)
+	chains := make(map[string]DataAvailabilityService)
+	readers := make(map[string]arbstate.DataAvailabilityReader)
+	for _, name := range names {
+		_, privKey, err := blsSignatures.GenerateKeys()
+		Require(t, err)
+		storages[name] = NewMemoryBackedStorageService(ctx)
+		localDas, err := NewSignAfterStoreDASWithSeqInboxCaller(privKey, nil, storages[name], "")
+		Require(t, err)
+		chains[name] = NewChainMetricsDAS(name, localDas)
+		readers[name] = chains[name]
+	}
+
+	rpcListener, err := net.Listen("tcp", "localhost:0")
+	Require(t, err)
+	rpcServer, err := StartDASRPCServerForChainsOnListener(ctx, rpcListener, genericconf.HTTPServerTimeoutConfigDefault, chains)
+	Require(t, err)
+	defer func() {
+		Require(t, rpcServer.Shutdown(ctx))
+	}()
+	restListener, err := net.Listen("tcp", "localhost:0")
+	Require(t, err)
+	restServer, err := NewRestfulDasServerForChainsOnListener(restListener, genericconf.HTTPServerTimeoutConfigDefault, DefaultRestfulEndpointsConfig, nil, readers)
+	Require(t, err)
+	defer func() {
+		Require(t, restServer.Shutdown())
+	}()
+
+	// Data stored on one chain is only served by that chain.
+	rpcClient, err := NewDASRPCClient("http://" + rpcListener.Addr().String() + "/chains/chain-a")
+	Require(t, err)
+	message := []byte("stored on chain-a")
+	cert, err := rpcClient.Store(ctx, message, 0, nil)
+	Require(t, err)
+	if _, err := storages["chain-b"].GetByHash(ctx, cert.DataHash); !errors.Is(err, ErrNotFound) {
+		Fail(t, "message stored on chain-a was stored on chain-b")
+	}
+
+	restURL := "http://" + restListener.Addr().String()
+	clientA, err := NewRestfulDasClientFromURL(restURL + "/chains/chain-a")
+	Require(t, err)
+	retrieved, err := clientA.GetByHash(ctx, cert.DataHash)
+	Require(t, err)
+	if !bytes.Equal(retrieved, message) {
+		Fail(t, "retrieved the wrong message")
+	}
+	clientB, err := NewRestfulDasClientFromURL(restURL + "/chains/chain-b")
+	Require(t, err)
+	if _, err := clientB.GetByHash(ctx, cert.DataHash); err == nil {
+		Fail(t, "chain-b served a message stored on chain-a")
+	}
+
+	res, err := http.Get(restURL + "/chains/chain-c/health")
+	Require(t, err)
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		Fail(t, "unknown chain returned status", res.StatusCode)
+	}
+
+	res, err = http.Get(restURL + "/health")
+	Require(t, err)
+	defer res.Body.Close()
+	var health map[string]string
+	Require(t, json.NewDecoder(res.Body).Decode(&health))
+	if res.StatusCode != http.StatusOK || len(health) != len(names) {
+		Fail(t, "unexpected health", res.StatusCode, health)
+	}
+	for _, name := range names {
+		if health[name] != "ok" {
+			Fail(t, "chain", name, "reported unhealthy:", health[name])
+		}
+	}
+
+	if _, err := NewRestfulDasServerForChainsOnListener(restListener, genericconf.HTTPServerTimeoutConfigDefault, DefaultRestfulEndpointsConfig, nil, map[string]arbstate.DataAvailabilityReader{"a/b": readers["chain-a"]}); err == nil {
+		Fail(t, "expected chain name with a slash to be rejected")
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	flag "github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/das/dastree"
+	"github.com/offchainlabs/nitro/util/pretty"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	prefetchQueuedCounter  = metrics.NewRegisteredCounter("arb/das/prefetch/queued", nil)
+	prefetchDroppedCounter = metrics.NewRegisteredCounter("arb/das/prefetch/dropped", nil)
+	prefetchFetchedCounter = metrics.NewRegisteredCounter("arb/das/prefetch/fetched", nil)
+	prefetchFailureCounter = metrics.NewRegisteredCounter("arb/das/prefetch/failure", nil)
+	prefetchHitCounter     = metrics.NewRegisteredCounter("arb/das/prefetch/hit", nil)
+	prefetchWaitCounter    = metrics.NewRegisteredCounter("arb/das/prefetch/wait", nil)
+	prefetchMissCounter    = metrics.NewRegisteredCounter("arb/das/prefetch/miss", nil)
+)
+
+type PrefetcherConfig struct {
+	Enable      bool          `koanf:"enable"`
+	QueueSize   int           `koanf:"queue-size"`
+	Concurrency int           `koanf:"concurrency"`
+	Expiration  time.Duration `koanf:"expiration"`
+}
+
+var DefaultPrefetcherConfig = PrefetcherConfig{
+	Enable:      false,
+	QueueSize:   256,
+	Concurrency: 8,
+	Expiration:  10 * time.Minute,
+}
+
+func PrefetcherConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultPrefetcherConfig.Enable, "fetch the data of batches the inbox reader has read from L1 ahead of it being processed")
+	f.Int(prefix+".queue-size", DefaultPrefetcherConfig.QueueSize, "maximum number of batches waiting to be prefetched; further batches aren't prefetched")
+	f.Int(prefix+".concurrency", DefaultPrefetcherConfig.Concurrency, "number of batches prefetched in parallel")
+	f.Duration(prefix+".expiration", DefaultPrefetcherConfig.Expiration, "how long prefetched data is kept in memory")
+}
+
+// Prefetcher is a DataAvailabilityReader that can be told about sequencer
+// batches before they're processed, and fetches their data in the background
+// into a local cache so that it's there when the batch is processed.
+type Prefetcher struct {
+	stopwaiter.StopWaiter
+	arbstate.DataAvailabilityReader
+	config *PrefetcherConfig
+	cache  StorageService
+	queue  chan []byte
+
+	inFlightMutex sync.Mutex
+	inFlight      map[common.Hash]chan struct{}
+}
+
+func NewPrefetcher(reader arbstate.DataAvailabilityReader, cache StorageService, config *PrefetcherConfig) (*Prefetcher, error) {
+	if config.QueueSize <= 0 || config.Concurrency <= 0 {
+		return nil, fmt.Errorf("prefetch queue-size and concurrency must be positive, got %d and %d", config.QueueSize, config.Concurrency)
+	}
+	return &Prefetcher{
+		DataAvailabilityReader: reader,
+		config:                 config,
+		cache:                  cache,
+		queue:                  make(chan []byte, config.QueueSize),
+		inFlight:               make(map[common.Hash]chan struct{}),
+	}, nil
+}
+
+// Prefetch queues the data referenced by a serialized sequencer message to be
+// fetched, unless the queue is full. It never blocks.
+func (p *Prefetcher) Prefetch(sequencerMsg []byte) {
+	if len(sequencerMsg) <= 40 || !arbstate.IsDASMessageHeaderByte(sequencerMsg[40]) {
+		return
+	}
+	select {
+	case p.queue <- sequencerMsg:
+		prefetchQueuedCounter.Inc(1)
+	default:
+		prefetchDroppedCounter.Inc(1)
+	}
+}
+
+func (p *Prefetcher) prefetch(ctx context.Context, sequencerMsg []byte) {
+	cert, err := arbstate.DeserializeDASCertFrom(bytes.NewReader(sequencerMsg[40:]))
+	if err != nil {
+		// Processing the batch will report this.
+		return
+	}
+	if cert.Version != 1 {
+		// Version 0 certificates use flat hashes, which the cache isn't keyed by.
+		return
+	}
+	for _, hash := range []common.Hash{common.Hash(cert.KeysetHash), common.Hash(cert.DataHash)} {
+		p.fetch(ctx, hash, cert.Timeout)
+	}
+}
+
+func (p *Prefetcher) fetch(ctx context.Context, hash common.Hash, timeout uint64) {
+	p.inFlightMutex.Lock()
+	if _, ok := p.inFlight[hash]; ok {
+		p.inFlightMutex.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	p.inFlight[hash] = done
+	p.inFlightMutex.Unlock()
+	defer func() {
+		p.inFlightMutex.Lock()
+		delete(p.inFlight, hash)
+		p.inFlightMutex.Unlock()
+		close(done)
+	}()
+
+	if _, err := p.cache.GetByHash(ctx, hash); err == nil {
+		return
+	}
+	data, err := p.DataAvailabilityReader.GetByHash(ctx, hash)
+	if err != nil {
+		if ctx.Err() == nil {
+			prefetchFailureCounter.Inc(1)
+			log.Debug("Failed to prefetch DAS data", "hash", pretty.PrettyHash(hash), "err", err)
+		}
+		return
+	}
+	if dastree.Hash(data) != hash {
+		prefetchFailureCounter.Inc(1)
+		log.Warn("Prefetched DAS data doesn't match its hash", "hash", pretty.PrettyHash(hash))
+		return
+	}
+	if err := p.cache.Put(ctx, data, timeout); err != nil {
+		log.Warn("Failed to cache prefetched DAS data", "hash", pretty.PrettyHash(hash), "err", err)
+		return
+	}
+	prefetchFetchedCounter.Inc(1)
+}
+
+// GetByHash returns prefetched data if there is any, waiting for it if it's
+// being prefetched, and otherwise reads it from the underlying reader.
+func (p *Prefetcher) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	if data, err := p.cache.GetByHash(ctx, hash); err == nil {
+		prefetchHitCounter.Inc(1)
+		return data, nil
+	}
+	p.inFlightMutex.Lock()
+	done, inFlight := p.inFlight[hash]
+	p.inFlightMutex.Unlock()
+	if inFlight {
+		prefetchWaitCounter.Inc(1)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if data, err := p.cache.GetByHash(ctx, hash); err == nil {
+			return data, nil
+		}
+	}
+	prefetchMissCounter.Inc(1)
+	return p.DataAvailabilityReader.GetByHash(ctx, hash)
+}
+
+func (p *Prefetcher) Start(ctx context.Context) {
+	p.StopWaiter.Start(ctx)
+	for i := 0; i < p.config.Concurrency; i++ {
+		p.LaunchThread(func(ctx context.Context) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case sequencerMsg := <-p.queue:
+					p.prefetch(ctx, sequencerMsg)
+				}
+			}
+		})
+	}
+}
+
+func (p *Prefetcher) Close(ctx context.Context) error {
+	p.StopWaiter.StopOnly()
+	waitChan, err := p.StopWaiter.GetWaitChannel()
+	if err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-waitChan:
+		return nil
+	}
+}
+
+func (p *Prefetcher) String() string {
+	return fmt.Sprintf("Prefetcher(%v)", p.DataAvailabilityReader)
+}
@@ -0,0 +1,132 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+type concurrencyCountingReader struct {
+	arbstate.DataAvailabilityReader
+	delay time.Duration
+
+	mutex         sync.Mutex
+	current       int
+	maxConcurrent int
+}
+
+func (r *concurrencyCountingReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	r.mutex.Lock()
+	r.current++
+	if r.current > r.maxConcurrent {
+		r.maxConcurrent = r.current
+	}
+	r.mutex.Unlock()
+	defer func() {
+		r.mutex.Lock()
+		r.current--
+		r.mutex.Unlock()
+	}()
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return r.DataAvailabilityReader.GetByHash(ctx, hash)
+}
+
+func prefetcherTestSequencerMsg(t *testing.T, keysetHash, dataHash common.Hash) []byte {
+	_, privKey, err := blsSignatures.GenerateKeys()
+	Require(t, err)
+	sig, err := blsSignatures.SignMessage(privKey, dataHash.Bytes())
+	Require(t, err)
+	cert := &arbstate.DataAvailabilityCertificate{
+		KeysetHash:  keysetHash,
+		DataHash:    dataHash,
+		Timeout:     uint64(time.Now().Add(time.Hour).Unix()),
+		SignersMask: 1,
+		Sig:         sig,
+		Version:     1,
+	}
+	return append(make([]byte, 40), Serialize(cert)...)
+}
+
+func TestDAS_Prefetcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const batches = 8
+	const delay = 200 * time.Millisecond
+	backing := NewMemoryBackedStorageService(ctx)
+	keyset := []byte("prefetcher test keyset")
+	Require(t, backing.Put(ctx, keyset, 0))
+	var values [][]byte
+	var sequencerMsgs [][]byte
+	for i := 0; i < batches; i++ {
+		data := []byte(fmt.Sprintf("prefetched batch %d", i))
+		Require(t, backing.Put(ctx, data, 0))
+		values = append(values, data)
+		sequencerMsgs = append(sequencerMsgs, prefetcherTestSequencerMsg(t, dastree.Hash(keyset), dastree.Hash(data)))
+	}
+
+	reader := &concurrencyCountingReader{DataAvailabilityReader: backing, delay: delay}
+	config := PrefetcherConfig{Enable: true, QueueSize: batches, Concurrency: batches / 2}
+	prefetcher, err := NewPrefetcher(reader, NewMemoryBackedStorageService(ctx), &config)
+	Require(t, err)
+	prefetcher.Start(ctx)
+	defer func() {
+		Require(t, prefetcher.Close(ctx))
+	}()
+
+	start := time.Now()
+	prefetcher.Prefetch([]byte("not a DAS batch"))
+	for _, sequencerMsg := range sequencerMsgs {
+		prefetcher.Prefetch(sequencerMsg)
+	}
+	for _, data := range values {
+		got, err := prefetcher.GetByHash(ctx, dastree.Hash(data))
+		Require(t, err)
+		if !bytes.Equal(got, data) {
+			Fail(t, "prefetcher returned the wrong data")
+		}
+	}
+	elapsed := time.Since(start)
+	if elapsed >= batches*delay {
+		Fail(t, "reading prefetched batches took", elapsed, "which is no faster than fetching them one by one")
+	}
+	reader.mutex.Lock()
+	maxConcurrent := reader.maxConcurrent
+	reader.mutex.Unlock()
+	if maxConcurrent < 2 || maxConcurrent > config.Concurrency+1 {
+		Fail(t, "expected up to", config.Concurrency, "concurrent prefetches, got", maxConcurrent)
+	}
+}
+
+func TestDAS_PrefetcherQueueBounded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := PrefetcherConfig{Enable: true, QueueSize: 2, Concurrency: 1}
+	prefetcher, err := NewPrefetcher(NewMemoryBackedStorageService(ctx), NewMemoryBackedStorageService(ctx), &config)
+	Require(t, err)
+
+	// Without workers running nothing is taken off the queue.
+	for i := 0; i < 5; i++ {
+		prefetcher.Prefetch(prefetcherTestSequencerMsg(t, common.Hash{}, common.Hash{byte(i)}))
+	}
+	if len(prefetcher.queue) != config.QueueSize {
+		Fail(t, "expected the queue to be capped at", config.QueueSize, "got", len(prefetcher.queue))
+	}
+}
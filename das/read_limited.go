@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/offchainlabs/nitro/arbstate"
 )
 
@@ -36,6 +38,10 @@ func (s *readLimitedStorageService) Close(ctx context.Context) error {
 	return nil
 }
 
+func (s *readLimitedStorageService) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	return forEachKey(ctx, s.DataAvailabilityReader, startAfter, fn)
+}
+
 func (s *readLimitedStorageService) String() string {
 	return fmt.Sprintf("readLimitedStorageService(%v)", s.DataAvailabilityReader)
 
@@ -53,6 +59,10 @@ func (*readLimitedDataAvailabilityService) Store(ctx context.Context, message []
 	panic("Logic error: readLimitedDataAvailabilityService.Store shouldn't be called.")
 }
 
+func (s *readLimitedDataAvailabilityService) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	return forEachKey(ctx, s.DataAvailabilityReader, startAfter, fn)
+}
+
 func (s *readLimitedDataAvailabilityService) String() string {
 	return fmt.Sprintf("ReadLimitedDataAvailabilityService(%v)", s.DataAvailabilityReader)
 }
@@ -5,10 +5,12 @@ package das
 
 import (
 	"errors"
+	"math"
 	"math/rand"
 	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/offchainlabs/nitro/arbstate"
 )
@@ -80,6 +82,141 @@ func (s *simpleExploreExploitStrategy) newInstance() aggregatorStrategyInstance
 	return &basicStrategyInstance{readerSets: readerSets}
 }
 
+// ewma returns the exponentially weighted moving averages of the latency of
+// successful requests and of the success rate, giving weight decay to the
+// newest sample. ok is false if there are no stats.
+func (s readerStats) ewma(decay float64) (latency time.Duration, successRate float64, ok bool) {
+	if len(s) == 0 {
+		return 0, 0, false
+	}
+	var ewmaLatency float64
+	haveLatency := false
+	for i, stat := range s {
+		var success float64
+		if stat.success {
+			success = 1
+			if !haveLatency {
+				ewmaLatency = float64(stat.latency)
+				haveLatency = true
+			} else {
+				ewmaLatency = (1-decay)*ewmaLatency + decay*float64(stat.latency)
+			}
+		}
+		if i == 0 {
+			successRate = success
+		} else {
+			successRate = (1-decay)*successRate + decay*success
+		}
+	}
+	if !haveLatency {
+		return time.Duration(math.MaxInt64), 0, true
+	}
+	return time.Duration(ewmaLatency), successRate, true
+}
+
+// latencyPercentile returns the p-th percentile (0-1) latency of successful
+// requests, or false if there were none.
+func (s readerStats) latencyPercentile(p float64) (time.Duration, bool) {
+	var latencies []time.Duration
+	for _, stat := range s {
+		if stat.success {
+			latencies = append(latencies, stat.latency)
+		}
+	}
+	if len(latencies) == 0 {
+		return 0, false
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	index := int(math.Ceil(p*float64(len(latencies)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(latencies) {
+		index = len(latencies) - 1
+	}
+	return latencies[index], true
+}
+
+// Latency Weighted Strategy
+//
+// Readers are tried one at a time, in order of EWMA latency divided by EWMA
+// success rate. Readers with no stats yet are tried first so that they get
+// some.
+type latencyWeightedStrategy struct {
+	decayFactor float64
+
+	abstractAggregatorStrategy
+}
+
+// orderedReaders must be called with the lock held.
+func (s *latencyWeightedStrategy) orderedReaders() []arbstate.DataAvailabilityReader {
+	readers := make([]arbstate.DataAvailabilityReader, len(s.readers))
+	copy(readers, s.readers)
+	// Shuffle first so that ties, in particular between readers without
+	// stats, aren't always broken the same way.
+	rand.Shuffle(len(readers), func(i, j int) { readers[i], readers[j] = readers[j], readers[i] })
+
+	scores := make(map[arbstate.DataAvailabilityReader]float64, len(readers))
+	for _, reader := range readers {
+		latency, successRate, ok := s.stats[reader].ewma(s.decayFactor)
+		switch {
+		case !ok:
+			scores[reader] = -1
+		case successRate == 0:
+			scores[reader] = math.Inf(1)
+		default:
+			scores[reader] = float64(latency) / successRate
+		}
+	}
+	sort.SliceStable(readers, func(i, j int) bool {
+		return scores[readers[i]] < scores[readers[j]]
+	})
+	return readers
+}
+
+func (s *latencyWeightedStrategy) newInstance() aggregatorStrategyInstance {
+	s.RLock()
+	defer s.RUnlock()
+
+	si := basicStrategyInstance{}
+	for _, reader := range s.orderedReaders() {
+		si.readerSets = append(si.readerSets, []arbstate.DataAvailabilityReader{reader})
+	}
+	return &si
+}
+
+// Hedged Strategy
+//
+// Readers are ordered as in the latency weighted strategy, but instead of
+// waiting a fixed time before trying the next reader the aggregator only waits
+// for the current reader's percentile latency, so that a request to a reader
+// that is being unusually slow is hedged by a request to the next one.
+type hedgedStrategy struct {
+	latencyWeightedStrategy
+	percentile float64
+	minDelay   time.Duration
+	maxDelay   time.Duration
+}
+
+func (s *hedgedStrategy) newInstance() aggregatorStrategyInstance {
+	s.RLock()
+	defer s.RUnlock()
+
+	si := hedgedStrategyInstance{}
+	for _, reader := range s.orderedReaders() {
+		delay, ok := s.stats[reader].latencyPercentile(s.percentile)
+		if !ok || delay > s.maxDelay {
+			delay = s.maxDelay
+		}
+		if delay < s.minDelay {
+			delay = s.minDelay
+		}
+		si.readerSets = append(si.readerSets, []arbstate.DataAvailabilityReader{reader})
+		si.delays = append(si.delays, delay)
+	}
+	return &si
+}
+
 // Sequential Strategy for Testing
 type testingSequentialStrategy struct {
 	abstractAggregatorStrategy
@@ -102,6 +239,14 @@ type aggregatorStrategyInstance interface {
 	nextReaders() []arbstate.DataAvailabilityReader
 }
 
+// Implemented by strategy instances that decide how long to wait for the
+// readers last returned by nextReaders before trying the next ones, instead
+// of waiting WaitBeforeTryNext.
+type delayingStrategyInstance interface {
+	aggregatorStrategyInstance
+	waitBeforeTryNext() time.Duration
+}
+
 type basicStrategyInstance struct {
 	readerSets [][]arbstate.DataAvailabilityReader
 }
@@ -114,3 +259,21 @@ func (si *basicStrategyInstance) nextReaders() []arbstate.DataAvailabilityReader
 	si.readerSets = si.readerSets[1:]
 	return next
 }
+
+type hedgedStrategyInstance struct {
+	basicStrategyInstance
+	delays    []time.Duration
+	lastDelay time.Duration
+}
+
+func (si *hedgedStrategyInstance) nextReaders() []arbstate.DataAvailabilityReader {
+	if len(si.delays) > 0 {
+		si.lastDelay = si.delays[0]
+		si.delays = si.delays[1:]
+	}
+	return si.basicStrategyInstance.nextReaders()
+}
+
+func (si *hedgedStrategyInstance) waitBeforeTryNext() time.Duration {
+	return si.lastDelay
+}
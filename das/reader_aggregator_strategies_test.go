@@ -114,3 +114,75 @@ func TestDAS_SimpleExploreExploit(t *testing.T) {
 	}
 
 }
+
+func TestDAS_LatencyWeightedStrategy(t *testing.T) {
+	readers := []arbstate.DataAvailabilityReader{&dummyReader{0}, &dummyReader{1}, &dummyReader{2}, &dummyReader{3}}
+	stats := make(map[arbstate.DataAvailabilityReader]readerStats)
+	stats[readers[0]] = []readerStat{ // fast but mostly failing
+		{500 * time.Millisecond, false},
+		{500 * time.Millisecond, false},
+		{500 * time.Millisecond, true},
+	}
+	stats[readers[1]] = []readerStat{ // slow but reliable
+		{2 * time.Second, true},
+		{2 * time.Second, true},
+	}
+	stats[readers[2]] = []readerStat{ // recently got fast
+		{5 * time.Second, true},
+		{200 * time.Millisecond, true},
+		{200 * time.Millisecond, true},
+		{200 * time.Millisecond, true},
+	}
+	// readers[3] has no stats and should be tried first.
+	// With a decay factor of 0.5 the scores are 0: 500ms/0.5, 1: 2s/1 and
+	// 2: 800ms/1.
+
+	strategy := latencyWeightedStrategy{decayFactor: 0.5}
+	strategy.update(readers, stats)
+
+	expectedOrdering := []int{3, 2, 0, 1}
+	si := strategy.newInstance()
+	for _, expected := range expectedOrdering {
+		next := si.nextReaders()
+		if len(next) != 1 {
+			Fail(t, "expected readers to be tried one at a time, got", len(next))
+		}
+		if next[0].(*dummyReader).int != expected {
+			Fail(t, fmt.Sprintf("expected %d, was %d", expected, next[0].(*dummyReader).int))
+		}
+	}
+	if len(si.nextReaders()) != 0 {
+		Fail(t, "expected no more readers")
+	}
+}
+
+func TestDAS_HedgedStrategyDelays(t *testing.T) {
+	readers := []arbstate.DataAvailabilityReader{&dummyReader{0}, &dummyReader{1}, &dummyReader{2}}
+	stats := make(map[arbstate.DataAvailabilityReader]readerStats)
+	for i := 1; i <= 20; i++ {
+		stats[readers[0]] = append(stats[readers[0]], readerStat{time.Duration(i) * 10 * time.Millisecond, true})
+	}
+	stats[readers[1]] = []readerStat{{time.Millisecond, true}}
+	stats[readers[2]] = []readerStat{{time.Minute, true}}
+
+	strategy := hedgedStrategy{
+		latencyWeightedStrategy: latencyWeightedStrategy{decayFactor: 0.3},
+		percentile:              0.95,
+		minDelay:                50 * time.Millisecond,
+		maxDelay:                time.Second,
+	}
+	strategy.update(readers, stats)
+
+	expectedDelays := map[int]time.Duration{
+		0: 190 * time.Millisecond, // p95 of 10ms..200ms
+		1: 50 * time.Millisecond,  // clamped to minDelay
+		2: time.Second,            // clamped to maxDelay
+	}
+	si := strategy.newInstance().(delayingStrategyInstance)
+	for next := si.nextReaders(); len(next) != 0; next = si.nextReaders() {
+		index := next[0].(*dummyReader).int
+		if si.waitBeforeTryNext() != expectedDelays[index] {
+			Fail(t, fmt.Sprintf("reader %d: expected delay %v, was %v", index, expectedDelays[index], si.waitBeforeTryNext()))
+		}
+	}
+}
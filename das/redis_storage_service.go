@@ -6,7 +6,13 @@ package das
 import (
 	"context"
 	"crypto/hmac"
+	"crypto/tls"
 	"fmt"
+	"math"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/sha3"
@@ -23,23 +29,123 @@ import (
 )
 
 type RedisConfig struct {
-	Enable     bool          `koanf:"enable"`
-	RedisUrl   string        `koanf:"redis-url"`
-	Expiration time.Duration `koanf:"redis-expiration"`
-	KeyConfig  string        `koanf:"redis-key-config"`
+	Enable          bool          `koanf:"enable"`
+	RedisUrl        string        `koanf:"redis-url"`
+	Expiration      time.Duration `koanf:"redis-expiration"`
+	KeyConfig       string        `koanf:"redis-key-config"`
+	AdmissionPolicy string        `koanf:"redis-admission-policy"`
+	AdmissionSize   int           `koanf:"redis-admission-size"`
 }
 
 var DefaultRedisConfig = RedisConfig{
-	RedisUrl:   "",
-	Expiration: time.Hour,
-	KeyConfig:  "",
+	RedisUrl:        "",
+	Expiration:      time.Hour,
+	KeyConfig:       "",
+	AdmissionPolicy: "all",
+	AdmissionSize:   1 << 20,
 }
 
 func RedisConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultRedisConfig.Enable, "enable Redis caching of sequencer batch data")
-	f.String(prefix+".redis-url", DefaultRedisConfig.RedisUrl, "Redis url")
-	f.Duration(prefix+".redis-expiration", DefaultRedisConfig.Expiration, "Redis expiration")
+	f.String(prefix+".redis-url", DefaultRedisConfig.RedisUrl, "Redis url; redis:// or rediss:// for a single server, redis+cluster://host1:port1,host2:port2 for Redis Cluster, or redis+sentinel://host1:port1,host2:port2/mastername[/db] for Redis Sentinel (rediss+cluster and rediss+sentinel use TLS)")
+	f.Duration(prefix+".redis-expiration", DefaultRedisConfig.Expiration, "maximum time to cache a value in Redis; values stored with an earlier expiration time are only cached until then")
 	f.String(prefix+".redis-key-config", DefaultRedisConfig.KeyConfig, "Redis key config")
+	f.String(prefix+".redis-admission-policy", DefaultRedisConfig.AdmissionPolicy, "which values to cache in Redis; 'all', 'max-size' to only cache values up to redis-admission-size bytes, or 'size-weighted' to always cache values up to redis-admission-size bytes and larger values with a probability inversely proportional to their size")
+	f.Int(prefix+".redis-admission-size", DefaultRedisConfig.AdmissionSize, "size in bytes used by the max-size and size-weighted admission policies")
+}
+
+// redisAdmissionPolicy decides whether a value of the given size is cached.
+type redisAdmissionPolicy func(size int) bool
+
+func newRedisAdmissionPolicy(config *RedisConfig) (redisAdmissionPolicy, error) {
+	switch config.AdmissionPolicy {
+	case "", "all":
+		return func(int) bool { return true }, nil
+	case "max-size":
+		return func(size int) bool { return size <= config.AdmissionSize }, nil
+	case "size-weighted":
+		return sizeWeightedAdmission(config.AdmissionSize, rand.Float64), nil
+	default:
+		return nil, fmt.Errorf("unknown redis admission policy %s", config.AdmissionPolicy)
+	}
+}
+
+// sizeWeightedAdmission admits values up to threshold bytes, and larger ones
+// with probability threshold/size, so that a large value has to be requested
+// repeatedly before it can evict many smaller ones.
+func sizeWeightedAdmission(threshold int, random func() float64) redisAdmissionPolicy {
+	return func(size int) bool {
+		if size <= threshold {
+			return true
+		}
+		return random() < float64(threshold)/float64(size)
+	}
+}
+
+// redisClientFromURL connects to a single Redis server, a Redis Cluster or a
+// Redis Sentinel deployment depending on the URL's scheme.
+func redisClientFromURL(redisUrl string) (redis.UniversalClient, error) {
+	u, err := url.Parse(redisUrl)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "redis+cluster", "rediss+cluster":
+		return redis.NewClusterClient(redisClusterOptionsFromURL(u)), nil
+	case "redis+sentinel", "rediss+sentinel":
+		options, err := redisFailoverOptionsFromURL(u)
+		if err != nil {
+			return nil, err
+		}
+		return redis.NewFailoverClient(options), nil
+	default:
+		options, err := redis.ParseURL(redisUrl)
+		if err != nil {
+			return nil, err
+		}
+		return redis.NewClient(options), nil
+	}
+}
+
+func redisTLSConfig(u *url.URL) *tls.Config {
+	if !strings.HasPrefix(u.Scheme, "rediss") {
+		return nil
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+func redisClusterOptionsFromURL(u *url.URL) *redis.ClusterOptions {
+	password, _ := u.User.Password()
+	return &redis.ClusterOptions{
+		Addrs:     strings.Split(u.Host, ","),
+		Username:  u.User.Username(),
+		Password:  password,
+		TLSConfig: redisTLSConfig(u),
+	}
+}
+
+func redisFailoverOptionsFromURL(u *url.URL) (*redis.FailoverOptions, error) {
+	pathParts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if pathParts[0] == "" || len(pathParts) > 2 {
+		return nil, fmt.Errorf("redis sentinel url must end with /mastername or /mastername/db, got %s", u.Path)
+	}
+	db := 0
+	if len(pathParts) == 2 {
+		var err error
+		db, err = strconv.Atoi(pathParts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis database number %s", pathParts[1])
+		}
+	}
+	password, _ := u.User.Password()
+	return &redis.FailoverOptions{
+		MasterName:    pathParts[0],
+		SentinelAddrs: strings.Split(u.Host, ","),
+		Username:      u.User.Username(),
+		Password:      password,
+		DB:            db,
+		TLSConfig:     redisTLSConfig(u),
+	}, nil
 }
 
 type RedisStorageService struct {
@@ -47,25 +153,66 @@ type RedisStorageService struct {
 	redisConfig        RedisConfig
 	signingKey         common.Hash
 	client             redis.UniversalClient
+	admit              redisAdmissionPolicy
 }
 
 func NewRedisStorageService(redisConfig RedisConfig, baseStorageService StorageService) (StorageService, error) {
-	redisOptions, err := redis.ParseURL(redisConfig.RedisUrl)
-	if err != nil {
-		return nil, err
-	}
 	signingKey := common.HexToHash(redisConfig.KeyConfig)
 	if signingKey == (common.Hash{}) {
 		return nil, errors.New("signing key file contents are not 32 bytes of hex")
 	}
+	admit, err := newRedisAdmissionPolicy(&redisConfig)
+	if err != nil {
+		return nil, err
+	}
+	client, err := redisClientFromURL(redisConfig.RedisUrl)
+	if err != nil {
+		return nil, err
+	}
 	return &RedisStorageService{
 		baseStorageService: baseStorageService,
 		redisConfig:        redisConfig,
 		signingKey:         signingKey,
-		client:             redis.NewClient(redisOptions),
+		client:             client,
+		admit:              admit,
 	}, nil
 }
 
+// cacheExpiration returns how long to cache a value that expires at the Unix
+// time timeout, where 0 means unknown, and false if it shouldn't be cached.
+func (rs *RedisStorageService) cacheExpiration(timeout uint64) (time.Duration, bool) {
+	maxExpiration := rs.redisConfig.Expiration
+	if timeout == 0 {
+		return maxExpiration, true
+	}
+	now := uint64(time.Now().Unix())
+	if timeout <= now {
+		return 0, false
+	}
+	remaining := timeout - now
+	if maxExpiration > 0 && remaining >= uint64(maxExpiration/time.Second) {
+		return maxExpiration, true
+	}
+	if remaining > uint64(math.MaxInt64/time.Second) {
+		// Redis treats 0 as never expiring.
+		return 0, true
+	}
+	return time.Duration(remaining) * time.Second, true
+}
+
+// cache stores value in Redis if the admission policy accepts it.
+func (rs *RedisStorageService) cache(ctx context.Context, key common.Hash, value []byte, timeout uint64) error {
+	expiration, ok := rs.cacheExpiration(timeout)
+	if !ok {
+		return nil
+	}
+	if !rs.admit(len(value)) {
+		redisRejectedCounter.Inc(1)
+		return nil
+	}
+	return rs.client.Set(ctx, string(key.Bytes()), rs.signMessage(value), expiration).Err()
+}
+
 func (rs *RedisStorageService) verifyMessageSignature(data []byte) ([]byte, error) {
 	if len(data) < 32 {
 		return nil, errors.New("data is too short to contain message signature")
@@ -104,18 +251,24 @@ func (rs *RedisStorageService) GetByHash(ctx context.Context, key common.Hash) (
 	log.Trace("das.RedisStorageService.GetByHash", "key", pretty.PrettyHash(key), "this", rs)
 	ret, err := rs.getVerifiedData(ctx, key)
 	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			redisMissCounter.Inc(1)
+		} else {
+			redisErrorCounter.Inc(1)
+		}
 		ret, err = rs.baseStorageService.GetByHash(ctx, key)
 		if err != nil {
 			return nil, err
 		}
 
-		err = rs.client.Set(ctx, string(key.Bytes()), rs.signMessage(ret), rs.redisConfig.Expiration).Err()
+		err = rs.cache(ctx, key, ret, 0)
 		if err != nil {
 			return nil, err
 		}
 		return ret, err
 	}
 
+	redisHitCounter.Inc(1)
 	return ret, err
 }
 
@@ -125,9 +278,7 @@ func (rs *RedisStorageService) Put(ctx context.Context, value []byte, timeout ui
 	if err != nil {
 		return err
 	}
-	err = rs.client.Set(
-		ctx, string(dastree.Hash(value).Bytes()), rs.signMessage(value), rs.redisConfig.Expiration,
-	).Err()
+	err = rs.cache(ctx, dastree.Hash(value), value, timeout)
 	if err != nil {
 		log.Error("das.RedisStorageService.Store", "err", err)
 	}
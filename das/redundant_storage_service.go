@@ -155,6 +155,18 @@ func (r *RedundantStorageService) ExpirationPolicy(ctx context.Context) (arbstat
 	return res, nil
 }
 
+// ForEach iterates over the keys of the first inner service that supports it.
+// Keys whose Put failed on that service, but not on others, are missed.
+func (r *RedundantStorageService) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	for _, serv := range r.innerServices {
+		err := forEachKey(ctx, serv, startAfter, fn)
+		if !errors.Is(err, ErrIterationNotSupported) {
+			return err
+		}
+	}
+	return ErrIterationNotSupported
+}
+
 func (r *RedundantStorageService) String() string {
 	str := "RedundantStorageService("
 	for _, serv := range r.innerServices {
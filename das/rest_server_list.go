@@ -6,12 +6,22 @@ package das
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/signature"
 )
 
 const initialMaxRecurseDepth uint16 = 8
@@ -85,11 +95,131 @@ func restfulServerURLsFromList(
 	return urls, nil
 }
 
+const signedServerListDomain = "Arbitrum DAS REST server list"
+
+const maxSignedServerListSize = 1 << 20
+
+// SignedRestfulServerList is the JSON document served at an online-url-list
+// when online-url-list-signer is set. The signature is over SigningHash, made
+// with the signer's ECDSA key.
+type SignedRestfulServerList struct {
+	Urls      []string      `json:"urls"`
+	Version   uint64        `json:"version"`
+	Expiry    uint64        `json:"expiry"` // unix timestamp in seconds
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+func (l *SignedRestfulServerList) SigningHash() common.Hash {
+	fields := [][]byte{
+		[]byte(signedServerListDomain),
+		arbmath.UintToBytes(l.Version),
+		arbmath.UintToBytes(l.Expiry),
+	}
+	for _, url := range l.Urls {
+		fields = append(fields, crypto.Keccak256([]byte(url)))
+	}
+	return crypto.Keccak256Hash(fields...)
+}
+
+func (l *SignedRestfulServerList) Sign(signer signature.DataSignerFunc) error {
+	sig, err := signer(l.SigningHash().Bytes())
+	if err != nil {
+		return err
+	}
+	l.Signature = sig
+	return nil
+}
+
+var (
+	ErrServerListExpired = errors.New("online-url-list has expired")
+	ErrServerListOld     = errors.New("online-url-list is older than the current one")
+)
+
+// RestfulServerListFetcher fetches an online-url-list. If it was created with
+// a signer, it only accepts SignedRestfulServerLists signed by that signer
+// which haven't expired and aren't older than the last list it accepted.
+type RestfulServerListFetcher struct {
+	listUrl  string
+	verifier *signature.Verifier
+
+	mutex   sync.Mutex
+	version uint64
+}
+
+// NewRestfulServerListFetcher creates a fetcher for listUrl. If signer is nil
+// the list is read in the plain format documented at RestfulServerURLsFromList.
+func NewRestfulServerListFetcher(listUrl string, signer *common.Address) *RestfulServerListFetcher {
+	fetcher := &RestfulServerListFetcher{listUrl: listUrl}
+	if signer != nil {
+		fetcher.verifier = signature.NewVerifier(true, []common.Address{*signer}, nil)
+	}
+	return fetcher
+}
+
+func (f *RestfulServerListFetcher) Fetch(ctx context.Context) ([]string, error) {
+	if f.verifier == nil {
+		return RestfulServerURLsFromList(ctx, f.listUrl)
+	}
+	list, err := fetchSignedRestfulServerList(ctx, f.listUrl)
+	if err != nil {
+		return nil, err
+	}
+	return f.accept(ctx, list)
+}
+
+func (f *RestfulServerListFetcher) accept(ctx context.Context, list *SignedRestfulServerList) ([]string, error) {
+	valid, err := f.verifier.VerifyHash(ctx, list.Signature, list.SigningHash())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify online-url-list signature: %w", err)
+	}
+	if !valid {
+		return nil, errors.New("online-url-list isn't signed by online-url-list-signer")
+	}
+	if list.Expiry <= uint64(time.Now().Unix()) {
+		return nil, fmt.Errorf("%w at %v", ErrServerListExpired, time.Unix(int64(list.Expiry), 0))
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if list.Version < f.version {
+		return nil, fmt.Errorf("%w: version %d, current version %d", ErrServerListOld, list.Version, f.version)
+	}
+	f.version = list.Version
+	return list.Urls, nil
+}
+
+func fetchSignedRestfulServerList(ctx context.Context, listUrl string) (*SignedRestfulServerList, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, listUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("Recieved error response (%d) fetching online-url-list at %s", resp.StatusCode, listUrl)
+	}
+	var list SignedRestfulServerList
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSignedServerListSize)).Decode(&list); err != nil {
+		return nil, fmt.Errorf("online-url-list at %s isn't a signed list: %w", listUrl, err)
+	}
+	return &list, nil
+}
+
 const maxListFetchTime = time.Minute
 
 func StartRestfulServerListFetchDaemon(ctx context.Context, listUrl string, updatePeriod time.Duration) <-chan []string {
+	return NewRestfulServerListFetcher(listUrl, nil).StartDaemon(ctx, updatePeriod)
+}
+
+// StartDaemon periodically fetches the list and sends the URLs from each list
+// that is accepted. Lists that can't be fetched or are rejected aren't sent,
+// so users keep the last good list.
+func (f *RestfulServerListFetcher) StartDaemon(ctx context.Context, updatePeriod time.Duration) <-chan []string {
 	updateChan := make(chan []string)
-	if listUrl == "" {
+	if f.listUrl == "" {
 		log.Info("Trying to start RestfulServerListFetchDaemon with empty online-url-list, not starting.")
 		return updateChan
 	}
@@ -101,7 +231,7 @@ func StartRestfulServerListFetchDaemon(ctx context.Context, listUrl string, upda
 		subCtx, subCtxCancel := context.WithTimeout(ctx, maxListFetchTime)
 		defer subCtxCancel()
 
-		urls, err := RestfulServerURLsFromList(subCtx, listUrl)
+		urls, err := f.Fetch(subCtx)
 		if err != nil {
 			return err
 		}
@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -39,8 +41,39 @@ func NewRestfulDasClientFromURL(url string) (*RestfulDasClient, error) {
 	}, nil
 }
 
+// GetByHash returns the preimage of hash, which may be either its tree or its
+// flat hash; use GetByHashInMode to only accept one of them.
 func (c *RestfulDasClient) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
-	res, err := http.Get(c.url + getByHashRequestPath + EncodeStorageServiceKey(hash))
+	data, err := c.getByKey(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !dastree.ValidHash(hash, data) {
+		return nil, arbstate.ErrHashMismatch
+	}
+	return data, nil
+}
+
+// GetByHashInMode returns the preimage of hash, which must be its hash in the
+// given mode. Flat hashes are looked up by the key the server stores them
+// under, falling back to the flat hash itself for servers not yet upgraded.
+func (c *RestfulDasClient) GetByHashInMode(ctx context.Context, hash common.Hash, mode dastree.HashMode) ([]byte, error) {
+	key := mode.StorageKey(hash)
+	data, err := c.getByKey(ctx, key)
+	if err != nil && key != hash {
+		data, err = c.getByKey(ctx, hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mode.Hash(data) != hash {
+		return nil, arbstate.ErrHashMismatch
+	}
+	return data, nil
+}
+
+func (c *RestfulDasClient) getByKey(ctx context.Context, key common.Hash) ([]byte, error) {
+	res, err := http.Get(c.url + getByHashRequestPath + EncodeStorageServiceKey(key))
 	if err != nil {
 		return nil, err
 	}
@@ -60,15 +93,7 @@ func (c *RestfulDasClient) GetByHash(ctx context.Context, hash common.Hash) ([]b
 	}
 
 	decoder := base64.NewDecoder(base64.StdEncoding, bytes.NewReader([]byte(response.Data)))
-	decodedBytes, err := io.ReadAll(decoder)
-	if err != nil {
-		return nil, err
-	}
-	if !dastree.ValidHash(hash, decodedBytes) {
-		return nil, arbstate.ErrHashMismatch
-	}
-
-	return decodedBytes, nil
+	return io.ReadAll(decoder)
 }
 
 func (c *RestfulDasClient) HealthCheck(ctx context.Context) error {
@@ -103,3 +128,87 @@ func (c *RestfulDasClient) ExpirationPolicy(ctx context.Context) (arbstate.Expir
 
 	return arbstate.StringToExpirationPolicy(response.ExpirationPolicy)
 }
+
+// getJSON decodes the server's response into response, returning
+// notImplemented if the server doesn't support the request.
+func (c *RestfulDasClient) getJSON(ctx context.Context, requestPath string, query url.Values, notImplemented error, response interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+requestPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotImplemented {
+		return notImplemented
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP error with status %d returned by server: %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+	return json.NewDecoder(res.Body).Decode(response)
+}
+
+func keyFilterQuery(bucketBits uint8, filter KeyFilter) url.Values {
+	query := url.Values{}
+	query.Set("bucket-bits", strconv.FormatUint(uint64(bucketBits), 10))
+	if filter.MinExpiration != 0 {
+		query.Set("min-expiration", strconv.FormatUint(filter.MinExpiration, 10))
+	}
+	if filter.MaxExpiration != 0 {
+		query.Set("max-expiration", strconv.FormatUint(filter.MaxExpiration, 10))
+	}
+	return query
+}
+
+// KeySummary fetches the server's KeySummary, see ComputeKeySummary.
+func (c *RestfulDasClient) KeySummary(ctx context.Context, bucketBits uint8, filter KeyFilter) (*KeySummary, error) {
+	var summary KeySummary
+	if err := c.getJSON(ctx, keySummaryRequestPath, keyFilterQuery(bucketBits, filter), ErrIterationNotSupported, &summary); err != nil {
+		return nil, err
+	}
+	if summary.BucketBits != bucketBits || len(summary.Buckets) != 1<<bucketBits {
+		return nil, fmt.Errorf("server returned a key summary with %d buckets, expected %d", len(summary.Buckets), 1<<bucketBits)
+	}
+	return &summary, nil
+}
+
+// ListKeys fetches a page of the server's keys in bucket, see ListKeys.
+func (c *RestfulDasClient) ListKeys(ctx context.Context, bucketBits uint8, bucket uint64, startAfter common.Hash, limit int, filter KeyFilter) (*KeyList, error) {
+	query := keyFilterQuery(bucketBits, filter)
+	query.Set("bucket", strconv.FormatUint(bucket, 10))
+	if startAfter != (common.Hash{}) {
+		query.Set("start-after", EncodeStorageServiceKey(startAfter))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var list KeyList
+	if err := c.getJSON(ctx, keyListRequestPath, query, ErrIterationNotSupported, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ListSigned fetches a page of the certificates the server signed, see
+// SignedCertificateLister.
+func (c *RestfulDasClient) ListSigned(ctx context.Context, from, to, offset uint64) (*SigningJournalList, error) {
+	query := url.Values{}
+	query.Set("from", strconv.FormatUint(from, 10))
+	if to != 0 {
+		query.Set("to", strconv.FormatUint(to, 10))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.FormatUint(offset, 10))
+	}
+	var list SigningJournalList
+	if err := c.getJSON(ctx, signedRequestPath, query, ErrSigningJournalDisabled, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (c *RestfulDasClient) String() string {
+	return fmt.Sprintf("RestfulDasClient(%s)", c.url)
+}
@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +22,7 @@ import (
 	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/util/pretty"
+	flag "github.com/spf13/pflag"
 )
 
 var (
@@ -33,32 +36,100 @@ var (
 	// downwards to make a smaller window of samples that are included. The alpha parameter
 	// can be adjusted to downweight the importance of older samples.
 	restGetByHashDurationHistogram = metrics.NewRegisteredHistogram("arb/das/rest/getbyhash/duration", nil, metrics.NewExpDecaySample(1028, 0.015))
+	restGetByHashSizeHistogram     = metrics.NewRegisteredHistogram("arb/das/rest/getbyhash/size", nil, metrics.NewExpDecaySample(1028, 0.015))
+
+	restRequestDurationHistogram = metrics.NewRegisteredHistogram("arb/das/rest/all/duration", nil, metrics.NewExpDecaySample(1028, 0.015))
 )
 
+type AccessLogConfig struct {
+	Enable bool   `koanf:"enable"`
+	File   string `koanf:"file"`
+}
+
+var DefaultAccessLogConfig = AccessLogConfig{
+	Enable: false,
+	File:   "",
+}
+
+func AccessLogConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultAccessLogConfig.Enable, "log every request with its hash, status and latency")
+	f.String(prefix+".file", DefaultAccessLogConfig.File, "file to write the access log to as JSON lines; if empty it goes to the regular log")
+}
+
+// NewAccessLogger returns the logger to pass to the RESTful server, or nil if
+// the access log is disabled.
+func NewAccessLogger(config *AccessLogConfig) (log.Logger, error) {
+	if !config.Enable {
+		return nil, nil
+	}
+	logger := log.New("component", "das-rest-access")
+	if config.File != "" {
+		handler, err := log.FileHandler(config.File, log.JSONFormat())
+		if err != nil {
+			return nil, err
+		}
+		logger.SetHandler(handler)
+	}
+	return logger, nil
+}
+
+// RestfulEndpointsConfig enables the REST endpoints that aren't needed to
+// fetch batch data. They're off by default, since they're expensive to serve
+// or meant for the committee member's operator rather than the public.
+type RestfulEndpointsConfig struct {
+	KeyIteration        bool          `koanf:"key-iteration"`
+	KeySummaryCacheTime time.Duration `koanf:"key-summary-cache-time"`
+	Signed              bool          `koanf:"signed"`
+}
+
+var DefaultRestfulEndpointsConfig = RestfulEndpointsConfig{
+	KeyIteration:        false,
+	KeySummaryCacheTime: 10 * time.Minute,
+	Signed:              false,
+}
+
+func RestfulEndpointsConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".key-iteration", DefaultRestfulEndpointsConfig.KeyIteration, "serve the key-summary and keys endpoints that mirrors reconcile with, each of which iterates over the storage (a full listing on S3)")
+	f.Duration(prefix+".key-summary-cache-time", DefaultRestfulEndpointsConfig.KeySummaryCacheTime, "how long to serve a computed key summary before computing it again")
+	f.Bool(prefix+".signed", DefaultRestfulEndpointsConfig.Signed, "serve the signed endpoint listing the certificates signed, from the signing journal")
+}
+
 type RestfulDasServer struct {
 	server               *http.Server
 	storage              arbstate.DataAvailabilityReader
 	httpServerExitedChan chan interface{}
 	httpServerError      error
+	accessLog            log.Logger
+	endpoints            RestfulEndpointsConfig
+	keySummaries         *keySummaryCache
 }
 
-func NewRestfulDasServer(address string, port uint64, restServerTimeouts genericconf.HTTPServerTimeoutConfig, storageService arbstate.DataAvailabilityReader) (*RestfulDasServer, error) {
+func NewRestfulDasServer(address string, port uint64, restServerTimeouts genericconf.HTTPServerTimeoutConfig, endpoints RestfulEndpointsConfig, accessLog log.Logger, storageService arbstate.DataAvailabilityReader) (*RestfulDasServer, error) {
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", address, port))
 	if err != nil {
 		return nil, err
 	}
-	return NewRestfulDasServerOnListener(listener, restServerTimeouts, storageService)
+	return NewRestfulDasServerOnListener(listener, restServerTimeouts, endpoints, accessLog, storageService)
 }
 
-func NewRestfulDasServerOnListener(listener net.Listener, restServerTimeouts genericconf.HTTPServerTimeoutConfig, storageService arbstate.DataAvailabilityReader) (*RestfulDasServer, error) {
+// NewRestfulDasServerOnListener starts serving on listener. If accessLog is
+// non-nil every request is logged to it.
+func NewRestfulDasServerOnListener(listener net.Listener, restServerTimeouts genericconf.HTTPServerTimeoutConfig, endpoints RestfulEndpointsConfig, accessLog log.Logger, storageService arbstate.DataAvailabilityReader) (*RestfulDasServer, error) {
 
 	ret := &RestfulDasServer{
 		storage:              storageService,
 		httpServerExitedChan: make(chan interface{}),
+		accessLog:            accessLog,
+		endpoints:            endpoints,
+		keySummaries:         newKeySummaryCache(endpoints.KeySummaryCacheTime),
 	}
+	ret.serve(listener, restServerTimeouts, ret)
+	return ret, nil
+}
 
-	ret.server = &http.Server{
-		Handler:           ret,
+func (rds *RestfulDasServer) serve(listener net.Listener, restServerTimeouts genericconf.HTTPServerTimeoutConfig, handler http.Handler) {
+	rds.server = &http.Server{
+		Handler:           handler,
 		ReadTimeout:       restServerTimeouts.ReadTimeout,
 		ReadHeaderTimeout: restServerTimeouts.ReadHeaderTimeout,
 		WriteTimeout:      restServerTimeouts.WriteTimeout,
@@ -66,14 +137,12 @@ func NewRestfulDasServerOnListener(listener net.Listener, restServerTimeouts gen
 	}
 
 	go func() {
-		err := ret.server.Serve(listener)
+		err := rds.server.Serve(listener)
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			ret.httpServerError = err
+			rds.httpServerError = err
 		}
-		close(ret.httpServerExitedChan)
+		close(rds.httpServerExitedChan)
 	}()
-
-	return ret, nil
 }
 
 type RestfulDasServerResponse struct {
@@ -88,10 +157,59 @@ const cacheControlValueForSuccessfulGetByHash = "public, max-age=2419200, immuta
 const healthRequestPath = "/health"
 const expirationPolicyRequestPath = "/expiration-policy/"
 const getByHashRequestPath = "/get-by-hash/"
+const keySummaryRequestPath = "/key-summary"
+const keyListRequestPath = "/keys"
+const signedRequestPath = "/signed"
+
+// statusRecordingResponseWriter remembers the status and size of a response
+// for metrics and the access log.
+type statusRecordingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (w *statusRecordingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecordingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
 
 func (rds *RestfulDasServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header()[cacheControlKey] = []string{cacheControlValueDefault}
+	start := time.Now()
+	recorder := &statusRecordingResponseWriter{ResponseWriter: w}
 	requestPath := path.Clean(r.URL.Path)
+	defer func() {
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		latency := time.Since(start)
+		restRequestDurationHistogram.Update(latency.Nanoseconds())
+		metrics.GetOrRegisterCounter(fmt.Sprintf("arb/das/rest/status/%d", status), nil).Inc(1)
+		if rds.accessLog != nil {
+			hash := ""
+			if strings.HasPrefix(requestPath, getByHashRequestPath) {
+				hash = strings.TrimPrefix(requestPath, getByHashRequestPath)
+			}
+			rds.accessLog.Info("DAS REST request", "method", r.Method, "path", requestPath, "hash", hash, "status", status, "bytes", recorder.size, "latency", latency, "remoteAddr", r.RemoteAddr)
+		}
+	}()
+	rds.serveHTTP(recorder, r, requestPath)
+}
+
+func (rds *RestfulDasServer) serveHTTP(w http.ResponseWriter, r *http.Request, requestPath string) {
+	w.Header()[cacheControlKey] = []string{cacheControlValueDefault}
 	log.Debug("Got request", "requestPath", requestPath)
 	switch {
 	case strings.HasPrefix(requestPath, healthRequestPath):
@@ -100,6 +218,12 @@ func (rds *RestfulDasServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		rds.ExpirationPolicyHandler(w, r, requestPath)
 	case strings.HasPrefix(requestPath, getByHashRequestPath):
 		rds.GetByHashHandler(w, r, requestPath)
+	case requestPath == keySummaryRequestPath && rds.endpoints.KeyIteration:
+		rds.KeySummaryHandler(w, r, requestPath)
+	case requestPath == keyListRequestPath && rds.endpoints.KeyIteration:
+		rds.KeyListHandler(w, r, requestPath)
+	case requestPath == signedRequestPath && rds.endpoints.Signed:
+		rds.SignedHandler(w, r, requestPath)
 	default:
 		log.Warn("Unknown requestPath", "requestPath", requestPath)
 		w.WriteHeader(http.StatusBadRequest)
@@ -179,6 +303,7 @@ func (rds *RestfulDasServer) GetByHashHandler(w http.ResponseWriter, r *http.Req
 	var response RestfulDasServerResponse
 	response.Data = string(encodedResponseData)
 	restGetByHashReturnedBytesGauge.Inc(int64(len(response.Data)))
+	restGetByHashSizeHistogram.Update(int64(len(responseData)))
 
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
@@ -190,6 +315,146 @@ func (rds *RestfulDasServer) GetByHashHandler(w http.ResponseWriter, r *http.Req
 	success = true
 }
 
+func parseUintParam(query url.Values, name string, defaultValue uint64, bits int) (uint64, error) {
+	value := query.Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return parsed, nil
+}
+
+func parseKeyFilterParams(query url.Values) (KeyFilter, error) {
+	var filter KeyFilter
+	var err error
+	filter.MinExpiration, err = parseUintParam(query, "min-expiration", 0, 64)
+	if err != nil {
+		return filter, err
+	}
+	filter.MaxExpiration, err = parseUintParam(query, "max-expiration", 0, 64)
+	return filter, err
+}
+
+func writeKeyIterationError(w http.ResponseWriter, requestPath string, err error) {
+	if errors.Is(err, ErrIterationNotSupported) {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	log.Warn("Error iterating over keys", "path", requestPath, "err", err)
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// KeySummaryHandler serves a KeySummary of the stored keys, split into
+// 2^bucket-bits buckets, optionally only of those expiring between
+// min-expiration and max-expiration. Summaries are cached for
+// key-summary-cache-time.
+func (rds *RestfulDasServer) KeySummaryHandler(w http.ResponseWriter, r *http.Request, requestPath string) {
+	query := r.URL.Query()
+	bucketBits, err := parseUintParam(query, "bucket-bits", DefaultKeySummaryBucketBits, 8)
+	if err != nil {
+		log.Warn("Bad key summary request", "path", requestPath, "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	filter, err := parseKeyFilterParams(query)
+	if err == nil {
+		err = checkBucketBits(uint8(bucketBits))
+	}
+	if err != nil {
+		log.Warn("Bad key summary request", "path", requestPath, "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	summary, err := rds.keySummaries.get(r.Context(), rds.storage, uint8(bucketBits), filter)
+	if err != nil {
+		writeKeyIterationError(w, requestPath, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		log.Warn("Failed encoding and writing response", "path", requestPath, "err", err)
+	}
+}
+
+// KeyListHandler serves a KeyList of up to limit stored keys in a bucket of
+// the key summary, after start-after.
+func (rds *RestfulDasServer) KeyListHandler(w http.ResponseWriter, r *http.Request, requestPath string) {
+	query := r.URL.Query()
+	bucketBits, err := parseUintParam(query, "bucket-bits", DefaultKeySummaryBucketBits, 8)
+	var bucket, limit uint64
+	if err == nil {
+		bucket, err = parseUintParam(query, "bucket", 0, 64)
+	}
+	if err == nil {
+		limit, err = parseUintParam(query, "limit", maxKeyListLimit, 64)
+	}
+	var startAfter common.Hash
+	if err == nil && query.Get("start-after") != "" {
+		startAfter, err = DecodeStorageServiceKey(query.Get("start-after"))
+	}
+	var filter KeyFilter
+	if err == nil {
+		filter, err = parseKeyFilterParams(query)
+	}
+	if err == nil {
+		err = checkBucketBits(uint8(bucketBits))
+	}
+	if err == nil && bucket >= 1<<bucketBits {
+		err = fmt.Errorf("bucket %d out of range for %d bucket bits", bucket, bucketBits)
+	}
+	if err != nil {
+		log.Warn("Bad key list request", "path", requestPath, "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if limit == 0 || limit > maxKeyListLimit {
+		limit = maxKeyListLimit
+	}
+	list, err := ListKeys(r.Context(), rds.storage, uint8(bucketBits), bucket, startAfter, int(limit), filter)
+	if err != nil {
+		writeKeyIterationError(w, requestPath, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		log.Warn("Failed encoding and writing response", "path", requestPath, "err", err)
+	}
+}
+
+// SignedHandler serves a SigningJournalList of the certificates signed from
+// from until to, starting at the journal offset offset, if the signing journal
+// is enabled.
+func (rds *RestfulDasServer) SignedHandler(w http.ResponseWriter, r *http.Request, requestPath string) {
+	query := r.URL.Query()
+	from, err := parseUintParam(query, "from", 0, 64)
+	var to, offset uint64
+	if err == nil {
+		to, err = parseUintParam(query, "to", 0, 64)
+	}
+	if err == nil {
+		offset, err = parseUintParam(query, "offset", 0, 63)
+	}
+	if err != nil {
+		log.Warn("Bad signed certificates request", "path", requestPath, "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	list, err := listSigned(r.Context(), rds.storage, from, to, offset)
+	if errors.Is(err, ErrSigningJournalDisabled) {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		log.Warn("Error listing signed certificates", "path", requestPath, "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		log.Warn("Failed encoding and writing response", "path", requestPath, "err", err)
+	}
+}
+
 func (rds *RestfulDasServer) GetServerExitedChan() <-chan interface{} { // channel will close when server terminates
 	return rds.httpServerExitedChan
 }
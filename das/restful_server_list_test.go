@@ -5,11 +5,17 @@ package das
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/util/signature"
 )
 
 func TestRestfulServerList(t *testing.T) {
@@ -94,6 +100,95 @@ func TestRestfulServerListDaemonWithErrors(t *testing.T) {
 	Require(t, err)
 }
 
+func TestRestfulServerListSigned(t *testing.T) {
+	initTest(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	signerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	signer := signature.DataSignerFromPrivateKey(privateKey)
+	otherKey, err := crypto.GenerateKey()
+	Require(t, err)
+
+	urlsIn := []string{"https://supersecret.nowhere.com:9871", "http://www.google.com"}
+	expiry := uint64(time.Now().Add(time.Hour).Unix())
+	list := &SignedRestfulServerList{Urls: urlsIn, Version: 2, Expiry: expiry}
+	Require(t, list.Sign(signer))
+	listContents, err := json.Marshal(list)
+	Require(t, err)
+	port, server := newListHttpServerForTest(t, &stringHandler{string(listContents)})
+	defer func() {
+		Require(t, server.Shutdown(ctx))
+	}()
+
+	fetcher := NewRestfulServerListFetcher(fmt.Sprintf("http://localhost:%d", port), &signerAddr)
+	urls, err := fetcher.Fetch(ctx)
+	Require(t, err)
+	if !stringListIsPermutation(urlsIn, urls) {
+		t.Fatal()
+	}
+
+	// A plain list is rejected when a signer is configured.
+	plainPort, plainServer := newListHttpServerForTest(t, &stringHandler{urlsIn[0]})
+	defer func() {
+		Require(t, plainServer.Shutdown(ctx))
+	}()
+	plainFetcher := NewRestfulServerListFetcher(fmt.Sprintf("http://localhost:%d", plainPort), &signerAddr)
+	if _, err := plainFetcher.Fetch(ctx); err == nil {
+		Fail(t, "accepted an unsigned list")
+	}
+
+	unsigned := &SignedRestfulServerList{Urls: urlsIn, Version: 3, Expiry: expiry}
+	if _, err := fetcher.accept(ctx, unsigned); err == nil {
+		Fail(t, "accepted a list without a signature")
+	}
+
+	wrongSigner := &SignedRestfulServerList{Urls: urlsIn, Version: 3, Expiry: expiry}
+	Require(t, wrongSigner.Sign(signature.DataSignerFromPrivateKey(otherKey)))
+	if _, err := fetcher.accept(ctx, wrongSigner); err == nil {
+		Fail(t, "accepted a list signed by the wrong key")
+	}
+
+	tampered := &SignedRestfulServerList{Urls: urlsIn, Version: 3, Expiry: expiry}
+	Require(t, tampered.Sign(signer))
+	tampered.Urls = append(tampered.Urls, "http://malicious.mirror")
+	if _, err := fetcher.accept(ctx, tampered); err == nil {
+		Fail(t, "accepted a list modified after signing")
+	}
+
+	expired := &SignedRestfulServerList{Urls: urlsIn, Version: 3, Expiry: uint64(time.Now().Add(-time.Minute).Unix())}
+	Require(t, expired.Sign(signer))
+	if _, err := fetcher.accept(ctx, expired); !errors.Is(err, ErrServerListExpired) {
+		Fail(t, "expected expired list to be rejected, got", err)
+	}
+
+	older := &SignedRestfulServerList{Urls: urlsIn, Version: 1, Expiry: expiry}
+	Require(t, older.Sign(signer))
+	if _, err := fetcher.accept(ctx, older); !errors.Is(err, ErrServerListOld) {
+		Fail(t, "expected older list to be rejected, got", err)
+	}
+
+	newer := &SignedRestfulServerList{Urls: urlsIn[:1], Version: 3, Expiry: expiry}
+	Require(t, newer.Sign(signer))
+	urls, err = fetcher.accept(ctx, newer)
+	Require(t, err)
+	if !stringListIsPermutation(urlsIn[:1], urls) {
+		t.Fatal()
+	}
+
+	// The list served is now older than the one accepted, so the daemon
+	// doesn't send it and users keep the last good list.
+	listChan := fetcher.StartDaemon(ctx, 100*time.Millisecond)
+	select {
+	case urls := <-listChan:
+		Fail(t, "daemon sent a rejected list", urls)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
 func stringListIsPermutation(lis1, lis2 []string) bool {
 	if len(lis1) != len(lis2) {
 		return false
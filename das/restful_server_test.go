@@ -20,6 +20,11 @@ import (
 
 const LocalServerAddressForTest = "localhost"
 
+var testRestfulEndpointsConfig = RestfulEndpointsConfig{
+	KeyIteration:        true,
+	KeySummaryCacheTime: 0,
+}
+
 func NewRestfulDasServerOnRandomPort(address string, storageService arbstate.DataAvailabilityReader) (*RestfulDasServer, int, error) {
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:0", address))
 	if err != nil {
@@ -29,7 +34,7 @@ func NewRestfulDasServerOnRandomPort(address string, storageService arbstate.Dat
 	if !ok {
 		return nil, 0, errors.New("attempt to listen on TCP returned non-TCP address")
 	}
-	rds, err := NewRestfulDasServerOnListener(listener, genericconf.HTTPServerTimeoutConfigDefault, storageService)
+	rds, err := NewRestfulDasServerOnListener(listener, genericconf.HTTPServerTimeoutConfigDefault, testRestfulEndpointsConfig, nil, storageService)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/url"
+	"os"
 	"strings"
 
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
@@ -46,13 +47,32 @@ func NewRPCAggregatorWithSeqInboxCaller(config DataAvailabilityConfig, seqInboxC
 }
 
 func setUpServices(config DataAvailabilityConfig) ([]ServiceDetails, error) {
-	var cs []BackendConfig
-	err := json.Unmarshal([]byte(config.AggregatorConfig.Backends), &cs)
+	backends := []byte(config.AggregatorConfig.Backends)
+	if config.AggregatorConfig.BackendsFile != "" {
+		var err error
+		backends, err = os.ReadFile(config.AggregatorConfig.BackendsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ParseServices(backends)
+}
+
+// ParseServices creates an RPC client for each backend in a JSON list of
+// BackendConfigs, in the format accepted by --rpc-aggregator.backends.
+func ParseServices(backends []byte) ([]ServiceDetails, error) {
+	cs, err := ParseBackendConfigs(backends)
 	if err != nil {
 		return nil, err
 	}
 
 	var services []ServiceDetails
+	success := false
+	defer func() {
+		if !success {
+			closeServices(services)
+		}
+	}()
 
 	for _, b := range cs {
 		url, err := url.Parse(b.URL)
@@ -69,16 +89,28 @@ func setUpServices(config DataAvailabilityConfig) ([]ServiceDetails, error) {
 
 		pubKey, err := DecodeBase64BLSPublicKey([]byte(b.PubKeyBase64Encoded))
 		if err != nil {
+			service.Close()
 			return nil, err
 		}
 
 		d, err := NewServiceDetails(service, *pubKey, uint64(b.SignerMask), metricName)
 		if err != nil {
+			service.Close()
 			return nil, err
 		}
 
 		services = append(services, *d)
 	}
 
+	success = true
 	return services, nil
 }
+
+func ParseBackendConfigs(backends []byte) ([]BackendConfig, error) {
+	var cs []BackendConfig
+	err := json.Unmarshal(backends, &cs)
+	if err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -94,9 +95,13 @@ func (s3s *S3StorageService) GetByHash(ctx context.Context, key common.Hash) ([]
 
 func (s3s *S3StorageService) Put(ctx context.Context, value []byte, timeout uint64) error {
 	logPut("das.S3StorageService.Store", value, timeout, s3s)
+	return s3s.PutByKey(ctx, dastree.Hash(value), value, timeout)
+}
+
+func (s3s *S3StorageService) PutByKey(ctx context.Context, key common.Hash, value []byte, timeout uint64) error {
 	putObjectInput := s3.PutObjectInput{
 		Bucket: aws.String(s3s.bucket),
-		Key:    aws.String(s3s.objectPrefix + EncodeStorageServiceKey(dastree.Hash(value))),
+		Key:    aws.String(s3s.objectPrefix + EncodeStorageServiceKey(key)),
 		Body:   bytes.NewReader(value)}
 	if !s3s.discardAfterTimeout {
 		expires := time.Unix(int64(timeout), 0)
@@ -109,6 +114,36 @@ func (s3s *S3StorageService) Put(ctx context.Context, value []byte, timeout uint
 	return err
 }
 
+// ForEach lists the bucket's objects under object-prefix. S3 doesn't return
+// object expiry times in listings, so expiration is always 0.
+func (s3s *S3StorageService) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s3s.client, &s3.ListObjectsV2Input{
+		Bucket:     aws.String(s3s.bucket),
+		Prefix:     aws.String(s3s.objectPrefix),
+		StartAfter: aws.String(s3s.objectPrefix + EncodeStorageServiceKey(startAfter)),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(aws.ToString(object.Key), s3s.objectPrefix)
+			if len(name) != 64 {
+				continue
+			}
+			key, err := DecodeStorageServiceKey(name)
+			if err != nil {
+				continue
+			}
+			if err := fn(key, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (s3s *S3StorageService) Sync(ctx context.Context) error {
 	return nil
 }
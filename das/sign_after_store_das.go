@@ -28,13 +28,51 @@ import (
 )
 
 type KeyConfig struct {
-	KeyDir  string `koanf:"key-dir"`
-	PrivKey string `koanf:"priv-key"`
+	KeyDir               string                `koanf:"key-dir"`
+	PrivKey              string                `koanf:"priv-key"`
+	Keystore             string                `koanf:"keystore"`
+	KeystorePasswordFile string                `koanf:"keystore-password-file"`
+	KeystorePasswordEnv  string                `koanf:"keystore-password-env"`
+	RemoteSigner         RemoteBLSSignerConfig `koanf:"remote-signer"`
+}
+
+// IsSet returns whether any source of a BLS signing key was configured.
+func (c *KeyConfig) IsSet() bool {
+	return c.KeyDir != "" || c.PrivKey != "" || c.Keystore != "" || c.RemoteSigner.URL != ""
+}
+
+// BLSSigner returns a signer for the configured key; a RemoteBLSSigner if
+// remote-signer.url is set, otherwise a LocalBLSSigner using BLSPrivKey.
+func (c *KeyConfig) BLSSigner(ctx context.Context) (BLSSigner, error) {
+	if c.RemoteSigner.URL != "" {
+		if c.KeyDir != "" || c.PrivKey != "" || c.Keystore != "" {
+			return nil, errors.New("remote-signer.url may not be specified along with key-dir, priv-key or keystore")
+		}
+		return NewRemoteBLSSigner(ctx, &c.RemoteSigner)
+	}
+	privKey, err := c.BLSPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	return NewLocalBLSSigner(privKey)
 }
 
 func (c *KeyConfig) BLSPrivKey() (blsSignatures.PrivateKey, error) {
 	var privKeyBytes []byte
-	if len(c.PrivKey) != 0 {
+	if len(c.Keystore) != 0 {
+		if len(c.PrivKey) != 0 || len(c.KeyDir) != 0 {
+			return nil, errors.New("keystore may not be specified along with key-dir or priv-key")
+		}
+		password, err := ReadKeystorePassword(c.KeystorePasswordFile, c.KeystorePasswordEnv)
+		if err != nil {
+			return nil, err
+		}
+		privKey, err := ReadPrivKeyFromKeystore(c.Keystore, password)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decrypt BLS keystore %s: %w", c.Keystore, err)
+		}
+		return privKey, nil
+	} else if len(c.PrivKey) != 0 {
 		privKeyBytes = []byte(c.PrivKey)
 	} else if len(c.KeyDir) != 0 {
 		var err error
@@ -46,7 +84,7 @@ func (c *KeyConfig) BLSPrivKey() (blsSignatures.PrivateKey, error) {
 			return nil, err
 		}
 	} else {
-		return nil, errors.New("must specify PrivKey or KeyDir")
+		return nil, errors.New("must specify PrivKey, KeyDir or Keystore")
 	}
 	privKey, err := DecodeBase64BLSPrivateKey(privKeyBytes)
 	if err != nil {
@@ -55,11 +93,17 @@ func (c *KeyConfig) BLSPrivKey() (blsSignatures.PrivateKey, error) {
 	return privKey, nil
 }
 
-var DefaultKeyConfig = KeyConfig{}
+var DefaultKeyConfig = KeyConfig{
+	RemoteSigner: DefaultRemoteBLSSignerConfig,
+}
 
 func KeyConfigAddOptions(prefix string, f *flag.FlagSet) {
-	f.String(prefix+".key-dir", DefaultKeyConfig.KeyDir, fmt.Sprintf("the directory to read the bls keypair ('%s' and '%s') from; if using any of the DAS storage types exactly one of key-dir or priv-key must be specified", DefaultPubKeyFilename, DefaultPrivKeyFilename))
-	f.String(prefix+".priv-key", DefaultKeyConfig.PrivKey, "the base64 BLS private key to use for signing DAS certificates; if using any of the DAS storage types exactly one of key-dir or priv-key must be specified")
+	f.String(prefix+".key-dir", DefaultKeyConfig.KeyDir, fmt.Sprintf("the directory to read the bls keypair ('%s' and '%s') from; if using any of the DAS storage types exactly one of key-dir, priv-key, keystore or remote-signer.url must be specified", DefaultPubKeyFilename, DefaultPrivKeyFilename))
+	f.String(prefix+".priv-key", DefaultKeyConfig.PrivKey, "the base64 BLS private key to use for signing DAS certificates; if using any of the DAS storage types exactly one of key-dir, priv-key, keystore or remote-signer.url must be specified")
+	f.String(prefix+".keystore", DefaultKeyConfig.Keystore, fmt.Sprintf("encrypted BLS keystore file (eg '%s' created by datool keygen --keystore) to read the private key from", DefaultKeystoreFilename))
+	f.String(prefix+".keystore-password-file", DefaultKeyConfig.KeystorePasswordFile, "file containing the password to decrypt the keystore")
+	f.String(prefix+".keystore-password-env", DefaultKeyConfig.KeystorePasswordEnv, "name of the environment variable containing the password to decrypt the keystore, used if keystore-password-file isn't set")
+	RemoteBLSSignerConfigAddOptions(prefix+".remote-signer", f)
 }
 
 // SignAfterStoreDAS provides DAS signature functionality over a StorageService
@@ -74,7 +118,7 @@ func KeyConfigAddOptions(prefix string, f *flag.FlagSet) {
 // is from the batch poster. If the contract details are not provided, then the
 // signature is not checked, which is useful for testing.
 type SignAfterStoreDAS struct {
-	privKey        blsSignatures.PrivateKey
+	signer         BLSSigner
 	pubKey         *blsSignatures.PublicKey
 	keysetHash     [32]byte
 	keysetBytes    []byte
@@ -84,33 +128,45 @@ type SignAfterStoreDAS struct {
 	// Extra batch poster verifier, for local installations to have their
 	// own way of testing Stores.
 	extraBpVerifier func(message []byte, timeout uint64, sig []byte) bool
+
+	// If set, every certificate signed is recorded here before it's stored.
+	journal *SigningJournal
 }
 
 func NewSignAfterStoreDAS(ctx context.Context, config DataAvailabilityConfig, storageService StorageService) (*SignAfterStoreDAS, error) {
-	privKey, err := config.KeyConfig.BLSPrivKey()
+	signer, err := config.KeyConfig.BLSSigner(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if config.L1NodeURL == "none" {
-		return NewSignAfterStoreDASWithSeqInboxCaller(privKey, nil, storageService, config.ExtraSignatureCheckingPublicKey)
-	}
-	l1client, err := GetL1Client(ctx, config.L1ConnectionAttempts, config.L1NodeURL)
-	if err != nil {
-		return nil, err
+	var seqInboxCaller *bridgegen.SequencerInboxCaller
+	if config.L1NodeURL != "none" {
+		l1client, err := GetL1Client(ctx, config.L1ConnectionAttempts, config.L1NodeURL)
+		if err != nil {
+			return nil, err
+		}
+		seqInboxAddress, err := OptionalAddressFromString(config.SequencerInboxAddress)
+		if err != nil {
+			return nil, err
+		}
+		if seqInboxAddress != nil {
+			seqInboxCaller, err = bridgegen.NewSequencerInboxCaller(*seqInboxAddress, l1client)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
-	seqInboxAddress, err := OptionalAddressFromString(config.SequencerInboxAddress)
+	das, err := NewSignAfterStoreDASWithSigner(signer, seqInboxCaller, storageService, config.ExtraSignatureCheckingPublicKey)
 	if err != nil {
 		return nil, err
 	}
-	if seqInboxAddress == nil {
-		return NewSignAfterStoreDASWithSeqInboxCaller(privKey, nil, storageService, config.ExtraSignatureCheckingPublicKey)
-	}
-
-	seqInboxCaller, err := bridgegen.NewSequencerInboxCaller(*seqInboxAddress, l1client)
-	if err != nil {
-		return nil, err
+	if config.SigningJournal.Enable {
+		journal, err := config.SigningJournal.Open()
+		if err != nil {
+			return nil, err
+		}
+		das.SetJournal(journal)
 	}
-	return NewSignAfterStoreDASWithSeqInboxCaller(privKey, seqInboxCaller, storageService, config.ExtraSignatureCheckingPublicKey)
+	return das, nil
 }
 
 func NewSignAfterStoreDASWithSeqInboxCaller(
@@ -119,10 +175,21 @@ func NewSignAfterStoreDASWithSeqInboxCaller(
 	storageService StorageService,
 	extraSignatureCheckingPublicKey string,
 ) (*SignAfterStoreDAS, error) {
-	publicKey, err := blsSignatures.PublicKeyFromPrivateKey(privKey)
+	signer, err := NewLocalBLSSigner(privKey)
 	if err != nil {
 		return nil, err
 	}
+	return NewSignAfterStoreDASWithSigner(signer, seqInboxCaller, storageService, extraSignatureCheckingPublicKey)
+}
+
+func NewSignAfterStoreDASWithSigner(
+	signer BLSSigner,
+	seqInboxCaller *bridgegen.SequencerInboxCaller,
+	storageService StorageService,
+	extraSignatureCheckingPublicKey string,
+) (*SignAfterStoreDAS, error) {
+	publicKey := signer.PublicKey()
+	var err error
 
 	keyset := &arbstate.DataAvailabilityKeyset{
 		AssumedHonest: 1,
@@ -170,7 +237,7 @@ func NewSignAfterStoreDASWithSeqInboxCaller(
 	}
 
 	return &SignAfterStoreDAS{
-		privKey:         privKey,
+		signer:          signer,
 		pubKey:          &publicKey,
 		keysetHash:      ksHash,
 		keysetBytes:     ksBuf.Bytes(),
@@ -211,11 +278,26 @@ func (d *SignAfterStoreDAS) Store(
 	}
 
 	fields := c.SerializeSignableFields()
-	c.Sig, err = blsSignatures.SignMessage(d.privKey, fields)
+	c.Sig, err = d.signer.Sign(ctx, fields)
 	if err != nil {
 		return nil, err
 	}
 
+	// Record the promise before keeping it, so that it's recorded even if
+	// storing only partially succeeds.
+	if d.journal != nil {
+		err = d.journal.Record(SigningJournalEntry{
+			DataHash:   c.DataHash,
+			Timeout:    timeout,
+			KeysetHash: d.keysetHash,
+			Sig:        blsSignatures.SignatureToBytes(c.Sig),
+			SignedAt:   uint64(time.Now().Unix()),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = d.storageService.Put(ctx, message, timeout)
 	if err != nil {
 		return nil, err
@@ -230,10 +312,40 @@ func (d *SignAfterStoreDAS) Store(
 	return c, nil
 }
 
+// SetJournal makes d record every certificate it signs in journal, which d
+// closes when it's closed.
+func (d *SignAfterStoreDAS) SetJournal(journal *SigningJournal) {
+	d.journal = journal
+}
+
+// Close closes the signer, if it holds resources such as a remote connection,
+// and the signing journal, if any. It doesn't close the storage service, which
+// has a lifecycle of its own.
+func (d *SignAfterStoreDAS) Close(ctx context.Context) error {
+	if closer, ok := d.signer.(interface{ Close() }); ok {
+		closer.Close()
+	}
+	if d.journal == nil {
+		return nil
+	}
+	return d.journal.Close(ctx)
+}
+
+func (d *SignAfterStoreDAS) ListSigned(ctx context.Context, from, to, offset uint64) (*SigningJournalList, error) {
+	if d.journal == nil {
+		return nil, ErrSigningJournalDisabled
+	}
+	return d.journal.ListSigned(ctx, from, to, offset)
+}
+
 func (d *SignAfterStoreDAS) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
 	return d.storageService.GetByHash(ctx, hash)
 }
 
+func (d *SignAfterStoreDAS) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	return forEachKey(ctx, d.storageService, startAfter, fn)
+}
+
 func (d *SignAfterStoreDAS) String() string {
 	return fmt.Sprintf("SignAfterStoreDAS{%v}", hexutil.Encode(blsSignatures.PublicKeyToBytes(*d.pubKey)))
 }
@@ -0,0 +1,219 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	flag "github.com/spf13/pflag"
+)
+
+type SigningJournalConfig struct {
+	Enable bool   `koanf:"enable"`
+	File   string `koanf:"file"`
+}
+
+var DefaultSigningJournalConfig = SigningJournalConfig{
+	Enable: false,
+	File:   "",
+}
+
+func SigningJournalConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultSigningJournalConfig.Enable, "record every certificate signed in an append-only journal before returning it")
+	f.String(prefix+".file", DefaultSigningJournalConfig.File, "file to append the signing journal to, as JSON lines")
+}
+
+func (c *SigningJournalConfig) Open() (*SigningJournal, error) {
+	if c.File == "" {
+		return nil, errors.New("signing-journal.file must be specified when the signing journal is enabled")
+	}
+	return OpenSigningJournal(c.File)
+}
+
+var ErrSigningJournalDisabled = errors.New("signing journal not enabled")
+
+const maxSigningJournalListEntries = 10000
+
+// SigningJournalEntry records a certificate a committee member signed, which
+// is a promise to keep the data with DataHash available until Timeout.
+type SigningJournalEntry struct {
+	DataHash   common.Hash   `json:"dataHash"`
+	Timeout    uint64        `json:"timeout"`
+	KeysetHash common.Hash   `json:"keysetHash"`
+	Sig        hexutil.Bytes `json:"sig"`
+	SignedAt   uint64        `json:"signedAt"`
+}
+
+type SigningJournalList struct {
+	Entries []SigningJournalEntry `json:"entries"`
+	// More is set if there are entries in the range after the last one listed,
+	// which are listed by listing again from the offset Next.
+	More bool   `json:"more"`
+	Next uint64 `json:"next"`
+}
+
+// SignedCertificateLister is implemented by DASes that can list what they
+// signed, and by wrappers of DASes that may.
+type SignedCertificateLister interface {
+	// ListSigned lists the certificates signed at or after from and before
+	// to (both in Unix seconds, to of 0 meaning no upper bound), in the
+	// order they were signed, starting at the journal offset offset: 0 for
+	// the first page, and the Next of the previous page for the others.
+	ListSigned(ctx context.Context, from, to, offset uint64) (*SigningJournalList, error)
+}
+
+// listSigned calls ListSigned on das if it can list what it signed.
+func listSigned(ctx context.Context, das interface{}, from, to, offset uint64) (*SigningJournalList, error) {
+	lister, ok := das.(SignedCertificateLister)
+	if !ok {
+		return nil, ErrSigningJournalDisabled
+	}
+	return lister.ListSigned(ctx, from, to, offset)
+}
+
+// SigningJournal is an append-only file of the certificates signed, one JSON
+// encoded SigningJournalEntry per line. Each entry is synced to disk before
+// Record returns.
+type SigningJournal struct {
+	mutex sync.Mutex
+	path  string
+	file  *os.File
+}
+
+func OpenSigningJournal(path string) (*SigningJournal, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	// Terminate the last line if a write was interrupted, so that the next
+	// entry starts on a line of its own.
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+			file.Close()
+			return nil, err
+		}
+		if last[0] != '\n' {
+			if _, err := file.Write([]byte{'\n'}); err != nil {
+				file.Close()
+				return nil, err
+			}
+		}
+	}
+	return &SigningJournal{path: path, file: file}, nil
+}
+
+func (j *SigningJournal) Record(entry SigningJournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file == nil {
+		return ErrClosed
+	}
+	if _, err := j.file.Write(line); err != nil {
+		return fmt.Errorf("error writing signing journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing signing journal: %w", err)
+	}
+	return nil
+}
+
+func (j *SigningJournal) ListSigned(ctx context.Context, from, to, offset uint64) (*SigningJournalList, error) {
+	return ListSigningJournalFile(ctx, j.path, from, to, offset)
+}
+
+// ListSigningJournalFile lists the certificates recorded in the signing journal
+// at path, see SignedCertificateLister. It's safe to use while the journal is
+// being written to.
+func ListSigningJournalFile(ctx context.Context, path string, from, to, offset uint64) (*SigningJournalList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if offset > uint64(info.Size()) {
+		return nil, fmt.Errorf("offset %d is past the end of the signing journal", offset)
+	}
+	if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
+		return nil, err
+	}
+	return readSigningJournal(ctx, file, from, to, offset)
+}
+
+// readSigningJournal lists the entries in range from journal, which starts at
+// the offset offset of the journal file.
+func readSigningJournal(ctx context.Context, journal io.Reader, from, to, offset uint64) (*SigningJournalList, error) {
+	list := &SigningJournalList{Entries: []SigningJournalEntry{}}
+	reader := bufio.NewReader(journal)
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) != 0 {
+				log.Warn("Ignoring incomplete last line of signing journal", "offset", offset)
+			}
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var entry SigningJournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// Left by an interrupted write, whose certificate wasn't returned.
+			log.Warn("Ignoring undecodable line of signing journal", "offset", offset, "err", err)
+			offset += uint64(len(line))
+			continue
+		}
+		if entry.SignedAt >= from && (to == 0 || entry.SignedAt < to) {
+			if len(list.Entries) == maxSigningJournalListEntries {
+				list.More = true
+				list.Next = offset
+				return list, nil
+			}
+			list.Entries = append(list.Entries, entry)
+		}
+		offset += uint64(len(line))
+	}
+}
+
+func (j *SigningJournal) Close(ctx context.Context) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+func (j *SigningJournal) String() string {
+	return fmt.Sprintf("SigningJournal(%s)", j.path)
+}
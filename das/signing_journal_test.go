@@ -0,0 +1,175 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+func TestDAS_SigningJournal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, privKey, err := blsSignatures.GenerateKeys()
+	Require(t, err)
+	localDas, err := NewSignAfterStoreDASWithSeqInboxCaller(privKey, nil, NewMemoryBackedStorageService(ctx), "")
+	Require(t, err)
+	if _, err := localDas.ListSigned(ctx, 0, 0, 0); !errors.Is(err, ErrSigningJournalDisabled) {
+		Fail(t, "expected listing without a journal to fail with", ErrSigningJournalDisabled, "got", err)
+	}
+
+	journalPath := filepath.Join(t.TempDir(), "signing-journal")
+	journal, err := OpenSigningJournal(journalPath)
+	Require(t, err)
+	localDas.SetJournal(journal)
+
+	start := uint64(time.Now().Unix())
+	timeout := uint64(time.Now().Add(time.Hour).Unix())
+	messages := [][]byte{[]byte("first"), []byte("second"), []byte("third")}
+	for _, message := range messages {
+		cert, err := localDas.Store(ctx, message, timeout, nil)
+		Require(t, err)
+		if cert.DataHash != dastree.Hash(message) {
+			Fail(t, "unexpected data hash in certificate")
+		}
+	}
+
+	// A write interrupted before the last certificate was returned.
+	Require(t, journal.Close(ctx))
+	file, err := os.OpenFile(journalPath, os.O_APPEND|os.O_WRONLY, 0600)
+	Require(t, err)
+	_, err = file.Write([]byte(`{"dataHash":"0x12`))
+	Require(t, err)
+	Require(t, file.Close())
+
+	list, err := ListSigningJournalFile(ctx, journalPath, start, 0, 0)
+	Require(t, err)
+	if len(list.Entries) != len(messages) || list.More {
+		Fail(t, "expected", len(messages), "entries, got", len(list.Entries))
+	}
+	for i, entry := range list.Entries {
+		if entry.DataHash != dastree.Hash(messages[i]) || entry.Timeout != timeout || entry.KeysetHash != localDas.keysetHash {
+			Fail(t, "unexpected entry", i, entry)
+		}
+		if entry.SignedAt < start {
+			Fail(t, "entry", i, "signed at", entry.SignedAt, "before the test started at", start)
+		}
+	}
+
+	// Reopening terminates the incomplete line, so new entries are readable.
+	journal, err = OpenSigningJournal(journalPath)
+	Require(t, err)
+	localDas.SetJournal(journal)
+	defer func() {
+		Require(t, localDas.Close(ctx))
+	}()
+	_, err = localDas.Store(ctx, []byte("fourth"), timeout, nil)
+	Require(t, err)
+
+	// The signed endpoint is only served when enabled.
+	listener, err := net.Listen("tcp", "localhost:0")
+	Require(t, err)
+	server, err := NewRestfulDasServerOnListener(listener, genericconf.HTTPServerTimeoutConfigDefault, DefaultRestfulEndpointsConfig, nil, localDas)
+	Require(t, err)
+	client, err := NewRestfulDasClientFromURL("http://" + listener.Addr().String())
+	Require(t, err)
+	if _, err := client.ListSigned(ctx, start, 0, 0); err == nil {
+		Fail(t, "listed signed certificates without the endpoint enabled")
+	}
+	Require(t, server.Shutdown())
+
+	endpoints := DefaultRestfulEndpointsConfig
+	endpoints.Signed = true
+	listener, err = net.Listen("tcp", "localhost:0")
+	Require(t, err)
+	server, err = NewRestfulDasServerOnListener(listener, genericconf.HTTPServerTimeoutConfigDefault, endpoints, nil, localDas)
+	Require(t, err)
+	defer func() {
+		Require(t, server.Shutdown())
+	}()
+	client, err = NewRestfulDasClientFromURL("http://" + listener.Addr().String())
+	Require(t, err)
+
+	list, err = client.ListSigned(ctx, start, 0, 0)
+	Require(t, err)
+	if len(list.Entries) != len(messages)+1 || list.Entries[len(list.Entries)-1].DataHash != dastree.Hash([]byte("fourth")) {
+		Fail(t, "unexpected entries listed over REST", list.Entries)
+	}
+	list, err = client.ListSigned(ctx, 0, start, 0)
+	Require(t, err)
+	if len(list.Entries) != 0 {
+		Fail(t, "expected no entries signed before the test started, got", len(list.Entries))
+	}
+
+	// Closing the DAS closes its journal.
+	Require(t, localDas.Close(ctx))
+	if _, err := localDas.Store(ctx, []byte("fifth"), timeout, nil); !errors.Is(err, ErrClosed) {
+		Fail(t, "expected storing after closing to fail with", ErrClosed, "got", err)
+	}
+}
+
+func TestDAS_SigningJournalPages(t *testing.T) {
+	ctx := context.Background()
+	journalPath := filepath.Join(t.TempDir(), "signing-journal")
+	file, err := os.Create(journalPath)
+	Require(t, err)
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	const entries = maxSigningJournalListEntries*2 + 10
+	for i := 0; i < entries; i++ {
+		Require(t, encoder.Encode(SigningJournalEntry{DataHash: common.BigToHash(big.NewInt(int64(i))), SignedAt: uint64(i)}))
+		if i == entries/2 {
+			_, err := writer.WriteString("not an entry\n")
+			Require(t, err)
+		}
+	}
+	Require(t, writer.Flush())
+	Require(t, file.Close())
+
+	// Page through all but the first and last few entries.
+	from, to := uint64(3), uint64(entries-3)
+	next := from
+	var offset uint64
+	pages := 0
+	for {
+		list, err := ListSigningJournalFile(ctx, journalPath, from, to, offset)
+		Require(t, err)
+		pages++
+		for _, entry := range list.Entries {
+			if entry.SignedAt != next || entry.DataHash != common.BigToHash(new(big.Int).SetUint64(next)) {
+				Fail(t, "expected entry", next, "got", entry)
+			}
+			next++
+		}
+		if !list.More {
+			break
+		}
+		if list.Next <= offset {
+			Fail(t, "listing didn't advance past offset", offset)
+		}
+		offset = list.Next
+	}
+	if next != to || pages != 3 {
+		Fail(t, "listed until", next, "in", pages, "pages, expected until", to, "in 3 pages")
+	}
+
+	if _, err := ListSigningJournalFile(ctx, journalPath, 0, 0, 1<<40); err == nil {
+		Fail(t, "listed from past the end of the journal")
+	}
+}
@@ -29,11 +29,14 @@ type RestfulClientAggregatorConfig struct {
 	Urls                               []string                           `koanf:"urls"`
 	OnlineUrlList                      string                             `koanf:"online-url-list"`
 	OnlineUrlListFetchInterval         time.Duration                      `koanf:"online-url-list-fetch-interval"`
+	OnlineUrlListSigner                string                             `koanf:"online-url-list-signer"`
 	Strategy                           string                             `koanf:"strategy"`
 	StrategyUpdateInterval             time.Duration                      `koanf:"strategy-update-interval"`
 	WaitBeforeTryNext                  time.Duration                      `koanf:"wait-before-try-next"`
 	MaxPerEndpointStats                int                                `koanf:"max-per-endpoint-stats"`
 	SimpleExploreExploitStrategyConfig SimpleExploreExploitStrategyConfig `koanf:"simple-explore-exploit-strategy"`
+	LatencyWeightedStrategyConfig      LatencyWeightedStrategyConfig      `koanf:"latency-weighted-strategy"`
+	HedgedStrategyConfig               HedgedStrategyConfig               `koanf:"hedged-strategy"`
 	SyncToStorageConfig                SyncToStorageConfig                `koanf:"sync-to-storage"`
 }
 
@@ -41,11 +44,14 @@ var DefaultRestfulClientAggregatorConfig = RestfulClientAggregatorConfig{
 	Urls:                               []string{},
 	OnlineUrlList:                      "",
 	OnlineUrlListFetchInterval:         1 * time.Hour,
+	OnlineUrlListSigner:                "",
 	Strategy:                           "simple-explore-exploit",
 	StrategyUpdateInterval:             10 * time.Second,
 	WaitBeforeTryNext:                  2 * time.Second,
 	MaxPerEndpointStats:                20,
 	SimpleExploreExploitStrategyConfig: DefaultSimpleExploreExploitStrategyConfig,
+	LatencyWeightedStrategyConfig:      DefaultLatencyWeightedStrategyConfig,
+	HedgedStrategyConfig:               DefaultHedgedStrategyConfig,
 	SyncToStorageConfig:                DefaultSyncToStorageConfig,
 }
 
@@ -59,16 +65,37 @@ var DefaultSimpleExploreExploitStrategyConfig = SimpleExploreExploitStrategyConf
 	ExploitIterations: 1000,
 }
 
+type LatencyWeightedStrategyConfig struct {
+	DecayFactor float64 `koanf:"decay-factor"`
+}
+
+var DefaultLatencyWeightedStrategyConfig = LatencyWeightedStrategyConfig{
+	DecayFactor: 0.3,
+}
+
+type HedgedStrategyConfig struct {
+	Percentile float64       `koanf:"percentile"`
+	MinDelay   time.Duration `koanf:"min-delay"`
+}
+
+var DefaultHedgedStrategyConfig = HedgedStrategyConfig{
+	Percentile: 0.95,
+	MinDelay:   50 * time.Millisecond,
+}
+
 func RestfulClientAggregatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultRestfulClientAggregatorConfig.Enable, "enable retrieval of sequencer batch data from a list of remote REST endpoints; if other DAS storage types are enabled, this mode is used as a fallback")
 	f.StringSlice(prefix+".urls", DefaultRestfulClientAggregatorConfig.Urls, "list of URLs including 'http://' or 'https://' prefixes and port numbers to REST DAS endpoints; additive with the online-url-list option")
 	f.String(prefix+".online-url-list", DefaultRestfulClientAggregatorConfig.OnlineUrlList, "a URL to a list of URLs of REST das endpoints that is checked at startup; additive with the url option")
 	f.Duration(prefix+".online-url-list-fetch-interval", DefaultRestfulClientAggregatorConfig.OnlineUrlListFetchInterval, "time interval to periodically fetch url list from online-url-list")
-	f.String(prefix+".strategy", DefaultRestfulClientAggregatorConfig.Strategy, "strategy to use to determine order and parallelism of calling REST endpoint URLs; valid options are 'simple-explore-exploit'")
+	f.String(prefix+".online-url-list-signer", DefaultRestfulClientAggregatorConfig.OnlineUrlListSigner, "address of the ECDSA key that must sign the online-url-list; if set, online-url-list must be a signed JSON list, and expired lists or lists with an older version than the current one are rejected")
+	f.String(prefix+".strategy", DefaultRestfulClientAggregatorConfig.Strategy, "strategy to use to determine order and parallelism of calling REST endpoint URLs; valid options are 'simple-explore-exploit', 'latency-weighted' and 'hedged'")
 	f.Duration(prefix+".strategy-update-interval", DefaultRestfulClientAggregatorConfig.StrategyUpdateInterval, "how frequently to update the strategy with endpoint latency and error rate data")
 	f.Duration(prefix+".wait-before-try-next", DefaultRestfulClientAggregatorConfig.WaitBeforeTryNext, "time to wait until trying the next set of REST endpoints while waiting for a response; the next set of REST endpoints is determined by the strategy selected")
 	f.Int(prefix+".max-per-endpoint-stats", DefaultRestfulClientAggregatorConfig.MaxPerEndpointStats, "number of stats entries (latency and success rate) to keep for each REST endpoint; controls whether strategy is faster or slower to respond to changing conditions")
 	SimpleExploreExploitStrategyConfigAddOptions(prefix+".simple-explore-exploit-strategy", f)
+	LatencyWeightedStrategyConfigAddOptions(prefix+".latency-weighted-strategy", f)
+	HedgedStrategyConfigAddOptions(prefix+".hedged-strategy", f)
 	SyncToStorageConfigAddOptions(prefix+".sync-to-storage", f)
 }
 
@@ -77,6 +104,15 @@ func SimpleExploreExploitStrategyConfigAddOptions(prefix string, f *flag.FlagSet
 	f.Int(prefix+".exploit-iterations", DefaultSimpleExploreExploitStrategyConfig.ExploitIterations, "number of consecutive GetByHash calls to the aggregator where each call will cause it to select from REST endpoints in order of best latency and success rate, before switching to explore mode")
 }
 
+func LatencyWeightedStrategyConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Float64(prefix+".decay-factor", DefaultLatencyWeightedStrategyConfig.DecayFactor, "weight (0-1) given to the newest request in the moving averages of REST endpoint latency and success rate; also used by the hedged strategy")
+}
+
+func HedgedStrategyConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Float64(prefix+".percentile", DefaultHedgedStrategyConfig.Percentile, "percentile (0-1) of a REST endpoint's recent latencies after which a request to it is hedged by a request to the next endpoint")
+	f.Duration(prefix+".min-delay", DefaultHedgedStrategyConfig.MinDelay, "minimum time to wait for a REST endpoint before hedging; wait-before-try-next is the maximum")
+}
+
 func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggregatorConfig) (*SimpleDASReaderAggregator, error) {
 	a := SimpleDASReaderAggregator{
 		config: config,
@@ -87,8 +123,17 @@ func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggreg
 	for _, url := range config.Urls {
 		combinedUrls[url] = true
 	}
+	var listSigner *common.Address
+	if config.OnlineUrlListSigner != "" {
+		if !common.IsHexAddress(config.OnlineUrlListSigner) {
+			return nil, fmt.Errorf("invalid rest-aggregator.online-url-list-signer address %s", config.OnlineUrlListSigner)
+		}
+		signer := common.HexToAddress(config.OnlineUrlListSigner)
+		listSigner = &signer
+	}
+	a.listFetcher = NewRestfulServerListFetcher(config.OnlineUrlList, listSigner)
 	if config.OnlineUrlList != DefaultRestfulClientAggregatorConfig.OnlineUrlList {
-		onlineUrls, err := RestfulServerURLsFromList(ctx, config.OnlineUrlList)
+		onlineUrls, err := a.listFetcher.Fetch(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -123,6 +168,19 @@ func NewRestfulClientAggregator(ctx context.Context, config *RestfulClientAggreg
 			exploreIterations: uint32(config.SimpleExploreExploitStrategyConfig.ExploreIterations),
 			exploitIterations: uint32(config.SimpleExploreExploitStrategyConfig.ExploitIterations),
 		}
+	case "latency-weighted":
+		a.strategy = &latencyWeightedStrategy{
+			decayFactor: config.LatencyWeightedStrategyConfig.DecayFactor,
+		}
+	case "hedged":
+		a.strategy = &hedgedStrategy{
+			latencyWeightedStrategy: latencyWeightedStrategy{
+				decayFactor: config.LatencyWeightedStrategyConfig.DecayFactor,
+			},
+			percentile: config.HedgedStrategyConfig.Percentile,
+			minDelay:   config.HedgedStrategyConfig.MinDelay,
+			maxDelay:   config.WaitBeforeTryNext,
+		}
 	case "testing-sequential":
 		a.strategy = &testingSequentialStrategy{}
 	default:
@@ -176,6 +234,8 @@ type SimpleDASReaderAggregator struct {
 	strategy aggregatorStrategy
 
 	statMessages chan readerStatMessage
+
+	listFetcher *RestfulServerListFetcher
 }
 
 func (a *SimpleDASReaderAggregator) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
@@ -214,10 +274,14 @@ func (a *SimpleDASReaderAggregator) GetByHash(ctx context.Context, hash common.H
 				wg.Wait()
 				close(waitChan)
 			}()
+			waitBeforeTryNext := a.config.WaitBeforeTryNext
+			if dsi, ok := si.(delayingStrategyInstance); ok {
+				waitBeforeTryNext = dsi.waitBeforeTryNext()
+			}
 			select {
 			case <-subCtx.Done():
 				return
-			case <-time.After(a.config.WaitBeforeTryNext):
+			case <-time.After(waitBeforeTryNext):
 			case <-waitChan:
 				// Yield to give the collector a chance to run in case a request succeeded
 				time.Sleep(10 * time.Millisecond)
@@ -271,7 +335,7 @@ func (a *SimpleDASReaderAggregator) tryGetByHash(
 
 func (a *SimpleDASReaderAggregator) Start(ctx context.Context) {
 	a.StopWaiter.Start(ctx)
-	onlineUrlsChan := StartRestfulServerListFetchDaemon(a.StopWaiter.GetContext(), a.config.OnlineUrlList, a.config.OnlineUrlListFetchInterval)
+	onlineUrlsChan := a.listFetcher.StartDaemon(a.StopWaiter.GetContext(), a.config.OnlineUrlListFetchInterval)
 
 	updateRestfulDasClients := func(urls []string) {
 		a.readersMutex.Lock()
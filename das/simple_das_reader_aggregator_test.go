@@ -6,12 +6,16 @@ package das
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/das/dastree"
 )
 
@@ -84,3 +88,111 @@ func TestSimpleDASReaderAggregator(t *testing.T) { //nolint
 	Require(t, err)
 
 }
+
+// simulatedReader serves data from storage after a delay, or always fails.
+type simulatedReader struct {
+	storage StorageService
+	delay   time.Duration
+	fail    bool
+}
+
+func (r *simulatedReader) GetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if r.fail {
+		return nil, errors.New("simulated failure")
+	}
+	return r.storage.GetByHash(ctx, hash)
+}
+
+func (r *simulatedReader) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (r *simulatedReader) ExpirationPolicy(ctx context.Context) (arbstate.ExpirationPolicy, error) {
+	return arbstate.KeepForever, nil
+}
+
+func TestDAS_HedgedReaderAggregator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := NewMemoryBackedStorageService(ctx)
+	data := []byte("hedge your bets")
+	Require(t, storage.Put(ctx, data, uint64(time.Now().Add(time.Hour).Unix())))
+
+	failing := &simulatedReader{storage: storage, fail: true}
+	// Historically the fastest, but now stalling.
+	stalled := &simulatedReader{storage: storage, delay: 3 * time.Second}
+	fast := &simulatedReader{storage: storage, delay: 20 * time.Millisecond}
+	readers := []arbstate.DataAvailabilityReader{failing, stalled, fast}
+	stats := map[arbstate.DataAvailabilityReader]readerStats{
+		failing: {{time.Millisecond, false}, {time.Millisecond, false}},
+		stalled: {{10 * time.Millisecond, true}, {10 * time.Millisecond, true}},
+		fast:    {{30 * time.Millisecond, true}, {30 * time.Millisecond, true}},
+	}
+
+	for _, strategyName := range []string{"hedged", "latency-weighted"} {
+		config := DefaultRestfulClientAggregatorConfig
+		config.Strategy = strategyName
+		config.WaitBeforeTryNext = 2 * time.Second
+		agg := &SimpleDASReaderAggregator{
+			config:       &config,
+			readers:      readers,
+			stats:        stats,
+			statMessages: make(chan readerStatMessage, 100),
+		}
+		if strategyName == "hedged" {
+			agg.strategy = &hedgedStrategy{
+				latencyWeightedStrategy: latencyWeightedStrategy{decayFactor: config.LatencyWeightedStrategyConfig.DecayFactor},
+				percentile:              config.HedgedStrategyConfig.Percentile,
+				minDelay:                config.HedgedStrategyConfig.MinDelay,
+				maxDelay:                config.WaitBeforeTryNext,
+			}
+		} else {
+			agg.strategy = &latencyWeightedStrategy{decayFactor: config.LatencyWeightedStrategyConfig.DecayFactor}
+		}
+		agg.strategy.update(agg.readers, agg.stats)
+
+		start := time.Now()
+		returnedData, err := agg.GetByHash(ctx, dastree.Hash(data))
+		Require(t, err)
+		if !bytes.Equal(returnedData, data) {
+			Fail(t, strategyName, "returned wrong data")
+		}
+		elapsed := time.Since(start)
+		if strategyName == "hedged" && elapsed > time.Second {
+			Fail(t, "hedged request took", elapsed, "expected it not to wait for the stalled reader")
+		}
+		if strategyName == "latency-weighted" && elapsed < config.WaitBeforeTryNext {
+			Fail(t, "latency-weighted request took", elapsed, "expected it to wait wait-before-try-next for the stalled reader")
+		}
+	}
+
+	// Readers returning data that doesn't match the hash aren't used.
+	corrupt := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+	corrupt.contents[dastree.Hash(data)] = []byte("not the data")
+	config := DefaultRestfulClientAggregatorConfig
+	config.WaitBeforeTryNext = time.Second
+	agg := &SimpleDASReaderAggregator{
+		config:       &config,
+		readers:      []arbstate.DataAvailabilityReader{&simulatedReader{storage: corrupt}, fast},
+		stats:        make(map[arbstate.DataAvailabilityReader]readerStats),
+		statMessages: make(chan readerStatMessage, 100),
+		strategy: &hedgedStrategy{
+			latencyWeightedStrategy: latencyWeightedStrategy{decayFactor: 0.3},
+			percentile:              0.95,
+			minDelay:                50 * time.Millisecond,
+			maxDelay:                time.Second,
+		},
+	}
+	agg.strategy.update(agg.readers, agg.stats)
+	returnedData, err := agg.GetByHash(ctx, dastree.Hash(data))
+	Require(t, err)
+	if !bytes.Equal(returnedData, data) {
+		Fail(t, "returned data that doesn't match its hash")
+	}
+}
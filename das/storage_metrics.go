@@ -0,0 +1,102 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	bigCacheHitCounter  = metrics.NewRegisteredCounter("arb/das/cache/bigcache/hit", nil)
+	bigCacheMissCounter = metrics.NewRegisteredCounter("arb/das/cache/bigcache/miss", nil)
+
+	redisHitCounter   = metrics.NewRegisteredCounter("arb/das/cache/redis/hit", nil)
+	redisMissCounter  = metrics.NewRegisteredCounter("arb/das/cache/redis/miss", nil)
+	redisErrorCounter = metrics.NewRegisteredCounter("arb/das/cache/redis/error", nil)
+	// Values the admission policy didn't cache.
+	redisRejectedCounter = metrics.NewRegisteredCounter("arb/das/cache/redis/rejected", nil)
+
+	fallbackPrimaryHitCounter     = metrics.NewRegisteredCounter("arb/das/fallback/primary/hit", nil)
+	fallbackBackupRequestCounter  = metrics.NewRegisteredCounter("arb/das/fallback/backup/requests", nil)
+	fallbackBackupFailureCounter  = metrics.NewRegisteredCounter("arb/das/fallback/backup/failure", nil)
+	fallbackRetentionErrorCounter = metrics.NewRegisteredCounter("arb/das/fallback/retention/error", nil)
+)
+
+type storageOperationMetrics struct {
+	success  metrics.Counter
+	failure  metrics.Counter
+	notFound metrics.Counter
+	bytes    metrics.Counter
+	duration metrics.Histogram
+}
+
+func newStorageOperationMetrics(prefix string) storageOperationMetrics {
+	return storageOperationMetrics{
+		success:  metrics.GetOrRegisterCounter(prefix+"/success", nil),
+		failure:  metrics.GetOrRegisterCounter(prefix+"/failure", nil),
+		notFound: metrics.GetOrRegisterCounter(prefix+"/notfound", nil),
+		bytes:    metrics.GetOrRegisterCounter(prefix+"/bytes", nil),
+		duration: metrics.GetOrRegisterHistogram(prefix+"/duration", nil, metrics.NewExpDecaySample(1028, 0.015)),
+	}
+}
+
+func (m *storageOperationMetrics) record(start time.Time, size int, err error) {
+	m.duration.Update(time.Since(start).Nanoseconds())
+	if err == nil {
+		m.success.Inc(1)
+		m.bytes.Inc(int64(size))
+	} else if errors.Is(err, ErrNotFound) {
+		m.notFound.Inc(1)
+	} else {
+		m.failure.Inc(1)
+	}
+}
+
+// MetricsStorageService records counts, sizes and durations of the GetByHash
+// and Put calls made to the StorageService it wraps, under
+// arb/das/storage/<name>/.
+type MetricsStorageService struct {
+	StorageService
+	name string
+	get  storageOperationMetrics
+	put  storageOperationMetrics
+}
+
+func NewMetricsStorageService(name string, storageService StorageService) *MetricsStorageService {
+	prefix := "arb/das/storage/" + name
+	return &MetricsStorageService{
+		StorageService: storageService,
+		name:           name,
+		get:            newStorageOperationMetrics(prefix + "/get"),
+		put:            newStorageOperationMetrics(prefix + "/put"),
+	}
+}
+
+func (m *MetricsStorageService) GetByHash(ctx context.Context, key common.Hash) ([]byte, error) {
+	start := time.Now()
+	data, err := m.StorageService.GetByHash(ctx, key)
+	m.get.record(start, len(data), err)
+	return data, err
+}
+
+func (m *MetricsStorageService) Put(ctx context.Context, data []byte, expirationTime uint64) error {
+	start := time.Now()
+	err := m.StorageService.Put(ctx, data, expirationTime)
+	m.put.record(start, len(data), err)
+	return err
+}
+
+func (m *MetricsStorageService) ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	return forEachKey(ctx, m.StorageService, startAfter, fn)
+}
+
+func (m *MetricsStorageService) String() string {
+	return fmt.Sprintf("MetricsStorageService(%s, %v)", m.name, m.StorageService)
+}
@@ -0,0 +1,111 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+func TestDAS_MetricsStorageService(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := NewMetricsStorageService("test_metrics_storage", NewMemoryBackedStorageService(ctx))
+	data := []byte("measured")
+	Require(t, storage.Put(ctx, data, uint64(time.Now().Add(time.Hour).Unix())))
+	_, err := storage.GetByHash(ctx, dastree.Hash(data))
+	Require(t, err)
+	if _, err := storage.GetByHash(ctx, dastree.Hash([]byte("missing"))); err == nil {
+		Fail(t, "expected missing data not to be found")
+	}
+
+	counter := func(name string) int64 {
+		return metrics.GetOrRegisterCounter("arb/das/storage/test_metrics_storage/"+name, nil).Count()
+	}
+	if counter("put/success") != 1 || counter("put/bytes") != int64(len(data)) {
+		Fail(t, "unexpected put metrics", counter("put/success"), counter("put/bytes"))
+	}
+	if counter("get/success") != 1 || counter("get/notfound") != 1 || counter("get/failure") != 0 {
+		Fail(t, "unexpected get metrics", counter("get/success"), counter("get/notfound"), counter("get/failure"))
+	}
+	if counter("get/bytes") != int64(len(data)) {
+		Fail(t, "unexpected get bytes", counter("get/bytes"))
+	}
+}
+
+func TestDAS_RestfulServerAccessLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mutex sync.Mutex
+	var records []*log.Record
+	accessLog := log.New()
+	accessLog.SetHandler(log.FuncHandler(func(r *log.Record) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		records = append(records, r)
+		return nil
+	}))
+
+	storage := NewMemoryBackedStorageService(ctx)
+	data := []byte("logged")
+	Require(t, storage.Put(ctx, data, uint64(time.Now().Add(time.Hour).Unix())))
+
+	listener, err := net.Listen("tcp", LocalServerAddressForTest+":0")
+	Require(t, err)
+	server, err := NewRestfulDasServerOnListener(listener, genericconf.HTTPServerTimeoutConfigDefault, DefaultRestfulEndpointsConfig, accessLog, storage)
+	Require(t, err)
+	defer func() {
+		_ = server.Shutdown()
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	client := NewRestfulDasClient("http", LocalServerAddressForTest, port)
+	_, err = client.GetByHash(ctx, dastree.Hash(data))
+	Require(t, err)
+	missingHash := dastree.Hash([]byte("missing"))
+	if _, err := client.GetByHash(ctx, missingHash); err == nil {
+		Fail(t, "expected missing data not to be found")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(records) != 2 {
+		Fail(t, "expected 2 access log records, got", len(records))
+	}
+	fields := func(r *log.Record) map[string]interface{} {
+		m := make(map[string]interface{})
+		for i := 0; i+1 < len(r.Ctx); i += 2 {
+			m[fmt.Sprint(r.Ctx[i])] = r.Ctx[i+1]
+		}
+		return m
+	}
+	first := fields(records[0])
+	if first["status"] != http.StatusOK || first["hash"] != EncodeStorageServiceKey(dastree.Hash(data)) {
+		Fail(t, "unexpected access log record", first)
+	}
+	second := fields(records[1])
+	if second["status"] != http.StatusNotFound || second["hash"] != EncodeStorageServiceKey(missingHash) {
+		Fail(t, "unexpected access log record", second)
+	}
+	if _, ok := second["latency"].(time.Duration); !ok {
+		Fail(t, "access log record has no latency", second)
+	}
+}
@@ -25,6 +25,54 @@ type StorageService interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// KeyIterator is implemented by storage backends that can enumerate their
+// contents, and by the layers over them that pass the iteration through.
+type KeyIterator interface {
+	// ForEach calls fn for every key greater than startAfter, in ascending
+	// byte order of the keys, so that an interrupted iteration can be resumed
+	// from the last key it handled. expiration is the Unix time the value
+	// expires at, or 0 if the backend doesn't know it. Iteration stops at the
+	// first error returned by fn, which ForEach then returns.
+	ForEach(ctx context.Context, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error
+}
+
+// IterableStorageService is a StorageService that can enumerate its contents.
+type IterableStorageService interface {
+	StorageService
+	KeyIterator
+}
+
+var ErrIterationNotSupported = errors.New("storage doesn't support iterating over keys")
+
+// forEachKey iterates over the keys of s if it supports it, and otherwise
+// returns ErrIterationNotSupported.
+func forEachKey(ctx context.Context, s interface{}, startAfter common.Hash, fn func(key common.Hash, expiration uint64) error) error {
+	iterator, ok := s.(KeyIterator)
+	if !ok {
+		return ErrIterationNotSupported
+	}
+	return iterator.ForEach(ctx, startAfter, fn)
+}
+
+// KeyedPutter is implemented by storage backends that can store a value under
+// a key other than its tree hash, such as the flat hash it was stored under by
+// storage predating tree hashes, or its upgraded key.
+type KeyedPutter interface {
+	PutByKey(ctx context.Context, key common.Hash, data []byte, expirationTime uint64) error
+}
+
+var ErrKeyedPutNotSupported = errors.New("storage doesn't support storing values under a given key")
+
+// putByKey stores data under key in s if it supports it, and otherwise
+// returns ErrKeyedPutNotSupported.
+func putByKey(ctx context.Context, s interface{}, key common.Hash, data []byte, expirationTime uint64) error {
+	putter, ok := s.(KeyedPutter)
+	if !ok {
+		return ErrKeyedPutNotSupported
+	}
+	return putter.PutByKey(ctx, key, data, expirationTime)
+}
+
 func EncodeStorageServiceKey(key common.Hash) string {
 	return key.Hex()[2:]
 }
@@ -6,10 +6,12 @@ package das
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"os"
 	"sync"
 	"time"
 
@@ -61,6 +63,12 @@ type SyncToStorageConfig struct {
 	DelayOnError         time.Duration `koanf:"delay-on-error"`
 	IgnoreWriteErrors    bool          `koanf:"ignore-write-errors"`
 	L1BlocksPerRead      uint64        `koanf:"l1-blocks-per-read"`
+	StateFile            string        `koanf:"state-file"`
+	FinalizeDistance     uint64        `koanf:"finalize-distance"`
+	UseMergeFinality     bool          `koanf:"use-merge-finality"`
+	RequireFullFinality  bool          `koanf:"require-full-finality"`
+	ReconcileUrls        []string      `koanf:"reconcile-urls"`
+	ReconcileInterval    time.Duration `koanf:"reconcile-interval"`
 }
 
 var DefaultSyncToStorageConfig = SyncToStorageConfig{
@@ -70,6 +78,12 @@ var DefaultSyncToStorageConfig = SyncToStorageConfig{
 	DelayOnError:         time.Second,
 	IgnoreWriteErrors:    true,
 	L1BlocksPerRead:      100,
+	StateFile:            "",
+	FinalizeDistance:     12,
+	UseMergeFinality:     true,
+	RequireFullFinality:  false,
+	ReconcileUrls:        []string{},
+	ReconcileInterval:    time.Hour,
 }
 
 func SyncToStorageConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -79,6 +93,75 @@ func SyncToStorageConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Duration(prefix+".retention-period", DefaultSyncToStorageConfig.RetentionPeriod, "period to retain synced data (defaults to forever)")
 	f.Duration(prefix+".delay-on-error", DefaultSyncToStorageConfig.DelayOnError, "time to wait if encountered an error before retrying")
 	f.Bool(prefix+".ignore-write-errors", DefaultSyncToStorageConfig.IgnoreWriteErrors, "log only on failures to write when syncing; otherwise treat it as an error")
+	f.String(prefix+".state-file", DefaultSyncToStorageConfig.StateFile, "when eagerly syncing, file to persist the sync position in so that a restart resumes from it instead of from eager-lower-bound-block")
+	f.Uint64(prefix+".finalize-distance", DefaultSyncToStorageConfig.FinalizeDistance, "when eagerly syncing, number of L1 blocks after which a block is considered final if the L1 doesn't support the safe and finalized tags")
+	f.Bool(prefix+".use-merge-finality", DefaultSyncToStorageConfig.UseMergeFinality, "when eagerly syncing, use the L1's safe or finalized block instead of finalize-distance once the L1 is past the merge")
+	f.Bool(prefix+".require-full-finality", DefaultSyncToStorageConfig.RequireFullFinality, "when eagerly syncing, use the finalized rather than the safe L1 block")
+	f.StringSlice(prefix+".reconcile-urls", DefaultSyncToStorageConfig.ReconcileUrls, "when eagerly syncing, URLs of REST endpoints of peer mirrors to periodically compare stored keys with, copying any that are missing; the local storage must support iterating over its keys")
+	f.Duration(prefix+".reconcile-interval", DefaultSyncToStorageConfig.ReconcileInterval, "when eagerly syncing, time between reconciliations with reconcile-urls")
+}
+
+// l1SyncCheckpoint records the inbox accumulator after a batch that was
+// synced, so that a reorg can be detected and syncing rewound to the last
+// batch that is still on chain.
+type l1SyncCheckpoint struct {
+	BlockNr    uint64      `json:"blockNr"`
+	BatchCount uint64      `json:"batchCount"`
+	BatchAcc   common.Hash `json:"batchAcc"`
+}
+
+const maxL1SyncCheckpoints = 128
+
+type l1SyncState struct {
+	InboxAddr   common.Address     `json:"inboxAddr"`
+	LowBlockNr  uint64             `json:"lowBlockNr"`
+	Checkpoints []l1SyncCheckpoint `json:"checkpoints"`
+}
+
+func readL1SyncState(stateFile string) (*l1SyncState, error) {
+	contents, err := os.ReadFile(stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state l1SyncState
+	if err := json.Unmarshal(contents, &state); err != nil {
+		return nil, fmt.Errorf("invalid sync state file %s: %w", stateFile, err)
+	}
+	return &state, nil
+}
+
+func writeL1SyncState(stateFile string, state *l1SyncState) error {
+	contents, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmpFile := stateFile + ".tmp"
+	if err := os.WriteFile(tmpFile, contents, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, stateFile)
+}
+
+// latestConsistentCheckpoint returns the index of the newest checkpoint whose
+// accumulator matches what accAt reports for its batch, or -1 if there's none.
+func latestConsistentCheckpoint(checkpoints []l1SyncCheckpoint, currentBatchCount uint64, accAt func(batchCount uint64) (common.Hash, error)) (int, error) {
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		cp := checkpoints[i]
+		if cp.BatchCount > currentBatchCount {
+			continue
+		}
+		acc, err := accAt(cp.BatchCount)
+		if err != nil {
+			return 0, err
+		}
+		if acc == cp.BatchAcc {
+			return i, nil
+		}
+	}
+	return -1, nil
 }
 
 type l1SyncService struct {
@@ -92,10 +175,9 @@ type l1SyncService struct {
 	inboxContract *bridgegen.SequencerInbox
 	inboxAddr     common.Address
 
-	catchingUp     bool
-	lowBlockNr     uint64
-	lastBatchCount *big.Int
-	lastBatchAcc   common.Hash
+	catchingUp  bool
+	lowBlockNr  uint64
+	checkpoints []l1SyncCheckpoint
 }
 
 func newl1SyncService(config *SyncToStorageConfig, syncTo StorageService, dataSource arbstate.DataAvailabilityReader, l1Reader *headerreader.HeaderReader, inboxAddr common.Address) (*l1SyncService, error) {
@@ -109,17 +191,105 @@ func newl1SyncService(config *SyncToStorageConfig, syncTo StorageService, dataSo
 	if err != nil {
 		return nil, err
 	}
-	return &l1SyncService{
-		config:         *config,
-		syncTo:         syncTo,
-		dataSource:     dataSource,
-		l1Reader:       l1Reader,
-		inboxContract:  inboxContract,
-		inboxAddr:      inboxAddr,
-		catchingUp:     true,
-		lowBlockNr:     config.EagerLowerBoundBlock,
-		lastBatchCount: big.NewInt(0),
-	}, nil
+	s := &l1SyncService{
+		config:        *config,
+		syncTo:        syncTo,
+		dataSource:    dataSource,
+		l1Reader:      l1Reader,
+		inboxContract: inboxContract,
+		inboxAddr:     inboxAddr,
+		catchingUp:    true,
+		lowBlockNr:    config.EagerLowerBoundBlock,
+	}
+	if config.StateFile != "" {
+		state, err := readL1SyncState(config.StateFile)
+		if err != nil {
+			return nil, err
+		}
+		if state != nil {
+			if state.InboxAddr != inboxAddr {
+				return nil, fmt.Errorf("sync state file %s is for sequencer inbox %v, not %v", config.StateFile, state.InboxAddr, inboxAddr)
+			}
+			if state.LowBlockNr > s.lowBlockNr {
+				s.lowBlockNr = state.LowBlockNr
+				s.checkpoints = state.Checkpoints
+			}
+			log.Info("Resuming DAS sync from L1", "block", s.lowBlockNr, "stateFile", config.StateFile)
+		}
+	}
+	return s, nil
+}
+
+func (s *l1SyncService) lastBatchCount() uint64 {
+	if len(s.checkpoints) == 0 {
+		return 0
+	}
+	return s.checkpoints[len(s.checkpoints)-1].BatchCount
+}
+
+func (s *l1SyncService) saveState() error {
+	if s.config.StateFile == "" {
+		return nil
+	}
+	return writeL1SyncState(s.config.StateFile, &l1SyncState{
+		InboxAddr:   s.inboxAddr,
+		LowBlockNr:  s.lowBlockNr,
+		Checkpoints: s.checkpoints,
+	})
+}
+
+// finalizedBlockNr returns the newest L1 block that won't be reorged, using
+// the safe or finalized tag if the L1 supports it.
+func (s *l1SyncService) finalizedBlockNr(latest *types.Header) uint64 {
+	if s.config.UseMergeFinality && latest.Difficulty.Sign() == 0 {
+		var header *types.Header
+		var err error
+		if s.config.RequireFullFinality {
+			header, err = s.l1Reader.LatestFinalizedHeader()
+		} else {
+			header, err = s.l1Reader.LatestSafeHeader()
+		}
+		if err == nil && header != nil {
+			return header.Number.Uint64()
+		}
+		log.Debug("L1 doesn't support safe/finalized tags, using finalize-distance", "err", err)
+	}
+	if latest.Number.Uint64() < s.config.FinalizeDistance {
+		return 0
+	}
+	return latest.Number.Uint64() - s.config.FinalizeDistance
+}
+
+func (s *l1SyncService) accAt(callOpts *bind.CallOpts) func(uint64) (common.Hash, error) {
+	return func(batchCount uint64) (common.Hash, error) {
+		if batchCount == 0 {
+			return common.Hash{}, nil
+		}
+		acc, err := s.inboxContract.InboxAccs(callOpts, new(big.Int).SetUint64(batchCount-1))
+		return acc, err
+	}
+}
+
+// rewind restarts syncing from the newest checkpoint that's still on chain.
+// Batches are idempotent to sync, so the block of that checkpoint is
+// rescanned in case it contained later batches too.
+func (s *l1SyncService) rewind(callOpts *bind.CallOpts, currentBatchCount uint64) error {
+	i, err := latestConsistentCheckpoint(s.checkpoints, currentBatchCount, s.accAt(callOpts))
+	if err != nil {
+		return err
+	}
+	if i < 0 {
+		log.Warn("L1 reorg detected, no synced batch is still on chain; rescanning from eager-lower-bound-block", "block", s.config.EagerLowerBoundBlock)
+		s.checkpoints = nil
+		s.lowBlockNr = s.config.EagerLowerBoundBlock
+	} else {
+		cp := s.checkpoints[i]
+		log.Warn("L1 reorg detected, rescanning", "fromBlock", cp.BlockNr, "lastGoodBatchCount", cp.BatchCount)
+		s.checkpoints = s.checkpoints[:i+1]
+		s.lowBlockNr = cp.BlockNr
+	}
+	s.catchingUp = true
+	return s.saveState()
 }
 
 func (s *l1SyncService) processBatchDelivered(ctx context.Context, batchDeliveredLog types.Log) error {
@@ -201,14 +371,30 @@ func (s *l1SyncService) processBatchDelivered(ctx context.Context, batchDelivere
 			return err
 		}
 	}
+	return nil
+}
+
+func (s *l1SyncService) recordBatch(batchDeliveredLog types.Log) error {
+	deliveredEvent, err := s.inboxContract.ParseSequencerBatchDelivered(batchDeliveredLog)
+	if err != nil {
+		return err
+	}
 	seqNumber := deliveredEvent.BatchSequenceNumber
 	if seqNumber == nil {
 		seqNumber = common.Big0
 	}
-	updatedBatchCount := new(big.Int).Add(seqNumber, common.Big1)
-	if s.lastBatchCount.Cmp(updatedBatchCount) <= 0 {
-		s.lastBatchCount.Set(seqNumber)
-		s.lastBatchAcc = deliveredEvent.AfterAcc
+	updatedBatchCount := seqNumber.Uint64() + 1
+	if updatedBatchCount <= s.lastBatchCount() {
+		// rescanning a batch we've already recorded
+		return nil
+	}
+	s.checkpoints = append(s.checkpoints, l1SyncCheckpoint{
+		BlockNr:    batchDeliveredLog.BlockNumber,
+		BatchCount: updatedBatchCount,
+		BatchAcc:   deliveredEvent.AfterAcc,
+	})
+	if len(s.checkpoints) > maxL1SyncCheckpoints {
+		s.checkpoints = s.checkpoints[len(s.checkpoints)-maxL1SyncCheckpoints:]
 	}
 	return nil
 }
@@ -228,6 +414,9 @@ func (s *l1SyncService) processBlockRange(ctx context.Context, lowerBound, highe
 		if err := s.processBatchDelivered(ctx, deliveredLog); err != nil {
 			return err
 		}
+		if err := s.recordBatch(deliveredLog); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -238,29 +427,35 @@ func (s *l1SyncService) readMore(ctx context.Context) error {
 		return err
 	}
 	highBlockNr := header.Number.Uint64()
-	finalizedHighBlockNr := highBlockNr - 12 // TODO
+	finalizedHighBlockNr := s.finalizedBlockNr(header)
 	callOpts := &bind.CallOpts{
 		Context:     ctx,
 		BlockNumber: header.Number,
 	}
-	if s.lastBatchCount != nil {
-		currentBatchCount, err := s.inboxContract.BatchCount(callOpts)
+	currentBatchCountBig, err := s.inboxContract.BatchCount(callOpts)
+	if err != nil {
+		return err
+	}
+	currentBatchCount := currentBatchCountBig.Uint64()
+	if len(s.checkpoints) > 0 {
+		last := s.checkpoints[len(s.checkpoints)-1]
+		if last.BatchCount > currentBatchCount {
+			return s.rewind(callOpts, currentBatchCount)
+		}
+		acc, err := s.accAt(callOpts)(last.BatchCount)
 		if err != nil {
 			return err
 		}
-		if currentBatchCount.Cmp(s.lastBatchCount) == 0 {
-			accBytes, err := s.inboxContract.InboxAccs(callOpts, new(big.Int).Sub(currentBatchCount, common.Big1))
-			if err != nil {
-				return err
-			}
-			var lastAccHash common.Hash
-			copy(lastAccHash[:], accBytes[:])
-			if lastAccHash == s.lastBatchAcc {
-				// we're up to date
+		if acc != last.BatchAcc {
+			return s.rewind(callOpts, currentBatchCount)
+		}
+		if currentBatchCount == last.BatchCount {
+			// we're up to date
+			if finalizedHighBlockNr > s.lowBlockNr {
 				s.lowBlockNr = finalizedHighBlockNr
-				s.catchingUp = false
-				return nil
 			}
+			s.catchingUp = false
+			return s.saveState()
 		}
 	}
 	if highBlockNr > s.lowBlockNr+s.config.L1BlocksPerRead {
@@ -276,8 +471,15 @@ func (s *l1SyncService) readMore(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	s.lowBlockNr = finalizedHighBlockNr + 1
-	return nil
+	if !s.catchingUp && len(s.checkpoints) > 0 && s.lastBatchCount() < currentBatchCount {
+		// We scanned up to the head but some batches are missing, so they
+		// must have been reorged into blocks we had already passed.
+		return s.rewind(callOpts, currentBatchCount)
+	}
+	if finalizedHighBlockNr+1 > s.lowBlockNr {
+		s.lowBlockNr = finalizedHighBlockNr + 1
+	}
+	return s.saveState()
 }
 
 func (s *l1SyncService) mainThread(ctx context.Context) {
@@ -319,6 +521,7 @@ type SyncingFallbackStorageService struct {
 	FallbackStorageService
 
 	syncService *l1SyncService
+	reconciler  *mirrorReconciler
 }
 
 func NewSyncingFallbackStorageService(ctx context.Context,
@@ -331,6 +534,14 @@ func NewSyncingFallbackStorageService(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	var reconciler *mirrorReconciler
+	if len(syncConf.ReconcileUrls) > 0 {
+		reconciler, err = newMirrorReconciler(primary, syncConf.ReconcileUrls, syncConf.ReconcileInterval, uint64(syncConf.RetentionPeriod.Seconds()))
+		if err != nil {
+			return nil, err
+		}
+		reconciler.Start(ctx)
+	}
 	syncService.Start(ctx)
 	return &SyncingFallbackStorageService{
 		FallbackStorageService{
@@ -343,11 +554,15 @@ func NewSyncingFallbackStorageService(ctx context.Context,
 			sync.RWMutex{},
 		},
 		syncService,
+		reconciler,
 	}, nil
 }
 
 func (s *SyncingFallbackStorageService) Close(ctx context.Context) error {
 	s.syncService.StopOnly()
+	if s.reconciler != nil {
+		s.reconciler.StopOnly()
+	}
 	s.FallbackStorageService.Close(ctx)
 	return nil
 }
@@ -0,0 +1,244 @@
+// Copyright 2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/solgen/go/mocksgen"
+	"github.com/offchainlabs/nitro/util/headerreader"
+)
+
+func TestDAS_L1SyncStatePersistence(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "sync-state.json")
+	state, err := readL1SyncState(stateFile)
+	Require(t, err)
+	if state != nil {
+		Fail(t, "expected no state before it was written")
+	}
+
+	written := &l1SyncState{
+		InboxAddr:  common.HexToAddress("0x1234"),
+		LowBlockNr: 1000,
+		Checkpoints: []l1SyncCheckpoint{
+			{BlockNr: 990, BatchCount: 5, BatchAcc: crypto.Keccak256Hash([]byte{5})},
+			{BlockNr: 995, BatchCount: 6, BatchAcc: crypto.Keccak256Hash([]byte{6})},
+		},
+	}
+	Require(t, writeL1SyncState(stateFile, written))
+	state, err = readL1SyncState(stateFile)
+	Require(t, err)
+	if !reflect.DeepEqual(state, written) {
+		Fail(t, "read state", state, "doesn't match written state", written)
+	}
+}
+
+func TestDAS_L1SyncReorgCheckpoint(t *testing.T) {
+	accFor := func(batchCount uint64, fork byte) common.Hash {
+		return crypto.Keccak256Hash([]byte{byte(batchCount), fork})
+	}
+	var checkpoints []l1SyncCheckpoint
+	for count := uint64(1); count <= 10; count++ {
+		checkpoints = append(checkpoints, l1SyncCheckpoint{BlockNr: 100 + count, BatchCount: count, BatchAcc: accFor(count, 0)})
+	}
+
+	// The chain was reorged from batch 8 on, and now has only 9 batches.
+	reorged := func(batchCount uint64) (common.Hash, error) {
+		if batchCount >= 8 {
+			return accFor(batchCount, 1), nil
+		}
+		return accFor(batchCount, 0), nil
+	}
+	i, err := latestConsistentCheckpoint(checkpoints, 9, reorged)
+	Require(t, err)
+	if i < 0 || checkpoints[i].BatchCount != 7 {
+		Fail(t, "expected to rewind to batch 7, got index", i)
+	}
+
+	// Nothing synced survived the reorg.
+	allReorged := func(batchCount uint64) (common.Hash, error) {
+		return accFor(batchCount, 1), nil
+	}
+	i, err = latestConsistentCheckpoint(checkpoints, 10, allReorged)
+	Require(t, err)
+	if i != -1 {
+		Fail(t, "expected no consistent checkpoint, got index", i)
+	}
+
+	// Errors reading the accumulator are passed on.
+	callErr := errors.New("L1 unavailable")
+	_, err = latestConsistentCheckpoint(checkpoints, 10, func(uint64) (common.Hash, error) {
+		return common.Hash{}, callErr
+	})
+	if !errors.Is(err, callErr) {
+		Fail(t, "expected L1 error, got", err)
+	}
+}
+
+const simulatedL1ChainId = 1337
+
+// simulatedL1 adds the methods of a real L1 client the header reader needs to
+// a simulated backend.
+type simulatedL1 struct {
+	*backends.SimulatedBackend
+}
+
+func (c *simulatedL1) BlockNumber(ctx context.Context) (uint64, error) {
+	header, err := c.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+func (c *simulatedL1) ChainID(context.Context) (*big.Int, error) {
+	return big.NewInt(simulatedL1ChainId), nil
+}
+
+func (c *simulatedL1) TransactionSender(_ context.Context, tx *types.Transaction, _ common.Hash, _ uint) (common.Address, error) {
+	return types.Sender(types.LatestSignerForChainID(big.NewInt(simulatedL1ChainId)), tx)
+}
+
+func TestDAS_L1SyncResumesAndRewindsOnReorg(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	key, err := crypto.GenerateKey()
+	Require(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(simulatedL1ChainId))
+	Require(t, err)
+	balance := new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)
+	client := &simulatedL1{backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: balance}}, 1_000_000_000)}
+	defer client.Close()
+	client.Commit()
+
+	bridge, _, _, err := mocksgen.DeployBridgeStub(auth, client)
+	Require(t, err)
+	client.Commit()
+	timeBounds := mocksgen.ISequencerInboxMaxTimeVariation{
+		DelayBlocks:   big.NewInt(10000),
+		FutureBlocks:  big.NewInt(10000),
+		DelaySeconds:  big.NewInt(10000),
+		FutureSeconds: big.NewInt(10000),
+	}
+	inboxAddr, _, inbox, err := mocksgen.DeploySequencerInboxStub(auth, client, bridge, auth.From, timeBounds)
+	Require(t, err)
+	client.Commit()
+
+	// postBatch posts a batch in a block of its own, returning that block.
+	postBatch := func(seqNum int64, data []byte) *types.Header {
+		t.Helper()
+		tx, err := inbox.AddSequencerL2BatchFromOrigin(auth, big.NewInt(seqNum), data, big.NewInt(0), common.Address{})
+		Require(t, err)
+		client.Commit()
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		Require(t, err)
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			Fail(t, "posting batch", seqNum, "failed")
+		}
+		header, err := client.HeaderByHash(ctx, receipt.BlockHash)
+		Require(t, err)
+		return header
+	}
+	// The batches aren't DAS batches, so only the sync position is tracked.
+	var batchBlocks []*types.Header
+	for i := int64(0); i < 3; i++ {
+		batchBlocks = append(batchBlocks, postBatch(i, []byte{0, byte(i)}))
+	}
+	for i := 0; i < 3; i++ {
+		client.Commit()
+	}
+
+	config := DefaultSyncToStorageConfig
+	config.Eager = true
+	config.StateFile = filepath.Join(t.TempDir(), "sync-state.json")
+	config.FinalizeDistance = 2
+	config.UseMergeFinality = false
+	l1Reader := headerreader.New(client, headerreader.TestConfig)
+	newSyncService := func() *l1SyncService {
+		t.Helper()
+		service, err := newl1SyncService(&config, NewMemoryBackedStorageService(ctx), NewMemoryBackedStorageService(ctx), l1Reader, inboxAddr)
+		Require(t, err)
+		return service
+	}
+	syncUp := func(service *l1SyncService) {
+		t.Helper()
+		for i := 0; ; i++ {
+			Require(t, service.readMore(ctx))
+			if !service.catchingUp {
+				return
+			}
+			if i > 10 {
+				Fail(t, "sync didn't catch up")
+			}
+		}
+	}
+	accOf := func(batchCount uint64) common.Hash {
+		t.Helper()
+		acc, err := inbox.InboxAccs(&bind.CallOpts{Context: ctx}, new(big.Int).SetUint64(batchCount-1))
+		Require(t, err)
+		return acc
+	}
+
+	first := newSyncService()
+	syncUp(first)
+	if first.lastBatchCount() != 3 {
+		Fail(t, "synced", first.lastBatchCount(), "batches, expected 3")
+	}
+	if first.lowBlockNr <= batchBlocks[2].Number.Uint64() {
+		Fail(t, "sync position", first.lowBlockNr, "didn't advance past the last batch")
+	}
+	oldAcc := accOf(3)
+
+	// A restarted service resumes from the persisted position.
+	resumed := newSyncService()
+	if resumed.lowBlockNr != first.lowBlockNr || !reflect.DeepEqual(resumed.checkpoints, first.checkpoints) {
+		Fail(t, "resumed at", resumed.lowBlockNr, resumed.checkpoints, "instead of", first.lowBlockNr, first.checkpoints)
+	}
+
+	// Reorg the last batch out for a different one, on a longer chain.
+	Require(t, client.Fork(ctx, batchBlocks[1].Hash()))
+	reorgedBlock := postBatch(2, []byte{0, 0xff})
+	for i := 0; i < 5; i++ {
+		client.Commit()
+	}
+	newAcc := accOf(3)
+	if newAcc == oldAcc {
+		Fail(t, "reorg didn't change the last batch")
+	}
+
+	// The service notices its last batch is gone, and rewinds to rescan from
+	// the last batch that's still on chain.
+	Require(t, resumed.readMore(ctx))
+	if resumed.lastBatchCount() != 2 || resumed.lowBlockNr != batchBlocks[1].Number.Uint64() {
+		Fail(t, "rewound to", resumed.lowBlockNr, "with", resumed.lastBatchCount(), "batches, expected", batchBlocks[1].Number, "with 2")
+	}
+	if !resumed.catchingUp {
+		Fail(t, "not catching up after rewinding")
+	}
+
+	// Rescanning re-fetches the batch that replaced the reorged one.
+	syncUp(resumed)
+	last := resumed.checkpoints[len(resumed.checkpoints)-1]
+	if last.BatchCount != 3 || last.BatchAcc != newAcc || last.BlockNr != reorgedBlock.Number.Uint64() {
+		Fail(t, "last synced batch", last, "isn't the one that replaced the reorged batch")
+	}
+	state, err := readL1SyncState(config.StateFile)
+	Require(t, err)
+	if !reflect.DeepEqual(state.Checkpoints, resumed.checkpoints) || state.LowBlockNr != resumed.lowBlockNr {
+		Fail(t, "persisted state", state, "doesn't match the rewound sync")
+	}
+}
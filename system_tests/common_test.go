@@ -15,6 +15,7 @@ import (
 	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/blsSignatures"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
+	cmdutil "github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/das"
 	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/headerreader"
@@ -326,10 +327,9 @@ func createTestNodeOnL1WithConfig(
 	var l2blockchain *core.BlockChain
 	l2info, l2stack, l2chainDb, l2arbDb, l2blockchain = createL2BlockChain(t, nil, "", chainConfig)
 	addresses := DeployOnTestL1(t, ctx, l1info, l1client, chainConfig.ChainID)
-	var sequencerTxOptsPtr *bind.TransactOpts
+	var sequencerSigner cmdutil.Signer
 	if isSequencer {
-		sequencerTxOpts := l1info.GetDefaultTransactOpts("Sequencer", ctx)
-		sequencerTxOptsPtr = &sequencerTxOpts
+		sequencerSigner = l1info.GetDefaultSigner("Sequencer")
 	}
 
 	if !isSequencer {
@@ -341,7 +341,7 @@ func createTestNodeOnL1WithConfig(
 	var err error
 	currentNode, err = arbnode.CreateNode(
 		ctx, l2stack, l2chainDb, l2arbDb, nodeConfig, l2blockchain, l1client,
-		addresses, sequencerTxOptsPtr, nil, fatalErrChan,
+		addresses, sequencerSigner, nil, fatalErrChan,
 	)
 	Require(t, err)
 
@@ -573,7 +573,7 @@ func setupConfigWithDAS(
 		Require(t, err)
 		_, err = das.StartDASRPCServerOnListener(ctx, rpcLis, genericconf.HTTPServerTimeoutConfigDefault, dasServerStack)
 		Require(t, err)
-		_, err = das.NewRestfulDasServerOnListener(restLis, genericconf.HTTPServerTimeoutConfigDefault, dasServerStack)
+		_, err = das.NewRestfulDasServerOnListener(restLis, genericconf.HTTPServerTimeoutConfigDefault, das.DefaultRestfulEndpointsConfig, nil, dasServerStack)
 		Require(t, err)
 
 		beConfigA := das.BackendConfig{
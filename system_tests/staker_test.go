@@ -19,6 +19,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/offchainlabs/nitro/arbnode"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/solgen/go/rollupgen"
 	"github.com/offchainlabs/nitro/util/colors"
@@ -86,22 +87,22 @@ func stakerTestImpl(t *testing.T, faultyStaker bool, honestStakerInactive bool)
 	balance.Mul(balance, big.NewInt(100))
 	l1info.GenerateAccount("ValidatorA")
 	TransferBalance(t, "Faucet", "ValidatorA", balance, l1info, l1client, ctx)
-	l1authA := l1info.GetDefaultTransactOpts("ValidatorA", ctx)
+	l1signerA := l1info.GetDefaultSigner("ValidatorA")
 
 	l1info.GenerateAccount("ValidatorB")
 	TransferBalance(t, "Faucet", "ValidatorB", balance, l1info, l1client, ctx)
-	l1authB := l1info.GetDefaultTransactOpts("ValidatorB", ctx)
+	l1signerB := l1info.GetDefaultSigner("ValidatorB")
 
-	valWalletAddrAPtr, err := validator.GetValidatorWallet(ctx, l2nodeA.DeployInfo.ValidatorWalletCreator, 0, &l1authA, l2nodeA.L1Reader, true)
+	valWalletAddrAPtr, err := validator.GetValidatorWallet(ctx, l2nodeA.DeployInfo.ValidatorWalletCreator, 0, l1signerA, l2nodeA.L1Reader, true)
 	Require(t, err)
 	valWalletAddrA := *valWalletAddrAPtr
-	valWalletAddrCheck, err := validator.GetValidatorWallet(ctx, l2nodeA.DeployInfo.ValidatorWalletCreator, 0, &l1authA, l2nodeA.L1Reader, true)
+	valWalletAddrCheck, err := validator.GetValidatorWallet(ctx, l2nodeA.DeployInfo.ValidatorWalletCreator, 0, l1signerA, l2nodeA.L1Reader, true)
 	Require(t, err)
 	if valWalletAddrA == *valWalletAddrCheck {
 		Require(t, err, "didn't cache validator wallet address", valWalletAddrA.String(), "vs", valWalletAddrCheck.String())
 	}
 
-	valWalletAddrBPtr, err := validator.GetValidatorWallet(ctx, l2nodeA.DeployInfo.ValidatorWalletCreator, 0, &l1authB, l2nodeB.L1Reader, true)
+	valWalletAddrBPtr, err := validator.GetValidatorWallet(ctx, l2nodeA.DeployInfo.ValidatorWalletCreator, 0, l1signerB, l2nodeB.L1Reader, true)
 	Require(t, err)
 	valWalletAddrB := *valWalletAddrBPtr
 
@@ -121,7 +122,7 @@ func stakerTestImpl(t *testing.T, faultyStaker bool, honestStakerInactive bool)
 		TargetMachineCount: 4,
 	}
 
-	valWalletA, err := validator.NewValidatorWallet(nil, l2nodeA.DeployInfo.ValidatorWalletCreator, l2nodeA.DeployInfo.Rollup, l2nodeA.L1Reader, &l1authA, 0, func(common.Address) {})
+	valWalletA, err := validator.NewValidatorWallet(nil, l2nodeA.DeployInfo.ValidatorWalletCreator, l2nodeA.DeployInfo.Rollup, l2nodeA.L1Reader, l1signerA, 0, func(common.Address) {})
 	Require(t, err)
 	if honestStakerInactive {
 		valConfig.Strategy = "Defensive"
@@ -147,7 +148,7 @@ func stakerTestImpl(t *testing.T, faultyStaker bool, honestStakerInactive bool)
 	err = stakerA.Initialize(ctx)
 	Require(t, err)
 
-	valWalletB, err := validator.NewValidatorWallet(nil, l2nodeB.DeployInfo.ValidatorWalletCreator, l2nodeB.DeployInfo.Rollup, l2nodeB.L1Reader, &l1authB, 0, func(common.Address) {})
+	valWalletB, err := validator.NewValidatorWallet(nil, l2nodeB.DeployInfo.ValidatorWalletCreator, l2nodeB.DeployInfo.Rollup, l2nodeB.L1Reader, l1signerB, 0, func(common.Address) {})
 	Require(t, err)
 	valConfig.Strategy = "MakeNodes"
 	stakerB, err := validator.NewStaker(
@@ -325,9 +326,149 @@ func stakerTestImpl(t *testing.T, faultyStaker bool, honestStakerInactive bool)
 		if !isStaked {
 			Fail(t, "staker B isn't staked")
 		}
+		checkNodesAgree(t, ctx, l2nodeA)
+	}
+}
+
+// checkNodesAgree checks the node's assertion checker agrees with every node
+// the stakers created that it has caught up to.
+func checkNodesAgree(t *testing.T, ctx context.Context, node *arbnode.Node) {
+	rollupWatcher, err := validator.NewRollupWatcher(node.DeployInfo.Rollup, node.L1Reader.Client(), bind.CallOpts{})
+	Require(t, err)
+	checker, err := validator.NewAssertionChecker(node.ArbInterface.BlockChain(), node.InboxTracker, node.TxStreamer, node.BlockValidator, rollupWatcher)
+	Require(t, err)
+	if _, err := checker.CheckNode(ctx, 0); err == nil {
+		Fail(t, "checked the genesis node")
+	}
+	latestCreatedNode, err := rollupWatcher.LatestNodeCreated(&bind.CallOpts{})
+	Require(t, err)
+	checked := 0
+	for nodeNum := uint64(1); nodeNum <= latestCreatedNode; nodeNum++ {
+		check, err := checker.CheckNode(ctx, nodeNum)
+		if err != nil && strings.Contains(err.Error(), "waiting to catch up") {
+			// The stakers may create nodes past what this node has checked.
+			continue
+		}
+		Require(t, err, "failed to check node", nodeNum)
+		if !check.Agree {
+			Fail(t, "disagreed with node", nodeNum, check.Reasons)
+		}
+		if check.Validated != (node.BlockValidator != nil) {
+			Fail(t, "checked node", nodeNum, "as validated", check.Validated, "with block validator", node.BlockValidator != nil)
+		}
+		checked++
+	}
+	if checked == 0 {
+		Fail(t, "checked none of the", latestCreatedNode, "nodes")
 	}
 }
 
 func TestStakersCooperative(t *testing.T) {
 	stakerTestImpl(t, false, false)
 }
+
+func TestStakerRotateWallet(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	l2info, l2node, l2client, l2stack, l1info, _, l1client, l1stack := createTestNodeOnL1(t, ctx, true)
+	defer requireClose(t, l1stack)
+	defer requireClose(t, l2stack)
+
+	deployAuth := l1info.GetDefaultTransactOpts("RollupOwner", ctx)
+	balance := big.NewInt(params.Ether)
+	balance.Mul(balance, big.NewInt(100))
+	l1info.GenerateAccount("ValidatorOld")
+	TransferBalance(t, "Faucet", "ValidatorOld", balance, l1info, l1client, ctx)
+	l1info.GenerateAccount("ValidatorNew")
+	TransferBalance(t, "Faucet", "ValidatorNew", balance, l1info, l1client, ctx)
+	oldSigner := l1info.GetDefaultSigner("ValidatorOld")
+	newSigner := l1info.GetDefaultSigner("ValidatorNew")
+
+	walletAddr, err := validator.GetValidatorWallet(ctx, l2node.DeployInfo.ValidatorWalletCreator, 0, oldSigner, l2node.L1Reader, true)
+	Require(t, err)
+	rollup, err := rollupgen.NewRollupAdminLogic(l2node.DeployInfo.Rollup, l1client)
+	Require(t, err)
+	tx, err := rollup.SetValidator(&deployAuth, []common.Address{*walletAddr}, []bool{true})
+	Require(t, err)
+	_, err = EnsureTxSucceeded(ctx, l1client, tx)
+	Require(t, err)
+	tx, err = rollup.SetMinimumAssertionPeriod(&deployAuth, big.NewInt(1))
+	Require(t, err)
+	_, err = EnsureTxSucceeded(ctx, l1client, tx)
+	Require(t, err)
+
+	wallet, err := validator.NewValidatorWallet(nil, l2node.DeployInfo.ValidatorWalletCreator, l2node.DeployInfo.Rollup, l2node.L1Reader, oldSigner, 0, func(common.Address) {})
+	Require(t, err)
+	valConfig := validator.L1ValidatorConfig{
+		TargetMachineCount: 4,
+		Strategy:           "MakeNodes",
+	}
+	staker, err := validator.NewStaker(
+		l2node.L1Reader,
+		wallet,
+		bind.CallOpts{},
+		valConfig,
+		l2node.ArbInterface.BlockChain(),
+		nil,
+		l2node.InboxReader,
+		l2node.InboxTracker,
+		l2node.TxStreamer,
+		l2node.BlockValidator,
+		validator.NewNitroMachineLoader(validator.DefaultNitroMachineConfig, nil),
+		l2node.DeployInfo.ValidatorUtils,
+	)
+	Require(t, err)
+	Require(t, staker.Initialize(ctx))
+	staker.RotateWallet(newSigner)
+
+	// Give the staker a block to make a node for.
+	l2info.GenerateAccount("User")
+	tx = l2info.PrepareTx("Faucet", "User", l2info.TransferGas, common.Big1, nil)
+	Require(t, l2client.SendTransaction(ctx, tx))
+	_, err = EnsureTxSucceeded(ctx, l2client, tx)
+	Require(t, err)
+
+	var senders []common.Address
+	isStaked := false
+	for i := 0; i < 200 && !isStaked; i++ {
+		tx, err := staker.Act(ctx)
+		if err != nil && strings.Contains(err.Error(), "waiting") {
+			time.Sleep(20 * time.Millisecond)
+			continue
+		}
+		Require(t, err, "staker failed to act")
+		if tx != nil {
+			receipt, err := EnsureTxSucceeded(ctx, l1client, tx)
+			Require(t, err)
+			sender, err := l1client.TransactionSender(ctx, tx, receipt.BlockHash, receipt.TransactionIndex)
+			Require(t, err)
+			senders = append(senders, sender)
+		}
+		isStaked, err = rollup.IsStaked(&bind.CallOpts{}, *walletAddr)
+		Require(t, err)
+		for j := 0; j < 5; j++ {
+			TransferBalance(t, "Faucet", "Faucet", common.Big0, l1info, l1client, ctx)
+		}
+	}
+	if !isStaked {
+		Fail(t, "staker didn't stake after rotating its wallet")
+	}
+
+	// The old key hands the wallet over, and the new one revokes it and acts
+	// from then on.
+	if len(senders) < 4 {
+		Fail(t, "staker sent transactions from", senders, "expected the rotation and then staking")
+	}
+	for i, sender := range senders {
+		expected := newSigner.Address()
+		if i < 2 {
+			expected = oldSigner.Address()
+		}
+		if sender != expected {
+			Fail(t, "staker sent transaction", i, "from", sender, "expected", expected)
+		}
+	}
+	if wallet.From() != newSigner.Address() {
+		Fail(t, "wallet sends from", wallet.From(), "instead of the new signer")
+	}
+}
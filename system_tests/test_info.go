@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/offchainlabs/nitro/arbos/l2pricing"
+	cmdutil "github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/util"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -189,6 +190,11 @@ func (b *BlockchainTestInfo) GetDefaultTransactOpts(name string, ctx context.Con
 	}
 }
 
+func (b *BlockchainTestInfo) GetDefaultSigner(name string) cmdutil.Signer {
+	b.T.Helper()
+	return cmdutil.NewPrivateKeySigner(b.GetInfoWithPrivKey(name).PrivateKey)
+}
+
 func (b *BlockchainTestInfo) GetDefaultCallOpts(name string, ctx context.Context) *bind.CallOpts {
 	b.T.Helper()
 	auth := b.GetDefaultTransactOpts(name, ctx)
@@ -0,0 +1,281 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+// AssertionChecker checks rollup assertions against the local chain, without
+// staking or acting on L1, the way the staker decides whether a node is correct.
+type AssertionChecker struct {
+	l2Blockchain       *core.BlockChain
+	inboxTracker       InboxTrackerInterface
+	txStreamer         TransactionStreamerInterface
+	blockValidator     *BlockValidator
+	rollup             *RollupWatcher
+	genesisBlockNumber uint64
+}
+
+type AssertionCheck struct {
+	Agree bool `json:"agree"`
+	// Why the assertion is wrong, if it is.
+	Reasons []string `json:"reasons,omitempty"`
+	// Whether the block validator has validated the assertion's blocks, or
+	// they've only been executed locally.
+	Validated bool `json:"validated"`
+	// The first block the assertion diverges from the local chain at, if it can
+	// be told locally. If the assertion claims a block hash that isn't in the
+	// local chain, it may diverge anywhere after its start block, and only a
+	// challenge would find where.
+	FirstDivergingBlock *uint64 `json:"firstDivergingBlock,omitempty"`
+	ExpectedNumBlocks   uint64  `json:"expectedNumBlocks"`
+	// The after state the assertion should have.
+	ExpectedState *ExecutionState `json:"expectedState"`
+}
+
+func (c *AssertionCheck) disagree(reason string, args ...interface{}) {
+	c.Agree = false
+	c.Reasons = append(c.Reasons, fmt.Sprintf(reason, args...))
+}
+
+// blockValidator and rollup may be nil, but without the rollup, only
+// assertions and not existing nodes can be checked.
+func NewAssertionChecker(
+	l2Blockchain *core.BlockChain,
+	inboxTracker InboxTrackerInterface,
+	txStreamer TransactionStreamerInterface,
+	blockValidator *BlockValidator,
+	rollup *RollupWatcher,
+) (*AssertionChecker, error) {
+	genesisBlockNumber, err := txStreamer.GetGenesisBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	return &AssertionChecker{
+		l2Blockchain:       l2Blockchain,
+		inboxTracker:       inboxTracker,
+		txStreamer:         txStreamer,
+		blockValidator:     blockValidator,
+		rollup:             rollup,
+		genesisBlockNumber: genesisBlockNumber,
+	}, nil
+}
+
+// lastBlockChecked returns the last block the local chain can vouch for: the
+// last validated block with a block validator, or the last block of the known
+// batches without.
+func (c *AssertionChecker) lastBlockChecked(localBatchCount uint64) (uint64, bool, error) {
+	if c.blockValidator != nil {
+		lastBlockValidated, expectedHash, _ := c.blockValidator.LastBlockValidatedAndHash()
+		haveHash := c.l2Blockchain.GetCanonicalHash(lastBlockValidated)
+		if haveHash != expectedHash {
+			return 0, false, fmt.Errorf("block validator validated block %v as hash %v but blockchain has hash %v", lastBlockValidated, expectedHash, haveHash)
+		}
+		return lastBlockValidated, true, nil
+	}
+	if localBatchCount == 0 {
+		return 0, false, nil
+	}
+	lastBlock := c.l2Blockchain.CurrentBlock().NumberU64()
+	messageCount, err := c.inboxTracker.GetBatchMessageCount(localBatchCount - 1)
+	if err != nil {
+		return 0, false, err
+	}
+	// Must be non-negative as a batch must contain at least one message
+	lastBatchBlock := uint64(arbutil.MessageCountToBlockNumber(messageCount, c.genesisBlockNumber))
+	if lastBlock > lastBatchBlock {
+		lastBlock = lastBatchBlock
+	}
+	return lastBlock, false, nil
+}
+
+// CheckAssertion checks an assertion building on a node with inboxMaxCount.
+// It returns an error if the local chain hasn't caught up to the assertion.
+func (c *AssertionChecker) CheckAssertion(ctx context.Context, assertion *Assertion, inboxMaxCount uint64) (*AssertionCheck, error) {
+	if assertion.BeforeState == nil || assertion.AfterState == nil {
+		return nil, errors.New("assertion missing its before or after state")
+	}
+	c.txStreamer.PauseReorgs()
+	defer c.txStreamer.ResumeReorgs()
+
+	check := &AssertionCheck{Agree: true}
+	beforeGs := assertion.BeforeState.GlobalState
+	afterGs := assertion.AfterState.GlobalState
+
+	localBatchCount, err := c.inboxTracker.GetBatchCount()
+	if err != nil {
+		return nil, err
+	}
+	requiredBatches := assertion.AfterState.RequiredBatches()
+	if localBatchCount < requiredBatches {
+		return nil, fmt.Errorf("waiting to catch up to assertion batches: %v/%v", localBatchCount, requiredBatches)
+	}
+	lastBlockChecked, validated, err := c.lastBlockChecked(localBatchCount)
+	if err != nil {
+		return nil, err
+	}
+
+	// The genesis state starts before the first block.
+	var startBlock *types.Header
+	if beforeGs != (GoGlobalState{}) {
+		startBlockNum, inboxPositionInvalid, err := blockNumberFromGlobalState(c.inboxTracker, c.genesisBlockNumber, beforeGs)
+		if err != nil {
+			return nil, err
+		}
+		if inboxPositionInvalid {
+			check.disagree("before state inbox position %v/%v is past the end of its batch", beforeGs.Batch, beforeGs.PosInBatch)
+		}
+		if startBlockNum >= 0 {
+			startBlock = c.l2Blockchain.GetHeaderByNumber(uint64(startBlockNum))
+		}
+		if startBlock == nil {
+			return nil, fmt.Errorf("waiting to catch up to assertion start block %v", startBlockNum)
+		}
+		if startBlock.Hash() != beforeGs.BlockHash {
+			check.disagree("before state block hash %v but block %v has hash %v", beforeGs.BlockHash, startBlockNum, startBlock.Hash())
+			diverging := uint64(startBlockNum)
+			check.FirstDivergingBlock = &diverging
+		}
+	}
+	if assertion.AfterState.MachineStatus != MachineStatusFinished {
+		check.disagree("after state machine status %v isn't finished", assertion.AfterState.MachineStatus)
+	}
+	if requiredBatches > inboxMaxCount {
+		check.disagree("assertion reads %v batches, past the inbox max count %v", requiredBatches, inboxMaxCount)
+	}
+
+	lastBlockNum, inboxPositionInvalid, err := blockNumberFromGlobalState(c.inboxTracker, c.genesisBlockNumber, afterGs)
+	if err != nil {
+		return nil, err
+	}
+	if inboxPositionInvalid {
+		check.disagree("after state inbox position %v/%v is past the end of its batch", afterGs.Batch, afterGs.PosInBatch)
+	}
+	if lastBlockNum >= 0 && uint64(lastBlockNum) > lastBlockChecked {
+		return nil, fmt.Errorf("waiting to catch up to assertion blocks: %v/%v", lastBlockChecked, lastBlockNum)
+	}
+	check.Validated = validated
+
+	expected := &ExecutionState{
+		GlobalState: GoGlobalState{
+			Batch:      afterGs.Batch,
+			PosInBatch: afterGs.PosInBatch,
+		},
+		MachineStatus: MachineStatusFinished,
+	}
+	if lastBlockNum >= 0 {
+		lastBlock := c.l2Blockchain.GetHeaderByNumber(uint64(lastBlockNum))
+		if lastBlock == nil {
+			return nil, fmt.Errorf("block %v not in database", lastBlockNum)
+		}
+		lastBlockExtra, err := types.DeserializeHeaderExtraInformation(lastBlock)
+		if err != nil {
+			return nil, err
+		}
+		expected.GlobalState.BlockHash = lastBlock.Hash()
+		expected.GlobalState.SendRoot = lastBlockExtra.SendRoot
+	}
+	check.ExpectedState = expected
+	if startBlock == nil {
+		check.ExpectedNumBlocks = uint64(lastBlockNum + 1)
+	} else if lastBlockNum >= int64(startBlock.Number.Uint64()) {
+		check.ExpectedNumBlocks = uint64(lastBlockNum) - startBlock.Number.Uint64()
+	} else {
+		check.disagree("after state is before the before state")
+	}
+
+	if assertion.NumBlocks != check.ExpectedNumBlocks {
+		check.disagree("assertion has %v blocks but expected %v", assertion.NumBlocks, check.ExpectedNumBlocks)
+	}
+	if afterGs.BlockHash != expected.GlobalState.BlockHash {
+		check.disagree("after state block hash %v but expected %v", afterGs.BlockHash, expected.GlobalState.BlockHash)
+		if check.FirstDivergingBlock == nil && lastBlockNum >= 0 {
+			// If the claimed block is one of ours, it's at the wrong inbox position,
+			// and the chains diverge after whichever of the two blocks is first.
+			claimed := c.l2Blockchain.GetHeaderByHash(afterGs.BlockHash)
+			if claimed != nil && c.l2Blockchain.GetCanonicalHash(claimed.Number.Uint64()) == afterGs.BlockHash {
+				diverging := claimed.Number.Uint64()
+				if uint64(lastBlockNum) < diverging {
+					diverging = uint64(lastBlockNum)
+				}
+				diverging++
+				check.FirstDivergingBlock = &diverging
+			}
+		}
+	} else if afterGs.SendRoot != expected.GlobalState.SendRoot {
+		check.disagree("after state send root %v but expected %v", afterGs.SendRoot, expected.GlobalState.SendRoot)
+		if check.FirstDivergingBlock == nil && lastBlockNum >= 0 {
+			diverging := uint64(lastBlockNum)
+			check.FirstDivergingBlock = &diverging
+		}
+	}
+	return check, nil
+}
+
+// CheckNode checks the assertion of an existing rollup node.
+func (c *AssertionChecker) CheckNode(ctx context.Context, nodeNum uint64) (*AssertionCheck, error) {
+	if c.rollup == nil {
+		return nil, errors.New("checking nodes requires an L1 connection")
+	}
+	if nodeNum == 0 {
+		return nil, errors.New("the genesis node has no assertion")
+	}
+	node, err := c.rollup.LookupNode(ctx, nodeNum)
+	if err != nil {
+		return nil, err
+	}
+	nodeState, err := c.rollup.GetNode(c.rollup.getCallOpts(ctx), nodeNum)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// The assertion builds on the inbox its parent node saw.
+	prevInboxMaxCount := big.NewInt(1)
+	if nodeState.PrevNum != 0 {
+		prevNode, err := c.rollup.LookupNode(ctx, nodeState.PrevNum)
+		if err != nil {
+			return nil, err
+		}
+		prevInboxMaxCount = prevNode.InboxMaxCount
+	}
+	if !prevInboxMaxCount.IsUint64() {
+		return nil, fmt.Errorf("inbox max count %v isn't a uint64", prevInboxMaxCount)
+	}
+	check, err := c.CheckAssertion(ctx, node.Assertion, prevInboxMaxCount.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	requiredBatches := node.AfterState().RequiredBatches()
+	if requiredBatches > 0 {
+		haveAcc, err := c.inboxTracker.GetBatchAcc(requiredBatches - 1)
+		if err != nil {
+			return nil, err
+		}
+		if haveAcc != node.AfterInboxBatchAcc {
+			check.disagree("node has batch accumulator %v but expected %v at batch %v", node.AfterInboxBatchAcc, haveAcc, requiredBatches-1)
+		}
+	}
+	if c.blockValidator != nil {
+		moduleRootValid := false
+		validRoots := c.blockValidator.GetModuleRootsToValidate()
+		for _, root := range validRoots {
+			if root == node.WasmModuleRoot {
+				moduleRootValid = true
+				break
+			}
+		}
+		if !moduleRootValid {
+			check.disagree("node module root %v isn't one of the validated %v", node.WasmModuleRoot, validRoots)
+		}
+	}
+	return check, nil
+}
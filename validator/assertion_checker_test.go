@@ -0,0 +1,293 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+type testInboxTracker struct {
+	batchMessageCounts []arbutil.MessageIndex
+}
+
+func (t *testInboxTracker) SetBlockValidator(*BlockValidator) {}
+
+func (t *testInboxTracker) GetDelayedMessageBytes(uint64) ([]byte, error) {
+	return nil, errors.New("no delayed messages")
+}
+
+func (t *testInboxTracker) GetBatchMessageCount(seqNum uint64) (arbutil.MessageIndex, error) {
+	if seqNum >= uint64(len(t.batchMessageCounts)) {
+		return 0, errors.New("batch not found")
+	}
+	return t.batchMessageCounts[seqNum], nil
+}
+
+func (t *testInboxTracker) GetBatchAcc(seqNum uint64) (common.Hash, error) {
+	if seqNum >= uint64(len(t.batchMessageCounts)) {
+		return common.Hash{}, errors.New("batch not found")
+	}
+	return common.BigToHash(new(big.Int).SetUint64(seqNum + 1)), nil
+}
+
+func (t *testInboxTracker) GetBatchCount() (uint64, error) {
+	return uint64(len(t.batchMessageCounts)), nil
+}
+
+type testTxStreamer struct{}
+
+func (s *testTxStreamer) SetBlockValidator(*BlockValidator) {}
+
+func (s *testTxStreamer) GetMessage(arbutil.MessageIndex) (*arbstate.MessageWithMetadata, error) {
+	return nil, errors.New("no messages")
+}
+
+func (s *testTxStreamer) GetGenesisBlockNumber() (uint64, error) {
+	return 0, nil
+}
+
+func (s *testTxStreamer) PauseReorgs()  {}
+func (s *testTxStreamer) ResumeReorgs() {}
+
+func testSendRoot(block int) common.Hash {
+	return common.BigToHash(big.NewInt(int64(block) + 1000))
+}
+
+// newTestBlockChain returns a chain of the first inserted of blocks blocks
+// after genesis, whose send roots are testSendRoot, along with all the blocks.
+func newTestBlockChain(t *testing.T, blocks int, inserted int) (*core.BlockChain, []*types.Block) {
+	t.Helper()
+	db := rawdb.NewMemoryDatabase()
+	genesis := &core.Genesis{
+		Config:  params.TestChainConfig,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	genesisBlock := genesis.MustCommit(db)
+	chainBlocks, _ := core.GenerateChain(params.TestChainConfig, genesisBlock, ethash.NewFaker(), db, blocks, func(i int, gen *core.BlockGen) {
+		sendRoot := testSendRoot(i + 1)
+		gen.SetExtra(sendRoot[:])
+	})
+	bc, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil)
+	Require(t, err)
+	t.Cleanup(bc.Stop)
+	_, err = bc.InsertChain(chainBlocks[:inserted])
+	Require(t, err)
+	return bc, chainBlocks
+}
+
+// newTestAssertionChecker returns a checker over a chain of blocks blocks after
+// genesis, one per message, whose messages are posted in batches with
+// batchMessageCounts.
+func newTestAssertionChecker(t *testing.T, blocks int, batchMessageCounts []arbutil.MessageIndex) (*AssertionChecker, *core.BlockChain) {
+	t.Helper()
+	bc, _ := newTestBlockChain(t, blocks, blocks)
+	checker, err := NewAssertionChecker(bc, &testInboxTracker{batchMessageCounts}, &testTxStreamer{}, nil, nil)
+	Require(t, err)
+	return checker, bc
+}
+
+func requireWaiting(t *testing.T, check *AssertionCheck, err error) {
+	t.Helper()
+	if err == nil {
+		Fail(t, "expected to wait to catch up, got check", check)
+	}
+	if !strings.Contains(err.Error(), "waiting to catch up") {
+		Fail(t, "expected to wait to catch up, got", err)
+	}
+}
+
+func requireDisagree(t *testing.T, check *AssertionCheck, reason string) {
+	t.Helper()
+	if check.Agree {
+		Fail(t, "agreed with an assertion that", reason)
+	}
+	for _, have := range check.Reasons {
+		if strings.Contains(have, reason) {
+			return
+		}
+	}
+	Fail(t, "expected reason", reason, "got", check.Reasons)
+}
+
+func TestCheckAssertion(t *testing.T) {
+	ctx := context.Background()
+	// Batch 0 is the init message of the genesis block, and blocks 1-5 and 6-10
+	// are in batches 1 and 2.
+	checker, bc := newTestAssertionChecker(t, 10, []arbutil.MessageIndex{1, 6, 11})
+	blockState := func(block uint64, batch uint64, posInBatch uint64) *ExecutionState {
+		return &ExecutionState{
+			GlobalState: GoGlobalState{
+				BlockHash:  bc.GetCanonicalHash(block),
+				SendRoot:   testSendRoot(int(block)),
+				Batch:      batch,
+				PosInBatch: posInBatch,
+			},
+			MachineStatus: MachineStatusFinished,
+		}
+	}
+	correct := func() *Assertion {
+		return &Assertion{
+			BeforeState: blockState(5, 2, 0),
+			AfterState:  blockState(10, 3, 0),
+			NumBlocks:   5,
+		}
+	}
+
+	check, err := checker.CheckAssertion(ctx, correct(), 3)
+	Require(t, err)
+	if !check.Agree || check.FirstDivergingBlock != nil || check.Validated {
+		Fail(t, "unexpected check of a correct assertion", check)
+	}
+	if check.ExpectedNumBlocks != 5 || check.ExpectedState.GlobalState != correct().AfterState.GlobalState {
+		Fail(t, "unexpected expectations of a correct assertion", check)
+	}
+
+	// An assertion from genesis counts the genesis block.
+	fromGenesis := correct()
+	fromGenesis.BeforeState = &ExecutionState{MachineStatus: MachineStatusFinished}
+	fromGenesis.NumBlocks = 11
+	check, err = checker.CheckAssertion(ctx, fromGenesis, 3)
+	Require(t, err)
+	if !check.Agree {
+		Fail(t, "disagreed with a correct assertion from genesis", check.Reasons)
+	}
+
+	// A block hash the local chain doesn't have may diverge anywhere.
+	wrongHash := correct()
+	wrongHash.AfterState.GlobalState.BlockHash = common.HexToHash("0x1234")
+	check, err = checker.CheckAssertion(ctx, wrongHash, 3)
+	Require(t, err)
+	requireDisagree(t, check, "after state block hash")
+	if check.FirstDivergingBlock != nil {
+		Fail(t, "found where an unknown block hash diverges", *check.FirstDivergingBlock)
+	}
+
+	// A local block at the wrong inbox position diverges after the first of the
+	// claimed and expected blocks.
+	wrongPosition := correct()
+	wrongPosition.AfterState.GlobalState.BlockHash = bc.GetCanonicalHash(8)
+	check, err = checker.CheckAssertion(ctx, wrongPosition, 3)
+	Require(t, err)
+	requireDisagree(t, check, "after state block hash")
+	if check.FirstDivergingBlock == nil || *check.FirstDivergingBlock != 9 {
+		Fail(t, "expected to diverge at block 9, got", check.FirstDivergingBlock)
+	}
+
+	wrongBefore := correct()
+	wrongBefore.BeforeState.GlobalState.BlockHash = common.HexToHash("0x1234")
+	check, err = checker.CheckAssertion(ctx, wrongBefore, 3)
+	Require(t, err)
+	requireDisagree(t, check, "before state block hash")
+	if check.FirstDivergingBlock == nil || *check.FirstDivergingBlock != 5 {
+		Fail(t, "expected to diverge at block 5, got", check.FirstDivergingBlock)
+	}
+
+	wrongSendRoot := correct()
+	wrongSendRoot.AfterState.GlobalState.SendRoot = common.HexToHash("0x1234")
+	check, err = checker.CheckAssertion(ctx, wrongSendRoot, 3)
+	Require(t, err)
+	requireDisagree(t, check, "after state send root")
+	if check.FirstDivergingBlock == nil || *check.FirstDivergingBlock != 10 {
+		Fail(t, "expected to diverge at block 10, got", check.FirstDivergingBlock)
+	}
+
+	wrongNumBlocks := correct()
+	wrongNumBlocks.NumBlocks = 4
+	check, err = checker.CheckAssertion(ctx, wrongNumBlocks, 3)
+	Require(t, err)
+	requireDisagree(t, check, "assertion has 4 blocks but expected 5")
+
+	running := correct()
+	running.AfterState.MachineStatus = MachineStatusRunning
+	check, err = checker.CheckAssertion(ctx, running, 3)
+	Require(t, err)
+	requireDisagree(t, check, "isn't finished")
+
+	wrongPosInBatch := correct()
+	wrongPosInBatch.AfterState = blockState(10, 2, 5)
+	check, err = checker.CheckAssertion(ctx, wrongPosInBatch, 3)
+	Require(t, err)
+	requireDisagree(t, check, "after state inbox position 2/5 is past the end of its batch")
+
+	// The assertion can't read batches its parent node didn't see.
+	check, err = checker.CheckAssertion(ctx, correct(), 2)
+	Require(t, err)
+	requireDisagree(t, check, "past the inbox max count 2")
+
+	// An assertion ending in the middle of a batch reads all of it.
+	midBatch := correct()
+	midBatch.AfterState = blockState(8, 2, 3)
+	midBatch.NumBlocks = 3
+	check, err = checker.CheckAssertion(ctx, midBatch, 3)
+	Require(t, err)
+	if !check.Agree {
+		Fail(t, "disagreed with a correct assertion ending mid batch", check.Reasons)
+	}
+	check, err = checker.CheckAssertion(ctx, midBatch, 2)
+	Require(t, err)
+	requireDisagree(t, check, "assertion reads 3 batches, past the inbox max count 2")
+
+	if _, err := checker.CheckAssertion(ctx, &Assertion{AfterState: blockState(10, 3, 0)}, 3); err == nil {
+		Fail(t, "checked an assertion without a before state")
+	}
+}
+
+func TestCheckAssertionBeyondLocalChain(t *testing.T) {
+	ctx := context.Background()
+	// The inbox has a batch the chain hasn't executed yet.
+	checker, bc := newTestAssertionChecker(t, 10, []arbutil.MessageIndex{1, 6, 11, 16})
+	assertion := &Assertion{
+		BeforeState: &ExecutionState{
+			GlobalState: GoGlobalState{
+				BlockHash: bc.GetCanonicalHash(10),
+				SendRoot:  testSendRoot(10),
+				Batch:     3,
+			},
+			MachineStatus: MachineStatusFinished,
+		},
+		AfterState: &ExecutionState{
+			GlobalState: GoGlobalState{
+				BlockHash: common.HexToHash("0x1234"),
+				Batch:     4,
+			},
+			MachineStatus: MachineStatusFinished,
+		},
+		NumBlocks: 5,
+	}
+	check, err := checker.CheckAssertion(ctx, assertion, 4)
+	requireWaiting(t, check, err)
+
+	// Nor can an assertion past the batches the inbox has read be checked.
+	assertion.AfterState.GlobalState.Batch = 5
+	check, err = checker.CheckAssertion(ctx, assertion, 5)
+	requireWaiting(t, check, err)
+
+	// Or one starting after the local chain.
+	assertion.BeforeState.GlobalState.PosInBatch = 2
+	assertion.AfterState.GlobalState.Batch = 4
+	check, err = checker.CheckAssertion(ctx, assertion, 5)
+	requireWaiting(t, check, err)
+}
+
+func TestCheckNodeWithoutRollup(t *testing.T) {
+	checker, _ := newTestAssertionChecker(t, 1, []arbutil.MessageIndex{1, 2})
+	if _, err := checker.CheckNode(context.Background(), 1); err == nil {
+		Fail(t, "checked a node without an L1 connection")
+	}
+}
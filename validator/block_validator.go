@@ -37,8 +37,9 @@ type BlockValidator struct {
 	reorgMutex        sync.Mutex
 	reorgsPending     int32 // atomic
 
-	lastBlockValidated      uint64      // both atomic and behind lastBlockValidatedMutex
-	lastBlockValidatedHash  common.Hash // behind lastBlockValidatedMutex
+	lastBlockValidated      uint64        // both atomic and behind lastBlockValidatedMutex
+	lastBlockValidatedHash  common.Hash   // behind lastBlockValidatedMutex
+	lastBlockValidatedRoots []common.Hash // behind lastBlockValidatedMutex; nil if not validated since startup
 	lastBlockValidatedMutex sync.Mutex
 	earliestBatchKept       uint64
 	nextBatchKept           uint64 // 1 + the last batch number kept
@@ -51,10 +52,20 @@ type BlockValidator struct {
 	config                   *BlockValidatorConfig
 	atomicValidationsRunning int32
 	concurrentRunsLimit      int32
+	remoteWorkers            *ValidationWorkerPool
+
+	resendMutex sync.Mutex
+	resends     []validationResend // validations to send again after no worker was available
 
 	sendValidationsChan chan struct{}
 	checkProgressChan   chan struct{}
 	progressChan        chan uint64
+
+	timingsMutex  sync.Mutex
+	recentTimings []ValidationTiming
+
+	moduleRootResultsMutex sync.Mutex
+	moduleRootResults      map[common.Hash]*ModuleRootResults
 }
 
 type BlockValidatorConfig struct {
@@ -66,7 +77,9 @@ type BlockValidatorConfig struct {
 	ConcurrentRunsLimit      int                           `koanf:"concurrent-runs-limit"`
 	CurrentModuleRoot        string                        `koanf:"current-module-root"`
 	PendingUpgradeModuleRoot string                        `koanf:"pending-upgrade-module-root"`
+	ModuleRootSchedule       string                        `koanf:"module-root-schedule"`
 	StorePreimages           bool                          `koanf:"store-preimages"`
+	RemoteValidation         RemoteValidationConfig        `koanf:"remote-validation"`
 	Dangerous                BlockValidatorDangerousConfig `koanf:"dangerous"`
 }
 
@@ -83,7 +96,9 @@ func BlockValidatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Int(prefix+".concurrent-runs-limit", DefaultBlockValidatorConfig.ConcurrentRunsLimit, "")
 	f.String(prefix+".current-module-root", DefaultBlockValidatorConfig.CurrentModuleRoot, "current wasm module root ('current' read from chain, 'latest' from machines/latest dir, or provide hash)")
 	f.String(prefix+".pending-upgrade-module-root", DefaultBlockValidatorConfig.PendingUpgradeModuleRoot, "pending upgrade wasm module root to additionally validate (hash, 'latest' or empty)")
+	f.String(prefix+".module-root-schedule", DefaultBlockValidatorConfig.ModuleRootSchedule, "JSON list of wasm module roots to validate blocks against in order of activation, replacing current-module-root and pending-upgrade-module-root, e.g. [{\"moduleRoot\":\"0x..\"},{\"moduleRoot\":\"0x..\",\"l1Block\":100,\"overlapL1Blocks\":50},{\"moduleRoot\":\"0x..\",\"node\":20}]")
 	f.Bool(prefix+".store-preimages", DefaultBlockValidatorConfig.StorePreimages, "store preimages of running machines (higher memory cost, better debugging, potentially better performance)")
+	RemoteValidationConfigAddOptions(prefix+".remote-validation", f)
 	BlockValidatorDangerousConfigAddOptions(prefix+".dangerous", f)
 }
 
@@ -100,7 +115,9 @@ var DefaultBlockValidatorConfig = BlockValidatorConfig{
 	ConcurrentRunsLimit:      0,
 	CurrentModuleRoot:        "current",
 	PendingUpgradeModuleRoot: "latest",
+	ModuleRootSchedule:       "",
 	StorePreimages:           false,
+	RemoteValidation:         DefaultRemoteValidationConfig,
 	Dangerous:                DefaultBlockValidatorDangerousConfig,
 }
 
@@ -113,7 +130,9 @@ var TestBlockValidatorConfig = BlockValidatorConfig{
 	ConcurrentRunsLimit:      0,
 	CurrentModuleRoot:        "latest",
 	PendingUpgradeModuleRoot: "latest",
+	ModuleRootSchedule:       "",
 	StorePreimages:           false,
+	RemoteValidation:         DefaultRemoteValidationConfig,
 	Dangerous:                DefaultBlockValidatorDangerousConfig,
 }
 
@@ -130,6 +149,15 @@ type validationStatus struct {
 	Cancel      func()           // non-atomic: only read/written to with reorg mutex
 	Entry       *validationEntry // non-atomic: only read if Status >= validationStatusPrepared
 	ModuleRoots []common.Hash    // non-atomic: present from the start
+	RecordTime  time.Duration    // non-atomic: only read if Status >= validationStatusPrepared
+	PreparedAt  time.Time        // non-atomic: only read if Status >= validationStatusPrepared
+}
+
+// validationResend is a prepared validation to send again, as no remote worker
+// was available to run it.
+type validationResend struct {
+	status *validationStatus
+	seqMsg []byte
 }
 
 func NewBlockValidator(
@@ -141,6 +169,17 @@ func NewBlockValidator(
 	config *BlockValidatorConfig,
 ) (*BlockValidator, error) {
 	concurrent := config.ConcurrentRunsLimit
+	var remoteWorkers *ValidationWorkerPool
+	if config.RemoteValidation.Enabled() {
+		var err error
+		remoteWorkers, err = NewValidationWorkerPool(&config.RemoteValidation)
+		if err != nil {
+			return nil, err
+		}
+		if concurrent == 0 {
+			concurrent = len(config.RemoteValidation.Workers) * config.RemoteValidation.RunsPerWorker
+		}
+	}
 	if concurrent == 0 {
 		concurrent = runtime.NumCPU()
 	}
@@ -150,7 +189,9 @@ func NewBlockValidator(
 		checkProgressChan:       make(chan struct{}, 1),
 		progressChan:            make(chan uint64, 1),
 		concurrentRunsLimit:     int32(concurrent),
+		remoteWorkers:           remoteWorkers,
 		config:                  config,
+		moduleRootResults:       make(map[common.Hash]*ModuleRootResults),
 	}
 	err := validator.readLastBlockValidatedDbInfo(reorgingToBlock)
 	if err != nil {
@@ -226,7 +267,10 @@ func (v *BlockValidator) readLastBlockValidatedDbInfo(reorgingToBlock *types.Blo
 }
 
 func (v *BlockValidator) prepareBlock(ctx context.Context, header *types.Header, prevHeader *types.Header, msg arbstate.MessageWithMetadata, validationStatus *validationStatus) {
-	preimages, readBatchInfo, hasDelayedMessage, delayedMsgToRead, err := BlockDataForValidation(ctx, v.blockchain, v.inboxReader, header, prevHeader, msg, v.config.StorePreimages)
+	// Remote workers have no chain database, so need every preimage recorded.
+	producePreimages := v.config.StorePreimages || v.remoteWorkers != nil
+	recordStart := time.Now()
+	preimages, readBatchInfo, hasDelayedMessage, delayedMsgToRead, err := BlockDataForValidation(ctx, v.blockchain, v.inboxReader, header, prevHeader, msg, producePreimages)
 	if err != nil {
 		log.Error("failed to set up validation", "err", err, "header", header, "prevHeader", prevHeader)
 		return
@@ -237,6 +281,8 @@ func (v *BlockValidator) prepareBlock(ctx context.Context, header *types.Header,
 		return
 	}
 	validationStatus.Entry = validationEntry
+	validationStatus.RecordTime = time.Since(recordStart)
+	validationStatus.PreparedAt = time.Now()
 	atomic.StoreUint32(&validationStatus.Status, validationStatusPrepared)
 	select {
 	case v.sendValidationsChan <- struct{}{}:
@@ -261,10 +307,15 @@ func (v *BlockValidator) NewBlock(block *types.Block, prevHeader *types.Header,
 		}
 		return
 	}
+	moduleRoots, err := v.ModuleRootsForBlock(block.Header())
+	if err != nil {
+		log.Error("failed to get module roots for block, validating against all of them", "blockNr", blockNum, "err", err)
+		moduleRoots = v.GetModuleRootsToValidate()
+	}
 	status := &validationStatus{
 		Status:      validationStatusUnprepared,
 		Entry:       nil,
-		ModuleRoots: v.GetModuleRootsToValidate(),
+		ModuleRoots: moduleRoots,
 	}
 	// It's fine to separately load and then store as we have the blockMutex acquired
 	_, present := v.validationEntries.Load(blockNum)
@@ -280,10 +331,15 @@ func (v *BlockValidator) NewBlock(block *types.Block, prevHeader *types.Header,
 
 var launchTime = time.Now().Format("2006_01_02__15_04")
 
+func (v *BlockValidator) outputDirPath(blockNumber uint64) string {
+	machConf := v.MachineLoader.GetConfig()
+	return filepath.Join(machConf.RootPath, v.config.OutputPath, launchTime, fmt.Sprintf("block_%d", blockNumber))
+}
+
 //nolint:gosec
 func (v *BlockValidator) writeToFile(validationEntry *validationEntry, moduleRoot common.Hash, start, end GlobalStatePosition, preimages map[common.Hash][]byte, sequencerMsg, delayedMsg []byte) error {
 	machConf := v.MachineLoader.GetConfig()
-	outDirPath := filepath.Join(machConf.RootPath, v.config.OutputPath, launchTime, fmt.Sprintf("block_%d", validationEntry.BlockNumber))
+	outDirPath := v.outputDirPath(validationEntry.BlockNumber)
 	err := os.MkdirAll(outDirPath, 0755)
 	if err != nil {
 		return err
@@ -385,6 +441,16 @@ func (v *BlockValidator) SetCurrentWasmModuleRoot(hash common.Hash) error {
 	if (hash == common.Hash{}) {
 		return errors.New("trying to set zero as wsmModuleRoot")
 	}
+	if v.moduleRootSchedule != nil {
+		// The schedule decides which root each block is validated against.
+		for _, root := range v.moduleRootSchedule.ModuleRoots() {
+			if root == hash {
+				v.currentWasmModuleRoot = hash
+				return nil
+			}
+		}
+		return fmt.Errorf("unexpected wasmModuleRoot! cannot validate! found %v, not in module root schedule", hash)
+	}
 	if hash == v.currentWasmModuleRoot {
 		return nil
 	}
@@ -412,6 +478,7 @@ func (v *BlockValidator) validate(ctx context.Context, validationStatus *validat
 		return
 	}
 	entry := validationStatus.Entry
+	executeStart := time.Now()
 	defer func() {
 		atomic.AddInt32(&v.atomicValidationsRunning, -1)
 		select {
@@ -419,22 +486,21 @@ func (v *BlockValidator) validate(ctx context.Context, validationStatus *validat
 		default:
 		}
 	}()
-	entry.BatchInfo = append(entry.BatchInfo, BatchInfo{
-		Number: entry.StartPosition.BatchNumber,
-		Data:   seqMsg,
-	})
 	log.Info("starting validation for block", "blockNr", entry.BlockNumber)
 	for _, moduleRoot := range validationStatus.ModuleRoots {
 
 		type replay = func(context.Context, *validationEntry, common.Hash) (GoGlobalState, []byte, error)
 		var delayedMsg []byte
+		unavailable := false
 
 		validate := func(replay replay, jit bool) bool {
 			gsEnd, delayed, err := replay(ctx, entry, moduleRoot)
 			delayedMsg = delayed
 
 			if err != nil {
-				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				if errors.Is(err, ErrValidationWorkersUnavailable) {
+					unavailable = true
+				} else if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 					log.Info(
 						"Validation of block canceled", "blockNr", entry.BlockNumber,
 						"blockHash", entry.BlockHash, "jit", jit, "err", err,
@@ -461,14 +527,30 @@ func (v *BlockValidator) validate(ctx context.Context, validationStatus *validat
 			return resultValid
 		}
 
+		executeBlock, jitBlock := v.executeBlock, v.jitBlock
+		if v.remoteWorkers != nil {
+			executeBlock = func(ctx context.Context, entry *validationEntry, moduleRoot common.Hash) (GoGlobalState, []byte, error) {
+				return v.remoteBlock(ctx, entry, moduleRoot, false)
+			}
+			jitBlock = func(ctx context.Context, entry *validationEntry, moduleRoot common.Hash) (GoGlobalState, []byte, error) {
+				return v.remoteBlock(ctx, entry, moduleRoot, true)
+			}
+		}
+
 		before := time.Now()
 		writeThisBlock := false // we write the block if either fail
 
 		if v.config.ArbitratorValidator {
-			writeThisBlock = writeThisBlock || !validate(v.executeBlock, false)
+			writeThisBlock = writeThisBlock || !validate(executeBlock, false)
+		}
+		if v.config.JitValidator && !unavailable {
+			writeThisBlock = writeThisBlock || !validate(jitBlock, true)
 		}
-		if v.config.JitValidator {
-			writeThisBlock = writeThisBlock || !validate(v.jitBlock, true)
+		if unavailable {
+			// The block wasn't validated, so it's left prepared to be sent again.
+			log.Warn("no validation worker available, will resend block", "blockNr", entry.BlockNumber, "moduleRoot", moduleRoot)
+			v.resendAfterBackoff(validationStatus, seqMsg)
+			return
 		}
 
 		if writeThisBlock {
@@ -479,6 +561,15 @@ func (v *BlockValidator) validate(ctx context.Context, validationStatus *validat
 			if err != nil {
 				log.Error("failed to write file", "err", err)
 			}
+			bundlePath, err := v.writeBundle(ctx, entry.BlockNumber, moduleRoot)
+			if err != nil {
+				log.Error("failed to write validation bundle", "blockNr", entry.BlockNumber, "err", err)
+			} else {
+				log.Warn("wrote validation bundle of failed block", "blockNr", entry.BlockNumber, "path", bundlePath)
+			}
+		}
+		if ctx.Err() == nil {
+			v.recordModuleRootResult(moduleRoot, entry.BlockNumber, !writeThisBlock)
 		}
 
 		log.Info(
@@ -487,13 +578,81 @@ func (v *BlockValidator) validate(ctx context.Context, validationStatus *validat
 		)
 	}
 
+	validatedAt := time.Now()
+	v.recordTiming(ValidationTiming{
+		BlockNumber: entry.BlockNumber,
+		Record:      validationStatus.RecordTime,
+		Prepare:     executeStart.Sub(validationStatus.PreparedAt),
+		Execute:     validatedAt.Sub(executeStart),
+		ValidatedAt: validatedAt,
+	})
 	atomic.StoreUint32(&validationStatus.Status, validationStatusValid) // after that - validation entry could be deleted from map
 	v.checkProgressChan <- struct{}{}
 }
 
+// resendAfterBackoff queues a validation no worker was available for to be
+// sent again, once the workers have had time to recover.
+func (v *BlockValidator) resendAfterBackoff(validationStatus *validationStatus, seqMsg []byte) {
+	v.LaunchThread(func(ctx context.Context) {
+		timer := time.NewTimer(v.config.RemoteValidation.FailureBackoff)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+		v.resendMutex.Lock()
+		v.resends = append(v.resends, validationResend{validationStatus, seqMsg})
+		v.resendMutex.Unlock()
+		select {
+		case v.sendValidationsChan <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// remoteBlock validates entry on one of the remote workers.
+func (v *BlockValidator) remoteBlock(
+	ctx context.Context, entry *validationEntry, moduleRoot common.Hash, jit bool,
+) (GoGlobalState, []byte, error) {
+	input, delayedMsg, err := v.validationInput(ctx, entry, moduleRoot, jit)
+	if err != nil {
+		return GoGlobalState{}, nil, err
+	}
+	gsEnd, err := v.remoteWorkers.Validate(ctx, input)
+	return gsEnd, delayedMsg, err
+}
+
+func (v *BlockValidator) launchValidation(validationStatus *validationStatus, seqMsg []byte) {
+	atomic.AddInt32(&v.atomicValidationsRunning, 1)
+	v.LaunchThread(func(ctx context.Context) {
+		validationCtx, cancel := context.WithCancel(ctx)
+		validationStatus.Cancel = cancel
+		v.validate(validationCtx, validationStatus, seqMsg)
+		cancel()
+	})
+}
+
+// sendResends sends the validations queued to be sent again, unless a reorg
+// has dropped them since.
+func (v *BlockValidator) sendResends() {
+	v.resendMutex.Lock()
+	defer v.resendMutex.Unlock()
+	for len(v.resends) > 0 && atomic.LoadInt32(&v.atomicValidationsRunning) < v.concurrentRunsLimit {
+		resend := v.resends[0]
+		v.resends = v.resends[1:]
+		entry, found := v.validationEntries.Load(resend.status.Entry.BlockNumber)
+		if !found || entry != resend.status {
+			continue
+		}
+		v.launchValidation(resend.status, resend.seqMsg)
+	}
+}
+
 func (v *BlockValidator) sendValidations(ctx context.Context) {
 	v.reorgMutex.Lock()
 	defer v.reorgMutex.Unlock()
+	v.sendResends()
 	var batchCount uint64
 	for atomic.LoadInt32(&v.reorgsPending) == 0 {
 		if atomic.LoadInt32(&v.atomicValidationsRunning) >= v.concurrentRunsLimit {
@@ -582,7 +741,6 @@ func (v *BlockValidator) sendValidations(ctx context.Context) {
 			log.Error("inconsistent pos mapping", "msg", nextMsg, "expected", v.globalPosNextSend, "found", startPos)
 			return
 		}
-		atomic.AddInt32(&v.atomicValidationsRunning, 1)
 		validationStatus.Entry.StartPosition = startPos
 		validationStatus.Entry.EndPosition = endPos
 
@@ -592,14 +750,13 @@ func (v *BlockValidator) sendValidations(ctx context.Context) {
 			log.Error("sequencer message bad format", "blockNr", v.nextBlockToValidate, "msgNum", batchNum)
 			return
 		}
-
-		v.LaunchThread(func(ctx context.Context) {
-			validationCtx, cancel := context.WithCancel(ctx)
-			validationStatus.Cancel = cancel
-			v.validate(validationCtx, validationStatus, seqMsg)
-			cancel()
+		validationStatus.Entry.BatchInfo = append(validationStatus.Entry.BatchInfo, BatchInfo{
+			Number: batchNum,
+			Data:   seqMsg,
 		})
 
+		v.launchValidation(validationStatus, seqMsg)
+
 		v.nextBlockToValidate++
 		v.globalPosNextSend = endPos
 	}
@@ -662,6 +819,7 @@ func (v *BlockValidator) progressValidated() {
 		v.lastBlockValidatedMutex.Lock()
 		atomic.StoreUint64(&v.lastBlockValidated, checkingBlock)
 		v.lastBlockValidatedHash = validationEntry.BlockHash
+		v.lastBlockValidatedRoots = validationStatus.ModuleRoots
 		v.lastBlockValidatedMutex.Unlock()
 
 		v.validationEntries.Delete(checkingBlock)
@@ -699,6 +857,7 @@ func (v *BlockValidator) AssumeValid(globalState GoGlobalState) error {
 		v.nextBlockToValidate = v.lastBlockValidated + 1
 	}
 	v.lastBlockValidatedHash = globalState.BlockHash
+	v.lastBlockValidatedRoots = nil
 	v.globalPosNextSend = GlobalStatePosition{
 		BatchNumber: globalState.Batch,
 		PosInBatch:  globalState.PosInBatch,
@@ -714,10 +873,20 @@ func (v *BlockValidator) LastBlockValidatedAndHash() (blockNumber uint64, blockH
 	v.lastBlockValidatedMutex.Lock()
 	blockValidated := v.lastBlockValidated
 	blockValidatedHash := v.lastBlockValidatedHash
+	moduleRootsValidated := v.lastBlockValidatedRoots
 	v.lastBlockValidatedMutex.Unlock()
 
-	// things can be removed from, but not added to, moduleRootsToValidate. By taking root hashes fter the block we know result is valid
-	moduleRootsValidated := v.GetModuleRootsToValidate()
+	if moduleRootsValidated == nil {
+		// things can be removed from, but not added to, moduleRootsToValidate. By taking root hashes fter the block we know result is valid
+		moduleRootsValidated = v.GetModuleRootsToValidate()
+		if v.moduleRootSchedule != nil {
+			if header := v.blockchain.GetHeaderByNumber(blockValidated); header != nil {
+				if roots, err := v.ModuleRootsForBlock(header); err == nil {
+					moduleRootsValidated = roots
+				}
+			}
+		}
+	}
 
 	return blockValidated, blockValidatedHash, moduleRootsValidated
 }
@@ -851,6 +1020,7 @@ func (v *BlockValidator) reorgToBlockImpl(blockNum uint64, blockHash common.Hash
 		}
 		atomic.StoreUint64(&v.lastBlockValidated, blockNum)
 		v.lastBlockValidatedHash = blockHash
+		v.lastBlockValidatedRoots = nil
 		if !hasLastValidatedMutex {
 			v.lastBlockValidatedMutex.Unlock()
 		}
@@ -866,6 +1036,11 @@ func (v *BlockValidator) reorgToBlockImpl(blockNum uint64, blockHash common.Hash
 
 // Must be called after SetCurrentWasmModuleRoot sets the current one
 func (v *BlockValidator) Initialize() error {
+	if v.moduleRootSchedule != nil {
+		// The machines were created along with the schedule.
+		log.Info("BlockValidator initialized", "moduleRoots", v.moduleRootSchedule.ModuleRoots())
+		return nil
+	}
 	switch v.config.CurrentModuleRoot {
 	case "latest":
 		latest, err := v.MachineLoader.GetConfig().ReadLatestWasmModuleRoot()
@@ -900,6 +1075,13 @@ func (v *BlockValidator) Initialize() error {
 
 func (v *BlockValidator) Start(ctxIn context.Context) error {
 	v.StopWaiter.Start(ctxIn)
+	if v.remoteWorkers != nil {
+		v.LaunchThread(func(ctx context.Context) {
+			<-ctx.Done()
+			v.remoteWorkers.Close()
+		})
+	}
+	v.CallIteratively(v.updateProgressMetrics)
 	v.LaunchThread(func(ctx context.Context) {
 		// `progressValidated` and `sendValidations` should both only do `concurrentRunsLimit` iterations of work,
 		// so they won't stomp on each other and prevent the other from running.
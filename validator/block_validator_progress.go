@@ -0,0 +1,181 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	validatorLagBlocksGauge           = metrics.NewRegisteredGauge("arb/blockvalidator/lag/blocks", nil)
+	validatorLagSecondsGauge          = metrics.NewRegisteredGauge("arb/blockvalidator/lag/seconds", nil)
+	validatorUnpreparedEntriesGauge   = metrics.NewRegisteredGauge("arb/blockvalidator/entries/unprepared", nil)
+	validatorPreparedEntriesGauge     = metrics.NewRegisteredGauge("arb/blockvalidator/entries/prepared", nil)
+	validatorValidEntriesGauge        = metrics.NewRegisteredGauge("arb/blockvalidator/entries/valid", nil)
+	validatorRunningGauge             = metrics.NewRegisteredGauge("arb/blockvalidator/running", nil)
+	validatorValidatedMeter           = metrics.NewRegisteredMeter("arb/blockvalidator/validated", nil)
+	validatorRecordDurationHistogram  = metrics.NewRegisteredHistogram("arb/blockvalidator/duration/record", nil, metrics.NewExpDecaySample(1028, 0.015))
+	validatorPrepareDurationHistogram = metrics.NewRegisteredHistogram("arb/blockvalidator/duration/prepare", nil, metrics.NewExpDecaySample(1028, 0.015))
+	validatorExecuteDurationHistogram = metrics.NewRegisteredHistogram("arb/blockvalidator/duration/execute", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+const recentValidationTimings = 64
+const progressMetricsInterval = 10 * time.Second
+
+// ValidationTiming is how long the phases of validating a block took, in
+// nanoseconds: recording its creation with the preimages it reads, waiting to
+// be sent for validation once recorded, and running the machines.
+type ValidationTiming struct {
+	BlockNumber uint64        `json:"blockNumber"`
+	Record      time.Duration `json:"recordNanos"`
+	Prepare     time.Duration `json:"prepareNanos"`
+	Execute     time.Duration `json:"executeNanos"`
+	ValidatedAt time.Time     `json:"validatedAt"`
+}
+
+type ValidationEntryCounts struct {
+	Unprepared int `json:"unprepared"`
+	Prepared   int `json:"prepared"`
+	Valid      int `json:"valid"`
+	Running    int `json:"running"`
+}
+
+// ModuleRootResults is how validating blocks against a module root went.
+type ModuleRootResults struct {
+	Validated uint64 `json:"validated"`
+	Failed    uint64 `json:"failed"`
+	// The last block that passed validation against the module root, and the
+	// last one that failed, if any.
+	LastBlock       uint64  `json:"lastBlock"`
+	LastFailedBlock *uint64 `json:"lastFailedBlock,omitempty"`
+}
+
+type ValidationProgress struct {
+	LastValidatedBlock     uint64      `json:"lastValidatedBlock"`
+	LastValidatedBlockHash common.Hash `json:"lastValidatedBlockHash"`
+	HeadBlock              uint64      `json:"headBlock"`
+	// How far validation is behind the head, in blocks and by block timestamps.
+	LagBlocks  uint64 `json:"lagBlocks"`
+	LagSeconds uint64 `json:"lagSeconds"`
+	// Blocks validated per second over the recent blocks.
+	BlocksPerSecond float64               `json:"blocksPerSecond"`
+	Entries         ValidationEntryCounts `json:"entries"`
+	ModuleRoots     []common.Hash         `json:"moduleRoots"`
+	// Validation results by module root, since startup.
+	ModuleRootResults map[common.Hash]ModuleRootResults `json:"moduleRootResults"`
+	RecentBlocks      []ValidationTiming                `json:"recentBlocks"`
+}
+
+func (v *BlockValidator) recordTiming(timing ValidationTiming) {
+	validatorRecordDurationHistogram.Update(timing.Record.Nanoseconds())
+	validatorPrepareDurationHistogram.Update(timing.Prepare.Nanoseconds())
+	validatorExecuteDurationHistogram.Update(timing.Execute.Nanoseconds())
+	validatorValidatedMeter.Mark(1)
+
+	v.timingsMutex.Lock()
+	defer v.timingsMutex.Unlock()
+	if len(v.recentTimings) >= recentValidationTimings {
+		v.recentTimings = v.recentTimings[1:]
+	}
+	v.recentTimings = append(v.recentTimings, timing)
+}
+
+func (v *BlockValidator) recordModuleRootResult(moduleRoot common.Hash, blockNumber uint64, valid bool) {
+	v.moduleRootResultsMutex.Lock()
+	defer v.moduleRootResultsMutex.Unlock()
+	results, ok := v.moduleRootResults[moduleRoot]
+	if !ok {
+		results = &ModuleRootResults{}
+		v.moduleRootResults[moduleRoot] = results
+	}
+	if valid {
+		results.Validated++
+		if blockNumber > results.LastBlock {
+			results.LastBlock = blockNumber
+		}
+	} else {
+		results.Failed++
+		failed := blockNumber
+		results.LastFailedBlock = &failed
+	}
+}
+
+// ModuleRootResults returns how validating against each module root has gone
+// since startup.
+func (v *BlockValidator) ModuleRootResults() map[common.Hash]ModuleRootResults {
+	v.moduleRootResultsMutex.Lock()
+	defer v.moduleRootResultsMutex.Unlock()
+	results := make(map[common.Hash]ModuleRootResults, len(v.moduleRootResults))
+	for root, rootResults := range v.moduleRootResults {
+		results[root] = *rootResults
+	}
+	return results
+}
+
+func (v *BlockValidator) entryCounts() ValidationEntryCounts {
+	var counts ValidationEntryCounts
+	v.validationEntries.Range(func(_, value interface{}) bool {
+		switch atomic.LoadUint32(&value.(*validationStatus).Status) {
+		case validationStatusUnprepared:
+			counts.Unprepared++
+		case validationStatusPrepared:
+			counts.Prepared++
+		case validationStatusValid:
+			counts.Valid++
+		}
+		return true
+	})
+	counts.Running = int(atomic.LoadInt32(&v.atomicValidationsRunning))
+	return counts
+}
+
+// Progress reports how far behind the head validation is, how fast it's going
+// and how long recent blocks took to validate.
+func (v *BlockValidator) Progress() *ValidationProgress {
+	progress := &ValidationProgress{
+		Entries:           v.entryCounts(),
+		ModuleRoots:       v.GetModuleRootsToValidate(),
+		ModuleRootResults: v.ModuleRootResults(),
+	}
+	progress.LastValidatedBlock, progress.LastValidatedBlockHash, _ = v.LastBlockValidatedAndHash()
+	head := v.blockchain.CurrentHeader()
+	if head != nil {
+		progress.HeadBlock = head.Number.Uint64()
+		if progress.HeadBlock > progress.LastValidatedBlock {
+			progress.LagBlocks = progress.HeadBlock - progress.LastValidatedBlock
+		}
+		validated := v.blockchain.GetHeaderByNumber(progress.LastValidatedBlock)
+		if validated != nil && head.Time > validated.Time {
+			progress.LagSeconds = head.Time - validated.Time
+		}
+	}
+
+	v.timingsMutex.Lock()
+	progress.RecentBlocks = append([]ValidationTiming{}, v.recentTimings...)
+	v.timingsMutex.Unlock()
+	if len(progress.RecentBlocks) > 1 {
+		first := progress.RecentBlocks[0].ValidatedAt
+		last := progress.RecentBlocks[len(progress.RecentBlocks)-1].ValidatedAt
+		if elapsed := last.Sub(first); elapsed > 0 {
+			progress.BlocksPerSecond = float64(len(progress.RecentBlocks)-1) / elapsed.Seconds()
+		}
+	}
+	return progress
+}
+
+func (v *BlockValidator) updateProgressMetrics(ctx context.Context) time.Duration {
+	progress := v.Progress()
+	validatorLagBlocksGauge.Update(int64(progress.LagBlocks))
+	validatorLagSecondsGauge.Update(int64(progress.LagSeconds))
+	validatorUnpreparedEntriesGauge.Update(int64(progress.Entries.Unprepared))
+	validatorPreparedEntriesGauge.Update(int64(progress.Entries.Prepared))
+	validatorValidEntriesGauge.Update(int64(progress.Entries.Valid))
+	validatorRunningGauge.Update(int64(progress.Entries.Running))
+	return progressMetricsInterval
+}
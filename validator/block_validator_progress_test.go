@@ -0,0 +1,38 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockValidatorProgressTracking(t *testing.T) {
+	v := &BlockValidator{}
+	statuses := []uint32{validationStatusUnprepared, validationStatusPrepared, validationStatusPrepared, validationStatusValid}
+	for i, status := range statuses {
+		v.validationEntries.Store(uint64(i), &validationStatus{Status: status})
+	}
+	v.atomicValidationsRunning = 2
+	counts := v.entryCounts()
+	expected := ValidationEntryCounts{Unprepared: 1, Prepared: 2, Valid: 1, Running: 2}
+	if counts != expected {
+		Fail(t, "expected entry counts", expected, "got", counts)
+	}
+
+	start := time.Now()
+	for i := 0; i < recentValidationTimings+10; i++ {
+		v.recordTiming(ValidationTiming{
+			BlockNumber: uint64(i),
+			Execute:     time.Millisecond,
+			ValidatedAt: start.Add(time.Duration(i) * time.Second),
+		})
+	}
+	if len(v.recentTimings) != recentValidationTimings {
+		Fail(t, "expected", recentValidationTimings, "recent timings kept, got", len(v.recentTimings))
+	}
+	if v.recentTimings[0].BlockNumber != 10 {
+		Fail(t, "expected the oldest timings to be dropped, first kept is of block", v.recentTimings[0].BlockNumber)
+	}
+}
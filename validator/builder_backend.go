@@ -9,7 +9,6 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/offchainlabs/nitro/arbutil"
@@ -25,7 +24,6 @@ type ValidatorTxBuilder struct {
 	arbutil.L1Interface
 	transactions []*types.Transaction
 	builderAuth  *bind.TransactOpts
-	realSender   common.Address
 	wallet       *ValidatorWallet
 }
 
@@ -40,7 +38,6 @@ func NewValidatorTxBuilder(wallet *ValidatorWallet) (*ValidatorTxBuilder, error)
 	}
 	return &ValidatorTxBuilder{
 		builderAuth: fakeAuth,
-		realSender:  wallet.From(),
 		wallet:      wallet,
 		L1Interface: wallet.l1Reader.Client(),
 	}, nil
@@ -69,7 +66,7 @@ func (b *ValidatorTxBuilder) SendTransaction(ctx context.Context, tx *types.Tran
 		return err
 	}
 	msg := ethereum.CallMsg{
-		From:  b.realSender,
+		From:  b.wallet.From(), // which changes if the wallet's signer is rotated
 		To:    b.wallet.Address(),
 		Value: totalAmount,
 		Data:  realData,
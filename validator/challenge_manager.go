@@ -7,7 +7,9 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"math"
 	"math/big"
+	"time"
 
 	"github.com/offchainlabs/nitro/arbstate"
 
@@ -72,6 +74,7 @@ type ChallengeManager struct {
 	das               arbstate.DataAvailabilityReader
 	machineLoader     *NitroMachineLoader
 	targetNumMachines int
+	machineCacheDisk  *MachineCacheDiskConfig
 	wasmModuleRoot    common.Hash
 
 	initialMachine        *ArbitratorMachine
@@ -97,6 +100,7 @@ func NewChallengeManager(
 	machineLoader *NitroMachineLoader,
 	startL1Block uint64,
 	targetNumMachines int,
+	machineCacheDisk *MachineCacheDiskConfig,
 	confirmationBlocks int64,
 ) (*ChallengeManager, error) {
 	con, err := challengegen.NewChallengeManager(challengeManagerAddr, l1client)
@@ -161,6 +165,7 @@ func NewChallengeManager(
 		das:                   das,
 		machineLoader:         machineLoader,
 		targetNumMachines:     targetNumMachines,
+		machineCacheDisk:      machineCacheDisk,
 		wasmModuleRoot:        challengeInfo.WasmModuleRoot,
 	}, nil
 }
@@ -180,7 +185,7 @@ func NewExecutionChallengeManager(
 	if err != nil {
 		return nil, err
 	}
-	backend, err := NewExecutionChallengeBackend(initialMachine, targetNumMachines, nil)
+	backend, err := NewExecutionChallengeBackend(initialMachine, targetNumMachines, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -352,6 +357,36 @@ func (m *ChallengeManager) IsMyTurn(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+func (m *ChallengeManager) CurrentResponderTimeLeft(ctx context.Context) (*big.Int, error) {
+	timeLeft, err := m.con.CurrentResponderTimeLeft(&bind.CallOpts{Context: ctx}, m.challengeIndex)
+	return timeLeft, errors.WithStack(err)
+}
+
+// TimeUntilTimeout returns how long the current responder has left to move
+// before they can be timed out, as of the latest L1 block. Unlike
+// CurrentResponderTimeLeft, this accounts for the time since the last move.
+func (m *ChallengeManager) TimeUntilTimeout(ctx context.Context) (time.Duration, error) {
+	header, err := m.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	callOpts := &bind.CallOpts{Context: ctx, BlockNumber: header.Number}
+	challengeState, err := m.con.ChallengeInfo(callOpts, m.challengeIndex)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	sinceLastMove := new(big.Int).Sub(new(big.Int).SetUint64(header.Time), challengeState.LastMoveTimestamp)
+	timeLeft := new(big.Int).Sub(challengeState.Current.TimeLeft, sinceLastMove)
+	if timeLeft.Sign() <= 0 {
+		return 0, nil
+	}
+	maxSeconds := big.NewInt(int64(math.MaxInt64 / time.Second))
+	if timeLeft.Cmp(maxSeconds) > 0 {
+		timeLeft = maxSeconds
+	}
+	return time.Duration(timeLeft.Int64()) * time.Second, nil
+}
+
 func (m *ChallengeManager) GetChallengeState(ctx context.Context) (*ChallengeState, error) {
 	callOpts := &bind.CallOpts{Context: ctx}
 	var err error
@@ -531,7 +566,14 @@ func (m *ChallengeManager) LoadExecChallengeIfExists(ctx context.Context) error
 	if err != nil {
 		return err
 	}
-	execBackend, err := NewExecutionChallengeBackend(m.initialMachine, m.targetNumMachines, nil)
+	var snapshots *MachineSnapshots
+	if m.machineCacheDisk != nil {
+		snapshots, err = OpenMachineSnapshots(m.machineCacheDisk, m.challengeIndex, m.initialMachine)
+		if err != nil {
+			return err
+		}
+	}
+	execBackend, err := NewExecutionChallengeBackend(m.initialMachine, m.targetNumMachines, nil, snapshots)
 	if err != nil {
 		return err
 	}
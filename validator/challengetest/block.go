@@ -0,0 +1,213 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package challengetest
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/solgen/go/challengegen"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+// blockChallengeBackend is the validator.ChallengeBackend of a block challenge
+// over the global states a party claims after each block, in place of the
+// validator.BlockChallengeBackend that reads them from an L2 chain.
+type blockChallengeBackend struct {
+	states []validator.GoGlobalState
+}
+
+var _ validator.ChallengeBackend = (*blockChallengeBackend)(nil)
+
+func (b *blockChallengeBackend) SetRange(context.Context, uint64, uint64) error {
+	return nil
+}
+
+func (b *blockChallengeBackend) GetHashAtStep(_ context.Context, position uint64) (common.Hash, error) {
+	if position >= uint64(len(b.states)) {
+		return crypto.Keccak256Hash([]byte("Block state, too far:")), nil
+	}
+	gsHash := b.states[position].Hash()
+	return crypto.Keccak256Hash([]byte("Block state:"), gsHash[:]), nil
+}
+
+// blockPlayer plays a party's side of a block challenge: it bisects the block
+// states until the parties disagree on a single block, and then plays the
+// execution challenge over that block with a validator.ChallengeManager.
+type blockPlayer struct {
+	client        *backends.SimulatedBackend
+	con           *challengegen.ChallengeManager
+	challengeAddr common.Address
+	auth          *bind.TransactOpts
+	party         BlockParty
+	strategy      Strategy
+	backend       *blockChallengeBackend
+	config        *Config
+
+	// Reads the challenge state while bisecting blocks.
+	reader *validator.ChallengeManager
+	// nil until the execution challenge has begun.
+	execution *validator.ChallengeManager
+}
+
+// blockMachine returns the machine the party executes block with.
+func (p *blockPlayer) blockMachine(block uint64) (validator.MachineInterface, error) {
+	if block >= uint64(len(p.party.States)) {
+		return nil, errors.Errorf("block %v past the %v blocks claimed", block, len(p.party.States)-1)
+	}
+	machine := p.party.Machine.Clone()
+	if err := machine.SetGlobalState(p.party.States[block]); err != nil {
+		return nil, err
+	}
+	return p.strategy.Machine(machine), nil
+}
+
+func (p *blockPlayer) newChallengeManager(machine validator.MachineInterface) (*validator.ChallengeManager, error) {
+	return validator.NewExecutionChallengeManager(
+		p.client,
+		p.auth,
+		p.challengeAddr,
+		challengeIndex,
+		machine,
+		0,
+		p.config.TargetNumMachines,
+		0,
+	)
+}
+
+// loadExecutionChallenge starts playing the execution challenge once it has
+// begun, over the block it was begun at.
+func (p *blockPlayer) loadExecutionChallenge(ctx context.Context) error {
+	callOpts := &bind.CallOpts{Context: ctx}
+	info, err := p.con.ChallengeInfo(callOpts, challengeIndex)
+	if err != nil {
+		return err
+	}
+	if info.Mode != challengeModeExecution {
+		return nil
+	}
+	events, err := p.con.FilterExecutionChallengeBegun(&bind.FilterOpts{Context: ctx}, []uint64{challengeIndex})
+	if err != nil {
+		return err
+	}
+	defer events.Close()
+	var blockSteps *big.Int
+	for events.Next() {
+		blockSteps = events.Event.BlockSteps
+	}
+	if err := events.Error(); err != nil {
+		return err
+	}
+	if blockSteps == nil || !blockSteps.IsUint64() {
+		return errors.New("expected ExecutionChallengeBegun event")
+	}
+	machine, err := p.blockMachine(blockSteps.Uint64())
+	if err != nil {
+		return err
+	}
+	p.execution, err = p.newChallengeManager(machine)
+	return err
+}
+
+func (p *blockPlayer) Act(ctx context.Context) (*types.Transaction, error) {
+	if p.execution == nil {
+		if err := p.loadExecutionChallenge(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if p.execution != nil {
+		return p.execution.Act(ctx)
+	}
+	if p.reader == nil {
+		var err error
+		p.reader, err = p.newChallengeManager(p.party.Machine.Clone())
+		if err != nil {
+			return nil, err
+		}
+	}
+	myTurn, err := p.reader.IsMyTurn(ctx)
+	if !myTurn || err != nil {
+		return nil, err
+	}
+	state, err := p.reader.GetChallengeState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	segment, err := p.reader.ScanChallengeState(ctx, p.backend, state)
+	if err != nil {
+		return nil, err
+	}
+	start := state.Segments[segment].Position
+	end := state.Segments[segment+1].Position
+	if start+1 != end {
+		log.Info("bisecting blocks", "party", p.auth.From, "start", start, "end", end)
+		return p.bisect(ctx, state, segment)
+	}
+	log.Info("challenging execution", "party", p.auth.From, "block", start)
+	return p.challengeExecution(ctx, state, segment)
+}
+
+func segmentSelection(state *validator.ChallengeState, segment int) challengegen.ChallengeLibSegmentSelection {
+	return challengegen.ChallengeLibSegmentSelection{
+		OldSegmentsStart:  state.Start,
+		OldSegmentsLength: new(big.Int).Sub(state.End, state.Start),
+		OldSegments:       state.RawSegments,
+		ChallengePosition: big.NewInt(int64(segment)),
+	}
+}
+
+// bisect splits the blocks of segment, which the party disagrees with the end
+// of, into as many segments as the challenge manager allows.
+func (p *blockPlayer) bisect(ctx context.Context, state *validator.ChallengeState, segment int) (*types.Transaction, error) {
+	start := state.Segments[segment].Position
+	end := state.Segments[segment+1].Position
+	degree := end - start
+	if degree > maxBisectionDegree {
+		degree = maxBisectionDegree
+	}
+	segmentLength := (end - start) / degree
+	newSegments := make([][32]byte, degree+1)
+	for i := range newSegments {
+		position := start + uint64(i)*segmentLength
+		if i == len(newSegments)-1 {
+			position = end
+		}
+		hash, err := p.backend.GetHashAtStep(ctx, position)
+		if err != nil {
+			return nil, err
+		}
+		newSegments[i] = hash
+	}
+	return p.con.BisectExecution(p.auth, challengeIndex, segmentSelection(state, segment), newSegments)
+}
+
+// challengeExecution begins the execution challenge over the single block of
+// segment, claiming the party's state after it.
+func (p *blockPlayer) challengeExecution(ctx context.Context, state *validator.ChallengeState, segment int) (*types.Transaction, error) {
+	block := state.Segments[segment].Position
+	machine, err := p.blockMachine(block)
+	if err != nil {
+		return nil, err
+	}
+	if err := machine.Step(ctx, ^uint64(0)); err != nil {
+		return nil, err
+	}
+	return p.con.ChallengeExecution(
+		p.auth,
+		challengeIndex,
+		segmentSelection(state, segment),
+		[2]uint8{validator.StatusFinished, validator.StatusFinished},
+		[2][32]byte{p.party.States[block].Hash(), p.party.States[block+1].Hash()},
+		new(big.Int).SetUint64(machine.GetStepCount()),
+	)
+}
@@ -0,0 +1,435 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package challengetest runs two-party challenges end to end against a
+// simulated L1 with the challenge contracts deployed. Execution challenges have
+// each party played by a validator.ChallengeManager acting on its own machine,
+// and block challenges bisect the parties' claimed block states down to a
+// single block before playing the execution challenge over it.
+package challengetest
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/solgen/go/challengegen"
+	"github.com/offchainlabs/nitro/solgen/go/mocksgen"
+	"github.com/offchainlabs/nitro/solgen/go/ospgen"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+const simulatedChainID = 1337
+
+// The challenge index of the only challenge each harness run creates.
+const challengeIndex = 1
+
+// The most segments a bisection may have, as the challenge manager enforces.
+const maxBisectionDegree = 40
+
+// The challenge mode of challenges bisecting execution steps.
+const challengeModeExecution = 2
+
+type Config struct {
+	// The number of inbox messages the machines may read.
+	MaxInboxMessagesRead uint64
+	// Each party's chess clock, in seconds.
+	AsserterTimeLeft   uint64
+	ChallengerTimeLeft uint64
+	// How far the simulated clock advances each round.
+	RoundTime         time.Duration
+	TargetNumMachines int
+	// The challenge fails to resolve if there's no winner after this many rounds.
+	MaxRounds int
+}
+
+var DefaultConfig = Config{
+	MaxInboxMessagesRead: 0,
+	AsserterTimeLeft:     100,
+	ChallengerTimeLeft:   100,
+	RoundTime:            time.Second,
+	TargetNumMachines:    4,
+	MaxRounds:            200,
+}
+
+// A Party is one side of the challenge, playing with its machine as scripted
+// by its strategy.
+type Party struct {
+	// Must be at step count 0.
+	Machine  validator.MachineInterface
+	Strategy Strategy
+}
+
+type PartyResult struct {
+	Address common.Address
+	// The moves the party made, including claiming a timeout.
+	Moves   int
+	GasUsed uint64
+	// The errors acting returned, e.g. when the party couldn't make a valid move.
+	Errors []error
+}
+
+type Result struct {
+	Winner      common.Address
+	AsserterWon bool
+	// Timeout is true if the challenge ended by the loser running out of time.
+	Timeout    bool
+	Rounds     int
+	Asserter   PartyResult
+	Challenger PartyResult
+}
+
+// A player makes a party's moves.
+type player interface {
+	Act(ctx context.Context) (*types.Transaction, error)
+}
+
+type party struct {
+	Party
+	auth     *bind.TransactOpts
+	player   player
+	machine  validator.MachineInterface
+	result   *PartyResult
+	acted    int
+	stuckErr error
+}
+
+// NewTransactOpts returns transaction options for a new key on the simulated L1.
+func NewTransactOpts() (*bind.TransactOpts, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewKeyedTransactorWithChainID(key, big.NewInt(simulatedChainID))
+}
+
+// GenesisAlloc funds the accounts of auths in a simulated L1's genesis.
+func GenesisAlloc(auths ...*bind.TransactOpts) core.GenesisAlloc {
+	alloc := make(core.GenesisAlloc)
+	balance := new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)
+	for _, auth := range auths {
+		alloc[auth.From] = core.GenesisAccount{Balance: new(big.Int).Set(balance)}
+	}
+	return alloc
+}
+
+// DeployOneStepProofEntry deploys the one step provers and the entry that
+// challenges prove steps with.
+func DeployOneStepProofEntry(auth *bind.TransactOpts, client bind.ContractBackend) (common.Address, error) {
+	osp0, _, _, err := ospgen.DeployOneStepProver0(auth, client)
+	if err != nil {
+		return common.Address{}, err
+	}
+	ospMem, _, _, err := ospgen.DeployOneStepProverMemory(auth, client)
+	if err != nil {
+		return common.Address{}, err
+	}
+	ospMath, _, _, err := ospgen.DeployOneStepProverMath(auth, client)
+	if err != nil {
+		return common.Address{}, err
+	}
+	ospHostIo, _, _, err := ospgen.DeployOneStepProverHostIo(auth, client)
+	if err != nil {
+		return common.Address{}, err
+	}
+	ospEntry, _, _, err := ospgen.DeployOneStepProofEntry(auth, client, osp0, ospMem, ospMath, ospHostIo)
+	return ospEntry, err
+}
+
+// Run deploys an execution challenge of the asserter's claimed end state, and
+// has the parties play it out until one of them wins.
+func Run(ctx context.Context, config *Config, asserter, challenger Party) (*Result, error) {
+	deployer, err := NewTransactOpts()
+	if err != nil {
+		return nil, err
+	}
+	parties, result, err := newParties(asserter, challenger)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range parties {
+		p.machine = p.Strategy.Machine(p.Machine.CloneMachineInterface())
+	}
+
+	backend := backends.NewSimulatedBackend(GenesisAlloc(deployer, parties[0].auth, parties[1].auth), 1_000_000_000)
+	defer backend.Close()
+	backend.Commit()
+
+	ospEntry, err := DeployOneStepProofEntry(deployer, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "error deploying one step proof contracts")
+	}
+	resultReceiverAddr, _, resultReceiver, err := mocksgen.DeployMockResultReceiver(deployer, backend, common.Address{})
+	if err != nil {
+		return nil, err
+	}
+
+	// The challenge is of the asserter's claim of where its machine ends up.
+	endMachine := parties[0].machine.CloneMachineInterface()
+	startHash := endMachine.Hash()
+	if err := endMachine.Step(ctx, ^uint64(0)); err != nil {
+		return nil, err
+	}
+	challengeAddr, _, _, err := mocksgen.DeploySingleExecutionChallenge(
+		deployer,
+		backend,
+		ospEntry,
+		resultReceiverAddr,
+		config.MaxInboxMessagesRead,
+		[2][32]byte{startHash, endMachine.Hash()},
+		new(big.Int).SetUint64(endMachine.GetStepCount()),
+		parties[0].auth.From,
+		parties[1].auth.From,
+		new(big.Int).SetUint64(config.AsserterTimeLeft),
+		new(big.Int).SetUint64(config.ChallengerTimeLeft),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error deploying challenge")
+	}
+	backend.Commit()
+
+	for _, p := range parties {
+		p.player, err = validator.NewExecutionChallengeManager(
+			backend,
+			p.auth,
+			challengeAddr,
+			challengeIndex,
+			p.machine,
+			0,
+			config.TargetNumMachines,
+			0,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return play(ctx, config, backend, challengeAddr, resultReceiver, parties, result)
+}
+
+// A BlockParty is one side of a block challenge, claiming the global states
+// after each block, and executing blocks with its machine.
+type BlockParty struct {
+	// The global state before the first block, followed by those after each.
+	// The parties must claim the same number of blocks.
+	States []validator.GoGlobalState
+	// Must be at step count 0. It's set to the global state before a block
+	// to execute the block.
+	Machine  *validator.ArbitratorMachine
+	Strategy Strategy
+}
+
+// RunBlockChallenge deploys a block challenge of the asserter's claimed block
+// states, and has the parties bisect them down to a block and play out the
+// execution challenge over it until one of them wins.
+func RunBlockChallenge(ctx context.Context, config *Config, asserter, challenger BlockParty) (*Result, error) {
+	if len(asserter.States) < 2 || len(asserter.States) != len(challenger.States) {
+		return nil, errors.New("parties must claim the same number of blocks, and at least one")
+	}
+	if asserter.States[0] != challenger.States[0] {
+		return nil, errors.New("parties must agree on the state before the first block")
+	}
+	deployer, err := NewTransactOpts()
+	if err != nil {
+		return nil, err
+	}
+	parties, result, err := newParties(
+		Party{Machine: asserter.Machine, Strategy: asserter.Strategy},
+		Party{Machine: challenger.Machine, Strategy: challenger.Strategy},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := backends.NewSimulatedBackend(GenesisAlloc(deployer, parties[0].auth, parties[1].auth), 1_000_000_000)
+	defer backend.Close()
+	backend.Commit()
+
+	ospEntry, err := DeployOneStepProofEntry(deployer, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "error deploying one step proof contracts")
+	}
+	challengeLogic, _, _, err := challengegen.DeployChallengeManager(deployer, backend)
+	if err != nil {
+		return nil, err
+	}
+	challengeAddr, _, _, err := mocksgen.DeploySimpleProxy(deployer, backend, challengeLogic)
+	if err != nil {
+		return nil, err
+	}
+	resultReceiverAddr, _, resultReceiver, err := mocksgen.DeployMockResultReceiver(deployer, backend, challengeAddr)
+	if err != nil {
+		return nil, err
+	}
+	backend.Commit()
+	con, err := challengegen.NewChallengeManager(challengeAddr, backend)
+	if err != nil {
+		return nil, err
+	}
+	// The machines don't read the inbox, so there's no need for one.
+	if _, err := con.Initialize(deployer, resultReceiverAddr, common.Address{}, common.Address{}, ospEntry); err != nil {
+		return nil, err
+	}
+	numBlocks := len(asserter.States) - 1
+	_, err = resultReceiver.CreateChallenge(
+		deployer,
+		asserter.Machine.GetModuleRoot(),
+		[2]uint8{validator.StatusFinished, validator.StatusFinished},
+		[2]mocksgen.GlobalState{
+			mocksgen.GlobalState(asserter.States[0].AsSolidityStruct()),
+			mocksgen.GlobalState(asserter.States[numBlocks].AsSolidityStruct()),
+		},
+		uint64(numBlocks),
+		parties[0].auth.From,
+		parties[1].auth.From,
+		new(big.Int).SetUint64(config.AsserterTimeLeft),
+		new(big.Int).SetUint64(config.ChallengerTimeLeft),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating challenge")
+	}
+	backend.Commit()
+
+	for i, blockParty := range []BlockParty{asserter, challenger} {
+		parties[i].player = &blockPlayer{
+			client:        backend,
+			con:           con,
+			challengeAddr: challengeAddr,
+			auth:          parties[i].auth,
+			party:         blockParty,
+			strategy:      parties[i].Strategy,
+			backend:       &blockChallengeBackend{states: blockParty.States},
+			config:        config,
+		}
+	}
+	return play(ctx, config, backend, challengeAddr, resultReceiver, parties, result)
+}
+
+func newParties(asserter, challenger Party) ([]*party, *Result, error) {
+	parties := make([]*party, 2)
+	result := &Result{}
+	for i, p := range []Party{asserter, challenger} {
+		if p.Machine.GetStepCount() != 0 {
+			return nil, nil, errors.New("party's machine not at step count 0")
+		}
+		if p.Strategy == nil {
+			p.Strategy = Honest()
+		}
+		auth, err := NewTransactOpts()
+		if err != nil {
+			return nil, nil, err
+		}
+		parties[i] = &party{Party: p, auth: auth}
+	}
+	result.Asserter.Address = parties[0].auth.From
+	result.Challenger.Address = parties[1].auth.From
+	parties[0].result = &result.Asserter
+	parties[1].result = &result.Challenger
+	return parties, result, nil
+}
+
+// play has the parties take turns moving in the challenge until one of them
+// wins, claiming the win by timeout when the other runs out of time.
+func play(
+	ctx context.Context,
+	config *Config,
+	backend *backends.SimulatedBackend,
+	challengeAddr common.Address,
+	resultReceiver *mocksgen.MockResultReceiver,
+	parties []*party,
+	result *Result,
+) (*Result, error) {
+	con, err := challengegen.NewChallengeManager(challengeAddr, backend)
+	if err != nil {
+		return nil, err
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	for result.Rounds = 1; result.Rounds <= config.MaxRounds; result.Rounds++ {
+		if err := backend.AdjustTime(config.RoundTime); err != nil {
+			return nil, err
+		}
+		backend.Commit()
+
+		responder, err := con.CurrentResponder(callOpts, challengeIndex)
+		if err != nil {
+			return nil, err
+		}
+		var current, other *party
+		switch responder {
+		case parties[0].auth.From:
+			current, other = parties[0], parties[1]
+		case parties[1].auth.From:
+			current, other = parties[1], parties[0]
+		default:
+			return nil, errors.Errorf("unexpected challenge responder %v", responder)
+		}
+
+		timedOut, err := con.IsTimedOut(callOpts, challengeIndex)
+		if err != nil {
+			return nil, err
+		}
+		var tx *types.Transaction
+		var sender *party
+		if timedOut {
+			log.Info("claiming challenge timeout", "party", other.auth.From, "timedOut", current.auth.From)
+			sender = other
+			tx, err = con.Timeout(other.auth, challengeIndex)
+		} else if current.stuckErr == nil && current.Strategy.Acts(current.acted) {
+			sender = current
+			tx, err = current.player.Act(ctx)
+		} else {
+			// Skip ahead to when the party that isn't responding runs out of time.
+			timeLeft, err := con.CurrentResponderTimeLeft(callOpts, challengeIndex)
+			if err != nil {
+				return nil, err
+			}
+			if err := backend.AdjustTime(time.Duration(timeLeft.Uint64()+1) * time.Second); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			// It's stuck until it times out, e.g. as it can't make a valid move.
+			log.Info("challenge party failed to act", "party", sender.auth.From, "err", err)
+			sender.result.Errors = append(sender.result.Errors, err)
+			sender.stuckErr = err
+			continue
+		}
+		if tx == nil {
+			continue
+		}
+		backend.Commit()
+		receipt, err := backend.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, err
+		}
+		sender.acted++
+		sender.result.Moves++
+		sender.result.GasUsed += receipt.GasUsed
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			sender.result.Errors = append(sender.result.Errors, errors.Errorf("move %v reverted", tx.Hash()))
+			continue
+		}
+
+		winner, err := resultReceiver.Winner(callOpts)
+		if err != nil {
+			return nil, err
+		}
+		if winner != (common.Address{}) {
+			result.Winner = winner
+			result.AsserterWon = winner == parties[0].auth.From
+			result.Timeout = timedOut
+			return result, nil
+		}
+	}
+	return result, errors.Errorf("challenge not resolved after %v rounds", config.MaxRounds)
+}
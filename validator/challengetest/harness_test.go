@@ -0,0 +1,163 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package challengetest
+
+import (
+	"context"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
+
+func loadTestMachine(t *testing.T) *validator.ArbitratorMachine {
+	_, filename, _, _ := runtime.Caller(0)
+	wasmDir := path.Join(path.Dir(filename), "../../arbitrator/prover/test-cases/")
+	machine, err := validator.LoadSimpleMachine(
+		path.Join(wasmDir, "global-state.wasm"),
+		[]string{path.Join(wasmDir, "global-state-wrapper.wasm")},
+	)
+	Require(t, err)
+	return machine
+}
+
+func runTestChallenge(t *testing.T, asserter, challenger Strategy) *Result {
+	machine := loadTestMachine(t)
+	result, err := Run(
+		context.Background(),
+		&DefaultConfig,
+		Party{Machine: machine.Clone(), Strategy: asserter},
+		Party{Machine: machine.Clone(), Strategy: challenger},
+	)
+	Require(t, err)
+	if result.Winner != result.Asserter.Address && result.Winner != result.Challenger.Address {
+		Fail(t, "winner", result.Winner, "isn't a party to the challenge")
+	}
+	for _, party := range []PartyResult{result.Asserter, result.Challenger} {
+		if party.Moves > 0 && party.GasUsed == 0 {
+			Fail(t, "party", party.Address, "made", party.Moves, "moves without spending gas")
+		}
+	}
+	t.Logf("%+v", result)
+	return result
+}
+
+func TestHarnessWrongAsserter(t *testing.T) {
+	result := runTestChallenge(t, WrongHashAtStep(200), Honest())
+	if result.AsserterWon {
+		Fail(t, "asserter with wrong hashes won")
+	}
+	if result.Challenger.Moves == 0 {
+		Fail(t, "challenger won without moving")
+	}
+}
+
+func TestHarnessWrongChallenger(t *testing.T) {
+	result := runTestChallenge(t, Honest(), WrongHashAtStep(200))
+	if !result.AsserterWon {
+		Fail(t, "challenger with wrong hashes won")
+	}
+}
+
+func TestHarnessTimeout(t *testing.T) {
+	result := runTestChallenge(t, TimeoutAfterMoves(WrongHashAtStep(200), 0), Honest())
+	if result.AsserterWon || !result.Timeout {
+		Fail(t, "asserter that stopped responding didn't lose by timeout")
+	}
+	if result.Asserter.Moves != 0 {
+		Fail(t, "asserter made", result.Asserter.Moves, "moves after it should have stopped")
+	}
+}
+
+const testBlocks = 50
+
+// testBlockStates returns the global states after each of the blocks machine
+// executes from start.
+func testBlockStates(t *testing.T, machine *validator.ArbitratorMachine, start validator.GoGlobalState) []validator.GoGlobalState {
+	states := []validator.GoGlobalState{start}
+	for i := 0; i < testBlocks; i++ {
+		block := machine.Clone()
+		Require(t, block.SetGlobalState(states[i]))
+		Require(t, block.Step(context.Background(), ^uint64(0)))
+		states = append(states, block.GetGlobalState())
+	}
+	return states
+}
+
+// wrongBlockStates returns states with those after the given block changed.
+func wrongBlockStates(states []validator.GoGlobalState, from int) []validator.GoGlobalState {
+	wrong := append([]validator.GoGlobalState{}, states...)
+	for i := from; i < len(wrong); i++ {
+		wrong[i].SendRoot = common.HexToHash("0xbad")
+	}
+	return wrong
+}
+
+func runTestBlockChallenge(t *testing.T, asserterWrong bool) *Result {
+	machine := loadTestMachine(t)
+	states := testBlockStates(t, machine, validator.GoGlobalState{BlockHash: common.HexToHash("0x1234"), Batch: 3})
+	asserter := BlockParty{States: states, Machine: machine.Clone()}
+	challenger := BlockParty{States: states, Machine: machine.Clone()}
+	honest := &challenger
+	if asserterWrong {
+		asserter.States = wrongBlockStates(states, 30)
+	} else {
+		challenger.States = wrongBlockStates(states, 30)
+		honest = &asserter
+	}
+	result, err := RunBlockChallenge(context.Background(), &DefaultConfig, asserter, challenger)
+	Require(t, err)
+	t.Logf("%+v", result)
+	if result.AsserterWon == asserterWrong {
+		Fail(t, "party with wrong block states won")
+	}
+	honestResult := result.Challenger
+	if honest == &asserter {
+		honestResult = result.Asserter
+	}
+	if honestResult.Moves == 0 || len(honestResult.Errors) != 0 {
+		Fail(t, "honest party made", honestResult.Moves, "moves with errors", honestResult.Errors)
+	}
+	return result
+}
+
+func TestHarnessBlockChallengeWrongAsserter(t *testing.T) {
+	runTestBlockChallenge(t, true)
+}
+
+func TestHarnessBlockChallengeWrongChallenger(t *testing.T) {
+	runTestBlockChallenge(t, false)
+}
+
+func TestHarnessBlockChallengeTimeout(t *testing.T) {
+	machine := loadTestMachine(t)
+	states := testBlockStates(t, machine, validator.GoGlobalState{Batch: 3})
+	result, err := RunBlockChallenge(
+		context.Background(),
+		&DefaultConfig,
+		BlockParty{States: wrongBlockStates(states, 1), Machine: machine.Clone(), Strategy: TimeoutAfterMoves(Honest(), 1)},
+		BlockParty{States: states, Machine: machine.Clone()},
+	)
+	Require(t, err)
+	if result.AsserterWon || !result.Timeout {
+		Fail(t, "asserter that stopped responding didn't lose by timeout")
+	}
+	if result.Asserter.Moves > 1 {
+		Fail(t, "asserter made", result.Asserter.Moves, "moves after it should have stopped")
+	}
+}
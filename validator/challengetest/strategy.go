@@ -0,0 +1,122 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package challengetest
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/validator"
+)
+
+// A Strategy scripts how a party plays the challenge.
+type Strategy interface {
+	// Machine returns the machine the party plays with, given the one it was
+	// configured with, e.g. wrapped to report wrong hashes.
+	Machine(validator.MachineInterface) validator.MachineInterface
+	// Acts returns whether the party makes its next move, given how many it has
+	// made so far, or stops responding so that it runs out of time.
+	Acts(moves int) bool
+}
+
+type honest struct{}
+
+// Honest plays every move with the party's machine as is.
+func Honest() Strategy {
+	return honest{}
+}
+
+func (honest) Machine(mach validator.MachineInterface) validator.MachineInterface {
+	return mach
+}
+
+func (honest) Acts(int) bool {
+	return true
+}
+
+type wrongHashAtStep struct {
+	honest
+	step uint64
+}
+
+// WrongHashAtStep plays honestly except that the party's machine hashes are
+// wrong from the given step count on, which must be at most the step count the
+// machine halts at.
+func WrongHashAtStep(step uint64) Strategy {
+	return wrongHashAtStep{step: step}
+}
+
+func (s wrongHashAtStep) Machine(mach validator.MachineInterface) validator.MachineInterface {
+	return NewWrongHashMachine(mach, s.step)
+}
+
+type timeoutAfterMoves struct {
+	Strategy
+	moves int
+}
+
+// TimeoutAfterMoves plays as the given strategy does, but stops responding
+// after making the given number of moves.
+func TimeoutAfterMoves(strategy Strategy, moves int) Strategy {
+	return timeoutAfterMoves{Strategy: strategy, moves: moves}
+}
+
+func (s timeoutAfterMoves) Acts(moves int) bool {
+	return moves < s.moves && s.Strategy.Acts(moves)
+}
+
+// WrongHashMachine is a machine whose hash is wrong from a given step count on.
+// Its proofs are those of the underlying machine, so it can't prove the step
+// to the first wrong hash.
+type WrongHashMachine struct {
+	inner     validator.MachineInterface
+	wrongStep uint64
+}
+
+var _ validator.MachineInterface = (*WrongHashMachine)(nil)
+
+func NewWrongHashMachine(inner validator.MachineInterface, wrongStep uint64) *WrongHashMachine {
+	return &WrongHashMachine{
+		inner:     inner,
+		wrongStep: wrongStep,
+	}
+}
+
+func (m *WrongHashMachine) CloneMachineInterface() validator.MachineInterface {
+	return NewWrongHashMachine(m.inner.CloneMachineInterface(), m.wrongStep)
+}
+
+func (m *WrongHashMachine) GetStepCount() uint64 {
+	return m.inner.GetStepCount()
+}
+
+func (m *WrongHashMachine) IsRunning() bool {
+	return m.inner.IsRunning()
+}
+
+func (m *WrongHashMachine) ValidForStep(step uint64) bool {
+	return m.inner.ValidForStep(step)
+}
+
+func (m *WrongHashMachine) Step(ctx context.Context, count uint64) error {
+	return m.inner.Step(ctx, count)
+}
+
+func (m *WrongHashMachine) Hash() common.Hash {
+	hash := m.inner.Hash()
+	if m.inner.GetStepCount() < m.wrongStep {
+		return hash
+	}
+	return crypto.Keccak256Hash(hash[:], []byte("wrong"))
+}
+
+func (m *WrongHashMachine) GetGlobalState() validator.GoGlobalState {
+	return m.inner.GetGlobalState()
+}
+
+func (m *WrongHashMachine) ProveNextStep() []byte {
+	return m.inner.ProveNextStep()
+}
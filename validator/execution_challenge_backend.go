@@ -20,16 +20,19 @@ type ExecutionChallengeBackend struct {
 	machineCacheStart uint64
 	machineCacheEnd   uint64
 	targetNumMachines int
+	snapshots         *MachineSnapshots
 }
 
 // Assert that ExecutionChallengeBackend implements ChallengeBackend
 var _ ChallengeBackend = (*ExecutionChallengeBackend)(nil)
 
 // machineCache may be nil, but if present, it must not have a restricted range
+// snapshots may be nil, otherwise it's the disk tier of the machine caches
 func NewExecutionChallengeBackend(
 	initialMachine MachineInterface,
 	targetNumMachines int,
 	machineCache *MachineCache,
+	snapshots *MachineSnapshots,
 ) (*ExecutionChallengeBackend, error) {
 	if initialMachine.GetStepCount() != 0 {
 		return nil, errors.New("initialMachine not at step count 0")
@@ -38,6 +41,7 @@ func NewExecutionChallengeBackend(
 		initialMachine:    initialMachine,
 		targetNumMachines: targetNumMachines,
 		machineCache:      machineCache,
+		snapshots:         snapshots,
 	}, nil
 }
 
@@ -48,7 +52,12 @@ func (b *ExecutionChallengeBackend) getMachineAt(ctx context.Context, stepCount
 			mach = b.lastMachine
 		}
 		mach = mach.CloneMachineInterface()
-		err := mach.Step(ctx, stepCount-mach.GetStepCount())
+		var err error
+		if b.snapshots != nil {
+			mach, err = b.snapshots.advance(ctx, mach, stepCount)
+		} else {
+			err = mach.Step(ctx, stepCount-mach.GetStepCount())
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -73,7 +82,7 @@ func (b *ExecutionChallengeBackend) SetRange(ctx context.Context, start uint64,
 		return err
 	}
 	b.machineCache = nil
-	b.machineCache, err = NewMachineCacheWithEndSteps(ctx, startMach, b.targetNumMachines, end)
+	b.machineCache, err = NewMachineCacheWithEndSteps(ctx, startMach, b.targetNumMachines, end, b.snapshots)
 	return err
 }
 
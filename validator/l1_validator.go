@@ -56,6 +56,11 @@ type L1Validator struct {
 	txStreamer         TransactionStreamerInterface
 	blockValidator     *BlockValidator
 	lastWasmModuleRoot common.Hash
+	moduleRootNodes    *moduleRootNodeResolver
+	notifier           StakerNotifier // may be nil
+	// The earliest confirmation deadline block of the incorrect nodes seen
+	// since it was last reset, if any.
+	wrongNodeDeadline *uint64
 }
 
 func NewL1Validator(
@@ -102,9 +107,21 @@ func NewL1Validator(
 		inboxTracker:       inboxTracker,
 		txStreamer:         txStreamer,
 		blockValidator:     blockValidator,
+		moduleRootNodes:    newModuleRootNodeResolver(rollup, l2Blockchain, blockValidator),
 	}, nil
 }
 
+func (v *L1Validator) notify(event StakerEvent) {
+	if v.notifier == nil {
+		return
+	}
+	event.Rollup = v.rollupAddress
+	if address := v.wallet.AddressOrZero(); address != (common.Address{}) {
+		event.Staker = &address
+	}
+	v.notifier.Notify(event)
+}
+
 func (v *L1Validator) getCallOpts(ctx context.Context) *bind.CallOpts {
 	opts := v.callOpts
 	opts.Context = ctx
@@ -140,6 +157,119 @@ func (v *L1Validator) updateBlockValidatorModuleRoot(ctx context.Context) error
 	} else if (moduleRoot == common.Hash{}) {
 		return errors.New("wasmModuleRoot in rollup is zero")
 	}
+	return v.moduleRootNodes.resolve(ctx, v.getCallOpts(ctx))
+}
+
+// moduleRootNodeReader is the part of the rollup read to resolve the nodes
+// that activate module roots.
+type moduleRootNodeReader interface {
+	LatestConfirmed(opts *bind.CallOpts) (uint64, error)
+	GetNode(opts *bind.CallOpts, nodeNum uint64) (rollupgen.Node, error)
+	LookupNode(ctx context.Context, number uint64) (*NodeInfo, error)
+}
+
+// moduleRootNodeResolver tells the block validator which of the nodes that
+// activate module roots in its schedule have been confirmed or rejected.
+type moduleRootNodeResolver struct {
+	rollup         moduleRootNodeReader
+	l2Blockchain   *core.BlockChain
+	blockValidator *BlockValidator
+
+	// What's been read from L1 about the nodes, so it's read once rather than on
+	// every tick: which nodes are confirmed as of the latest confirmed node
+	// checkedAt, and the last block asserted by each confirmed node.
+	checkedAt        uint64
+	confirmed        map[uint64]bool
+	afterBlockHashes map[uint64]common.Hash
+}
+
+func newModuleRootNodeResolver(rollup moduleRootNodeReader, l2Blockchain *core.BlockChain, blockValidator *BlockValidator) *moduleRootNodeResolver {
+	return &moduleRootNodeResolver{
+		rollup:           rollup,
+		l2Blockchain:     l2Blockchain,
+		blockValidator:   blockValidator,
+		confirmed:        make(map[uint64]bool),
+		afterBlockHashes: make(map[uint64]common.Hash),
+	}
+}
+
+// updateConfirmed walks back the confirmed nodes from latestConfirmed to
+// minNode. It stops at the latest confirmed node of the last walk, as the
+// nodes confirmed before it haven't changed.
+func (r *moduleRootNodeResolver) updateConfirmed(callOpts *bind.CallOpts, latestConfirmed uint64, minNode uint64) error {
+	if latestConfirmed == r.checkedAt && r.confirmed[latestConfirmed] {
+		return nil
+	}
+	for node := latestConfirmed; ; {
+		if node == r.checkedAt && r.confirmed[node] {
+			break
+		}
+		r.confirmed[node] = true
+		if node <= minNode {
+			break
+		}
+		nodeState, err := r.rollup.GetNode(callOpts, node)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		node = nodeState.PrevNum
+	}
+	r.checkedAt = latestConfirmed
+	return nil
+}
+
+func (r *moduleRootNodeResolver) resolve(ctx context.Context, callOpts *bind.CallOpts) error {
+	nodes := r.blockValidator.UnresolvedModuleRootNodes()
+	if len(nodes) == 0 {
+		return nil
+	}
+	latestConfirmed, err := r.rollup.LatestConfirmed(callOpts)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	minNode := nodes[0]
+	for _, node := range nodes {
+		if node < minNode {
+			minNode = node
+		}
+	}
+	if minNode > latestConfirmed {
+		return nil
+	}
+	// Nodes at or before the latest confirmed one were either confirmed, and
+	// so are its ancestors, or rejected.
+	if err := r.updateConfirmed(callOpts, latestConfirmed, minNode); err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if node > latestConfirmed {
+			continue
+		}
+		if !r.confirmed[node] {
+			if err := r.blockValidator.ResolveModuleRootNode(node, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		afterBlockHash, ok := r.afterBlockHashes[node]
+		if !ok {
+			nodeInfo, err := r.rollup.LookupNode(ctx, node)
+			if err != nil {
+				return err
+			}
+			afterBlockHash = nodeInfo.AfterState().GlobalState.BlockHash
+			r.afterBlockHashes[node] = afterBlockHash
+		}
+		header := r.l2Blockchain.GetHeaderByHash(afterBlockHash)
+		if header == nil {
+			// We'll try again once we've caught up to the node.
+			log.Info("waiting to catch up to module root activation node", "node", node, "blockHash", afterBlockHash)
+			continue
+		}
+		if err := r.blockValidator.ResolveModuleRootNode(node, header); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -229,10 +359,14 @@ type OurStakerInfo struct {
 // Returns (block number, global state inbox position is invalid, error).
 // If global state is invalid, block number is set to the last of the batch.
 func (v *L1Validator) blockNumberFromGlobalState(gs GoGlobalState) (int64, bool, error) {
+	return blockNumberFromGlobalState(v.inboxTracker, v.genesisBlockNumber, gs)
+}
+
+func blockNumberFromGlobalState(inboxTracker InboxTrackerInterface, genesisBlockNumber uint64, gs GoGlobalState) (int64, bool, error) {
 	var batchHeight arbutil.MessageIndex
 	if gs.Batch > 0 {
 		var err error
-		batchHeight, err = v.inboxTracker.GetBatchMessageCount(gs.Batch - 1)
+		batchHeight, err = inboxTracker.GetBatchMessageCount(gs.Batch - 1)
 		if err != nil {
 			return 0, false, err
 		}
@@ -240,7 +374,7 @@ func (v *L1Validator) blockNumberFromGlobalState(gs GoGlobalState) (int64, bool,
 
 	// Validate the PosInBatch if it's non-zero
 	if gs.PosInBatch > 0 {
-		nextBatchHeight, err := v.inboxTracker.GetBatchMessageCount(gs.Batch)
+		nextBatchHeight, err := inboxTracker.GetBatchMessageCount(gs.Batch)
 		if err != nil {
 			return 0, false, err
 		}
@@ -248,11 +382,11 @@ func (v *L1Validator) blockNumberFromGlobalState(gs GoGlobalState) (int64, bool,
 		if gs.PosInBatch >= uint64(nextBatchHeight-batchHeight) {
 			// This PosInBatch would enter the next batch. Return the last block before the next batch.
 			// We can be sure that MessageCountToBlockNumber will return a non-negative number as nextBatchHeight must be nonzero.
-			return arbutil.MessageCountToBlockNumber(nextBatchHeight, v.genesisBlockNumber), true, nil
+			return arbutil.MessageCountToBlockNumber(nextBatchHeight, genesisBlockNumber), true, nil
 		}
 	}
 
-	return arbutil.MessageCountToBlockNumber(batchHeight+arbutil.MessageIndex(gs.PosInBatch), v.genesisBlockNumber), false, nil
+	return arbutil.MessageCountToBlockNumber(batchHeight+arbutil.MessageIndex(gs.PosInBatch), genesisBlockNumber), false, nil
 }
 
 func (v *L1Validator) generateNodeAction(ctx context.Context, stakerInfo *OurStakerInfo, strategy StakerStrategy, makeAssertionInterval time.Duration) (nodeAction, bool, error) {
@@ -448,6 +582,21 @@ func (v *L1Validator) generateNodeAction(ctx context.Context, stakerInfo *OurSta
 		}
 		// If we've hit this point, the node is "wrong"
 		wrongNodesExist = true
+		nodeState, err := v.rollup.GetNode(v.getCallOpts(ctx), nd.NodeNum)
+		if err != nil {
+			return nil, false, errors.WithStack(err)
+		}
+		if v.wrongNodeDeadline == nil || nodeState.DeadlineBlock < *v.wrongNodeDeadline {
+			deadline := nodeState.DeadlineBlock
+			v.wrongNodeDeadline = &deadline
+		}
+		nodeNum := nd.NodeNum
+		v.notify(StakerEvent{
+			Type:    StakerEventBadAssertion,
+			Key:     nd.NodeHash.String(),
+			Message: fmt.Sprintf("rollup node %v has an incorrect assertion", nodeNum),
+			Node:    &nodeNum,
+		})
 	}
 
 	if correctNode != nil || strategy == WatchtowerStrategy {
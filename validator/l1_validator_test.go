@@ -0,0 +1,160 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/solgen/go/rollupgen"
+)
+
+type testRollupNode struct {
+	prev           uint64
+	afterBlockHash common.Hash
+}
+
+// testModuleRootNodeReader is a rollup of nodes, counting the reads of them.
+type testModuleRootNodeReader struct {
+	latestConfirmed uint64
+	nodes           map[uint64]testRollupNode
+	getNodeCalls    int
+	lookupNodeCalls int
+}
+
+func (r *testModuleRootNodeReader) LatestConfirmed(*bind.CallOpts) (uint64, error) {
+	return r.latestConfirmed, nil
+}
+
+func (r *testModuleRootNodeReader) GetNode(_ *bind.CallOpts, nodeNum uint64) (rollupgen.Node, error) {
+	r.getNodeCalls++
+	node, ok := r.nodes[nodeNum]
+	if !ok {
+		return rollupgen.Node{}, fmt.Errorf("no node %v", nodeNum)
+	}
+	return rollupgen.Node{PrevNum: node.prev}, nil
+}
+
+func (r *testModuleRootNodeReader) LookupNode(_ context.Context, number uint64) (*NodeInfo, error) {
+	r.lookupNodeCalls++
+	node, ok := r.nodes[number]
+	if !ok {
+		return nil, fmt.Errorf("no node %v", number)
+	}
+	return &NodeInfo{
+		NodeNum: number,
+		Assertion: &Assertion{
+			AfterState: &ExecutionState{
+				GlobalState:   GoGlobalState{BlockHash: node.afterBlockHash},
+				MachineStatus: MachineStatusFinished,
+			},
+		},
+	}, nil
+}
+
+func checkValidatorRoots(t *testing.T, v *BlockValidator, blockNum uint64, expected ...common.Hash) {
+	t.Helper()
+	header := v.blockchain.GetHeaderByNumber(blockNum)
+	if header == nil {
+		Fail(t, "no block", blockNum)
+	}
+	roots, err := v.ModuleRootsForBlock(header)
+	Require(t, err)
+	if len(roots) != len(expected) {
+		Fail(t, "block", blockNum, "has module roots", roots, "expected", expected)
+	}
+	for i := range roots {
+		if roots[i] != expected[i] {
+			Fail(t, "block", blockNum, "has module roots", roots, "expected", expected)
+		}
+	}
+}
+
+func TestResolveModuleRootNodes(t *testing.T) {
+	ctx := context.Background()
+	genesisRoot := common.HexToHash("0x01")
+	confirmedRoot := common.HexToHash("0x02")
+	rejectedRoot := common.HexToHash("0x03")
+	schedule, err := ParseModuleRootSchedule(fmt.Sprintf(`[
+		{"moduleRoot": "%v"},
+		{"moduleRoot": "%v", "node": 5},
+		{"moduleRoot": "%v", "node": 7}
+	]`, genesisRoot, confirmedRoot, rejectedRoot))
+	Require(t, err)
+	// The local chain hasn't caught up to the last block node 5 asserts yet.
+	bc, blocks := newTestBlockChain(t, 10, 6)
+	v := &BlockValidator{
+		StatelessBlockValidator: &StatelessBlockValidator{
+			blockchain:         bc,
+			moduleRootSchedule: schedule,
+		},
+	}
+	// Node 7 was rejected, as the confirmed node 8 builds on node 6.
+	rollup := &testModuleRootNodeReader{
+		latestConfirmed: 3,
+		nodes: map[uint64]testRollupNode{
+			4: {prev: 3},
+			5: {prev: 4, afterBlockHash: blocks[7].Hash()},
+			6: {prev: 5},
+			7: {prev: 6},
+			8: {prev: 6},
+			9: {prev: 8},
+		},
+	}
+	resolver := newModuleRootNodeResolver(rollup, bc, v)
+	checkReads := func(getNodeCalls int, lookupNodeCalls int) {
+		t.Helper()
+		if rollup.getNodeCalls != getNodeCalls || rollup.lookupNodeCalls != lookupNodeCalls {
+			Fail(t, "read", rollup.getNodeCalls, "nodes and looked up", rollup.lookupNodeCalls, "expected", getNodeCalls, "and", lookupNodeCalls)
+		}
+	}
+
+	// Before the nodes are confirmed, blocks are validated against their roots too.
+	Require(t, resolver.resolve(ctx, &bind.CallOpts{}))
+	checkReads(0, 0)
+	checkValidatorRoots(t, v, 6, genesisRoot, confirmedRoot, rejectedRoot)
+
+	rollup.latestConfirmed = 8
+	Require(t, resolver.resolve(ctx, &bind.CallOpts{}))
+	checkReads(2, 1)
+	unresolved := v.UnresolvedModuleRootNodes()
+	if len(unresolved) != 1 || unresolved[0] != 5 {
+		Fail(t, "unresolved nodes", unresolved, "expected [5]")
+	}
+	checkValidatorRoots(t, v, 6, genesisRoot, confirmedRoot)
+
+	// Nothing is read again while waiting to catch up to node 5.
+	Require(t, resolver.resolve(ctx, &bind.CallOpts{}))
+	checkReads(2, 1)
+
+	// Only the newly confirmed nodes are read.
+	rollup.latestConfirmed = 9
+	Require(t, resolver.resolve(ctx, &bind.CallOpts{}))
+	checkReads(3, 1)
+
+	_, err = bc.InsertChain(blocks[6:])
+	Require(t, err)
+	Require(t, resolver.resolve(ctx, &bind.CallOpts{}))
+	checkReads(3, 1)
+	if unresolved := v.UnresolvedModuleRootNodes(); len(unresolved) != 0 {
+		Fail(t, "unresolved nodes", unresolved, "expected none")
+	}
+	checkValidatorRoots(t, v, 8, genesisRoot)
+	checkValidatorRoots(t, v, 9, confirmedRoot)
+
+	// With no nodes left to resolve, the rollup isn't read.
+	rollup.latestConfirmed = 10
+	Require(t, resolver.resolve(ctx, &bind.CallOpts{}))
+	checkReads(3, 1)
+
+	// The rollup's module root must be one of the schedule's.
+	Require(t, v.SetCurrentWasmModuleRoot(confirmedRoot))
+	if err := v.SetCurrentWasmModuleRoot(common.HexToHash("0x04")); err == nil {
+		Fail(t, "set a current module root not in the schedule")
+	}
+}
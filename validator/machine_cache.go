@@ -17,6 +17,7 @@ type MachineCache struct {
 	firstMachineStep    uint64
 	machineStepInterval uint64
 	targetNumMachines   int
+	snapshots           *MachineSnapshots // may be nil
 }
 
 // `initialMachine` won't be mutated by this function.
@@ -35,7 +36,8 @@ func NewMachineCache(ctx context.Context, initialMachine MachineInterface, targe
 
 // `endSteps` should be the *total* step count at which the cache ends, not the number of steps from `initialMachine` to the end.
 // `initialMachine` may be mutated by this function.
-func NewMachineCacheWithEndSteps(ctx context.Context, initialMachine MachineInterface, targetNumMachines int, endSteps uint64) (*MachineCache, error) {
+// `snapshots` may be nil, otherwise machines are loaded from and written to it.
+func NewMachineCacheWithEndSteps(ctx context.Context, initialMachine MachineInterface, targetNumMachines int, endSteps uint64, snapshots *MachineSnapshots) (*MachineCache, error) {
 	startSteps := initialMachine.GetStepCount()
 	if endSteps < startSteps {
 		return nil, errors.Errorf("endSteps %v before initialMachine step count %v", endSteps, startSteps)
@@ -45,16 +47,19 @@ func NewMachineCacheWithEndSteps(ctx context.Context, initialMachine MachineInte
 		targetNumMachines:   targetNumMachines,
 		firstMachineStep:    startSteps,
 		machineStepInterval: (endSteps - startSteps) / uint64(targetNumMachines+1),
+		snapshots:           snapshots,
 	}
+	mach := initialMachine
 	for i := 1; i < targetNumMachines; i++ {
-		if !initialMachine.IsRunning() {
+		if !mach.IsRunning() {
 			break
 		}
-		err := initialMachine.Step(ctx, cache.machineStepInterval)
+		var err error
+		mach, err = cache.advance(ctx, mach, mach.GetStepCount()+cache.machineStepInterval)
 		if err != nil {
 			return nil, err
 		}
-		cache.machines = append(cache.machines, initialMachine.CloneMachineInterface())
+		cache.machines = append(cache.machines, mach.CloneMachineInterface())
 	}
 	return cache, nil
 }
@@ -100,6 +105,19 @@ func (c *MachineCache) populateInitialCache(ctx context.Context) error {
 	return nil
 }
 
+// advance steps `mach` to `stepCount` through the disk tier if there is one.
+// `mach` may be mutated, and the machine at `stepCount` is returned.
+func (c *MachineCache) advance(ctx context.Context, mach MachineInterface, stepCount uint64) (MachineInterface, error) {
+	if c.snapshots != nil {
+		return c.snapshots.advance(ctx, mach, stepCount)
+	}
+	err := mach.Step(ctx, stepCount-mach.GetStepCount())
+	if err != nil {
+		return nil, err
+	}
+	return mach, nil
+}
+
 // Warning: don't mutate the result of this!
 func (c *MachineCache) getClosestMachine(stepCount uint64) (MachineInterface, error) {
 	if stepCount < c.firstMachineStep {
@@ -124,7 +142,7 @@ func (c *MachineCache) GetMachineAt(ctx context.Context, haveMachine MachineInte
 	} else {
 		closestMachine = closestMachine.CloneMachineInterface()
 	}
-	err = closestMachine.Step(ctx, stepCount-closestMachine.GetStepCount())
+	closestMachine, err = c.advance(ctx, closestMachine, stepCount)
 	if err != nil {
 		return nil, err
 	}
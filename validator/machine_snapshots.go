@@ -0,0 +1,222 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+	flag "github.com/spf13/pflag"
+)
+
+type MachineCacheDiskConfig struct {
+	Enable           bool   `koanf:"enable"`
+	Dir              string `koanf:"dir"`
+	SnapshotInterval uint64 `koanf:"snapshot-interval"`
+}
+
+var DefaultMachineCacheDiskConfig = MachineCacheDiskConfig{
+	Enable:           false,
+	Dir:              "./target/machine-snapshots",
+	SnapshotInterval: 1 << 30,
+}
+
+func MachineCacheDiskConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultMachineCacheDiskConfig.Enable, "write snapshots of the execution challenge machine to disk, so they're reused after a restart")
+	f.String(prefix+".dir", DefaultMachineCacheDiskConfig.Dir, "directory to write machine snapshots to, in a subdirectory per challenge")
+	f.Uint64(prefix+".snapshot-interval", DefaultMachineCacheDiskConfig.SnapshotInterval, "number of machine steps between snapshots")
+}
+
+// The machines that can be written to disk and read back.
+type snapshotMachine interface {
+	MachineInterface
+	SerializeState(path string) error
+	DeserializeAndReplaceState(path string) error
+}
+
+const machineSnapshotSuffix = ".snapshot"
+const challengeSnapshotsPrefix = "challenge-"
+
+// MachineSnapshots is the disk tier of a MachineCache: snapshots of a
+// challenge's machine every interval steps, in a directory per challenge and
+// initial machine so that they're only ever loaded into the machine they were
+// taken from.
+type MachineSnapshots struct {
+	dir         string
+	interval    uint64
+	baseMachine MachineInterface
+
+	mutex sync.Mutex
+	steps []uint64 // sorted
+}
+
+func challengeSnapshotsDir(dir string, challengeIndex uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%d", challengeSnapshotsPrefix, challengeIndex))
+}
+
+// OpenMachineSnapshots picks up the snapshots already written for the
+// challenge's initial machine. It returns nil if the disk tier is disabled.
+// `initialMachine` won't be mutated, and snapshots are loaded into clones of it.
+func OpenMachineSnapshots(config *MachineCacheDiskConfig, challengeIndex uint64, initialMachine MachineInterface) (*MachineSnapshots, error) {
+	if !config.Enable {
+		return nil, nil
+	}
+	if config.SnapshotInterval == 0 {
+		return nil, errors.New("machine snapshot interval must be positive")
+	}
+	if _, ok := initialMachine.(snapshotMachine); !ok {
+		return nil, errors.Errorf("machine snapshots unsupported for %T", initialMachine)
+	}
+	dir := filepath.Join(challengeSnapshotsDir(config.Dir, challengeIndex), initialMachine.Hash().String())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &MachineSnapshots{
+		dir:         dir,
+		interval:    config.SnapshotInterval,
+		baseMachine: initialMachine,
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, machineSnapshotSuffix) {
+			continue
+		}
+		step, err := strconv.ParseUint(strings.TrimSuffix(name, machineSnapshotSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.steps = append(s.steps, step)
+	}
+	sort.Slice(s.steps, func(i, j int) bool { return s.steps[i] < s.steps[j] })
+	if len(s.steps) > 0 {
+		log.Info("found machine snapshots", "challenge", challengeIndex, "count", len(s.steps), "lastStep", s.steps[len(s.steps)-1])
+	}
+	return s, nil
+}
+
+func (s *MachineSnapshots) path(step uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d%s", step, machineSnapshotSuffix))
+}
+
+// nearest returns the step count of the last snapshot at or before stepCount.
+func (s *MachineSnapshots) nearest(stepCount uint64) (uint64, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := sort.Search(len(s.steps), func(i int) bool { return s.steps[i] > stepCount })
+	if i == 0 {
+		return 0, false
+	}
+	return s.steps[i-1], true
+}
+
+func (s *MachineSnapshots) has(step uint64) bool {
+	nearest, ok := s.nearest(step)
+	return ok && nearest == step
+}
+
+func (s *MachineSnapshots) load(step uint64) (MachineInterface, error) {
+	mach := s.baseMachine.CloneMachineInterface().(snapshotMachine)
+	if err := mach.DeserializeAndReplaceState(s.path(step)); err != nil {
+		return nil, err
+	}
+	if mach.GetStepCount() != step {
+		return nil, errors.Errorf("machine snapshot for step %v is at step %v", step, mach.GetStepCount())
+	}
+	return mach, nil
+}
+
+func (s *MachineSnapshots) save(mach snapshotMachine) error {
+	step := mach.GetStepCount()
+	if s.has(step) {
+		return nil
+	}
+	// Write to a temporary file first so an interrupted write isn't picked up as a snapshot.
+	tmpPath := s.path(step) + ".tmp"
+	if err := mach.SerializeState(tmpPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path(step)); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := sort.Search(len(s.steps), func(i int) bool { return s.steps[i] >= step })
+	s.steps = append(s.steps, 0)
+	copy(s.steps[i+1:], s.steps[i:])
+	s.steps[i] = step
+	return nil
+}
+
+// advance steps `mach` to `stepCount`, first jumping to the nearest snapshot if
+// it's further along, and snapshotting each interval crossed on the way.
+// `mach` may be mutated, and the machine at `stepCount` is returned.
+func (s *MachineSnapshots) advance(ctx context.Context, mach MachineInterface, stepCount uint64) (MachineInterface, error) {
+	if step, ok := s.nearest(stepCount); ok && step > mach.GetStepCount() {
+		loaded, err := s.load(step)
+		if err == nil {
+			mach = loaded
+		} else {
+			log.Warn("failed to load machine snapshot", "step", step, "err", err)
+		}
+	}
+	for mach.GetStepCount() < stepCount && mach.IsRunning() {
+		current := mach.GetStepCount()
+		next := (current/s.interval + 1) * s.interval
+		if next > stepCount || next < current {
+			next = stepCount
+		}
+		if err := mach.Step(ctx, next-current); err != nil {
+			return nil, err
+		}
+		if mach.GetStepCount()%s.interval != 0 || !mach.IsRunning() {
+			continue
+		}
+		if err := s.save(mach.(snapshotMachine)); err != nil {
+			log.Warn("failed to write machine snapshot", "step", mach.GetStepCount(), "err", err)
+		}
+	}
+	return mach, nil
+}
+
+// RemoveMachineSnapshots deletes the snapshots of every challenge in the
+// directory other than `keepChallenge`, which may be nil to delete them all.
+func RemoveMachineSnapshots(config *MachineCacheDiskConfig, keepChallenge *uint64) error {
+	if !config.Enable {
+		return nil
+	}
+	entries, err := os.ReadDir(config.Dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || !strings.HasPrefix(name, challengeSnapshotsPrefix) {
+			continue
+		}
+		challengeIndex, err := strconv.ParseUint(strings.TrimPrefix(name, challengeSnapshotsPrefix), 10, 64)
+		if err != nil || (keepChallenge != nil && challengeIndex == *keepChallenge) {
+			continue
+		}
+		log.Info("removing machine snapshots of resolved challenge", "challenge", challengeIndex)
+		if err := os.RemoveAll(filepath.Join(config.Dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
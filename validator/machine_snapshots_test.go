@@ -0,0 +1,129 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+// countingMachine runs for a fixed number of steps, and counts the steps it
+// actually executes so tests can tell when snapshots saved work.
+type countingMachine struct {
+	endStep   uint64
+	stepCount uint64
+	executed  *uint64
+}
+
+var _ snapshotMachine = (*countingMachine)(nil)
+
+func (m *countingMachine) CloneMachineInterface() MachineInterface {
+	clone := *m
+	return &clone
+}
+
+func (m *countingMachine) GetStepCount() uint64 { return m.stepCount }
+
+func (m *countingMachine) IsRunning() bool { return m.stepCount < m.endStep }
+
+func (m *countingMachine) ValidForStep(step uint64) bool {
+	return m.stepCount == step || (!m.IsRunning() && m.stepCount < step)
+}
+
+func (m *countingMachine) Step(ctx context.Context, count uint64) error {
+	if count > m.endStep-m.stepCount {
+		count = m.endStep - m.stepCount
+	}
+	m.stepCount += count
+	*m.executed += count
+	return nil
+}
+
+func (m *countingMachine) Hash() common.Hash {
+	return crypto.Keccak256Hash(arbmath.UintToBytes(m.stepCount))
+}
+
+func (m *countingMachine) GetGlobalState() GoGlobalState {
+	return GoGlobalState{PosInBatch: m.stepCount}
+}
+
+func (m *countingMachine) ProveNextStep() []byte { return nil }
+
+func (m *countingMachine) SerializeState(path string) error {
+	return os.WriteFile(path, arbmath.UintToBytes(m.stepCount), 0644)
+}
+
+func (m *countingMachine) DeserializeAndReplaceState(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	m.stepCount = binary.BigEndian.Uint64(data)
+	return nil
+}
+
+func TestMachineCacheSnapshots(t *testing.T) {
+	ctx := context.Background()
+	config := MachineCacheDiskConfig{
+		Enable:           true,
+		Dir:              t.TempDir(),
+		SnapshotInterval: 100,
+	}
+	const challengeIndex = 3
+	var executed uint64
+	initialMachine := &countingMachine{endStep: 1050, executed: &executed}
+
+	snapshots, err := OpenMachineSnapshots(&config, challengeIndex, initialMachine)
+	Require(t, err)
+	cache, err := NewMachineCacheWithEndSteps(ctx, initialMachine.CloneMachineInterface(), 4, 1000, snapshots)
+	Require(t, err)
+	mach, err := cache.GetMachineAt(ctx, nil, 1040)
+	Require(t, err)
+	if mach.GetStepCount() != 1040 {
+		Fail(t, "got machine at step", mach.GetStepCount())
+	}
+	for step := uint64(100); step <= 1000; step += 100 {
+		if !snapshots.has(step) {
+			Fail(t, "missing snapshot at step", step)
+		}
+	}
+
+	// After a restart, the snapshots are picked up rather than executing from the start.
+	executed = 0
+	snapshots, err = OpenMachineSnapshots(&config, challengeIndex, initialMachine)
+	Require(t, err)
+	cache, err = NewMachineCacheWithEndSteps(ctx, initialMachine.CloneMachineInterface(), 4, 1000, snapshots)
+	Require(t, err)
+	mach, err = cache.GetMachineAt(ctx, nil, 950)
+	Require(t, err)
+	if mach.GetStepCount() != 950 {
+		Fail(t, "got machine at step", mach.GetStepCount())
+	}
+	if executed > 200 {
+		Fail(t, "executed", executed, "steps despite the snapshots")
+	}
+
+	// Only the snapshots of the current challenge are kept.
+	otherIndex := uint64(challengeIndex + 1)
+	_, err = OpenMachineSnapshots(&config, otherIndex, initialMachine)
+	Require(t, err)
+	Require(t, RemoveMachineSnapshots(&config, &otherIndex))
+	if _, err := os.Stat(challengeSnapshotsDir(config.Dir, challengeIndex)); !os.IsNotExist(err) {
+		Fail(t, "snapshots of resolved challenge not removed", err)
+	}
+	Require(t, RemoveMachineSnapshots(&config, nil))
+	entries, err := os.ReadDir(config.Dir)
+	Require(t, err)
+	if len(entries) != 0 {
+		Fail(t, "snapshots left after all challenges resolved:", filepath.Join(config.Dir, entries[0].Name()))
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// ModuleRootActivation is when blocks start being validated against a wasm
+// module root: from genesis if it has no activation, from the first block
+// with an L1 block number of at least L1Block, or after the last block
+// asserted by the rollup node Node once that node is confirmed.
+type ModuleRootActivation struct {
+	ModuleRoot common.Hash `json:"moduleRoot"`
+	L1Block    *uint64     `json:"l1Block,omitempty"`
+	Node       *uint64     `json:"node,omitempty"`
+	// For how many L1 blocks after the activation blocks are still validated
+	// against the previous module root as well.
+	OverlapL1Blocks uint64 `json:"overlapL1Blocks,omitempty"`
+}
+
+type scheduledModuleRoot struct {
+	ModuleRootActivation
+	// Node activations are resolved once the node is confirmed, to the last
+	// block it asserted and that block's L1 block number, or never activate if
+	// the node is rejected.
+	resolved   bool
+	rejected   bool
+	afterBlock uint64
+	l1Block    uint64
+}
+
+func (r *scheduledModuleRoot) activeFor(blockNum uint64, l1Block uint64) bool {
+	switch {
+	case r.L1Block != nil:
+		return l1Block >= *r.L1Block
+	case r.Node != nil:
+		return r.resolved && !r.rejected && blockNum > r.afterBlock
+	default:
+		return true
+	}
+}
+
+// activatedAt returns the L1 block the module root activated at, which for a
+// node activation is that of the last block the node asserted.
+func (r *scheduledModuleRoot) activatedAt() uint64 {
+	if r.L1Block != nil {
+		return *r.L1Block
+	}
+	return r.l1Block
+}
+
+func (r *scheduledModuleRoot) inOverlap(l1Block uint64) bool {
+	return l1Block < r.activatedAt()+r.OverlapL1Blocks
+}
+
+// ModuleRootSchedule is the wasm module roots to validate each block against,
+// as they change at upgrades.
+type ModuleRootSchedule struct {
+	mutex sync.Mutex
+	roots []*scheduledModuleRoot
+}
+
+// ParseModuleRootSchedule parses a JSON list of module root activations, the
+// first of which must have no activation. The L1 block activations must be in
+// the order they activate, as must the node activations.
+func ParseModuleRootSchedule(config string) (*ModuleRootSchedule, error) {
+	var activations []ModuleRootActivation
+	if err := json.Unmarshal([]byte(config), &activations); err != nil {
+		return nil, fmt.Errorf("failed to parse module root schedule: %w", err)
+	}
+	return NewModuleRootSchedule(activations)
+}
+
+func NewModuleRootSchedule(activations []ModuleRootActivation) (*ModuleRootSchedule, error) {
+	if len(activations) == 0 {
+		return nil, errors.New("module root schedule is empty")
+	}
+	schedule := &ModuleRootSchedule{}
+	var lastL1Block, lastNode *uint64
+	for i, activation := range activations {
+		if activation.ModuleRoot == (common.Hash{}) {
+			return nil, fmt.Errorf("module root schedule entry %v has no module root", i)
+		}
+		if activation.L1Block != nil && activation.Node != nil {
+			return nil, fmt.Errorf("module root %v activates at both an L1 block and a node", activation.ModuleRoot)
+		}
+		hasActivation := activation.L1Block != nil || activation.Node != nil
+		if i == 0 && hasActivation {
+			return nil, fmt.Errorf("first module root %v must be active from genesis", activation.ModuleRoot)
+		}
+		if i > 0 && !hasActivation {
+			return nil, fmt.Errorf("module root %v has no activation", activation.ModuleRoot)
+		}
+		if activation.L1Block != nil {
+			if lastL1Block != nil && *activation.L1Block <= *lastL1Block {
+				return nil, fmt.Errorf("module root %v activates at L1 block %v, not after the previous one", activation.ModuleRoot, *activation.L1Block)
+			}
+			lastL1Block = activation.L1Block
+		}
+		if activation.Node != nil {
+			if lastNode != nil && *activation.Node <= *lastNode {
+				return nil, fmt.Errorf("module root %v activates at node %v, not after the previous one", activation.ModuleRoot, *activation.Node)
+			}
+			lastNode = activation.Node
+		}
+		schedule.roots = append(schedule.roots, &scheduledModuleRoot{ModuleRootActivation: activation})
+	}
+	return schedule, nil
+}
+
+// ModuleRoots returns every module root in the schedule.
+func (s *ModuleRootSchedule) ModuleRoots() []common.Hash {
+	var roots []common.Hash
+	for _, root := range s.roots {
+		roots = appendModuleRoot(roots, root.ModuleRoot)
+	}
+	return roots
+}
+
+func appendModuleRoot(roots []common.Hash, root common.Hash) []common.Hash {
+	for _, have := range roots {
+		if have == root {
+			return roots
+		}
+	}
+	return append(roots, root)
+}
+
+// RootsForBlock returns the module roots to validate a block against: the one
+// active at it, the previous one within the overlap window, and any whose node
+// isn't confirmed yet. The latter might turn out to be active at the block,
+// which would be too late to find out after validating it.
+//
+// With both L1 block and node activations, more than one module root may be
+// active at a block, whatever their order in the schedule. The one that
+// activated at the latest L1 block applies, and the one before it is the
+// previous one.
+func (s *ModuleRootSchedule) RootsForBlock(blockNum uint64, l1Block uint64) []common.Hash {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// The first root is active from genesis.
+	active, previous := 0, -1
+	for i := 1; i < len(s.roots); i++ {
+		root := s.roots[i]
+		if !root.activeFor(blockNum, l1Block) {
+			continue
+		}
+		if root.activatedAt() >= s.roots[active].activatedAt() {
+			active, previous = i, active
+		} else if previous < 0 || root.activatedAt() >= s.roots[previous].activatedAt() {
+			previous = i
+		}
+	}
+	roots := []common.Hash{s.roots[active].ModuleRoot}
+	if previous >= 0 && s.roots[active].inOverlap(l1Block) {
+		roots = appendModuleRoot(roots, s.roots[previous].ModuleRoot)
+	}
+	for _, root := range s.roots {
+		if root.Node != nil && !root.resolved {
+			roots = appendModuleRoot(roots, root.ModuleRoot)
+		}
+	}
+	return roots
+}
+
+// UnresolvedNodes returns the nodes of node activations that aren't yet known
+// to be confirmed or rejected.
+func (s *ModuleRootSchedule) UnresolvedNodes() []uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var nodes []uint64
+	for _, root := range s.roots {
+		if root.Node != nil && !root.resolved {
+			nodes = append(nodes, *root.Node)
+		}
+	}
+	return nodes
+}
+
+// ResolveNode activates the module root of a confirmed node after the last
+// block the node asserted, or never if the node was rejected.
+func (s *ModuleRootSchedule) ResolveNode(node uint64, rejected bool, afterBlock uint64, l1Block uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, root := range s.roots {
+		if root.Node == nil || *root.Node != node {
+			continue
+		}
+		root.resolved = true
+		root.rejected = rejected
+		root.afterBlock = afterBlock
+		root.l1Block = l1Block
+	}
+}
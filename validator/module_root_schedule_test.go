@@ -0,0 +1,113 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func checkRootsForBlock(t *testing.T, schedule *ModuleRootSchedule, blockNum uint64, l1Block uint64, expected ...common.Hash) {
+	t.Helper()
+	roots := schedule.RootsForBlock(blockNum, l1Block)
+	if len(roots) != len(expected) {
+		Fail(t, "block", blockNum, "at L1 block", l1Block, "has module roots", roots, "expected", expected)
+	}
+	for i := range roots {
+		if roots[i] != expected[i] {
+			Fail(t, "block", blockNum, "at L1 block", l1Block, "has module roots", roots, "expected", expected)
+		}
+	}
+}
+
+func TestModuleRootSchedule(t *testing.T) {
+	genesisRoot := common.HexToHash("0x01")
+	l1Root := common.HexToHash("0x02")
+	nodeRoot := common.HexToHash("0x03")
+	schedule, err := ParseModuleRootSchedule(fmt.Sprintf(`[
+		{"moduleRoot": "%v"},
+		{"moduleRoot": "%v", "l1Block": 100, "overlapL1Blocks": 10},
+		{"moduleRoot": "%v", "node": 5, "overlapL1Blocks": 20}
+	]`, genesisRoot, l1Root, nodeRoot))
+	Require(t, err)
+
+	nodes := schedule.UnresolvedNodes()
+	if len(nodes) != 1 || nodes[0] != 5 {
+		Fail(t, "unresolved nodes", nodes, "expected [5]")
+	}
+	// Until node 5 is confirmed, its root might apply to any block.
+	checkRootsForBlock(t, schedule, 1, 50, genesisRoot, nodeRoot)
+	checkRootsForBlock(t, schedule, 2, 100, l1Root, genesisRoot, nodeRoot)
+	checkRootsForBlock(t, schedule, 3, 109, l1Root, genesisRoot, nodeRoot)
+	checkRootsForBlock(t, schedule, 4, 110, l1Root, nodeRoot)
+
+	schedule.ResolveNode(5, false, 10, 200)
+	if len(schedule.UnresolvedNodes()) != 0 {
+		Fail(t, "node 5 still unresolved")
+	}
+	checkRootsForBlock(t, schedule, 1, 50, genesisRoot)
+	checkRootsForBlock(t, schedule, 10, 200, l1Root)
+	checkRootsForBlock(t, schedule, 11, 200, nodeRoot, l1Root)
+	checkRootsForBlock(t, schedule, 12, 219, nodeRoot, l1Root)
+	checkRootsForBlock(t, schedule, 13, 220, nodeRoot)
+
+	// A rejected node's root never activates.
+	schedule.ResolveNode(5, true, 0, 0)
+	checkRootsForBlock(t, schedule, 13, 220, l1Root)
+}
+
+func TestModuleRootScheduleMixedActivations(t *testing.T) {
+	genesisRoot := common.HexToHash("0x01")
+	l1Root := common.HexToHash("0x02")
+	nodeRoot := common.HexToHash("0x03")
+	lateL1Root := common.HexToHash("0x04")
+	schedule, err := ParseModuleRootSchedule(fmt.Sprintf(`[
+		{"moduleRoot": "%v"},
+		{"moduleRoot": "%v", "l1Block": 100, "overlapL1Blocks": 10},
+		{"moduleRoot": "%v", "node": 5, "overlapL1Blocks": 10},
+		{"moduleRoot": "%v", "l1Block": 300, "overlapL1Blocks": 10}
+	]`, genesisRoot, l1Root, nodeRoot, lateL1Root))
+	Require(t, err)
+
+	// Node 5 is confirmed before the first L1 block activation, so the L1 block
+	// activation applies after it despite coming first in the schedule.
+	schedule.ResolveNode(5, false, 10, 50)
+	checkRootsForBlock(t, schedule, 10, 50, genesisRoot)
+	checkRootsForBlock(t, schedule, 11, 50, nodeRoot, genesisRoot)
+	checkRootsForBlock(t, schedule, 12, 60, nodeRoot)
+	checkRootsForBlock(t, schedule, 13, 100, l1Root, nodeRoot)
+	checkRootsForBlock(t, schedule, 14, 110, l1Root)
+	checkRootsForBlock(t, schedule, 15, 300, lateL1Root, l1Root)
+	checkRootsForBlock(t, schedule, 16, 310, lateL1Root)
+
+	// Confirmed between the L1 block activations, node 5's activation applies
+	// between them.
+	schedule.ResolveNode(5, false, 20, 200)
+	checkRootsForBlock(t, schedule, 20, 200, l1Root)
+	checkRootsForBlock(t, schedule, 21, 200, nodeRoot, l1Root)
+	checkRootsForBlock(t, schedule, 22, 210, nodeRoot)
+	checkRootsForBlock(t, schedule, 23, 300, lateL1Root, nodeRoot)
+	checkRootsForBlock(t, schedule, 24, 310, lateL1Root)
+}
+
+func TestModuleRootScheduleInvalid(t *testing.T) {
+	root := func(b byte) string { return common.BytesToHash([]byte{b}).String() }
+	invalid := []string{
+		`[]`,
+		fmt.Sprintf(`[{"moduleRoot": "%v", "l1Block": 1}]`, root(1)),
+		fmt.Sprintf(`[{"moduleRoot": "%v"}, {"moduleRoot": "%v"}]`, root(1), root(2)),
+		fmt.Sprintf(`[{"moduleRoot": "%v"}, {"moduleRoot": "%v", "l1Block": 1, "node": 1}]`, root(1), root(2)),
+		fmt.Sprintf(`[{"moduleRoot": "%v"}, {"moduleRoot": "%v", "l1Block": 5}, {"moduleRoot": "%v", "l1Block": 5}]`, root(1), root(2), root(3)),
+		fmt.Sprintf(`[{"moduleRoot": "%v"}, {"moduleRoot": "%v", "node": 5}, {"moduleRoot": "%v", "node": 4}]`, root(1), root(2), root(3)),
+		fmt.Sprintf(`[{"moduleRoot": "%v"}]`, root(0)),
+		`not json`,
+	}
+	for _, config := range invalid {
+		if _, err := ParseModuleRootSchedule(config); err == nil {
+			Fail(t, "parsed invalid module root schedule", config)
+		}
+	}
+}
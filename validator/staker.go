@@ -20,6 +20,7 @@ import (
 	"github.com/pkg/errors"
 	flag "github.com/spf13/pflag"
 
+	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
 
@@ -37,32 +38,50 @@ const (
 )
 
 type L1PostingStrategy struct {
-	HighGasThreshold   float64 `koanf:"high-gas-threshold"`
-	HighGasDelayBlocks int64   `koanf:"high-gas-delay-blocks"`
+	HighGasThreshold      float64       `koanf:"high-gas-threshold"`
+	HighGasDelayBlocks    int64         `koanf:"high-gas-delay-blocks"`
+	DeadlineUrgencyBlocks uint64        `koanf:"deadline-urgency-blocks"`
+	MaxFeeMultiplier      float64       `koanf:"max-fee-multiplier"`
+	ReplaceAfterBlocks    uint64        `koanf:"replace-after-blocks"`
+	ReplacementFeeBump    uint64        `koanf:"replacement-fee-bump"`
+	L1BlockTime           time.Duration `koanf:"l1-block-time"`
 }
 
 var DefaultL1PostingStrategy = L1PostingStrategy{
-	HighGasThreshold:   0,
-	HighGasDelayBlocks: 0,
+	HighGasThreshold:      0,
+	HighGasDelayBlocks:    0,
+	DeadlineUrgencyBlocks: 0,
+	MaxFeeMultiplier:      4,
+	ReplaceAfterBlocks:    0,
+	ReplacementFeeBump:    25,
+	L1BlockTime:           12 * time.Second,
 }
 
 func L1PostingStrategyAddOptions(prefix string, f *flag.FlagSet) {
 	f.Float64(prefix+".high-gas-threshold", DefaultL1PostingStrategy.HighGasThreshold, "high gas threshold")
 	f.Int64(prefix+".high-gas-delay-blocks", DefaultL1PostingStrategy.HighGasDelayBlocks, "high gas delay blocks")
+	f.Uint64(prefix+".deadline-urgency-blocks", DefaultL1PostingStrategy.DeadlineUrgencyBlocks, "within this many L1 blocks of a challenge or confirmation deadline, act regardless of gas price and raise fees as the deadline approaches (0 to disable)")
+	f.Float64(prefix+".max-fee-multiplier", DefaultL1PostingStrategy.MaxFeeMultiplier, "multiple of the suggested fees to pay at a deadline, and the most a replacement transaction pays (0 for no limit on replacements)")
+	f.Uint64(prefix+".replace-after-blocks", DefaultL1PostingStrategy.ReplaceAfterBlocks, "replace a transaction with one of the same nonce and higher fees if it isn't mined after this many L1 blocks (0 to disable)")
+	f.Uint64(prefix+".replacement-fee-bump", DefaultL1PostingStrategy.ReplacementFeeBump, "percentage to raise the fees of a replacement transaction by (at least 10 for most L1 nodes to accept it)")
+	f.Duration(prefix+".l1-block-time", DefaultL1PostingStrategy.L1BlockTime, "expected L1 block time, to convert challenge time left into L1 blocks")
 }
 
 type L1ValidatorConfig struct {
-	Enable                   bool              `koanf:"enable"`
-	Strategy                 string            `koanf:"strategy"`
-	StakerInterval           time.Duration     `koanf:"staker-interval"`
-	MakeAssertionInterval    time.Duration     `koanf:"make-assertion-interval"`
-	L1PostingStrategy        L1PostingStrategy `koanf:"posting-strategy"`
-	DisableChallenge         bool              `koanf:"disable-challenge"`
-	TargetMachineCount       int               `koanf:"target-machine-count"`
-	ConfirmationBlocks       int64             `koanf:"confirmation-blocks"`
-	OnlyCreateWalletContract bool              `koanf:"only-create-wallet-contract"`
-	GasRefunderAddress       string            `koanf:"gas-refunder-address"`
-	Dangerous                DangerousConfig   `koanf:"dangerous"`
+	Enable                   bool                   `koanf:"enable"`
+	Strategy                 string                 `koanf:"strategy"`
+	StakerInterval           time.Duration          `koanf:"staker-interval"`
+	MakeAssertionInterval    time.Duration          `koanf:"make-assertion-interval"`
+	L1PostingStrategy        L1PostingStrategy      `koanf:"posting-strategy"`
+	DisableChallenge         bool                   `koanf:"disable-challenge"`
+	TargetMachineCount       int                    `koanf:"target-machine-count"`
+	MachineCacheDisk         MachineCacheDiskConfig `koanf:"machine-cache-disk"`
+	ConfirmationBlocks       int64                  `koanf:"confirmation-blocks"`
+	OnlyCreateWalletContract bool                   `koanf:"only-create-wallet-contract"`
+	GasRefunderAddress       string                 `koanf:"gas-refunder-address"`
+	ContractWalletAddress    string                 `koanf:"contract-wallet-address"`
+	Notifier                 StakerNotifierConfig   `koanf:"notifier"`
+	Dangerous                DangerousConfig        `koanf:"dangerous"`
 }
 
 var DefaultL1ValidatorConfig = L1ValidatorConfig{
@@ -70,12 +89,15 @@ var DefaultL1ValidatorConfig = L1ValidatorConfig{
 	Strategy:                 "Watchtower",
 	StakerInterval:           time.Minute,
 	MakeAssertionInterval:    time.Hour,
-	L1PostingStrategy:        L1PostingStrategy{},
+	L1PostingStrategy:        DefaultL1PostingStrategy,
 	DisableChallenge:         false,
 	TargetMachineCount:       4,
+	MachineCacheDisk:         DefaultMachineCacheDiskConfig,
 	ConfirmationBlocks:       12,
 	OnlyCreateWalletContract: false,
 	GasRefunderAddress:       "",
+	ContractWalletAddress:    "",
+	Notifier:                 DefaultStakerNotifierConfig,
 	Dangerous:                DefaultDangerousConfig,
 }
 
@@ -87,9 +109,12 @@ func L1ValidatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	L1PostingStrategyAddOptions(prefix+".posting-strategy", f)
 	f.Bool(prefix+".disable-challenge", DefaultL1ValidatorConfig.DisableChallenge, "disable validator challenge")
 	f.Int(prefix+".target-machine-count", DefaultL1ValidatorConfig.TargetMachineCount, "target machine count")
+	MachineCacheDiskConfigAddOptions(prefix+".machine-cache-disk", f)
 	f.Int64(prefix+".confirmation-blocks", DefaultL1ValidatorConfig.ConfirmationBlocks, "confirmation blocks")
 	f.Bool(prefix+".only-create-wallet-contract", DefaultL1ValidatorConfig.OnlyCreateWalletContract, "only create smart wallet contract and exit")
 	f.String(prefix+".gas-refunder-address", DefaultL1ValidatorConfig.GasRefunderAddress, "The gas refunder contract address (optional)")
+	f.String(prefix+".contract-wallet-address", DefaultL1ValidatorConfig.ContractWalletAddress, "validator smart contract wallet address, needed once its signer has been rotated (default is the one created by the signer)")
+	StakerNotifierConfigAddOptions(prefix+".notifier", f)
 	DangerousConfigAddOptions(prefix+".dangerous", f)
 }
 
@@ -124,6 +149,11 @@ type Staker struct {
 	bringActiveUntilNode    uint64
 	inboxReader             InboxReaderInterface
 	nitroMachineLoader      *NitroMachineLoader
+	notifier                *AlertingStakerNotifier // may be nil
+	challengeTurn           bool
+	challengeTurns          uint64
+	snapshotsCleaned        bool // whether stale machine snapshots were removed since startup
+	nextSigner              util.Signer
 }
 
 func stakerStrategyFromString(s string) (StakerStrategy, error) {
@@ -161,12 +191,15 @@ func NewStaker(
 	if len(config.GasRefunderAddress) > 0 && !common.IsHexAddress(config.GasRefunderAddress) {
 		return nil, errors.New("invalid validator gas refunder address")
 	}
+	if len(config.ContractWalletAddress) > 0 && !common.IsHexAddress(config.ContractWalletAddress) {
+		return nil, errors.New("invalid validator contract wallet address")
+	}
 	client := l1Reader.Client()
 	val, err := NewL1Validator(client, wallet, validatorUtilsAddress, callOpts, l2Blockchain, das, inboxTracker, txStreamer, blockValidator)
 	if err != nil {
 		return nil, err
 	}
-	return &Staker{
+	staker := &Staker{
 		L1Validator:         val,
 		l1Reader:            l1Reader,
 		strategy:            strategy,
@@ -176,7 +209,23 @@ func NewStaker(
 		lastActCalledBlock:  nil,
 		inboxReader:         inboxReader,
 		nitroMachineLoader:  nitroMachineLoader,
-	}, nil
+	}
+	if config.Notifier.Enabled() {
+		staker.notifier, err = NewAlertingStakerNotifier(&staker.config.Notifier)
+		if err != nil {
+			return nil, err
+		}
+		val.notifier = staker.notifier
+	}
+	return staker, nil
+}
+
+// RotateWallet has the staker hand its wallet over to the account of next
+// once its transactions in flight have been mined, and act from that account
+// from then on. The handover's transactions are sent and replaced like the
+// staker's others, before it acts again. It must be called before Start.
+func (s *Staker) RotateWallet(next util.Signer) {
+	s.nextSigner = next
 }
 
 func (s *Staker) Initialize(ctx context.Context) error {
@@ -211,6 +260,9 @@ func (s *Staker) Initialize(ctx context.Context) error {
 
 func (s *Staker) Start(ctxIn context.Context) {
 	s.StopWaiter.Start(ctxIn)
+	if s.notifier != nil {
+		s.notifier.Start(ctxIn)
+	}
 	backoff := time.Second
 	s.CallIteratively(func(ctx context.Context) time.Duration {
 		err := s.updateBlockValidatorModuleRoot(ctx)
@@ -219,7 +271,19 @@ func (s *Staker) Start(ctxIn context.Context) {
 		}
 		arbTx, err := s.Act(ctx)
 		if err == nil && arbTx != nil {
-			_, err = s.l1Reader.WaitForTxApproval(ctx, arbTx)
+			waitCtx := ctx
+			if s.config.L1PostingStrategy.ReplaceAfterBlocks > 0 {
+				// Stop waiting in time to replace the transaction if it's stuck.
+				var cancel context.CancelFunc
+				waitTime := time.Duration(s.config.L1PostingStrategy.ReplaceAfterBlocks) * s.config.L1PostingStrategy.l1BlockTime()
+				waitCtx, cancel = context.WithTimeout(ctx, waitTime)
+				defer cancel()
+			}
+			_, err = s.l1Reader.WaitForTxApproval(waitCtx, arbTx)
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				log.Warn("staker transaction not mined yet", "hash", arbTx.Hash(), "nonce", arbTx.Nonce())
+				return s.config.L1PostingStrategy.l1BlockTime()
+			}
 			err = errors.Wrap(err, "error waiting for tx receipt")
 			if err == nil {
 				log.Info("successfully executed staker transaction", "hash", arbTx.Hash())
@@ -233,6 +297,15 @@ func (s *Staker) Start(ctxIn context.Context) {
 		if backoff > time.Minute {
 			backoff = time.Minute
 			log.Error("error acting as staker", "err", err)
+			if s.activeChallenge != nil {
+				challengeIndex := s.activeChallenge.ChallengeIndex()
+				s.notify(StakerEvent{
+					Type:      StakerEventStakeAtRisk,
+					Key:       fmt.Sprintf("%v/failing", challengeIndex),
+					Message:   fmt.Sprintf("staker keeps failing to act during challenge %v: %v", challengeIndex, err),
+					Challenge: &challengeIndex,
+				})
+			}
 		} else {
 			log.Warn("error acting as staker", "err", err)
 		}
@@ -240,7 +313,14 @@ func (s *Staker) Start(ctxIn context.Context) {
 	})
 }
 
-func (s *Staker) shouldAct(ctx context.Context) bool {
+func (s *Staker) StopAndWait() {
+	s.StopWaiter.StopAndWait()
+	if s.notifier != nil {
+		s.notifier.StopAndWait()
+	}
+}
+
+func (s *Staker) shouldAct(ctx context.Context, latestBlockInfo *types.Header, blocksUntilDeadline *uint64) bool {
 	var gasPriceHigh = false
 	var gasPriceFloat float64
 	gasPrice, err := s.client.SuggestGasPrice(ctx)
@@ -252,9 +332,7 @@ func (s *Staker) shouldAct(ctx context.Context) bool {
 			gasPriceHigh = true
 		}
 	}
-	latestBlockInfo, err := s.client.HeaderByNumber(ctx, nil)
-	if err != nil {
-		log.Warn("error getting latest block", "err", err)
+	if latestBlockInfo == nil {
 		return true
 	}
 	latestBlockNum := latestBlockInfo.Number
@@ -276,6 +354,15 @@ func (s *Staker) shouldAct(ctx context.Context) bool {
 	} else if s.highGasBlocksBuffer.Cmp(big.NewInt(s.config.L1PostingStrategy.HighGasDelayBlocks)) > 0 {
 		s.highGasBlocksBuffer.SetInt64(s.config.L1PostingStrategy.HighGasDelayBlocks)
 	}
+	if gasPriceHigh && s.config.L1PostingStrategy.deadlineUrgent(blocksUntilDeadline) {
+		log.Warn(
+			"acting despite high gas price as a deadline approaches",
+			"gasPrice", gasPriceFloat,
+			"highGasPriceConfig", s.config.L1PostingStrategy.HighGasThreshold,
+			"blocksUntilDeadline", *blocksUntilDeadline,
+		)
+		return true
+	}
 	if gasPriceHigh && s.highGasBlocksBuffer.Sign() > 0 {
 		log.Warn(
 			"not acting yet as gas price is high",
@@ -290,16 +377,63 @@ func (s *Staker) shouldAct(ctx context.Context) bool {
 }
 
 func (s *Staker) Act(ctx context.Context) (*types.Transaction, error) {
-	if !s.shouldAct(ctx) {
+	// Without the latest block, act without regard to gas prices or deadlines.
+	var blocksUntilDeadline *uint64
+	latestBlockInfo, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Warn("error getting latest block", "err", err)
+		latestBlockInfo = nil
+	} else {
+		blocksUntilDeadline, err = s.blocksUntilDeadline(ctx, latestBlockInfo.Number.Uint64())
+		if err != nil {
+			return nil, err
+		}
+	}
+	fees, err := s.txFees(ctx, latestBlockInfo, blocksUntilDeadline)
+	if err != nil {
+		return nil, err
+	}
+	s.wallet.SetTxFees(fees)
+	// Wait on our transactions in flight rather than queueing more behind them.
+	// They're replaced even while gas is too high to act, as they were sent when
+	// it wasn't, or to meet a deadline.
+	replaceAfterBlocks := s.config.L1PostingStrategy.ReplaceAfterBlocks
+	var maxFees *TxFees
+	if latestBlockInfo == nil {
+		// Without the suggested fees to limit them by, don't replace any.
+		replaceAfterBlocks = 0
+	} else if s.wallet.HasTransactionsInFlight() {
+		maxFees, err = s.maxTxFees(ctx, latestBlockInfo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	inFlightTx, err := s.wallet.ReplaceStuckTransactions(
+		ctx,
+		replaceAfterBlocks,
+		s.config.L1PostingStrategy.ReplacementFeeBump,
+		maxFees,
+	)
+	if err != nil || inFlightTx != nil {
+		return inFlightTx, err
+	}
+	if !s.shouldAct(ctx, latestBlockInfo, blocksUntilDeadline) {
 		// The fact that we're delaying acting is alreay logged in `shouldAct`
 		return nil, nil
 	}
+	if s.nextSigner != nil {
+		tx, err := s.wallet.RotateSigner(ctx, s.nextSigner)
+		if err != nil || tx != nil {
+			return tx, err
+		}
+		s.nextSigner = nil
+	}
+	s.wrongNodeDeadline = nil
 	callOpts := s.getCallOpts(ctx)
 	s.builder.ClearTransactions()
 	var rawInfo *StakerInfo
 	walletAddressOrZero := s.wallet.AddressOrZero()
 	if walletAddressOrZero != (common.Address{}) {
-		var err error
 		rawInfo, err = s.rollup.StakerInfo(ctx, walletAddressOrZero)
 		if err != nil {
 			return nil, err
@@ -427,6 +561,12 @@ func (s *Staker) Act(ctx context.Context) (*types.Transaction, error) {
 		if err = s.handleConflict(ctx, rawInfo); err != nil {
 			return nil, err
 		}
+	} else if s.activeChallenge != nil {
+		// Losing a challenge takes away our stake.
+		s.challengeEnded(false)
+	} else if !s.snapshotsCleaned {
+		// Clean up after challenges resolved while the staker wasn't running.
+		s.removeMachineSnapshots(nil)
 	}
 
 	// Don't attempt to create a new stake if we're resolving a node and the stake is elevated,
@@ -456,14 +596,100 @@ func (s *Staker) Act(ctx context.Context) (*types.Transaction, error) {
 	return s.wallet.ExecuteTransactions(ctx, s.builder, common.HexToAddress(s.config.GasRefunderAddress))
 }
 
+func (s *Staker) challengeEnded(won bool) {
+	challengeIndex := s.activeChallenge.ChallengeIndex()
+	if won {
+		log.Info("won challenge", "challenge", challengeIndex)
+		s.notify(StakerEvent{
+			Type:      StakerEventChallengeWon,
+			Key:       fmt.Sprintf("%v", challengeIndex),
+			Message:   fmt.Sprintf("won challenge %v", challengeIndex),
+			Challenge: &challengeIndex,
+		})
+	} else {
+		log.Error("lost challenge", "challenge", challengeIndex)
+		s.notify(StakerEvent{
+			Type:      StakerEventChallengeLost,
+			Key:       fmt.Sprintf("%v", challengeIndex),
+			Message:   fmt.Sprintf("lost challenge %v and with it our stake", challengeIndex),
+			Challenge: &challengeIndex,
+		})
+	}
+	s.activeChallenge = nil
+	s.challengeTurn = false
+	s.removeMachineSnapshots(nil)
+}
+
+// removeMachineSnapshots removes the machine snapshots of challenges other than
+// keepChallenge. Failing to is logged rather than keeping the staker from acting.
+func (s *Staker) removeMachineSnapshots(keepChallenge *uint64) {
+	s.snapshotsCleaned = true
+	if err := RemoveMachineSnapshots(&s.config.MachineCacheDisk, keepChallenge); err != nil {
+		log.Warn("error removing machine snapshots of resolved challenges", "err", err)
+	}
+}
+
+// notifyChallengeTurn notifies when it becomes our turn in the active challenge,
+// and when our stake is at risk of us running out of time.
+func (s *Staker) notifyChallengeTurn(ctx context.Context) error {
+	myTurn, err := s.activeChallenge.IsMyTurn(ctx)
+	if err != nil {
+		return err
+	}
+	challengeIndex := s.activeChallenge.ChallengeIndex()
+	if myTurn && !s.challengeTurn {
+		s.challengeTurns++
+		s.notify(StakerEvent{
+			Type:      StakerEventChallengeTurn,
+			Key:       fmt.Sprintf("%v/%v", challengeIndex, s.challengeTurns),
+			Message:   fmt.Sprintf("our turn in challenge %v", challengeIndex),
+			Challenge: &challengeIndex,
+		})
+	}
+	s.challengeTurn = myTurn
+	if !myTurn {
+		return nil
+	}
+	timeLeft, err := s.activeChallenge.TimeUntilTimeout(ctx)
+	if err != nil {
+		return err
+	}
+	if timeLeft < time.Duration(s.config.Notifier.StakeAtRiskTimeLeft)*time.Second {
+		s.notify(StakerEvent{
+			Type:      StakerEventStakeAtRisk,
+			Key:       fmt.Sprintf("%v/time-left", challengeIndex),
+			Message:   fmt.Sprintf("only %v time left on our clock in challenge %v", timeLeft, challengeIndex),
+			Challenge: &challengeIndex,
+		})
+	}
+	return nil
+}
+
 func (s *Staker) handleConflict(ctx context.Context, info *StakerInfo) error {
 	if info.CurrentChallenge == nil {
-		s.activeChallenge = nil
+		if s.activeChallenge != nil {
+			s.challengeEnded(true)
+		} else if !s.snapshotsCleaned {
+			// Clean up after challenges resolved while the staker wasn't running.
+			s.removeMachineSnapshots(nil)
+		}
 		return nil
 	}
 
 	if s.activeChallenge == nil || s.activeChallenge.ChallengeIndex() != *info.CurrentChallenge {
+		if s.activeChallenge != nil {
+			// We're still staked, so we won the last one.
+			s.challengeEnded(true)
+		}
 		log.Warn("entered challenge", "challenge", info.CurrentChallenge)
+		challengeIndex := *info.CurrentChallenge
+		s.notify(StakerEvent{
+			Type:      StakerEventChallengeStarted,
+			Key:       fmt.Sprintf("%v", challengeIndex),
+			Message:   fmt.Sprintf("our stake is in challenge %v", challengeIndex),
+			Challenge: &challengeIndex,
+		})
+		s.removeMachineSnapshots(info.CurrentChallenge)
 
 		latestConfirmedCreated, err := s.rollup.LatestConfirmedCreationBlock(ctx)
 		if err != nil {
@@ -485,6 +711,7 @@ func (s *Staker) handleConflict(ctx context.Context, info *StakerInfo) error {
 			s.nitroMachineLoader,
 			latestConfirmedCreated,
 			s.config.TargetMachineCount,
+			&s.config.MachineCacheDisk,
 			s.config.ConfirmationBlocks,
 		)
 		if err != nil {
@@ -492,6 +719,13 @@ func (s *Staker) handleConflict(ctx context.Context, info *StakerInfo) error {
 		}
 
 		s.activeChallenge = newChallengeManager
+		s.challengeTurn = false
+	}
+
+	if s.notifier != nil {
+		if err := s.notifyChallengeTurn(ctx); err != nil {
+			return err
+		}
 	}
 
 	_, err := s.activeChallenge.Act(ctx)
@@ -0,0 +1,125 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+func (p *L1PostingStrategy) l1BlockTime() time.Duration {
+	if p.L1BlockTime <= 0 {
+		return DefaultL1PostingStrategy.L1BlockTime
+	}
+	return p.L1BlockTime
+}
+
+// deadlineUrgent returns whether a deadline blocksLeft away is close enough to
+// act regardless of the gas price.
+func (p *L1PostingStrategy) deadlineUrgent(blocksLeft *uint64) bool {
+	return blocksLeft != nil && *blocksLeft < p.DeadlineUrgencyBlocks
+}
+
+// feeMultiplier returns the multiple of the suggested fees to pay with
+// blocksLeft until a deadline: nothing extra outside the urgency window,
+// rising linearly to MaxFeeMultiplier at the deadline.
+func (p *L1PostingStrategy) feeMultiplier(blocksLeft *uint64) arbmath.Bips {
+	if !p.deadlineUrgent(blocksLeft) || p.MaxFeeMultiplier <= 1 {
+		return arbmath.OneInBips
+	}
+	urgency := 1 - float64(*blocksLeft)/float64(p.DeadlineUrgencyBlocks)
+	return arbmath.Bips(float64(arbmath.OneInBips) * (1 + (p.MaxFeeMultiplier-1)*urgency))
+}
+
+// blocksUntilDeadline returns how many L1 blocks are left before the nearest
+// deadline the staker must act by, or nil if there's none. The deadlines are
+// the end of our time in the active challenge when it's our turn, and the
+// confirmation deadline of any incorrect node seen when the staker last acted,
+// after which it can be confirmed unless someone stakes against it.
+func (s *Staker) blocksUntilDeadline(ctx context.Context, latestBlock uint64) (*uint64, error) {
+	var nearest *uint64
+	consider := func(blocks uint64) {
+		if nearest == nil || blocks < *nearest {
+			nearest = &blocks
+		}
+	}
+	if s.wrongNodeDeadline != nil {
+		consider(arbmath.SaturatingUSub(*s.wrongNodeDeadline, latestBlock))
+	}
+	if s.activeChallenge != nil {
+		myTurn, err := s.activeChallenge.IsMyTurn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if myTurn {
+			timeLeft, err := s.activeChallenge.TimeUntilTimeout(ctx)
+			if err != nil {
+				return nil, err
+			}
+			consider(uint64(timeLeft / s.config.L1PostingStrategy.l1BlockTime()))
+		}
+	}
+	return nearest, nil
+}
+
+// txFees returns the fees to send the staker's transactions with as a deadline
+// blocksLeft away approaches, or nil to leave them to the client. header is
+// only used with a deadline.
+func (s *Staker) txFees(ctx context.Context, header *types.Header, blocksLeft *uint64) (*TxFees, error) {
+	multiplier := s.config.L1PostingStrategy.feeMultiplier(blocksLeft)
+	if multiplier <= arbmath.OneInBips {
+		return nil, nil
+	}
+	fees, err := s.suggestedFees(ctx, header, multiplier)
+	if err != nil {
+		return nil, err
+	}
+	log.Info(
+		"raising staker fees as a deadline approaches",
+		"blocksLeft", *blocksLeft,
+		"multiplier", float64(multiplier)/float64(arbmath.OneInBips),
+		"gasPrice", fees.GasPrice,
+		"gasFeeCap", fees.GasFeeCap,
+		"gasTipCap", fees.GasTipCap,
+	)
+	return fees, nil
+}
+
+// maxTxFees returns the most the staker's transactions may pay when they're
+// replaced, or nil for no limit.
+func (s *Staker) maxTxFees(ctx context.Context, header *types.Header) (*TxFees, error) {
+	maxMultiplier := s.config.L1PostingStrategy.MaxFeeMultiplier
+	if maxMultiplier <= 0 {
+		return nil, nil
+	}
+	return s.suggestedFees(ctx, header, arbmath.Bips(float64(arbmath.OneInBips)*maxMultiplier))
+}
+
+// suggestedFees returns the fees the client suggests as of header, multiplied
+// by multiplier.
+func (s *Staker) suggestedFees(ctx context.Context, header *types.Header, multiplier arbmath.Bips) (*TxFees, error) {
+	var fees TxFees
+	if header.BaseFee == nil {
+		gasPrice, err := s.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		fees.GasPrice = arbmath.BigMulByBips(gasPrice, multiplier)
+	} else {
+		tipCap, err := s.client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// The fee cap the client would pick, raised along with the tip.
+		feeCap := arbmath.BigAdd(arbmath.BigMulByUint(header.BaseFee, 2), tipCap)
+		fees.GasFeeCap = arbmath.BigMulByBips(feeCap, multiplier)
+		fees.GasTipCap = arbmath.BigMulByBips(tipCap, multiplier)
+	}
+	return &fees, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/cmd/util"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+func TestFeeMultiplier(t *testing.T) {
+	strategy := DefaultL1PostingStrategy
+	strategy.DeadlineUrgencyBlocks = 100
+	strategy.MaxFeeMultiplier = 3
+	blocks := func(n uint64) *uint64 { return &n }
+
+	cases := []struct {
+		blocksLeft *uint64
+		urgent     bool
+		multiplier arbmath.Bips
+	}{
+		{nil, false, arbmath.OneInBips},
+		{blocks(1000), false, arbmath.OneInBips},
+		{blocks(100), false, arbmath.OneInBips},
+		{blocks(50), true, 2 * arbmath.OneInBips},
+		{blocks(0), true, 3 * arbmath.OneInBips},
+	}
+	for _, c := range cases {
+		if urgent := strategy.deadlineUrgent(c.blocksLeft); urgent != c.urgent {
+			Fail(t, "deadline", c.blocksLeft, "urgent", urgent, "expected", c.urgent)
+		}
+		if multiplier := strategy.feeMultiplier(c.blocksLeft); multiplier != c.multiplier {
+			Fail(t, "deadline", c.blocksLeft, "fee multiplier", multiplier, "expected", c.multiplier)
+		}
+	}
+
+	// Without an urgency window, deadlines change nothing.
+	strategy.DeadlineUrgencyBlocks = 0
+	if strategy.deadlineUrgent(blocks(0)) || strategy.feeMultiplier(blocks(0)) != arbmath.OneInBips {
+		Fail(t, "deadline urgent without an urgency window")
+	}
+}
+
+func TestReplacementTransaction(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	Require(t, err)
+	chainId := big.NewInt(1337)
+	signer := util.NewPrivateKeySigner(key)
+	wallet := &ValidatorWallet{signer: signer, l1ChainId: chainId}
+
+	to := common.HexToAddress("0x1234")
+	original, err := signer.SignTx(ctx, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainId,
+		Nonce:     7,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(1000),
+		Gas:       50000,
+		To:        &to,
+		Value:     big.NewInt(5),
+		Data:      []byte{1, 2, 3},
+	}), chainId)
+	Require(t, err)
+	pending := &inFlightTx{tx: original, signer: signer}
+
+	replacement, err := wallet.replacementTransaction(ctx, pending, 25, nil)
+	Require(t, err)
+	if replacement.Nonce() != original.Nonce() || replacement.Hash() == original.Hash() {
+		Fail(t, "replacement doesn't replace the original transaction")
+	}
+	if replacement.GasTipCap().Uint64() != 125 || replacement.GasFeeCap().Uint64() != 1250 {
+		Fail(t, "replacement fees", replacement.GasTipCap(), replacement.GasFeeCap(), "not bumped by 25%")
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainId), replacement)
+	Require(t, err)
+	if sender != signer.Address() {
+		Fail(t, "replacement signed by", sender, "expected", signer.Address())
+	}
+	if wallet.feesOutbid(original, 25) {
+		Fail(t, "wallet without fees outbid a transaction")
+	}
+
+	// Fees raised for a deadline take over from the bump, and outbid the original.
+	wallet.SetTxFees(&TxFees{GasFeeCap: big.NewInt(4000), GasTipCap: big.NewInt(400)})
+	if !wallet.feesOutbid(original, 25) {
+		Fail(t, "raised fees didn't outbid the original transaction")
+	}
+	replacement, err = wallet.replacementTransaction(ctx, pending, 25, nil)
+	Require(t, err)
+	if replacement.GasTipCap().Uint64() != 400 || replacement.GasFeeCap().Uint64() != 4000 {
+		Fail(t, "replacement fees", replacement.GasTipCap(), replacement.GasFeeCap(), "not the wallet's fees")
+	}
+
+	// Replacements pay no more than the maximum fees.
+	replacement, err = wallet.replacementTransaction(ctx, pending, 25, &TxFees{GasFeeCap: big.NewInt(2000), GasTipCap: big.NewInt(200)})
+	Require(t, err)
+	if replacement.GasTipCap().Uint64() != 200 || replacement.GasFeeCap().Uint64() != 2000 {
+		Fail(t, "replacement fees", replacement.GasTipCap(), replacement.GasFeeCap(), "not limited to the maximum")
+	}
+	// Once the maximum is too low to bump the fees enough, it isn't replaced.
+	replacement, err = wallet.replacementTransaction(ctx, pending, 25, &TxFees{GasFeeCap: big.NewInt(1100), GasTipCap: big.NewInt(200)})
+	Require(t, err)
+	if replacement != nil {
+		Fail(t, "replaced transaction without bumping its fees enough")
+	}
+
+	if bumpFee(big.NewInt(1), nil, 10).Uint64() != 2 {
+		Fail(t, "tiny fee not bumped")
+	}
+}
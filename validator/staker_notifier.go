@@ -0,0 +1,287 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+	flag "github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+type StakerEventType string
+
+const (
+	StakerEventBadAssertion     StakerEventType = "bad-assertion"
+	StakerEventChallengeStarted StakerEventType = "challenge-started"
+	StakerEventChallengeTurn    StakerEventType = "challenge-turn"
+	StakerEventChallengeWon     StakerEventType = "challenge-won"
+	StakerEventChallengeLost    StakerEventType = "challenge-lost"
+	StakerEventStakeAtRisk      StakerEventType = "stake-at-risk"
+)
+
+// A StakerEvent is something security relevant the staker saw, which someone
+// should know about sooner than they'd read the logs.
+type StakerEvent struct {
+	Type StakerEventType `json:"type"`
+	// Identifies the occurrence, so the same one is only notified once, e.g. the
+	// node number of a bad assertion.
+	Key       string          `json:"key"`
+	Message   string          `json:"message"`
+	Rollup    common.Address  `json:"rollup"`
+	Staker    *common.Address `json:"staker,omitempty"`
+	Node      *uint64         `json:"node,omitempty"`
+	Challenge *uint64         `json:"challenge,omitempty"`
+	Time      time.Time       `json:"time"`
+}
+
+type StakerNotifier interface {
+	// Notify must not block on delivering the event.
+	Notify(event StakerEvent)
+}
+
+type StakerNotifierConfig struct {
+	Webhooks            []string      `koanf:"webhooks"`
+	File                string        `koanf:"file"`
+	Exec                string        `koanf:"exec"`
+	Timeout             time.Duration `koanf:"timeout"`
+	Retries             int           `koanf:"retries"`
+	RetryDelay          time.Duration `koanf:"retry-delay"`
+	DedupWindow         time.Duration `koanf:"dedup-window"`
+	QueueSize           int           `koanf:"queue-size"`
+	StakeAtRiskTimeLeft uint64        `koanf:"stake-at-risk-time-left"`
+}
+
+var DefaultStakerNotifierConfig = StakerNotifierConfig{
+	Webhooks:            []string{},
+	File:                "",
+	Exec:                "",
+	Timeout:             10 * time.Second,
+	Retries:             5,
+	RetryDelay:          5 * time.Second,
+	DedupWindow:         24 * time.Hour,
+	QueueSize:           100,
+	StakeAtRiskTimeLeft: 1000,
+}
+
+func StakerNotifierConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.StringSlice(prefix+".webhooks", DefaultStakerNotifierConfig.Webhooks, "URLs to POST staker events to as JSON")
+	f.String(prefix+".file", DefaultStakerNotifierConfig.File, "file to append staker events to as JSON lines")
+	f.String(prefix+".exec", DefaultStakerNotifierConfig.Exec, "program to run on staker events, with the event as JSON on its stdin")
+	f.Duration(prefix+".timeout", DefaultStakerNotifierConfig.Timeout, "timeout of each attempt to deliver an event")
+	f.Int(prefix+".retries", DefaultStakerNotifierConfig.Retries, "number of times to retry delivering an event to a sink that failed")
+	f.Duration(prefix+".retry-delay", DefaultStakerNotifierConfig.RetryDelay, "delay before the first retry, doubling with each retry")
+	f.Duration(prefix+".dedup-window", DefaultStakerNotifierConfig.DedupWindow, "how long to ignore repeats of an event for")
+	f.Int(prefix+".queue-size", DefaultStakerNotifierConfig.QueueSize, "number of events to queue for delivery before dropping them")
+	f.Uint64(prefix+".stake-at-risk-time-left", DefaultStakerNotifierConfig.StakeAtRiskTimeLeft, "notify that our stake is at risk when it's our turn in a challenge with less than this many seconds left on our clock")
+}
+
+func (c *StakerNotifierConfig) Enabled() bool {
+	return len(c.Webhooks) > 0 || c.File != "" || c.Exec != ""
+}
+
+type stakerEventSink interface {
+	name() string
+	deliver(ctx context.Context, event *StakerEvent, encoded []byte) error
+}
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) name() string {
+	return s.url
+}
+
+func (s *webhookSink) deliver(ctx context.Context, _ *StakerEvent, encoded []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %v", res.Status)
+	}
+	return nil
+}
+
+type fileSink struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func (s *fileSink) name() string {
+	return s.path
+}
+
+func (s *fileSink) deliver(_ context.Context, _ *StakerEvent, encoded []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(encoded, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+type execSink struct {
+	path string
+}
+
+func (s *execSink) name() string {
+	return s.path
+}
+
+func (s *execSink) deliver(ctx context.Context, event *StakerEvent, encoded []byte) error {
+	cmd := exec.CommandContext(ctx, s.path)
+	cmd.Stdin = bytes.NewReader(encoded)
+	cmd.Env = append(os.Environ(),
+		"STAKER_EVENT_TYPE="+string(event.Type),
+		"STAKER_EVENT_KEY="+event.Key,
+		"STAKER_EVENT_MESSAGE="+event.Message,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, output)
+	}
+	return nil
+}
+
+// AlertingStakerNotifier delivers staker events to webhooks, a file and a
+// program, retrying each that fails, and ignoring events it's already seen
+// within the dedup window.
+type AlertingStakerNotifier struct {
+	stopwaiter.StopWaiter
+	config *StakerNotifierConfig
+	sinks  []stakerEventSink
+	queue  chan StakerEvent
+
+	seenMutex sync.Mutex
+	seen      map[string]time.Time
+}
+
+var _ StakerNotifier = (*AlertingStakerNotifier)(nil)
+
+func NewAlertingStakerNotifier(config *StakerNotifierConfig) (*AlertingStakerNotifier, error) {
+	if config.QueueSize <= 0 {
+		return nil, errors.New("staker notifier queue size must be positive")
+	}
+	client := &http.Client{Timeout: config.Timeout}
+	var sinks []stakerEventSink
+	for _, url := range config.Webhooks {
+		sinks = append(sinks, &webhookSink{url: url, client: client})
+	}
+	if config.File != "" {
+		sinks = append(sinks, &fileSink{path: config.File})
+	}
+	if config.Exec != "" {
+		sinks = append(sinks, &execSink{path: config.Exec})
+	}
+	return &AlertingStakerNotifier{
+		config: config,
+		sinks:  sinks,
+		queue:  make(chan StakerEvent, config.QueueSize),
+		seen:   make(map[string]time.Time),
+	}, nil
+}
+
+func (n *AlertingStakerNotifier) Notify(event StakerEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	dedupKey := string(event.Type) + "/" + event.Key
+	n.seenMutex.Lock()
+	for key, seenAt := range n.seen {
+		if event.Time.Sub(seenAt) >= n.config.DedupWindow {
+			delete(n.seen, key)
+		}
+	}
+	defer n.seenMutex.Unlock()
+	if _, seen := n.seen[dedupKey]; seen {
+		return
+	}
+	select {
+	case n.queue <- event:
+		// Only once queued, so that a dropped event is sent if it recurs.
+		n.seen[dedupKey] = event.Time
+	default:
+		log.Error("staker notifier queue full, dropping event", "type", event.Type, "key", event.Key, "message", event.Message)
+	}
+}
+
+func (n *AlertingStakerNotifier) deliverWithRetries(ctx context.Context, sink stakerEventSink, event *StakerEvent, encoded []byte) {
+	delay := n.config.RetryDelay
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, n.config.Timeout)
+		err := sink.deliver(attemptCtx, event, encoded)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= n.config.Retries {
+			log.Error("failed to deliver staker event", "sink", sink.name(), "type", event.Type, "key", event.Key, "err", err)
+			return
+		}
+		log.Warn("error delivering staker event, retrying", "sink", sink.name(), "type", event.Type, "key", event.Key, "err", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (n *AlertingStakerNotifier) deliver(ctx context.Context, event StakerEvent) {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		log.Error("failed to encode staker event", "type", event.Type, "err", err)
+		return
+	}
+	var wg sync.WaitGroup
+	for _, sink := range n.sinks {
+		wg.Add(1)
+		go func(sink stakerEventSink) {
+			defer wg.Done()
+			n.deliverWithRetries(ctx, sink, &event, encoded)
+		}(sink)
+	}
+	wg.Wait()
+}
+
+func (n *AlertingStakerNotifier) Start(ctxIn context.Context) {
+	n.StopWaiter.Start(ctxIn)
+	n.LaunchThread(func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-n.queue:
+				n.deliver(ctx, event)
+			}
+		}
+	})
+}
@@ -0,0 +1,224 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStakerNotifier(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mutex sync.Mutex
+	var attempts int
+	var received []StakerEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		if attempts == 1 {
+			// The first delivery must be retried.
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var event StakerEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, event)
+	}))
+	defer server.Close()
+
+	config := DefaultStakerNotifierConfig
+	config.Webhooks = []string{server.URL}
+	config.File = filepath.Join(t.TempDir(), "events.jsonl")
+	config.RetryDelay = 10 * time.Millisecond
+	notifier, err := NewAlertingStakerNotifier(&config)
+	Require(t, err)
+	notifier.Start(ctx)
+	defer notifier.StopAndWait()
+
+	node := uint64(7)
+	challenge := uint64(2)
+	events := []StakerEvent{
+		{Type: StakerEventBadAssertion, Key: "0x07", Message: "bad node", Node: &node},
+		{Type: StakerEventBadAssertion, Key: "0x07", Message: "bad node again", Node: &node},
+		{Type: StakerEventChallengeStarted, Key: "2", Message: "challenge", Challenge: &challenge},
+		{Type: StakerEventChallengeTurn, Key: "2/1", Message: "our turn", Challenge: &challenge},
+		{Type: StakerEventChallengeTurn, Key: "2/1", Message: "still our turn", Challenge: &challenge},
+	}
+	for _, event := range events {
+		notifier.Notify(event)
+	}
+	expected := []StakerEventType{StakerEventBadAssertion, StakerEventChallengeStarted, StakerEventChallengeTurn}
+
+	for start := time.Now(); ; {
+		mutex.Lock()
+		done := len(received) >= len(expected)
+		mutex.Unlock()
+		if done {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			Fail(t, "timed out waiting for webhook deliveries")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Give any duplicates a chance to show up.
+	time.Sleep(100 * time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(received) != len(expected) {
+		Fail(t, "webhook received", len(received), "events, expected", len(expected))
+	}
+	for i, event := range received {
+		if event.Type != expected[i] {
+			Fail(t, "webhook event", i, "is", event.Type, "expected", expected[i])
+		}
+	}
+	if received[0].Node == nil || *received[0].Node != node {
+		Fail(t, "bad assertion event lost its node")
+	}
+
+	fileEvents := waitForEventLines(t, config.File, len(expected))
+	for i, event := range fileEvents {
+		if event.Type != expected[i] {
+			Fail(t, "file event", i, "is", event.Type, "expected", expected[i])
+		}
+	}
+}
+
+// waitForEventLines waits for the file at path to have count staker events,
+// as the notifier may still be delivering the last of them to it.
+func waitForEventLines(t *testing.T, path string, count int) []StakerEvent {
+	t.Helper()
+	for start := time.Now(); ; {
+		events := readEventLines(t, path)
+		if len(events) > count {
+			Fail(t, path, "has", len(events), "events, expected", count)
+		}
+		if len(events) == count {
+			// Give any duplicates a chance to show up.
+			time.Sleep(100 * time.Millisecond)
+			if len(readEventLines(t, path)) != count {
+				Fail(t, path, "got more than", count, "events")
+			}
+			return events
+		}
+		if time.Since(start) > 10*time.Second {
+			Fail(t, "timed out waiting for", count, "events in", path, "got", len(events))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// readEventLines reads the complete lines of staker events in the file at
+// path, ignoring a last line that's still being written.
+func readEventLines(t *testing.T, path string) []StakerEvent {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	Require(t, err)
+	var events []StakerEvent
+	for {
+		end := bytes.IndexByte(contents, '\n')
+		if end < 0 {
+			return events
+		}
+		var event StakerEvent
+		Require(t, json.Unmarshal(contents[:end], &event))
+		events = append(events, event)
+		contents = contents[end+1:]
+	}
+}
+
+func TestStakerNotifierExec(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "events.jsonl")
+	failedOnce := filepath.Join(dir, "failed-once")
+	// The script fails its first run to be retried, and then appends the event
+	// type and key from its environment, and its input, to the outputs.
+	script := filepath.Join(dir, "notify.sh")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+if [ ! -e "`+failedOnce+`" ]; then
+	touch "`+failedOnce+`"
+	echo "failing once" >&2
+	exit 1
+fi
+event="$(cat)"
+echo "$STAKER_EVENT_TYPE $STAKER_EVENT_KEY" >> "`+output+`.env"
+echo "$event" >> "`+output+`"
+`), 0755)
+	Require(t, err)
+
+	config := DefaultStakerNotifierConfig
+	config.Exec = script
+	config.RetryDelay = 10 * time.Millisecond
+	notifier, err := NewAlertingStakerNotifier(&config)
+	Require(t, err)
+	notifier.Start(ctx)
+	defer notifier.StopAndWait()
+
+	node := uint64(3)
+	notifier.Notify(StakerEvent{Type: StakerEventBadAssertion, Key: "0x03", Message: "bad node", Node: &node})
+	notifier.Notify(StakerEvent{Type: StakerEventStakeAtRisk, Key: "1", Message: "stake at risk"})
+
+	events := waitForEventLines(t, output, 2)
+	if events[0].Type != StakerEventBadAssertion || events[0].Node == nil || *events[0].Node != node || events[0].Message != "bad node" {
+		Fail(t, "unexpected first event", events[0])
+	}
+	if events[1].Type != StakerEventStakeAtRisk || events[1].Key != "1" {
+		Fail(t, "unexpected second event", events[1])
+	}
+	env, err := os.ReadFile(output + ".env")
+	Require(t, err)
+	expectedEnv := "bad-assertion 0x03\nstake-at-risk 1\n"
+	if string(env) != expectedEnv {
+		Fail(t, "script environment was", string(env), "expected", expectedEnv)
+	}
+}
+
+func TestStakerNotifierDroppedEventRecurs(t *testing.T) {
+	config := DefaultStakerNotifierConfig
+	config.File = filepath.Join(t.TempDir(), "events.jsonl")
+	config.QueueSize = 1
+	notifier, err := NewAlertingStakerNotifier(&config)
+	Require(t, err)
+
+	queued := StakerEvent{Type: StakerEventChallengeTurn, Key: "1/1", Message: "our turn"}
+	dropped := StakerEvent{Type: StakerEventStakeAtRisk, Key: "1/time-left", Message: "stake at risk"}
+	notifier.Notify(queued)
+	notifier.Notify(dropped)
+	if event := <-notifier.queue; event.Key != queued.Key {
+		Fail(t, "queued", event.Key, "instead of", queued.Key)
+	}
+
+	// The dropped event wasn't delivered, so it isn't deduplicated when it
+	// recurs, unlike the one that was queued.
+	notifier.Notify(queued)
+	notifier.Notify(dropped)
+	if len(notifier.queue) != 1 {
+		Fail(t, "expected only the dropped event to be queued, queue has", len(notifier.queue))
+	}
+	if event := <-notifier.queue; event.Key != dropped.Key {
+		Fail(t, "queued", event.Key, "instead of", dropped.Key)
+	}
+}
@@ -38,6 +38,7 @@ type StatelessBlockValidator struct {
 	moduleMutex           sync.Mutex
 	currentWasmModuleRoot common.Hash
 	pendingWasmModuleRoot common.Hash
+	moduleRootSchedule    *ModuleRootSchedule // replaces the current and pending roots if set
 	fatalErrChan          chan error
 }
 
@@ -229,7 +230,27 @@ func NewStatelessBlockValidator(
 		genesisBlockNum: genesisBlockNum,
 		fatalErrChan:    fatalErrChan,
 	}
-	if config.PendingUpgradeModuleRoot != "" {
+	// the machines will be lazily created if need be later otherwise
+	// (remote validation workers run their own)
+	localMachines := !config.RemoteValidation.Enabled()
+	if config.ModuleRootSchedule != "" {
+		validator.moduleRootSchedule, err = ParseModuleRootSchedule(config.ModuleRootSchedule)
+		if err != nil {
+			return nil, err
+		}
+		for _, root := range validator.moduleRootSchedule.ModuleRoots() {
+			if config.ArbitratorValidator && localMachines {
+				if err := machineLoader.CreateMachine(root, true, false); err != nil {
+					return nil, err
+				}
+			}
+			if config.JitValidator && localMachines {
+				if err := machineLoader.CreateMachine(root, true, true); err != nil {
+					return nil, err
+				}
+			}
+		}
+	} else if config.PendingUpgradeModuleRoot != "" {
 		if config.PendingUpgradeModuleRoot == "latest" {
 			latest, err := machineLoader.GetConfig().ReadLatestWasmModuleRoot()
 			if err != nil {
@@ -243,13 +264,12 @@ func NewStatelessBlockValidator(
 			}
 		}
 
-		// the machine will be lazily created if need be later otherwise
-		if config.ArbitratorValidator {
+		if config.ArbitratorValidator && localMachines {
 			if err := machineLoader.CreateMachine(validator.pendingWasmModuleRoot, true, false); err != nil {
 				return nil, err
 			}
 		}
-		if config.JitValidator {
+		if config.JitValidator && localMachines {
 			if err := machineLoader.CreateMachine(validator.pendingWasmModuleRoot, true, true); err != nil {
 				return nil, err
 			}
@@ -258,7 +278,12 @@ func NewStatelessBlockValidator(
 	return validator, nil
 }
 
+// GetModuleRootsToValidate returns all the module roots blocks may be
+// validated against.
 func (v *StatelessBlockValidator) GetModuleRootsToValidate() []common.Hash {
+	if v.moduleRootSchedule != nil {
+		return v.moduleRootSchedule.ModuleRoots()
+	}
 	v.moduleMutex.Lock()
 	defer v.moduleMutex.Unlock()
 
@@ -269,6 +294,49 @@ func (v *StatelessBlockValidator) GetModuleRootsToValidate() []common.Hash {
 	return validatingModuleRoots
 }
 
+// ModuleRootsForBlock returns the module roots to validate the block with
+// header against.
+func (v *StatelessBlockValidator) ModuleRootsForBlock(header *types.Header) ([]common.Hash, error) {
+	if v.moduleRootSchedule == nil {
+		return v.GetModuleRootsToValidate(), nil
+	}
+	headerInfo, err := types.DeserializeHeaderExtraInformation(header)
+	if err != nil {
+		return nil, err
+	}
+	return v.moduleRootSchedule.RootsForBlock(header.Number.Uint64(), headerInfo.L1BlockNumber), nil
+}
+
+// UnresolvedModuleRootNodes returns the rollup nodes whose confirmation
+// activates a module root, that aren't yet known to be confirmed or rejected.
+func (v *StatelessBlockValidator) UnresolvedModuleRootNodes() []uint64 {
+	if v.moduleRootSchedule == nil {
+		return nil
+	}
+	return v.moduleRootSchedule.UnresolvedNodes()
+}
+
+// ResolveModuleRootNode activates the module root of a confirmed node after
+// the last block it asserted, with header, or never if it was rejected, in
+// which case header is nil.
+func (v *StatelessBlockValidator) ResolveModuleRootNode(node uint64, header *types.Header) error {
+	if v.moduleRootSchedule == nil {
+		return nil
+	}
+	if header == nil {
+		log.Error("module root activation node was rejected, so the module root will never activate", "node", node)
+		v.moduleRootSchedule.ResolveNode(node, true, 0, 0)
+		return nil
+	}
+	headerInfo, err := types.DeserializeHeaderExtraInformation(header)
+	if err != nil {
+		return err
+	}
+	log.Info("module root activation node confirmed", "node", node, "afterBlock", header.Number, "l1Block", headerInfo.L1BlockNumber)
+	v.moduleRootSchedule.ResolveNode(node, false, header.Number.Uint64(), headerInfo.L1BlockNumber)
+	return nil
+}
+
 type BatchInfo struct {
 	Number uint64
 	Data   []byte
@@ -468,25 +536,46 @@ func NewMachinePreimageResolver(
 func (v *StatelessBlockValidator) executeBlock(
 	ctx context.Context, entry *validationEntry, moduleRoot common.Hash,
 ) (GoGlobalState, []byte, error) {
-	start := entry.StartPosition
-	gsStart := entry.start()
-
 	basemachine, err := v.MachineLoader.GetMachine(ctx, moduleRoot, true)
 	if err != nil {
 		return GoGlobalState{}, nil, fmt.Errorf("unabled to get WASM machine: %w", err)
 	}
-	mach := basemachine.Clone()
 	resolver, err := NewMachinePreimageResolver(ctx, entry.Preimages, entry.BatchInfo, v.blockchain, v.daService)
 	if err != nil {
 		return GoGlobalState{}, nil, err
 	}
+	var delayedMsg []byte
+	if entry.HasDelayedMsg {
+		delayedMsg, err = v.inboxTracker.GetDelayedMessageBytes(entry.DelayedMsgNr)
+		if err != nil {
+			log.Error(
+				"error while trying to read delayed msg for proving",
+				"err", err, "seq", entry.DelayedMsgNr, "blockNr", entry.BlockNumber,
+			)
+			return GoGlobalState{}, nil, errors.New("error while trying to read delayed msg for proving")
+		}
+	}
+	gsEnd, err := executeMachine(ctx, basemachine, entry, moduleRoot, resolver, delayedMsg)
+	return gsEnd, delayedMsg, err
+}
+
+// executeMachine runs a clone of basemachine on entry's inputs until it stops,
+// and returns the global state it ends at.
+func executeMachine(
+	ctx context.Context, basemachine *ArbitratorMachine, entry *validationEntry, moduleRoot common.Hash,
+	resolver GoPreimageResolver, delayedMsg []byte,
+) (GoGlobalState, error) {
+	start := entry.StartPosition
+	gsStart := entry.start()
+
+	mach := basemachine.Clone()
 	if err := mach.SetPreimageResolver(resolver); err != nil {
-		return GoGlobalState{}, nil, err
+		return GoGlobalState{}, err
 	}
-	err = mach.SetGlobalState(gsStart)
+	err := mach.SetGlobalState(gsStart)
 	if err != nil {
 		log.Error("error while setting global state for proving", "err", err, "gsStart", gsStart)
-		return GoGlobalState{}, nil, errors.New("error while setting global state for proving")
+		return GoGlobalState{}, errors.New("error while setting global state for proving")
 	}
 	for _, batch := range entry.BatchInfo {
 		err = mach.AddSequencerInboxMessage(batch.Number, batch.Data)
@@ -495,26 +584,17 @@ func (v *StatelessBlockValidator) executeBlock(
 				"error while trying to add sequencer msg for proving",
 				"err", err, "seq", start.BatchNumber, "blockNr", entry.BlockNumber,
 			)
-			return GoGlobalState{}, nil, errors.New("error while trying to add sequencer msg for proving")
+			return GoGlobalState{}, errors.New("error while trying to add sequencer msg for proving")
 		}
 	}
-	var delayedMsg []byte
 	if entry.HasDelayedMsg {
-		delayedMsg, err = v.inboxTracker.GetDelayedMessageBytes(entry.DelayedMsgNr)
-		if err != nil {
-			log.Error(
-				"error while trying to read delayed msg for proving",
-				"err", err, "seq", entry.DelayedMsgNr, "blockNr", entry.BlockNumber,
-			)
-			return GoGlobalState{}, nil, errors.New("error while trying to read delayed msg for proving")
-		}
 		err = mach.AddDelayedInboxMessage(entry.DelayedMsgNr, delayedMsg)
 		if err != nil {
 			log.Error(
 				"error while trying to add delayed msg for proving",
 				"err", err, "seq", entry.DelayedMsgNr, "blockNr", entry.BlockNumber,
 			)
-			return GoGlobalState{}, nil, errors.New("error while trying to add delayed msg for proving")
+			return GoGlobalState{}, errors.New("error while trying to add delayed msg for proving")
 		}
 	}
 
@@ -526,15 +606,15 @@ func (v *StatelessBlockValidator) executeBlock(
 			log.Debug("validation", "moduleRoot", moduleRoot, "block", entry.BlockNumber, "steps", steps)
 		}
 		if err != nil {
-			return GoGlobalState{}, nil, fmt.Errorf("machine execution failed with error: %w", err)
+			return GoGlobalState{}, fmt.Errorf("machine execution failed with error: %w", err)
 		}
 		steps += count
 	}
 	if mach.IsErrored() {
 		log.Error("machine entered errored state during attempted validation", "block", entry.BlockNumber)
-		return GoGlobalState{}, nil, errors.New("machine entered errored state during attempted validation")
+		return GoGlobalState{}, errors.New("machine entered errored state during attempted validation")
 	}
-	return mach.GetGlobalState(), delayedMsg, nil
+	return mach.GetGlobalState(), nil
 }
 
 func (v *StatelessBlockValidator) jitBlock(
@@ -567,60 +647,72 @@ func (v *StatelessBlockValidator) jitBlock(
 	return state, delayed, err
 }
 
-func (v *StatelessBlockValidator) ValidateBlock(
-	ctx context.Context, header *types.Header, full bool, moduleRoot common.Hash,
-) (bool, error) {
+// validationEntryForBlock creates the entry to validate the block with header,
+// with the sequencer message it reads.
+func (v *StatelessBlockValidator) validationEntryForBlock(
+	ctx context.Context, header *types.Header, producePreimages bool,
+) (*validationEntry, error) {
 	if header == nil {
-		return false, errors.New("header not found")
+		return nil, errors.New("header not found")
 	}
 	blockNum := header.Number.Uint64()
 	msgIndex := arbutil.BlockNumberToMessageCount(blockNum, v.genesisBlockNum) - 1
 	prevHeader := v.blockchain.GetHeaderByNumber(blockNum - 1)
 	if prevHeader == nil {
-		return false, errors.New("prev header not found")
+		return nil, errors.New("prev header not found")
 	}
 	msg, err := v.streamer.GetMessage(msgIndex)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	preimages, readBatchInfo, hasDelayedMessage, delayedMsgToRead, err := BlockDataForValidation(
-		ctx, v.blockchain, v.inboxReader, header, prevHeader, *msg, false,
+		ctx, v.blockchain, v.inboxReader, header, prevHeader, *msg, producePreimages,
 	)
 	if err != nil {
-		return false, fmt.Errorf("failed to get block data to validate: %w", err)
+		return nil, fmt.Errorf("failed to get block data to validate: %w", err)
 	}
 
 	batchCount, err := v.inboxTracker.GetBatchCount()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	batch, err := FindBatchContainingMessageIndex(v.inboxTracker, msgIndex, batchCount)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	startPos, endPos, err := GlobalStatePositionsFor(v.inboxTracker, msgIndex, batch)
 	if err != nil {
-		return false, fmt.Errorf("failed calculating position for validation: %w", err)
+		return nil, fmt.Errorf("failed calculating position for validation: %w", err)
 	}
 
 	entry, err := newValidationEntry(
 		prevHeader, header, hasDelayedMessage, delayedMsgToRead, preimages, readBatchInfo,
 	)
 	if err != nil {
-		return false, fmt.Errorf("failed to create validation entry %w", err)
+		return nil, fmt.Errorf("failed to create validation entry %w", err)
 	}
 	entry.StartPosition = startPos
 	entry.EndPosition = endPos
 
 	seqMsg, err := v.inboxReader.GetSequencerMessageBytes(ctx, startPos.BatchNumber)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	entry.BatchInfo = append(entry.BatchInfo, BatchInfo{
 		Number: startPos.BatchNumber,
 		Data:   seqMsg,
 	})
+	return entry, nil
+}
+
+func (v *StatelessBlockValidator) ValidateBlock(
+	ctx context.Context, header *types.Header, full bool, moduleRoot common.Hash,
+) (bool, error) {
+	entry, err := v.validationEntryForBlock(ctx, header, false)
+	if err != nil {
+		return false, err
+	}
 
 	var gsEnd GoGlobalState
 	if full {
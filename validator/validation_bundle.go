@@ -0,0 +1,151 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// A validation bundle is a single file holding everything needed to re-run the
+// validation of a block with the machine for its module root, with no chain
+// database: the magic bytes, the format version as a big endian uint64, then
+// the gzipped JSON encoding of the ValidationBundle.
+const ValidationBundleVersion uint64 = 1
+
+var validationBundleMagic = []byte("NITROVB\n")
+
+type ValidationBundle struct {
+	Version uint64
+	// Which machine the bundle was created for is ignored; the one to run it
+	// on is chosen when running it.
+	ValidationInput
+	BlockHash common.Hash
+	EndState  GoGlobalState
+}
+
+func WriteValidationBundle(w io.Writer, bundle *ValidationBundle) error {
+	if _, err := w.Write(validationBundleMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, bundle.Version); err != nil {
+		return err
+	}
+	compressor := gzip.NewWriter(w)
+	if err := json.NewEncoder(compressor).Encode(bundle); err != nil {
+		return err
+	}
+	return compressor.Close()
+}
+
+func ReadValidationBundle(r io.Reader) (*ValidationBundle, error) {
+	magic := make([]byte, len(validationBundleMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, fmt.Errorf("error reading validation bundle header: %w", err)
+	}
+	if !bytes.Equal(magic, validationBundleMagic) {
+		return nil, errors.New("not a validation bundle")
+	}
+	var version uint64
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return nil, fmt.Errorf("error reading validation bundle version: %w", err)
+	}
+	if version == 0 || version > ValidationBundleVersion {
+		return nil, fmt.Errorf("unsupported validation bundle version %d, the latest supported is %d", version, ValidationBundleVersion)
+	}
+	decompressor, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer decompressor.Close()
+	var bundle ValidationBundle
+	if err := json.NewDecoder(decompressor).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("error decoding validation bundle: %w", err)
+	}
+	if bundle.Version != version {
+		return nil, fmt.Errorf("validation bundle header has version %d but its contents %d", version, bundle.Version)
+	}
+	return &bundle, nil
+}
+
+func ReadValidationBundleFile(path string) (*ValidationBundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadValidationBundle(file)
+}
+
+func WriteValidationBundleFile(path string, bundle *ValidationBundle) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteValidationBundle(file, bundle); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Run runs the bundle's validation with the machine for its module root, and
+// returns the global state it ends at.
+func (b *ValidationBundle) Run(ctx context.Context, machineLoader *NitroMachineLoader, jit bool) (GoGlobalState, error) {
+	input := b.ValidationInput
+	input.Jit = jit
+	return RunValidationInput(ctx, machineLoader, &input)
+}
+
+// CreateValidationBundle records the validation of the block with header with
+// every preimage it reads, so that it can be re-run with no chain database.
+func (v *StatelessBlockValidator) CreateValidationBundle(
+	ctx context.Context, header *types.Header, moduleRoot common.Hash,
+) (*ValidationBundle, error) {
+	entry, err := v.validationEntryForBlock(ctx, header, true)
+	if err != nil {
+		return nil, err
+	}
+	input, _, err := v.validationInput(ctx, entry, moduleRoot, false)
+	if err != nil {
+		return nil, err
+	}
+	return &ValidationBundle{
+		Version:         ValidationBundleVersion,
+		ValidationInput: *input,
+		BlockHash:       entry.BlockHash,
+		EndState:        entry.expectedEnd(),
+	}, nil
+}
+
+// writeBundle writes a validation bundle of the block to the output path,
+// beside the files writeToFile writes, returning the file written.
+func (v *BlockValidator) writeBundle(ctx context.Context, blockNumber uint64, moduleRoot common.Hash) (string, error) {
+	header := v.blockchain.GetHeaderByNumber(blockNumber)
+	if header == nil {
+		return "", fmt.Errorf("block %d not found", blockNumber)
+	}
+	bundle, err := v.CreateValidationBundle(ctx, header, moduleRoot)
+	if err != nil {
+		return "", err
+	}
+	outDirPath := v.outputDirPath(blockNumber)
+	if err := os.MkdirAll(outDirPath, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(outDirPath, fmt.Sprintf("validation_%s.bundle", moduleRoot))
+	return path, WriteValidationBundleFile(path, bundle)
+}
@@ -0,0 +1,42 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestValidationBundleRoundTrip(t *testing.T) {
+	bundle := &ValidationBundle{
+		Version:         ValidationBundleVersion,
+		ValidationInput: *testValidationInput(42),
+		BlockHash:       common.HexToHash("0xb10c"),
+		EndState:        GoGlobalState{BlockHash: common.HexToHash("0xb10c"), Batch: 43},
+	}
+	path := filepath.Join(t.TempDir(), "block.bundle")
+	Require(t, WriteValidationBundleFile(path, bundle))
+	read, err := ReadValidationBundleFile(path)
+	Require(t, err)
+	if !reflect.DeepEqual(read, bundle) {
+		Fail(t, "read", read, "but wrote", bundle)
+	}
+
+	var buf bytes.Buffer
+	Require(t, WriteValidationBundle(&buf, bundle))
+	encoded := buf.Bytes()
+	if _, err := ReadValidationBundle(bytes.NewReader(encoded[1:])); err == nil {
+		Fail(t, "expected a bundle without its magic bytes to be rejected")
+	}
+	future := append([]byte{}, encoded...)
+	binary.BigEndian.PutUint64(future[len(validationBundleMagic):], ValidationBundleVersion+1)
+	if _, err := ReadValidationBundle(bytes.NewReader(future)); err == nil {
+		Fail(t, "expected a bundle of a later version to be rejected")
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+// ValidationInput is what a validation worker needs to validate a block: the
+// validationEntry's start state and batches, the delayed message it reads, and
+// every preimage the machine may resolve, as workers have no chain database.
+// Preimages too large to send in one request are sent beforehand with
+// AddPreimages under the upload PreimageUpload, and the worker adds them to
+// the input's.
+type ValidationInput struct {
+	ModuleRoot     common.Hash
+	Jit            bool
+	BlockNumber    uint64
+	StartState     GoGlobalState
+	BatchInfo      []BatchInfo
+	HasDelayedMsg  bool
+	DelayedMsgNr   uint64
+	DelayedMsg     []byte
+	Preimages      map[common.Hash][]byte
+	PreimageUpload string
+}
+
+// validationInput collects the input to validate entry remotely, and returns
+// it with the delayed message read.
+func (v *StatelessBlockValidator) validationInput(
+	ctx context.Context, entry *validationEntry, moduleRoot common.Hash, jit bool,
+) (*ValidationInput, []byte, error) {
+	if entry.Preimages == nil {
+		return nil, nil, fmt.Errorf("validation entry for block %d has no preimages recorded", entry.BlockNumber)
+	}
+	// Adds the preimages of DAS batches to the entry's.
+	_, err := NewMachinePreimageResolver(ctx, entry.Preimages, entry.BatchInfo, v.blockchain, v.daService)
+	if err != nil {
+		return nil, nil, err
+	}
+	var delayedMsg []byte
+	if entry.HasDelayedMsg {
+		delayedMsg, err = v.inboxTracker.GetDelayedMessageBytes(entry.DelayedMsgNr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading delayed msg %d for block %d: %w", entry.DelayedMsgNr, entry.BlockNumber, err)
+		}
+	}
+	return &ValidationInput{
+		ModuleRoot:    moduleRoot,
+		Jit:           jit,
+		BlockNumber:   entry.BlockNumber,
+		StartState:    entry.start(),
+		BatchInfo:     entry.BatchInfo,
+		HasDelayedMsg: entry.HasDelayedMsg,
+		DelayedMsgNr:  entry.DelayedMsgNr,
+		DelayedMsg:    delayedMsg,
+		Preimages:     entry.Preimages,
+	}, delayedMsg, nil
+}
+
+func (i *ValidationInput) entry() *validationEntry {
+	return &validationEntry{
+		BlockNumber:   i.BlockNumber,
+		PrevBlockHash: i.StartState.BlockHash,
+		PrevSendRoot:  i.StartState.SendRoot,
+		HasDelayedMsg: i.HasDelayedMsg,
+		DelayedMsgNr:  i.DelayedMsgNr,
+		StartPosition: GlobalStatePosition{
+			BatchNumber: i.StartState.Batch,
+			PosInBatch:  i.StartState.PosInBatch,
+		},
+		Preimages: i.Preimages,
+		BatchInfo: i.BatchInfo,
+	}
+}
+
+// How long a worker keeps preimages uploaded for a validation that hasn't
+// been sent, after the last of them were added.
+const preimageUploadTimeout = 10 * time.Minute
+
+// The error code a worker returns validations with if it doesn't have their
+// preimage upload, which another worker may not fail with.
+const missingPreimageUploadErrorCode = -32050
+
+type missingPreimageUploadError struct {
+	upload string
+}
+
+func (e missingPreimageUploadError) Error() string {
+	return fmt.Sprintf("preimage upload %v not found", e.upload)
+}
+
+func (e missingPreimageUploadError) ErrorCode() int {
+	return missingPreimageUploadErrorCode
+}
+
+type preimageUpload struct {
+	preimages map[common.Hash][]byte
+	expires   time.Time
+}
+
+// ValidationWorker runs machines on the ValidationInputs nodes send it, and
+// returns the global states they end at. It's served as the "validation" RPC
+// namespace.
+type ValidationWorker struct {
+	machineLoader *NitroMachineLoader
+	runs          chan struct{}
+	execute       func(context.Context, *ValidationInput) (GoGlobalState, error)
+
+	uploadsMutex sync.Mutex
+	uploads      map[string]*preimageUpload
+}
+
+func NewValidationWorker(machineLoader *NitroMachineLoader, concurrentRunsLimit int) *ValidationWorker {
+	worker := &ValidationWorker{
+		machineLoader: machineLoader,
+		runs:          make(chan struct{}, concurrentRunsLimit),
+		uploads:       make(map[string]*preimageUpload),
+	}
+	worker.execute = worker.executeInput
+	return worker
+}
+
+// AddPreimages adds preimages to the upload upload, for the validation sent
+// with it as its PreimageUpload.
+func (w *ValidationWorker) AddPreimages(upload string, preimages map[common.Hash][]byte) error {
+	if upload == "" {
+		return errors.New("no preimage upload given")
+	}
+	w.uploadsMutex.Lock()
+	defer w.uploadsMutex.Unlock()
+	now := time.Now()
+	// Drop the uploads of validations that were never sent.
+	for id, stale := range w.uploads {
+		if now.After(stale.expires) {
+			delete(w.uploads, id)
+		}
+	}
+	existing, ok := w.uploads[upload]
+	if !ok {
+		existing = &preimageUpload{preimages: make(map[common.Hash][]byte)}
+		w.uploads[upload] = existing
+	}
+	for hash, preimage := range preimages {
+		existing.preimages[hash] = preimage
+	}
+	existing.expires = now.Add(preimageUploadTimeout)
+	return nil
+}
+
+// takePreimageUpload adds the preimages uploaded for input to its own.
+func (w *ValidationWorker) takePreimageUpload(input *ValidationInput) error {
+	if input.PreimageUpload == "" {
+		return nil
+	}
+	w.uploadsMutex.Lock()
+	upload, ok := w.uploads[input.PreimageUpload]
+	delete(w.uploads, input.PreimageUpload)
+	w.uploadsMutex.Unlock()
+	if !ok {
+		return missingPreimageUploadError{input.PreimageUpload}
+	}
+	if input.Preimages == nil {
+		input.Preimages = upload.preimages
+		return nil
+	}
+	for hash, preimage := range upload.preimages {
+		input.Preimages[hash] = preimage
+	}
+	return nil
+}
+
+// Validate runs input's machine once fewer than the concurrent runs limit are
+// running, and returns the global state it ends at.
+func (w *ValidationWorker) Validate(ctx context.Context, input *ValidationInput) (*GoGlobalState, error) {
+	if err := w.takePreimageUpload(input); err != nil {
+		return nil, err
+	}
+	select {
+	case w.runs <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-w.runs }()
+
+	before := time.Now()
+	gsEnd, err := w.execute(ctx, input)
+	if err != nil {
+		log.Warn("Validation failed", "blockNr", input.BlockNumber, "moduleRoot", input.ModuleRoot, "jit", input.Jit, "err", err)
+		return nil, err
+	}
+	log.Info("Validation ran", "blockNr", input.BlockNumber, "moduleRoot", input.ModuleRoot, "jit", input.Jit, "time", time.Since(before))
+	return &gsEnd, nil
+}
+
+func (w *ValidationWorker) executeInput(ctx context.Context, input *ValidationInput) (GoGlobalState, error) {
+	return RunValidationInput(ctx, w.machineLoader, input)
+}
+
+// RunValidationInput runs input's machine, resolving preimages only from those
+// in input, and returns the global state it ends at.
+func RunValidationInput(ctx context.Context, machineLoader *NitroMachineLoader, input *ValidationInput) (GoGlobalState, error) {
+	resolver := func(hash common.Hash) ([]byte, error) {
+		if preimage, ok := input.Preimages[hash]; ok {
+			return preimage, nil
+		}
+		return nil, fmt.Errorf("preimage %v not sent", hash)
+	}
+	entry := input.entry()
+	if input.Jit {
+		machine, err := machineLoader.GetJitMachine(ctx, input.ModuleRoot, true)
+		if err != nil {
+			return GoGlobalState{}, fmt.Errorf("unabled to get WASM machine: %w", err)
+		}
+		return machine.prove(entry, resolver, input.DelayedMsg)
+	}
+	machine, err := machineLoader.GetMachine(ctx, input.ModuleRoot, true)
+	if err != nil {
+		return GoGlobalState{}, fmt.Errorf("unabled to get WASM machine: %w", err)
+	}
+	return executeMachine(ctx, machine, entry, input.ModuleRoot, resolver, input.DelayedMsg)
+}
+
+// ServeValidationWorker serves worker's RPC API over both HTTP and WebSocket on
+// listener.
+func ServeValidationWorker(listener net.Listener, timeouts genericconf.HTTPServerTimeoutConfig, worker *ValidationWorker) (*http.Server, error) {
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("validation", worker); err != nil {
+		return nil, err
+	}
+	wsHandler := rpcServer.WebsocketHandler([]string{"*"})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			wsHandler.ServeHTTP(w, r)
+			return
+		}
+		rpcServer.ServeHTTP(w, r)
+	})
+	server := &http.Server{
+		Handler:           handler,
+		ReadTimeout:       timeouts.ReadTimeout,
+		ReadHeaderTimeout: timeouts.ReadHeaderTimeout,
+		WriteTimeout:      timeouts.WriteTimeout,
+		IdleTimeout:       timeouts.IdleTimeout,
+	}
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Validation worker server exited", "err", err)
+		}
+	}()
+	return server, nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type RemoteValidationConfig struct {
+	Workers        []string      `koanf:"workers"`
+	RunsPerWorker  int           `koanf:"runs-per-worker"`
+	Retries        int           `koanf:"retries"`
+	Timeout        time.Duration `koanf:"timeout"`
+	FailureBackoff time.Duration `koanf:"failure-backoff"`
+}
+
+func RemoteValidationConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.StringSlice(prefix+".workers", DefaultRemoteValidationConfig.Workers, "HTTP or WebSocket URLs of validation workers to run validations on instead of in-process")
+	f.Int(prefix+".runs-per-worker", DefaultRemoteValidationConfig.RunsPerWorker, "validations to run on each worker at once, if concurrent-runs-limit isn't set")
+	f.Int(prefix+".retries", DefaultRemoteValidationConfig.Retries, "times to retry a validation on another worker if a worker fails")
+	f.Duration(prefix+".timeout", DefaultRemoteValidationConfig.Timeout, "timeout for a worker to run a validation")
+	f.Duration(prefix+".failure-backoff", DefaultRemoteValidationConfig.FailureBackoff, "time to avoid sending validations to a worker after it fails")
+}
+
+var DefaultRemoteValidationConfig = RemoteValidationConfig{
+	Workers:        []string{},
+	RunsPerWorker:  4,
+	Retries:        3,
+	Timeout:        10 * time.Minute,
+	FailureBackoff: 30 * time.Second,
+}
+
+// ErrValidationWorkersUnavailable is returned when no worker could be reached
+// to run a validation, which says nothing about whether the block is valid.
+var ErrValidationWorkersUnavailable = errors.New("no validation worker available")
+
+func (c *RemoteValidationConfig) Enabled() bool {
+	return len(c.Workers) > 0
+}
+
+type remoteValidationWorker struct {
+	url         string
+	client      *workerClient // nil until dialled, and after failing
+	inFlight    int
+	failedUntil time.Time
+}
+
+// workerClient is a connection to a worker shared by the validations running
+// on it, which is closed once none are.
+type workerClient struct {
+	*rpc.Client
+	users int
+}
+
+// ValidationWorkerPool runs validations on remote ValidationWorkers, sending
+// each to the worker with the fewest running that hasn't recently failed, and
+// retrying on another worker if one fails.
+type ValidationWorkerPool struct {
+	config  *RemoteValidationConfig
+	mutex   sync.Mutex
+	workers []*remoteValidationWorker
+	closed  bool
+}
+
+func NewValidationWorkerPool(config *RemoteValidationConfig) (*ValidationWorkerPool, error) {
+	if !config.Enabled() {
+		return nil, errors.New("no validation workers configured")
+	}
+	pool := &ValidationWorkerPool{config: config}
+	for _, url := range config.Workers {
+		pool.workers = append(pool.workers, &remoteValidationWorker{url: url})
+	}
+	return pool, nil
+}
+
+// acquire picks the worker to send a validation to, preferring those not
+// tried yet for it. Workers are dialled lazily, so that one down at startup
+// isn't fatal.
+func (p *ValidationWorkerPool) acquire(ctx context.Context, tried map[*remoteValidationWorker]bool) (*remoteValidationWorker, *workerClient, error) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil, nil, errors.New("validation worker pool closed")
+	}
+	now := time.Now()
+	better := func(a, b *remoteValidationWorker) bool {
+		if tried[a] != tried[b] {
+			return !tried[a]
+		}
+		aFailed, bFailed := a.failedUntil.After(now), b.failedUntil.After(now)
+		if aFailed != bFailed {
+			return !aFailed
+		}
+		if aFailed {
+			return a.failedUntil.Before(b.failedUntil)
+		}
+		return a.inFlight < b.inFlight
+	}
+	var best *remoteValidationWorker
+	for _, worker := range p.workers {
+		if best == nil || better(worker, best) {
+			best = worker
+		}
+	}
+	best.inFlight++
+	if client := best.client; client != nil {
+		client.users++
+		p.mutex.Unlock()
+		return best, client, nil
+	}
+	p.mutex.Unlock()
+
+	// Dial without holding the mutex, which validations finishing need.
+	dialled, err := rpc.DialContext(ctx, best.url)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err != nil {
+		best.inFlight--
+		best.failedUntil = time.Now().Add(p.config.FailureBackoff)
+		return best, nil, err
+	}
+	if p.closed {
+		best.inFlight--
+		dialled.Close()
+		return best, nil, errors.New("validation worker pool closed")
+	}
+	if best.client != nil {
+		// Another validation dialled the worker meanwhile.
+		dialled.Close()
+	} else {
+		best.client = &workerClient{Client: dialled}
+	}
+	best.client.users++
+	return best, best.client, nil
+}
+
+// release returns client, acquired for worker. If the validation failed to
+// reach the worker, it isn't sent more until it's backed off and redialled,
+// but client is only closed once the validations still using it are done.
+func (p *ValidationWorkerPool) release(worker *remoteValidationWorker, client *workerClient, failed bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	worker.inFlight--
+	client.users--
+	if failed {
+		worker.failedUntil = time.Now().Add(p.config.FailureBackoff)
+		if worker.client == client {
+			worker.client = nil
+		}
+	}
+	if worker.client != client && client.users == 0 {
+		client.Close()
+	}
+}
+
+// Validate runs input on a worker and returns the global state it ends at.
+// Errors returned by a worker that ran the validation aren't retried, as
+// another would fail the same way. If no worker could be reached, the error
+// wraps ErrValidationWorkersUnavailable.
+func (p *ValidationWorkerPool) Validate(ctx context.Context, input *ValidationInput) (GoGlobalState, error) {
+	tried := make(map[*remoteValidationWorker]bool)
+	var lastErr error
+	for attempt := 0; attempt <= p.config.Retries; attempt++ {
+		worker, client, err := p.acquire(ctx, tried)
+		if worker == nil {
+			return GoGlobalState{}, err
+		}
+		tried[worker] = true
+		if err == nil {
+			var gsEnd GoGlobalState
+			gsEnd, err = p.validateOn(ctx, client.Client, input)
+			var rpcErr rpc.Error
+			if err == nil || (errors.As(err, &rpcErr) && rpcErr.ErrorCode() != missingPreimageUploadErrorCode) {
+				p.release(worker, client, false)
+				return gsEnd, err
+			}
+			p.release(worker, client, true)
+		}
+		if ctx.Err() != nil {
+			return GoGlobalState{}, ctx.Err()
+		}
+		log.Warn("Validation worker failed", "worker", worker.url, "blockNr", input.BlockNumber, "attempt", attempt, "err", err)
+		lastErr = err
+	}
+	return GoGlobalState{}, fmt.Errorf("%w: block %d failed on %d attempts: %v", ErrValidationWorkersUnavailable, input.BlockNumber, p.config.Retries+1, lastErr)
+}
+
+// The most preimage bytes to send a worker per request, which keeps requests
+// well under the 5MB that geth's RPC server accepts over HTTP, even encoded.
+const maxPreimageBytesPerRequest = 2 * 1024 * 1024
+
+func (p *ValidationWorkerPool) validateOn(ctx context.Context, client *rpc.Client, input *ValidationInput) (GoGlobalState, error) {
+	if p.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+	size := 0
+	for _, preimage := range input.Preimages {
+		size += len(preimage)
+	}
+	if size > maxPreimageBytesPerRequest {
+		// Upload the preimages in chunks, and send the input without them.
+		var err error
+		input, err = uploadPreimages(ctx, client, input)
+		if err != nil {
+			return GoGlobalState{}, err
+		}
+	}
+	var gsEnd GoGlobalState
+	err := client.CallContext(ctx, &gsEnd, "validation_validate", input)
+	return gsEnd, err
+}
+
+// uploadPreimages sends input's preimages to a worker in requests of at most
+// maxPreimageBytesPerRequest each (or a single larger preimage), and returns
+// the input to send the worker for it to validate with them.
+func uploadPreimages(ctx context.Context, client *rpc.Client, input *ValidationInput) (*ValidationInput, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	upload := hex.EncodeToString(id[:])
+	chunk := make(map[common.Hash][]byte)
+	chunkSize := 0
+	send := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		err := client.CallContext(ctx, nil, "validation_addPreimages", upload, chunk)
+		chunk = make(map[common.Hash][]byte)
+		chunkSize = 0
+		return err
+	}
+	for hash, preimage := range input.Preimages {
+		if chunkSize > 0 && chunkSize+len(preimage) > maxPreimageBytesPerRequest {
+			if err := send(); err != nil {
+				return nil, err
+			}
+		}
+		chunk[hash] = preimage
+		chunkSize += len(preimage)
+	}
+	if err := send(); err != nil {
+		return nil, err
+	}
+	withoutPreimages := *input
+	withoutPreimages.Preimages = nil
+	withoutPreimages.PreimageUpload = upload
+	return &withoutPreimages, nil
+}
+
+func (p *ValidationWorkerPool) Close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.closed = true
+	for _, worker := range p.workers {
+		if worker.client != nil {
+			worker.client.Close()
+			worker.client = nil
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+var errTestValidation = errors.New("machine failed")
+
+const failingTestBlock = 1000
+
+// testValidationResult stands in for running a machine, deriving the end state
+// from everything a worker is sent.
+func testValidationResult(input *ValidationInput) GoGlobalState {
+	var preimagesHash common.Hash
+	for hash, preimage := range input.Preimages {
+		if crypto.Keccak256Hash(preimage) == hash {
+			for i := range preimagesHash {
+				preimagesHash[i] ^= hash[i]
+			}
+		}
+	}
+	return GoGlobalState{
+		BlockHash:  crypto.Keccak256Hash(input.StartState.BlockHash[:], input.BatchInfo[0].Data, input.DelayedMsg),
+		SendRoot:   preimagesHash,
+		Batch:      input.StartState.Batch + 1,
+		PosInBatch: uint64(len(input.Preimages)),
+	}
+}
+
+func startTestValidationWorker(t *testing.T, calls *int32) string {
+	worker := NewValidationWorker(nil, 2)
+	worker.execute = func(ctx context.Context, input *ValidationInput) (GoGlobalState, error) {
+		atomic.AddInt32(calls, 1)
+		if input.BlockNumber == failingTestBlock {
+			return GoGlobalState{}, errTestValidation
+		}
+		time.Sleep(10 * time.Millisecond)
+		return testValidationResult(input), nil
+	}
+	listener, err := net.Listen("tcp", "localhost:0")
+	Require(t, err)
+	server, err := ServeValidationWorker(listener, genericconf.HTTPServerTimeoutConfigDefault, worker)
+	Require(t, err)
+	t.Cleanup(func() {
+		Require(t, server.Shutdown(context.Background()))
+	})
+	return listener.Addr().String()
+}
+
+func testValidationInput(blockNumber uint64) *ValidationInput {
+	preimage := []byte(fmt.Sprintf("preimage of block %d", blockNumber))
+	return &ValidationInput{
+		ModuleRoot:  common.HexToHash("0x1234"),
+		BlockNumber: blockNumber,
+		StartState: GoGlobalState{
+			BlockHash: common.BigToHash(common.Big1),
+			Batch:     blockNumber,
+		},
+		BatchInfo:     []BatchInfo{{Number: blockNumber, Data: []byte{byte(blockNumber), 1, 2, 3}}},
+		HasDelayedMsg: true,
+		DelayedMsgNr:  7,
+		DelayedMsg:    []byte("delayed"),
+		Preimages:     map[common.Hash][]byte{crypto.Keccak256Hash(preimage): preimage},
+	}
+}
+
+func TestRemoteValidationWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var callsA, callsB int32
+	addrA := startTestValidationWorker(t, &callsA)
+	addrB := startTestValidationWorker(t, &callsB)
+	deadListener, err := net.Listen("tcp", "localhost:0")
+	Require(t, err)
+	deadAddr := deadListener.Addr().String()
+	Require(t, deadListener.Close())
+
+	config := DefaultRemoteValidationConfig
+	config.Workers = []string{"http://" + deadAddr, "ws://" + addrA, "http://" + addrB}
+	config.Retries = 2
+	config.FailureBackoff = time.Minute
+	pool, err := NewValidationWorkerPool(&config)
+	Require(t, err)
+	defer pool.Close()
+
+	const validations = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, validations)
+	for i := uint64(0); i < validations; i++ {
+		wg.Add(1)
+		go func(blockNumber uint64) {
+			defer wg.Done()
+			input := testValidationInput(blockNumber)
+			gsEnd, err := pool.Validate(ctx, input)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if gsEnd != testValidationResult(input) {
+				errs <- fmt.Errorf("block %d validated to %v, expected %v", blockNumber, gsEnd, testValidationResult(input))
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		Require(t, err)
+	}
+	ranA, ranB := atomic.LoadInt32(&callsA), atomic.LoadInt32(&callsB)
+	if ranA+ranB != validations {
+		Fail(t, "expected", validations, "validations run, got", ranA+ranB)
+	}
+	if ranA == 0 || ranB == 0 {
+		Fail(t, "validations weren't spread over the workers:", ranA, ranB)
+	}
+
+	// Failures of the validation itself aren't retried.
+	_, err = pool.Validate(ctx, testValidationInput(failingTestBlock))
+	if err == nil || errors.Is(err, ErrValidationWorkersUnavailable) {
+		Fail(t, "expected the validation to fail on the worker, got", err)
+	}
+	ran := atomic.LoadInt32(&callsA) + atomic.LoadInt32(&callsB) - validations
+	if ran != 1 {
+		Fail(t, "failed validation was run", ran, "times")
+	}
+
+	// With no live workers, it gives up after the retries.
+	deadConfig := config
+	deadConfig.Workers = []string{"http://" + deadAddr}
+	deadPool, err := NewValidationWorkerPool(&deadConfig)
+	Require(t, err)
+	defer deadPool.Close()
+	if _, err := deadPool.Validate(ctx, testValidationInput(0)); !errors.Is(err, ErrValidationWorkersUnavailable) {
+		Fail(t, "expected validation with no live workers to fail as unavailable, got", err)
+	}
+}
+
+func TestRemoteValidationLargePreimages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	addr := startTestValidationWorker(t, &calls)
+
+	// Far more than a single request may hold, over either transport.
+	input := testValidationInput(1)
+	for i := 0; i < 160; i++ {
+		preimage := make([]byte, 128*1024)
+		_, err := rand.Read(preimage)
+		Require(t, err)
+		input.Preimages[crypto.Keccak256Hash(preimage)] = preimage
+	}
+	for _, url := range []string{"http://" + addr, "ws://" + addr} {
+		config := DefaultRemoteValidationConfig
+		config.Workers = []string{url}
+		config.Retries = 0
+		pool, err := NewValidationWorkerPool(&config)
+		Require(t, err)
+		gsEnd, err := pool.Validate(ctx, input)
+		pool.Close()
+		Require(t, err, url)
+		if gsEnd != testValidationResult(input) {
+			Fail(t, "validated over", url, "to", gsEnd, "expected", testValidationResult(input))
+		}
+	}
+
+	// A validation whose preimages the worker doesn't have is failed with an
+	// error the pool retries.
+	client, err := rpc.DialContext(ctx, "http://"+addr)
+	Require(t, err)
+	defer client.Close()
+	missing := testValidationInput(2)
+	missing.PreimageUpload = "missing"
+	var gsEnd GoGlobalState
+	err = client.CallContext(ctx, &gsEnd, "validation_validate", missing)
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != missingPreimageUploadErrorCode {
+		Fail(t, "expected a missing preimage upload error, got", err)
+	}
+}
+
+func TestRemoteValidationWorkerFailureKeepsClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	addr := startTestValidationWorker(t, &calls)
+	config := DefaultRemoteValidationConfig
+	config.Workers = []string{"ws://" + addr}
+	pool, err := NewValidationWorkerPool(&config)
+	Require(t, err)
+	defer pool.Close()
+
+	worker, first, err := pool.acquire(ctx, nil)
+	Require(t, err)
+	_, second, err := pool.acquire(ctx, nil)
+	Require(t, err)
+	if first != second {
+		Fail(t, "validations on the same worker didn't share its client")
+	}
+
+	// One validation failing doesn't break the other running on the client.
+	pool.release(worker, first, true)
+	input := testValidationInput(3)
+	gsEnd, err := pool.validateOn(ctx, second.Client, input)
+	Require(t, err)
+	if gsEnd != testValidationResult(input) {
+		Fail(t, "validated to", gsEnd, "expected", testValidationResult(input))
+	}
+	pool.release(worker, second, false)
+
+	// The next validation redials the worker.
+	_, third, err := pool.acquire(ctx, nil)
+	Require(t, err)
+	if third == second {
+		Fail(t, "reused the client of a failed worker")
+	}
+	pool.release(worker, third, false)
+}
@@ -14,7 +14,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/solgen/go/rollupgen"
+	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/pkg/errors"
 )
 
@@ -35,18 +37,36 @@ func init() {
 	walletCreatedID = parsedValidatorWalletCreator.Events["WalletCreated"].ID
 }
 
+// TxFees overrides the fees the wallet's transactions are sent with. Nil fields
+// are left for the client to suggest.
+type TxFees struct {
+	GasPrice  *big.Int // for chains without a base fee
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+}
+
+type inFlightTx struct {
+	tx        *types.Transaction
+	signer    util.Signer
+	sentBlock uint64
+}
+
 type ValidatorWallet struct {
 	con               *rollupgen.ValidatorWallet
 	address           *common.Address
 	onWalletCreated   func(common.Address)
 	l1Reader          L1ReaderInterface
-	auth              *bind.TransactOpts
+	signer            util.Signer
+	l1ChainId         *big.Int // read from the L1 client when first needed
 	rollupAddress     common.Address
 	walletFactoryAddr common.Address
 	rollupFromBlock   int64
+	fees              *TxFees
+	// Transactions sent but not yet known to be mined, in nonce order.
+	inFlight []*inFlightTx
 }
 
-func NewValidatorWallet(address *common.Address, walletFactoryAddr, rollupAddress common.Address, l1Reader L1ReaderInterface, auth *bind.TransactOpts, rollupFromBlock int64, onWalletCreated func(common.Address)) (*ValidatorWallet, error) {
+func NewValidatorWallet(address *common.Address, walletFactoryAddr, rollupAddress common.Address, l1Reader L1ReaderInterface, signer util.Signer, rollupFromBlock int64, onWalletCreated func(common.Address)) (*ValidatorWallet, error) {
 	var con *rollupgen.ValidatorWallet
 	if address != nil {
 		var err error
@@ -60,7 +80,7 @@ func NewValidatorWallet(address *common.Address, walletFactoryAddr, rollupAddres
 		address:           address,
 		onWalletCreated:   onWalletCreated,
 		l1Reader:          l1Reader,
-		auth:              auth,
+		signer:            signer,
 		rollupAddress:     rollupAddress,
 		walletFactoryAddr: walletFactoryAddr,
 		rollupFromBlock:   rollupFromBlock,
@@ -85,36 +105,87 @@ func (v *ValidatorWallet) AddressOrZero() common.Address {
 }
 
 func (v *ValidatorWallet) From() common.Address {
-	if v.auth == nil {
+	if v.signer == nil {
 		return common.Address{}
 	}
-	return v.auth.From
+	return v.signer.Address()
 }
 
 func (v *ValidatorWallet) RollupAddress() common.Address {
 	return v.rollupAddress
 }
 
-func (v *ValidatorWallet) executeTransaction(ctx context.Context, tx *types.Transaction, gasRefunder common.Address) (*types.Transaction, error) {
-	oldAuthValue := v.auth.Value
-	v.auth.Value = tx.Value()
-	defer (func() { v.auth.Value = oldAuthValue })()
+// HasTransactionsInFlight returns whether the wallet has sent transactions not
+// yet known to be mined.
+func (v *ValidatorWallet) HasTransactionsInFlight() bool {
+	return len(v.inFlight) > 0
+}
+
+// SetTxFees sets the fees of the transactions the wallet sends from now on, or
+// leaves them to the client if fees is nil.
+func (v *ValidatorWallet) SetTxFees(fees *TxFees) {
+	v.fees = fees
+}
+
+func (v *ValidatorWallet) chainId(ctx context.Context) (*big.Int, error) {
+	if v.l1ChainId == nil {
+		chainId, err := v.l1Reader.Client().ChainID(ctx)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		v.l1ChainId = chainId
+	}
+	return v.l1ChainId, nil
+}
+
+func (v *ValidatorWallet) transactOpts(ctx context.Context, value *big.Int) (*bind.TransactOpts, error) {
+	return v.transactOptsFor(ctx, v.signer, value)
+}
 
-	return v.con.ExecuteTransactionWithGasRefunder(v.auth, gasRefunder, tx.Data(), *tx.To(), tx.Value())
+func (v *ValidatorWallet) transactOptsFor(ctx context.Context, signer util.Signer, value *big.Int) (*bind.TransactOpts, error) {
+	chainId, err := v.chainId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := util.TransactOpts(ctx, signer, chainId)
+	opts.Value = value
+	if v.fees != nil {
+		opts.GasPrice = v.fees.GasPrice
+		opts.GasFeeCap = v.fees.GasFeeCap
+		opts.GasTipCap = v.fees.GasTipCap
+	}
+	return opts, nil
+}
+
+func (v *ValidatorWallet) trackTransaction(ctx context.Context, signer util.Signer, tx *types.Transaction) {
+	sentBlock, err := v.l1Reader.Client().BlockNumber(ctx)
+	if err != nil {
+		// It'll be replaced at the first chance rather than never.
+		log.Warn("error getting block number to track validator wallet transaction", "tx", tx.Hash(), "err", err)
+	}
+	v.inFlight = append(v.inFlight, &inFlightTx{tx: tx, signer: signer, sentBlock: sentBlock})
+}
+
+func (v *ValidatorWallet) executeTransaction(ctx context.Context, tx *types.Transaction, gasRefunder common.Address) (*types.Transaction, error) {
+	opts, err := v.transactOpts(ctx, tx.Value())
+	if err != nil {
+		return nil, err
+	}
+	return v.con.ExecuteTransactionWithGasRefunder(opts, gasRefunder, tx.Data(), *tx.To(), tx.Value())
 }
 
 func (v *ValidatorWallet) populateWallet(ctx context.Context, createIfMissing bool) error {
 	if v.con != nil {
 		return nil
 	}
-	if v.auth == nil {
+	if v.signer == nil {
 		if createIfMissing {
 			return errors.New("cannot create validator smart contract wallet without key wallet")
 		}
 		return nil
 	}
 	if v.address == nil {
-		addr, err := GetValidatorWallet(ctx, v.walletFactoryAddr, v.rollupFromBlock, v.auth, v.l1Reader, createIfMissing)
+		addr, err := GetValidatorWallet(ctx, v.walletFactoryAddr, v.rollupFromBlock, v.signer, v.l1Reader, createIfMissing)
 		if err != nil {
 			return err
 		}
@@ -166,6 +237,7 @@ func (v *ValidatorWallet) ExecuteTransactions(ctx context.Context, builder *Vali
 		if err != nil {
 			return nil, err
 		}
+		v.trackTransaction(ctx, v.signer, arbTx)
 		builder.transactions = nil
 		return arbTx, nil
 	}
@@ -187,30 +259,300 @@ func (v *ValidatorWallet) ExecuteTransactions(ctx context.Context, builder *Vali
 		return nil, err
 	}
 
-	oldAuthValue := v.auth.Value
-	v.auth.Value = new(big.Int).Sub(totalAmount, balanceInContract)
-	if v.auth.Value.Sign() < 0 {
-		v.auth.Value.SetInt64(0)
+	callValue := new(big.Int).Sub(totalAmount, balanceInContract)
+	if callValue.Sign() < 0 {
+		callValue.SetInt64(0)
 	}
-	defer (func() { v.auth.Value = oldAuthValue })()
 
-	arbTx, err := v.con.ExecuteTransactionsWithGasRefunder(v.auth, gasRefunder, data, dest, amount)
+	opts, err := v.transactOpts(ctx, callValue)
 	if err != nil {
 		return nil, err
 	}
+	arbTx, err := v.con.ExecuteTransactionsWithGasRefunder(opts, gasRefunder, data, dest, amount)
+	if err != nil {
+		return nil, err
+	}
+	v.trackTransaction(ctx, v.signer, arbTx)
 	builder.transactions = nil
 	return arbTx, nil
 }
 
 func (v *ValidatorWallet) TimeoutChallenges(ctx context.Context, manager common.Address, challenges []uint64) (*types.Transaction, error) {
-	return v.con.TimeoutChallenges(v.auth, manager, challenges)
+	opts, err := v.transactOpts(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := v.con.TimeoutChallenges(opts, manager, challenges)
+	if err != nil {
+		return nil, err
+	}
+	v.trackTransaction(ctx, v.signer, tx)
+	return tx, nil
+}
+
+// bumpFee returns the fee a replacement must pay to replace one paying oldFee,
+// raised to at least minFee if that's higher.
+func bumpFee(oldFee *big.Int, minFee *big.Int, bumpPercent uint64) *big.Int {
+	bumped := arbmath.BigMulByUfrac(oldFee, 100+bumpPercent, 100)
+	// Round up, so a small fee is still bumped by at least one wei.
+	if bumped.Cmp(oldFee) <= 0 {
+		bumped = arbmath.BigAddByUint(oldFee, 1)
+	}
+	if minFee != nil {
+		bumped = arbmath.BigMax(bumped, minFee)
+	}
+	return bumped
+}
+
+// capFee returns fee limited to maxFee, and whether that still bumps oldFee
+// enough for the replacement to be accepted.
+func capFee(fee *big.Int, maxFee *big.Int, oldFee *big.Int, bumpPercent uint64) (*big.Int, bool) {
+	if maxFee != nil {
+		fee = arbmath.BigMin(fee, maxFee)
+	}
+	return fee, fee.Cmp(bumpFee(oldFee, nil, bumpPercent)) >= 0
+}
+
+// feesOutbid returns whether the wallet's current fees are enough to replace tx
+// already, e.g. because they've been raised for a deadline.
+func (v *ValidatorWallet) feesOutbid(tx *types.Transaction, bumpPercent uint64) bool {
+	if v.fees == nil {
+		return false
+	}
+	if tx.Type() == types.LegacyTxType {
+		return v.fees.GasPrice != nil && v.fees.GasPrice.Cmp(bumpFee(tx.GasPrice(), nil, bumpPercent)) >= 0
+	}
+	return v.fees.GasFeeCap != nil && v.fees.GasTipCap != nil &&
+		v.fees.GasFeeCap.Cmp(bumpFee(tx.GasFeeCap(), nil, bumpPercent)) >= 0 &&
+		v.fees.GasTipCap.Cmp(bumpFee(tx.GasTipCap(), nil, bumpPercent)) >= 0
+}
+
+// replacementTransaction returns pending with its fees bumped, but no higher
+// than maxFees if given. It returns nil if that's too little to replace it.
+func (v *ValidatorWallet) replacementTransaction(ctx context.Context, pending *inFlightTx, bumpPercent uint64, maxFees *TxFees) (*types.Transaction, error) {
+	tx := pending.tx
+	var fees, limits TxFees
+	if v.fees != nil {
+		fees = *v.fees
+	}
+	if maxFees != nil {
+		limits = *maxFees
+	}
+	var inner types.TxData
+	if tx.Type() == types.LegacyTxType {
+		gasPrice, ok := capFee(bumpFee(tx.GasPrice(), fees.GasPrice, bumpPercent), limits.GasPrice, tx.GasPrice(), bumpPercent)
+		if !ok {
+			return nil, nil
+		}
+		inner = &types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	} else {
+		tipCap, tipOk := capFee(bumpFee(tx.GasTipCap(), fees.GasTipCap, bumpPercent), limits.GasTipCap, tx.GasTipCap(), bumpPercent)
+		feeCap, feeOk := capFee(bumpFee(tx.GasFeeCap(), fees.GasFeeCap, bumpPercent), limits.GasFeeCap, tx.GasFeeCap(), bumpPercent)
+		if !tipOk || !feeOk {
+			return nil, nil
+		}
+		inner = &types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tipCap,
+			GasFeeCap:  feeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	}
+	chainId, err := v.chainId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signCtx, cancel := context.WithTimeout(ctx, util.SignTxTimeout)
+	defer cancel()
+	return pending.signer.SignTx(signCtx, types.NewTx(inner), chainId)
+}
+
+// ReplaceStuckTransactions forgets the wallet's transactions that have been
+// mined, and replaces those that have gone unmined for replaceAfterBlocks (or
+// never if zero), or that the wallet's current fees outbid, with ones of the
+// same nonce paying bumpPercent more, or the wallet's current fees if higher.
+// Replacements pay no more than maxFees, if given, and transactions that can't
+// be bumped enough within them are left as they are. It returns the latest
+// transaction still in flight, if any.
+func (v *ValidatorWallet) ReplaceStuckTransactions(ctx context.Context, replaceAfterBlocks uint64, bumpPercent uint64, maxFees *TxFees) (*types.Transaction, error) {
+	if len(v.inFlight) == 0 {
+		return nil, nil
+	}
+	client := v.l1Reader.Client()
+	latestBlock, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Some version of each transaction below its sender's mined nonce was
+	// mined. The senders differ while the wallet's signer is being rotated.
+	minedNonces := make(map[common.Address]uint64)
+	stillInFlight := v.inFlight[:0]
+	for _, pending := range v.inFlight {
+		from := pending.signer.Address()
+		minedNonce, ok := minedNonces[from]
+		if !ok {
+			minedNonce, err = client.NonceAt(ctx, from, nil)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			minedNonces[from] = minedNonce
+		}
+		if pending.tx.Nonce() >= minedNonce {
+			stillInFlight = append(stillInFlight, pending)
+		}
+	}
+	v.inFlight = stillInFlight
+
+	var latest *types.Transaction
+	for _, pending := range v.inFlight {
+		stuck := replaceAfterBlocks > 0 && latestBlock >= pending.sentBlock+replaceAfterBlocks
+		if !stuck && !v.feesOutbid(pending.tx, bumpPercent) {
+			latest = pending.tx
+			continue
+		}
+		replacement, err := v.replacementTransaction(ctx, pending, bumpPercent, maxFees)
+		if err != nil {
+			return nil, err
+		}
+		if replacement == nil {
+			log.Warn(
+				"not replacing validator wallet transaction that hasn't been mined, as its fees are at the maximum",
+				"nonce", pending.tx.Nonce(),
+				"sentBlock", pending.sentBlock,
+				"tx", pending.tx.Hash(),
+			)
+			latest = pending.tx
+			continue
+		}
+		log.Warn(
+			"replacing validator wallet transaction that hasn't been mined",
+			"nonce", pending.tx.Nonce(),
+			"sentBlock", pending.sentBlock,
+			"oldTx", pending.tx.Hash(),
+			"newTx", replacement.Hash(),
+			"gasFeeCap", replacement.GasFeeCap(),
+			"gasTipCap", replacement.GasTipCap(),
+		)
+		if err := client.SendTransaction(ctx, replacement); err != nil {
+			if strings.Contains(err.Error(), "nonce too low") {
+				// It was mined after all; the next call will forget it.
+				latest = pending.tx
+				continue
+			}
+			return nil, errors.WithStack(err)
+		}
+		pending.tx = replacement
+		pending.sentBlock = latestBlock
+		latest = replacement
+	}
+	return latest, nil
+}
+
+// RotateSigner hands control of the wallet contract over to the account of
+// next, and sends the wallet's transactions from it once that's done. Using the
+// current account it makes next an executor and the owner, and then using next
+// it revokes the current account as an executor. Each step is a transaction
+// tracked like the wallet's others, which is returned, and the next step is
+// sent by calling this again once it's been mined. Steps already done are
+// skipped, so an interrupted rotation picks up where it left off. It returns
+// nil once the rotation is done. The wallet's transactions in flight must all
+// have been mined first.
+func (v *ValidatorWallet) RotateSigner(ctx context.Context, next util.Signer) (*types.Transaction, error) {
+	if v.signer == nil {
+		return nil, errors.New("cannot rotate validator wallet without key wallet")
+	}
+	current := v.signer
+	if current.Address() == next.Address() {
+		return nil, nil
+	}
+	if len(v.inFlight) != 0 {
+		return nil, errors.New("cannot rotate validator wallet with transactions in flight")
+	}
+	if err := v.populateWallet(ctx, false); err != nil {
+		return nil, err
+	}
+	if v.con != nil {
+		tx, err := v.handOverWallet(ctx, current, next)
+		if err != nil || tx != nil {
+			return tx, err
+		}
+	}
+	v.signer = next
+	log.Info("rotated validator wallet signer", "wallet", v.address, "from", current.Address(), "to", next.Address())
+	return nil, nil
+}
+
+// handOverWallet sends the first step of handing the wallet contract over from
+// current to next that hasn't been done yet, or returns nil if they all have.
+func (v *ValidatorWallet) handOverWallet(ctx context.Context, current util.Signer, next util.Signer) (*types.Transaction, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+	send := func(signer util.Signer, sendTx func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
+		opts, err := v.transactOptsFor(ctx, signer, nil)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := sendTx(opts)
+		if err != nil {
+			return nil, err
+		}
+		v.trackTransaction(ctx, signer, tx)
+		return tx, nil
+	}
+	isExecutor, err := v.con.Executors(callOpts, next.Address())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !isExecutor {
+		tx, err := send(current, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return v.con.SetExecutor(opts, []common.Address{next.Address()}, []bool{true})
+		})
+		return tx, errors.Wrap(err, "failed to make new signer a validator wallet executor")
+	}
+	owner, err := v.con.Owner(callOpts)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if owner == current.Address() {
+		tx, err := send(current, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return v.con.TransferOwnership(opts, next.Address())
+		})
+		return tx, errors.Wrap(err, "failed to transfer validator wallet ownership to new signer")
+	}
+	if owner != next.Address() {
+		// Only the owner can revoke executors.
+		log.Warn("validator wallet owned by another account, leaving previous signer as an executor", "owner", owner, "previous", current.Address())
+		return nil, nil
+	}
+	wasExecutor, err := v.con.Executors(callOpts, current.Address())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if wasExecutor {
+		tx, err := send(next, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return v.con.SetExecutor(opts, []common.Address{current.Address()}, []bool{false})
+		})
+		return tx, errors.Wrap(err, "failed to revoke previous signer as a validator wallet executor")
+	}
+	return nil, nil
 }
 
 func GetValidatorWallet(
 	ctx context.Context,
 	validatorWalletFactoryAddr common.Address,
 	fromBlock int64,
-	transactAuth *bind.TransactOpts,
+	signer util.Signer,
 	l1Reader L1ReaderInterface,
 	createIfMissing bool,
 ) (*common.Address, error) {
@@ -226,7 +568,7 @@ func GetValidatorWallet(
 		FromBlock: big.NewInt(fromBlock),
 		ToBlock:   nil,
 		Addresses: []common.Address{validatorWalletFactoryAddr},
-		Topics:    [][]common.Hash{{walletCreatedID}, nil, {transactAuth.From.Hash()}},
+		Topics:    [][]common.Hash{{walletCreatedID}, nil, {signer.Address().Hash()}},
 	}
 	logs, err := client.FilterLogs(ctx, query)
 	if err != nil {
@@ -248,8 +590,12 @@ func GetValidatorWallet(
 		return nil, nil
 	}
 
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	var initialExecutorAllowedDests []common.Address
-	tx, err := walletCreator.CreateWallet(transactAuth, initialExecutorAllowedDests)
+	tx, err := walletCreator.CreateWallet(util.TransactOpts(ctx, signer, chainId), initialExecutorAllowedDests)
 	if err != nil {
 		return nil, err
 	}
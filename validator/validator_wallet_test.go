@@ -0,0 +1,235 @@
+// Copyright 2021-2022, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package validator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/util"
+	"github.com/offchainlabs/nitro/solgen/go/rollupgen"
+)
+
+const simulatedL1ChainId = 1337
+
+// simulatedL1 adds the methods of a real L1 client the wallet uses to a
+// simulated backend.
+type simulatedL1 struct {
+	*backends.SimulatedBackend
+}
+
+func (c *simulatedL1) BlockNumber(ctx context.Context) (uint64, error) {
+	header, err := c.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+func (c *simulatedL1) ChainID(context.Context) (*big.Int, error) {
+	return big.NewInt(simulatedL1ChainId), nil
+}
+
+func (c *simulatedL1) TransactionSender(_ context.Context, tx *types.Transaction, _ common.Hash, _ uint) (common.Address, error) {
+	return types.Sender(types.LatestSignerForChainID(big.NewInt(simulatedL1ChainId)), tx)
+}
+
+// simulatedL1Reader mines a block whenever a transaction is waited on.
+type simulatedL1Reader struct {
+	client *simulatedL1
+}
+
+func (r *simulatedL1Reader) Client() arbutil.L1Interface {
+	return r.client
+}
+
+func (r *simulatedL1Reader) Subscribe(bool) (<-chan *types.Header, func()) {
+	return make(chan *types.Header), func() {}
+}
+
+func (r *simulatedL1Reader) WaitForTxApproval(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	r.client.Commit()
+	receipt, err := r.client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, errors.New("transaction failed")
+	}
+	return receipt, nil
+}
+
+type walletTestKeys struct {
+	current *ecdsa.PrivateKey
+	next    *ecdsa.PrivateKey
+}
+
+// newWalletTestL1 returns a simulated L1 with a validator wallet creator
+// deployed, where the keys are funded.
+func newWalletTestL1(t *testing.T) (*simulatedL1Reader, common.Address, walletTestKeys) {
+	t.Helper()
+	var keys []*ecdsa.PrivateKey
+	alloc := make(core.GenesisAlloc)
+	for i := 0; i < 3; i++ {
+		key, err := crypto.GenerateKey()
+		Require(t, err)
+		keys = append(keys, key)
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = core.GenesisAccount{Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)}
+	}
+	client := &simulatedL1{backends.NewSimulatedBackend(alloc, 1_000_000_000)}
+	t.Cleanup(func() { _ = client.Close() })
+	client.Commit()
+	deployer, err := bind.NewKeyedTransactorWithChainID(keys[0], big.NewInt(simulatedL1ChainId))
+	Require(t, err)
+	factory, _, _, err := rollupgen.DeployValidatorWalletCreator(deployer, client)
+	Require(t, err)
+	client.Commit()
+	return &simulatedL1Reader{client}, factory, walletTestKeys{current: keys[1], next: keys[2]}
+}
+
+func newTestWallet(t *testing.T, reader *simulatedL1Reader, factory common.Address, key *ecdsa.PrivateKey) *ValidatorWallet {
+	t.Helper()
+	wallet, err := NewValidatorWallet(nil, factory, common.Address{}, reader, util.NewPrivateKeySigner(key), 0, nil)
+	Require(t, err)
+	Require(t, wallet.populateWallet(context.Background(), true))
+	return wallet
+}
+
+// rotateWallet rotates the wallet's signer to next as the staker does, mining
+// each transaction it sends, and returns the senders of the steps it took. It
+// stops early after maxSteps steps.
+func rotateWallet(t *testing.T, reader *simulatedL1Reader, wallet *ValidatorWallet, next util.Signer, maxSteps int) []common.Address {
+	t.Helper()
+	ctx := context.Background()
+	var senders []common.Address
+	for {
+		inFlight, err := wallet.ReplaceStuckTransactions(ctx, 0, 10, nil)
+		Require(t, err)
+		if inFlight != nil {
+			reader.client.Commit()
+			continue
+		}
+		if len(senders) == maxSteps {
+			return senders
+		}
+		tx, err := wallet.RotateSigner(ctx, next)
+		Require(t, err)
+		if tx == nil {
+			return senders
+		}
+		senders = append(senders, requireSucceeded(t, reader, tx))
+		if len(senders) > 3 {
+			Fail(t, "rotation took more than 3 steps")
+		}
+	}
+}
+
+// requireSucceeded mines tx and checks it succeeded, returning its sender.
+func requireSucceeded(t *testing.T, reader *simulatedL1Reader, tx *types.Transaction) common.Address {
+	t.Helper()
+	reader.client.Commit()
+	receipt, err := reader.client.TransactionReceipt(context.Background(), tx.Hash())
+	Require(t, err)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		Fail(t, "transaction", tx.Hash(), "failed")
+	}
+	sender, err := reader.client.TransactionSender(context.Background(), tx, receipt.BlockHash, receipt.TransactionIndex)
+	Require(t, err)
+	return sender
+}
+
+func requireSenders(t *testing.T, senders []common.Address, expected ...common.Address) {
+	t.Helper()
+	if len(senders) != len(expected) {
+		Fail(t, "rotation sent transactions from", senders, "expected", expected)
+	}
+	for i := range senders {
+		if senders[i] != expected[i] {
+			Fail(t, "rotation sent transactions from", senders, "expected", expected)
+		}
+	}
+}
+
+func requireHandedOver(t *testing.T, wallet *ValidatorWallet, previous common.Address, next common.Address) {
+	t.Helper()
+	callOpts := &bind.CallOpts{}
+	isExecutor, err := wallet.con.Executors(callOpts, next)
+	Require(t, err)
+	if !isExecutor {
+		Fail(t, "new signer isn't an executor")
+	}
+	owner, err := wallet.con.Owner(callOpts)
+	Require(t, err)
+	if owner != next {
+		Fail(t, "wallet owned by", owner, "instead of new signer", next)
+	}
+	wasExecutor, err := wallet.con.Executors(callOpts, previous)
+	Require(t, err)
+	if wasExecutor {
+		Fail(t, "previous signer is still an executor")
+	}
+	if wallet.From() != next {
+		Fail(t, "wallet sends from", wallet.From(), "instead of new signer", next)
+	}
+}
+
+func TestRotateValidatorWalletSigner(t *testing.T) {
+	ctx := context.Background()
+	reader, factory, keys := newWalletTestL1(t)
+	current := crypto.PubkeyToAddress(keys.current.PublicKey)
+	next := util.NewPrivateKeySigner(keys.next)
+	wallet := newTestWallet(t, reader, factory, keys.current)
+
+	senders := rotateWallet(t, reader, wallet, next, -1)
+	requireSenders(t, senders, current, current, next.Address())
+	requireHandedOver(t, wallet, current, next.Address())
+	// Rotating again has nothing left to do.
+	requireSenders(t, rotateWallet(t, reader, wallet, next, -1))
+
+	// The wallet acts from the new signer, which the old one no longer can.
+	tx, err := wallet.TimeoutChallenges(ctx, common.Address{}, nil)
+	Require(t, err)
+	if sender := requireSucceeded(t, reader, tx); sender != next.Address() {
+		Fail(t, "wallet acted from", sender, "instead of new signer", next.Address())
+	}
+	oldAuth, err := bind.NewKeyedTransactorWithChainID(keys.current, big.NewInt(simulatedL1ChainId))
+	Require(t, err)
+	if _, err := wallet.con.TimeoutChallenges(oldAuth, common.Address{}, nil); err == nil {
+		Fail(t, "previous signer could still act through the wallet")
+	}
+}
+
+func TestResumeValidatorWalletRotation(t *testing.T) {
+	for interruptAfter := 1; interruptAfter < 3; interruptAfter++ {
+		reader, factory, keys := newWalletTestL1(t)
+		current := crypto.PubkeyToAddress(keys.current.PublicKey)
+		next := util.NewPrivateKeySigner(keys.next)
+		wallet := newTestWallet(t, reader, factory, keys.current)
+		senders := rotateWallet(t, reader, wallet, next, interruptAfter)
+		if wallet.From() != current {
+			Fail(t, "interrupted rotation switched the wallet's signer")
+		}
+
+		// Restarting with the old key finds the same wallet and resumes the
+		// rotation from the step it was interrupted at.
+		restarted := newTestWallet(t, reader, factory, keys.current)
+		if *restarted.Address() != *wallet.Address() {
+			Fail(t, "found wallet", restarted.Address(), "instead of", wallet.Address())
+		}
+		senders = append(senders, rotateWallet(t, reader, restarted, next, -1)...)
+		requireSenders(t, senders, current, current, next.Address())
+		requireHandedOver(t, restarted, current, next.Address())
+	}
+}